@@ -4,7 +4,6 @@ import (
 	"database/sql"
 	"os"
 	"path/filepath"
-	"time"
 
 	"testing"
 
@@ -35,11 +34,7 @@ func InitDB() error {
 	}
 
 	// Configure database connection
-	DB.SetMaxOpenConns(5) // Increase from 1 to 5
-	DB.SetMaxIdleConns(5) // Increase from 1 to 5
-
-	// Add this line
-	DB.SetConnMaxLifetime(time.Minute * 5)
+	configurePool(DB)
 
 	// Execute PRAGMA statements for better concurrency handling
 	_, err = DB.Exec("PRAGMA journal_mode=WAL;")
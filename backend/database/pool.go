@@ -0,0 +1,76 @@
+package database
+
+import (
+	"database/sql"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Connection pool defaults. Override with DB_MAX_OPEN_CONNS,
+// DB_MAX_IDLE_CONNS and DB_CONN_MAX_LIFETIME (a duration such as "5m").
+const (
+	defaultMaxOpenConns    = 5
+	defaultMaxIdleConns    = 5
+	defaultConnMaxLifetime = 5 * time.Minute
+)
+
+// PoolSettings holds the connection pool limits applied to the database
+type PoolSettings struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// poolSettingsFromEnv reads the pool settings from the environment, falling
+// back to the defaults for unset or invalid values
+func poolSettingsFromEnv() PoolSettings {
+	settings := PoolSettings{
+		MaxOpenConns:    defaultMaxOpenConns,
+		MaxIdleConns:    defaultMaxIdleConns,
+		ConnMaxLifetime: defaultConnMaxLifetime,
+	}
+
+	if value := os.Getenv("DB_MAX_OPEN_CONNS"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			settings.MaxOpenConns = parsed
+		} else {
+			log.Printf("Warning: ignoring invalid DB_MAX_OPEN_CONNS %q", value)
+		}
+	}
+
+	if value := os.Getenv("DB_MAX_IDLE_CONNS"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed >= 0 {
+			settings.MaxIdleConns = parsed
+		} else {
+			log.Printf("Warning: ignoring invalid DB_MAX_IDLE_CONNS %q", value)
+		}
+	}
+
+	if value := os.Getenv("DB_CONN_MAX_LIFETIME"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+			settings.ConnMaxLifetime = parsed
+		} else {
+			log.Printf("Warning: ignoring invalid DB_CONN_MAX_LIFETIME %q", value)
+		}
+	}
+
+	// Idle connections beyond the open limit would be closed immediately anyway
+	if settings.MaxIdleConns > settings.MaxOpenConns {
+		settings.MaxIdleConns = settings.MaxOpenConns
+	}
+
+	return settings
+}
+
+// configurePool applies the configured pool settings to db
+func configurePool(db *sql.DB) PoolSettings {
+	settings := poolSettingsFromEnv()
+	db.SetMaxOpenConns(settings.MaxOpenConns)
+	db.SetMaxIdleConns(settings.MaxIdleConns)
+	db.SetConnMaxLifetime(settings.ConnMaxLifetime)
+	log.Printf("Database pool: max open %d, max idle %d, max lifetime %s",
+		settings.MaxOpenConns, settings.MaxIdleConns, settings.ConnMaxLifetime)
+	return settings
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestPoolSettingsFromEnv(t *testing.T) {
+	t.Setenv("DB_MAX_OPEN_CONNS", "3")
+	t.Setenv("DB_MAX_IDLE_CONNS", "2")
+	t.Setenv("DB_CONN_MAX_LIFETIME", "90s")
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Error opening database: %v", err)
+	}
+	defer db.Close()
+
+	settings := configurePool(db)
+	if settings.MaxOpenConns != 3 || settings.MaxIdleConns != 2 || settings.ConnMaxLifetime != 90*time.Second {
+		t.Errorf("Unexpected pool settings: %+v", settings)
+	}
+	if got := db.Stats().MaxOpenConnections; got != 3 {
+		t.Errorf("Expected the database to allow 3 open connections, got %d", got)
+	}
+}
+
+func TestPoolSettingsFromEnvDefaults(t *testing.T) {
+	t.Setenv("DB_MAX_OPEN_CONNS", "lots")
+	t.Setenv("DB_MAX_IDLE_CONNS", "")
+	t.Setenv("DB_CONN_MAX_LIFETIME", "-1m")
+
+	settings := poolSettingsFromEnv()
+	if settings.MaxOpenConns != defaultMaxOpenConns || settings.MaxIdleConns != defaultMaxIdleConns ||
+		settings.ConnMaxLifetime != defaultConnMaxLifetime {
+		t.Errorf("Expected defaults for invalid values, got %+v", settings)
+	}
+
+	// The idle limit never exceeds the open limit
+	t.Setenv("DB_MAX_OPEN_CONNS", "2")
+	if settings := poolSettingsFromEnv(); settings.MaxIdleConns != 2 {
+		t.Errorf("Expected max idle to be capped at 2, got %d", settings.MaxIdleConns)
+	}
+}
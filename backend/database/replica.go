@@ -0,0 +1,57 @@
+package database
+
+import (
+	"database/sql"
+	"log"
+	"os"
+)
+
+// readReplica is the optional connection read-only queries are sent to. When
+// it is nil, reads go to the primary DB.
+var readReplica *sql.DB
+
+// ReadDB returns the connection for read-only queries: the read replica when
+// DATABASE_READ_URL is configured, otherwise the primary
+func ReadDB() *sql.DB {
+	if readReplica != nil {
+		return readReplica
+	}
+	return DB
+}
+
+// WriteDB returns the primary connection, which every write must use
+func WriteDB() *sql.DB {
+	return DB
+}
+
+// SetReadDB routes read-only queries to db. Passing nil sends them back to
+// the primary.
+func SetReadDB(db *sql.DB) {
+	readReplica = db
+}
+
+// InitReadDB opens the read replica named by DATABASE_READ_URL, a SQLite DSN
+// such as the path of a replicated database file. Without it, reads keep
+// using the primary.
+func InitReadDB() error {
+	dsn := os.Getenv("DATABASE_READ_URL")
+	if dsn == "" {
+		SetReadDB(nil)
+		return nil
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return err
+	}
+	configurePool(db)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return err
+	}
+
+	log.Println("Routing read-only queries to the read replica from DATABASE_READ_URL")
+	SetReadDB(db)
+	return nil
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestReadDBFallsBackToPrimary(t *testing.T) {
+	t.Setenv("DATABASE_READ_URL", "")
+
+	if err := InitReadDB(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ReadDB() != DB {
+		t.Error("Expected reads to use the primary without DATABASE_READ_URL")
+	}
+	if WriteDB() != DB {
+		t.Error("Expected writes to use the primary")
+	}
+}
+
+func TestReadDBUsesReplica(t *testing.T) {
+	t.Setenv("DATABASE_READ_URL", filepath.Join(t.TempDir(), "replica.db"))
+
+	if err := InitReadDB(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	replica := ReadDB()
+	defer func() {
+		SetReadDB(nil)
+		replica.Close()
+	}()
+
+	if replica == DB {
+		t.Fatal("Expected reads to use the read replica")
+	}
+	if WriteDB() != DB {
+		t.Error("Expected writes to keep using the primary")
+	}
+
+	// Data written through the replica handle stays out of the primary
+	if _, err := replica.Exec("CREATE TABLE replica_only (id INTEGER)"); err != nil {
+		t.Fatalf("Failed to use the replica: %v", err)
+	}
+	var count int
+	DB.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'replica_only'").Scan(&count)
+	if count != 0 {
+		t.Error("Expected the replica to be a separate database")
+	}
+
+	SetReadDB(nil)
+	if ReadDB() != DB {
+		t.Error("Expected reads to go back to the primary")
+	}
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"log"
+	"strconv"
+	"time"
+)
+
+// defaultResetGracePeriod is how long startup waits before a reset outside PR
+// deployments, leaving time to abort with Ctrl-C. Override with
+// RESET_DB_GRACE_SECONDS.
+const defaultResetGracePeriod = 10 * time.Second
+
+// ResetPlan describes whether and how startup resets the database
+type ResetPlan struct {
+	Reset       bool          // Reset and reseed the database, then exit
+	DeleteFile  bool          // Delete the database file first
+	GracePeriod time.Duration // Wait this long before resetting
+}
+
+// ResetPlanFromEnv decides how to reset the database from the environment.
+// Nothing is reset unless RESET_DB=true is set explicitly. PR deployments
+// also delete the database file and skip the grace period, as nobody is
+// watching them start.
+func ResetPlanFromEnv(getenv func(string) string) ResetPlan {
+	var plan ResetPlan
+	if getenv("RESET_DB") != "true" {
+		return plan
+	}
+	plan.Reset = true
+
+	prDeployment := getenv("PR_DEPLOYMENT") == "true"
+	plan.DeleteFile = prDeployment
+
+	plan.GracePeriod = defaultResetGracePeriod
+	if prDeployment {
+		plan.GracePeriod = 0
+	}
+	if value := getenv("RESET_DB_GRACE_SECONDS"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			plan.GracePeriod = time.Duration(seconds) * time.Second
+		} else {
+			log.Printf("Warning: ignoring invalid RESET_DB_GRACE_SECONDS %q", value)
+		}
+	}
+
+	return plan
+}
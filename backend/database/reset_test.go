@@ -0,0 +1,40 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResetPlanFromEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected ResetPlan
+	}{
+		{"nothing set", map[string]string{}, ResetPlan{}},
+		{"development without RESET_DB", map[string]string{"APP_ENV": "development"}, ResetPlan{}},
+		{"PR deployment without RESET_DB", map[string]string{"PR_DEPLOYMENT": "true"}, ResetPlan{}},
+		{"RESET_DB not exactly true", map[string]string{"RESET_DB": "1"}, ResetPlan{}},
+		{"explicit reset", map[string]string{"RESET_DB": "true"},
+			ResetPlan{Reset: true, GracePeriod: defaultResetGracePeriod}},
+		{"PR deployment reset", map[string]string{"RESET_DB": "true", "PR_DEPLOYMENT": "true"},
+			ResetPlan{Reset: true, DeleteFile: true}},
+		{"custom grace period", map[string]string{"RESET_DB": "true", "RESET_DB_GRACE_SECONDS": "3"},
+			ResetPlan{Reset: true, GracePeriod: 3 * time.Second}},
+		{"grace period disabled", map[string]string{"RESET_DB": "true", "RESET_DB_GRACE_SECONDS": "0"},
+			ResetPlan{Reset: true}},
+		{"PR deployment with grace period", map[string]string{"RESET_DB": "true", "PR_DEPLOYMENT": "true", "RESET_DB_GRACE_SECONDS": "5"},
+			ResetPlan{Reset: true, DeleteFile: true, GracePeriod: 5 * time.Second}},
+		{"invalid grace period", map[string]string{"RESET_DB": "true", "RESET_DB_GRACE_SECONDS": "-2"},
+			ResetPlan{Reset: true, GracePeriod: defaultResetGracePeriod}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := ResetPlanFromEnv(func(key string) string { return tt.env[key] })
+			if plan != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, plan)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// GetActivitySummary returns an overview of the caller's own transactions,
+// categories and YNAB sync status
+func GetActivitySummary(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	var summary models.ActivitySummary
+
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	month := DateRange{Start: monthStart, End: monthStart.AddDate(0, 1, -1)}
+	dateClause, dateArgs := month.SQLConditions("date")
+
+	err := database.DB.QueryRow(
+		"SELECT COUNT(*) FROM transactions WHERE userId = ? AND deleted_at IS NULL"+dateClause,
+		append([]interface{}{userID}, dateArgs...)...,
+	).Scan(&summary.TransactionsThisMonth)
+	if err != nil {
+		log.Printf("Error counting transactions this month: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = database.DB.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(amount), 0)
+		FROM transactions
+		WHERE userId = ? AND paid = 0 AND deleted_at IS NULL
+	`, userID).Scan(&summary.PendingReimbursements, &summary.PendingReimbursementTotal)
+	if err != nil {
+		log.Printf("Error counting pending reimbursements: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = database.DB.QueryRow(`
+		SELECT COUNT(*) FROM categories
+		WHERE user_id = ? AND archived = 0 AND deleted_at IS NULL
+	`, userID).Scan(&summary.Categories)
+	if err != nil {
+		log.Printf("Error counting categories: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	summary.LastSyncTime = lastYNABSyncTime(userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// lastYNABSyncTime returns when the user last synced with YNAB, checking the
+// legacy settings table when there is no ynab_config row
+func lastYNABSyncTime(userID string) *time.Time {
+	var lastSync sql.NullTime
+	err := database.DB.QueryRow("SELECT last_sync_time FROM ynab_config WHERE user_id = ?", userID).Scan(&lastSync)
+	if err == sql.ErrNoRows {
+		err = database.DB.QueryRow("SELECT last_synced FROM user_ynab_settings WHERE user_id = ?", userID).Scan(&lastSync)
+	}
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Error reading last YNAB sync time for user %s: %v", userID, err)
+	}
+	if !lastSync.Valid {
+		return nil
+	}
+	return &lastSync.Time
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+func TestGetActivitySummary(t *testing.T) {
+	setupTransactionCategoryTestDB()
+	defer CleanupTestDB()
+	if err := ensureYNABConfigTable(database.DB); err != nil {
+		t.Fatalf("Failed to create ynab_config table: %v", err)
+	}
+
+	now := time.Now()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 12, 0, 0, 0, time.UTC)
+	lastMonth := thisMonth.AddDate(0, -1, 0)
+	lastSync := time.Date(2024, time.June, 1, 8, 30, 0, 0, time.UTC)
+
+	for _, stmt := range []struct {
+		query string
+		args  []interface{}
+	}{
+		{`INSERT INTO transactions (id, amount, description, date, type, paid, enteredBy, userId) VALUES
+			('paid-this-month', 10, 'A', ?, 'Food', 1, 'me', ?),
+			('unpaid-this-month', 25.5, 'B', ?, 'Food', 0, 'me', ?),
+			('unpaid-last-month', 40, 'C', ?, 'Food', 0, 'me', ?),
+			('someone-else', 99, 'D', ?, 'Food', 0, 'them', 'other-user')`,
+			[]interface{}{thisMonth, TestUserID, thisMonth, TestUserID, lastMonth, TestUserID, thisMonth}},
+		{`INSERT INTO categories (name, user_id, archived, deleted_at) VALUES
+			('Food', ?, 0, NULL), ('Rent', ?, 0, NULL), ('Old', ?, 1, NULL), ('Gone', ?, 0, ?)`,
+			[]interface{}{TestUserID, TestUserID, TestUserID, TestUserID, now}},
+		{`INSERT INTO ynab_config (user_id, last_sync_time) VALUES (?, ?)`,
+			[]interface{}{TestUserID, lastSync}},
+	} {
+		if _, err := database.DB.Exec(stmt.query, stmt.args...); err != nil {
+			t.Fatalf("Failed to seed data: %v", err)
+		}
+	}
+
+	req := TestRequest("GET", "/me/activity-summary", nil)
+	w := httptest.NewRecorder()
+	GetActivitySummary(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var summary models.ActivitySummary
+	if err := json.NewDecoder(w.Body).Decode(&summary); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	if summary.TransactionsThisMonth != 2 {
+		t.Errorf("Expected 2 transactions this month, got %d", summary.TransactionsThisMonth)
+	}
+	if summary.Categories != 2 {
+		t.Errorf("Expected 2 active categories, got %d", summary.Categories)
+	}
+	if summary.PendingReimbursements != 2 || summary.PendingReimbursementTotal != 65.5 {
+		t.Errorf("Expected 2 pending reimbursements totalling 65.5, got %d totalling %v",
+			summary.PendingReimbursements, summary.PendingReimbursementTotal)
+	}
+	if summary.LastSyncTime == nil || !summary.LastSyncTime.Equal(lastSync) {
+		t.Errorf("Expected last sync time %v, got %v", lastSync, summary.LastSyncTime)
+	}
+}
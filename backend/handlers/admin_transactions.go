@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// GetAllTransactions returns every user's transactions, newest first and
+// paginated, each with its owner's name. It ignores the per-user access
+// filter, so it is limited to superadmins.
+func GetAllTransactions(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	isSuperAdmin, err := middleware.IsUserSuperAdmin(userID)
+	if err != nil {
+		log.Printf("Error checking superadmin status: %v", err)
+		http.Error(w, "Error checking permissions", http.StatusInternalServerError)
+		return
+	}
+	if !isSuperAdmin {
+		http.Error(w, "Forbidden: superadmin access required", http.StatusForbidden)
+		return
+	}
+
+	page, pageSize, err := parsePagination(r, pageResourceTransactions)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result := models.TransactionPage{
+		Transactions: []models.Transaction{},
+		Page:         page,
+		PageSize:     pageSize,
+	}
+
+	if err := database.DB.QueryRow("SELECT COUNT(*) FROM transactions WHERE deleted_at IS NULL").Scan(&result.Total); err != nil {
+		log.Printf("Error counting transactions: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional, userId,
+			source, status, `+ownerNameColumn+`
+		FROM transactions
+		WHERE deleted_at IS NULL
+		ORDER BY date DESC, id
+		LIMIT ? OFFSET ?
+	`, pageSize, (page-1)*pageSize)
+	if err != nil {
+		log.Printf("Error querying all transactions: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t models.Transaction
+		var payTo, paidDate, ownerID, ownerName sql.NullString
+		var transactionDate sql.NullTime
+		err := rows.Scan(&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate, &t.Type, &payTo,
+			&t.Paid, &paidDate, &t.EnteredBy, &t.Optional, &ownerID, &t.Source, &t.Status, &ownerName)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		t.PayTo = payTo.String
+		t.PaidDate = paidDate.String
+		t.UserID = ownerID.String
+		t.OwnerName = ownerName.String
+		if transactionDate.Valid {
+			t.TransactionDate = transactionDate.Time
+		} else {
+			t.TransactionDate = t.Date
+		}
+		result.Transactions = append(result.Transactions, t)
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
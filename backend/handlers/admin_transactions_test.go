@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+func getAllTransactions(t *testing.T, url string) models.TransactionPage {
+	req := TestRequest("GET", url, nil)
+	w := httptest.NewRecorder()
+	GetAllTransactions(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var page models.TransactionPage
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	return page
+}
+
+func TestGetAllTransactions(t *testing.T) {
+	setupTransactionCategoryTestDB()
+	defer CleanupTestDB()
+
+	for _, stmt := range []string{
+		`UPDATE users SET role = 'superadmin' WHERE id = 'test-user-id'`,
+		`INSERT INTO users (id, username, name, isAdmin, role) VALUES ('partner', 'partner', 'Partner', 0, 'user')`,
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to seed users: %v", err)
+		}
+	}
+
+	base := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	insertTestTransaction(t, "mine", 10, base, TestUserID)
+	insertTestTransaction(t, "theirs", 20, base.AddDate(0, 0, 1), "partner")
+	insertTestTransaction(t, "removed", 30, base.AddDate(0, 0, 2), "partner")
+	database.DB.Exec("UPDATE transactions SET deleted_at = CURRENT_TIMESTAMP WHERE id = 'removed'")
+
+	// No permission links the users, yet both users' transactions are listed
+	page := getAllTransactions(t, "/admin/transactions")
+	if page.Total != 2 || len(page.Transactions) != 2 {
+		t.Fatalf("Expected 2 transactions, got total=%d len=%d", page.Total, len(page.Transactions))
+	}
+	if page.Transactions[0].ID != "theirs" || page.Transactions[0].OwnerName != "Partner" {
+		t.Errorf("Expected the partner's transaction first with its owner, got %+v", page.Transactions[0])
+	}
+	if page.Transactions[1].ID != "mine" || page.Transactions[1].OwnerName != "Test User" {
+		t.Errorf("Expected the caller's transaction second with its owner, got %+v", page.Transactions[1])
+	}
+
+	// Pagination keeps the total but limits the page
+	page = getAllTransactions(t, "/admin/transactions?page=2&pageSize=1")
+	if page.Total != 2 || len(page.Transactions) != 1 || page.Transactions[0].ID != "mine" {
+		t.Errorf("Unexpected second page: %+v", page)
+	}
+}
+
+func TestGetAllTransactionsRequiresSuperadmin(t *testing.T) {
+	setupTransactionCategoryTestDB()
+	defer CleanupTestDB()
+
+	// The test user is an admin, which is not enough
+	req := TestRequest("GET", "/admin/transactions", nil)
+	w := httptest.NewRecorder()
+	GetAllTransactions(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", http.StatusForbidden, w.Code)
+	}
+}
@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+// GetCategorizationRules returns the user's categorization rules in the order
+// they are tried, highest priority first
+func GetCategorizationRules(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	rules, err := loadCategorizationRules(userID)
+	if err != nil {
+		log.Printf("Error querying categorization rules: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// AddCategorizationRule creates a categorization rule for one of the user's
+// categories
+func AddCategorizationRule(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	rule, status, err := decodeCategorizationRule(r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	result, err := database.DB.Exec(`
+		INSERT INTO categorization_rules (user_id, match_field, match_op, match_value, category_id, priority)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, rule.UserID, rule.MatchField, rule.MatchOp, rule.MatchValue, rule.CategoryID, rule.Priority)
+	if err != nil {
+		log.Printf("Error inserting categorization rule: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rule.ID = int(id)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+// UpdateCategorizationRule replaces one of the user's categorization rules
+func UpdateCategorizationRule(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid rule ID", http.StatusBadRequest)
+		return
+	}
+
+	rule, status, err := decodeCategorizationRule(r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	rule.ID = id
+
+	result, err := database.DB.Exec(`
+		UPDATE categorization_rules
+		SET match_field = ?, match_op = ?, match_value = ?, category_id = ?, priority = ?
+		WHERE id = ? AND user_id = ?
+	`, rule.MatchField, rule.MatchOp, rule.MatchValue, rule.CategoryID, rule.Priority, rule.ID, userID)
+	if err != nil {
+		log.Printf("Error updating categorization rule %d: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		http.Error(w, "Categorization rule not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// DeleteCategorizationRule removes one of the user's categorization rules
+func DeleteCategorizationRule(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+
+	result, err := database.DB.Exec("DELETE FROM categorization_rules WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		http.Error(w, "Categorization rule not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ApplyCategorizationRules runs the caller's categorization rules over their
+// existing transactions and assigns each matching transaction to its rule's
+// category. By default only uncategorized transactions are considered; with
+// ?onlyUncategorized=false a matching rule also replaces existing categories.
+// Locked transactions are left alone.
+func ApplyCategorizationRules(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	onlyUncategorized := true
+	if value := r.URL.Query().Get("onlyUncategorized"); value != "" {
+		var err error
+		onlyUncategorized, err = strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Invalid onlyUncategorized: expected true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	rules, err := loadCategorizationRules(userID)
+	if err != nil {
+		log.Printf("Error querying categorization rules: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	query := `
+		SELECT t.id, t.amount, t.description, COALESCE(t.payTo, ''), t.enteredBy
+		FROM transactions t
+		WHERE t.userId = ? AND t.deleted_at IS NULL
+	`
+	if onlyUncategorized {
+		query += " AND NOT EXISTS (SELECT 1 FROM transaction_categories tc WHERE tc.transaction_id = t.id)"
+	}
+	if transactionsHaveColumn("locked") {
+		query += " AND NOT t.locked"
+	}
+
+	rows, err := database.DB.Query(query, userID)
+	if err != nil {
+		log.Printf("Error querying transactions to categorize: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var transactions []models.Transaction
+	for rows.Next() {
+		var t models.Transaction
+		if err := rows.Scan(&t.ID, &t.Amount, &t.Description, &t.PayTo, &t.EnteredBy); err != nil {
+			rows.Close()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		transactions = append(transactions, t)
+	}
+	rows.Close()
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	result := models.ApplyRulesResult{Checked: len(transactions)}
+	for _, t := range transactions {
+		rule := firstMatchingRule(rules, t)
+		if rule == nil {
+			continue
+		}
+		if _, err := tx.Exec("DELETE FROM transaction_categories WHERE transaction_id = ?", t.ID); err != nil {
+			log.Printf("Error clearing categories of transaction %s: %v", t.ID, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, err := tx.Exec(`
+			INSERT INTO transaction_categories (transaction_id, category_id, amount)
+			VALUES (?, ?, ?)
+		`, t.ID, rule.CategoryID, t.Amount)
+		if err != nil {
+			log.Printf("Error applying categorization rule %d to transaction %s: %v", rule.ID, t.ID, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result.Categorized++
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Categorized %d of %d transactions for user %s", result.Categorized, result.Checked, userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// decodeCategorizationRule reads a rule from the request body for the user
+// and validates it, including that the category is one of theirs. On failure
+// it also returns the HTTP status to respond with.
+func decodeCategorizationRule(r *http.Request, userID string) (models.CategorizationRule, int, error) {
+	var rule models.CategorizationRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		return rule, http.StatusBadRequest, fmt.Errorf("Invalid request body")
+	}
+	rule.UserID = userID
+
+	if err := rule.Validate(); err != nil {
+		return rule, http.StatusBadRequest, err
+	}
+
+	err := database.DB.QueryRow(`
+		SELECT name FROM categories
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+	`, rule.CategoryID, userID).Scan(&rule.CategoryName)
+	if err == sql.ErrNoRows {
+		return rule, http.StatusBadRequest, fmt.Errorf("Category %d not found", rule.CategoryID)
+	} else if err != nil {
+		log.Printf("Error checking category %d: %v", rule.CategoryID, err)
+		return rule, http.StatusInternalServerError, err
+	}
+	return rule, http.StatusOK, nil
+}
+
+// loadCategorizationRules reads a user's rules, highest priority first. Among
+// rules of equal priority the oldest comes first. Rules whose category has
+// been deleted have no category name.
+func loadCategorizationRules(userID string) ([]models.CategorizationRule, error) {
+	rows, err := database.DB.Query(`
+		SELECT r.id, r.user_id, r.match_field, r.match_op, r.match_value, r.category_id, COALESCE(c.name, ''), r.priority
+		FROM categorization_rules r
+		LEFT JOIN categories c ON c.id = r.category_id AND c.user_id = r.user_id AND c.deleted_at IS NULL
+		WHERE r.user_id = ?
+		ORDER BY r.priority DESC, r.id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []models.CategorizationRule{}
+	for rows.Next() {
+		var rule models.CategorizationRule
+		err := rows.Scan(&rule.ID, &rule.UserID, &rule.MatchField, &rule.MatchOp, &rule.MatchValue, &rule.CategoryID,
+			&rule.CategoryName, &rule.Priority)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// firstMatchingRule returns the first of the ordered rules that matches the
+// transaction and points at a category that still exists, or nil
+func firstMatchingRule(rules []models.CategorizationRule, t models.Transaction) *models.CategorizationRule {
+	for i := range rules {
+		if rules[i].CategoryName != "" && rules[i].Matches(t) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// matchCategorizationRule finds the owner's highest priority rule matching the
+// transaction. It returns nil when no rule applies.
+func matchCategorizationRule(t models.Transaction) (*models.CategorizationRule, error) {
+	rules, err := loadCategorizationRules(t.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return firstMatchingRule(rules, t), nil
+}
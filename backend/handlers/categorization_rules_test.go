@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+func setupCategorizationRuleTestDB() {
+	setupTransactionCategoryTestDB()
+
+	_, err := database.DB.Exec(`
+		CREATE TABLE IF NOT EXISTS categorization_rules (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			match_field TEXT NOT NULL,
+			match_op TEXT NOT NULL,
+			match_value TEXT NOT NULL,
+			category_id INTEGER NOT NULL,
+			priority INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		panic(err)
+	}
+
+	database.DB.Exec("INSERT INTO categories (id, name, user_id) VALUES (1, 'Gas', ?), (2, 'Snacks', ?), (3, 'Travel', ?)", TestUserID, TestUserID, TestUserID)
+	database.DB.Exec("INSERT INTO categories (id, name, user_id) VALUES (9, 'Theirs', 'other-user')")
+}
+
+func addTestTransaction(t *testing.T, body string) models.Transaction {
+	req := TestRequest("POST", "/transactions", &body)
+	w := httptest.NewRecorder()
+	AddTransaction(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var created models.Transaction
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	return created
+}
+
+func TestCategorizationRuleCRUD(t *testing.T) {
+	setupCategorizationRuleTestDB()
+	defer CleanupTestDB()
+
+	body := `{"matchField": "payTo", "matchOp": "contains", "matchValue": "Shell", "categoryId": 1, "priority": 5}`
+	req := TestRequest("POST", "/categorization-rules", &body)
+	w := httptest.NewRecorder()
+	AddCategorizationRule(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var created models.CategorizationRule
+	json.NewDecoder(w.Body).Decode(&created)
+	if created.ID == 0 || created.UserID != TestUserID || created.Priority != 5 {
+		t.Errorf("Unexpected created rule: %+v", created)
+	}
+
+	for _, invalid := range []string{
+		`{"matchField": "amount", "matchOp": "contains", "matchValue": "1", "categoryId": 1}`,
+		`{"matchField": "payTo", "matchOp": "contains", "matchValue": "Shell", "categoryId": 9}`,
+	} {
+		req := TestRequest("POST", "/categorization-rules", &invalid)
+		w := httptest.NewRecorder()
+		AddCategorizationRule(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d for %s, got %d", http.StatusBadRequest, invalid, w.Code)
+		}
+	}
+
+	update := `{"matchField": "description", "matchOp": "equals", "matchValue": "Fuel", "categoryId": 1, "priority": 1}`
+	req = TestRequest("PUT", "/categorization-rules/1", &update)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w = httptest.NewRecorder()
+	UpdateCategorizationRule(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	req = TestRequest("GET", "/categorization-rules", nil)
+	w = httptest.NewRecorder()
+	GetCategorizationRules(w, req)
+	var rules []models.CategorizationRule
+	json.NewDecoder(w.Body).Decode(&rules)
+	if len(rules) != 1 || rules[0].MatchField != models.RuleFieldDescription || rules[0].MatchValue != "Fuel" {
+		t.Errorf("Expected the updated rule, got %+v", rules)
+	}
+
+	for _, status := range []int{http.StatusOK, http.StatusNotFound} {
+		req = TestRequest("DELETE", "/categorization-rules/1", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		w = httptest.NewRecorder()
+		DeleteCategorizationRule(w, req)
+		if w.Code != status {
+			t.Errorf("Expected status code %d, got %d", status, w.Code)
+		}
+	}
+}
+
+func TestAddTransactionAppliesCategorizationRule(t *testing.T) {
+	setupCategorizationRuleTestDB()
+	defer CleanupTestDB()
+
+	database.DB.Exec(`
+		INSERT INTO categorization_rules (user_id, match_field, match_op, match_value, category_id, priority) VALUES
+			(?, 'payTo', 'contains', 'shell', 1, 1),
+			(?, 'description', 'startsWith', 'snack', 2, 10),
+			(?, 'payTo', 'contains', 'station', 3, 1)
+	`, TestUserID, TestUserID, TestUserID)
+
+	testCases := []struct {
+		name         string
+		body         string
+		expectedType string
+		expectedID   int // 0 when the transaction stays uncategorized
+	}{
+		{
+			name:         "matching rule sets category and missing type",
+			body:         `{"amount": 40, "description": "Fuel", "payTo": "Shell Station"}`,
+			expectedType: "Gas",
+			expectedID:   1,
+		},
+		{
+			name:         "highest priority wins",
+			body:         `{"amount": 5, "description": "Snacks at the pump", "payTo": "Shell", "type": "Road trip"}`,
+			expectedType: "Road trip",
+			expectedID:   2,
+		},
+		{
+			name:         "no match stays uncategorized",
+			body:         `{"amount": 12, "description": "Lunch", "payTo": "Cafe", "type": "Food"}`,
+			expectedType: "Food",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			created := addTestTransaction(t, tc.body)
+			if created.Type != tc.expectedType {
+				t.Errorf("Expected type %q, got %q", tc.expectedType, created.Type)
+			}
+
+			assignments, err := loadTransactionCategories(created.ID)
+			if err != nil {
+				t.Fatalf("Error loading categories: %v", err)
+			}
+			if tc.expectedID == 0 {
+				if len(assignments) != 0 {
+					t.Errorf("Expected no category, got %+v", assignments)
+				}
+				return
+			}
+			if len(assignments) != 1 || assignments[0].CategoryID != tc.expectedID || assignments[0].Amount != created.Amount {
+				t.Errorf("Expected category %d for the whole amount, got %+v", tc.expectedID, assignments)
+			}
+		})
+	}
+}
+
+func TestApplyCategorizationRules(t *testing.T) {
+	setupCategorizationRuleTestDB()
+	defer CleanupTestDB()
+
+	base := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	for _, tx := range []struct {
+		id, payTo, userID string
+	}{
+		{"shell-uncategorized", "Shell", TestUserID},
+		{"shell-categorized", "Shell", TestUserID},
+		{"cafe-uncategorized", "Cafe", TestUserID},
+		{"shell-someone-else", "Shell", "other-user"},
+	} {
+		insertTestTransaction(t, tx.id, 25, base, tx.userID)
+		database.DB.Exec("UPDATE transactions SET payTo = ? WHERE id = ?", tx.payTo, tx.id)
+	}
+	database.DB.Exec("INSERT INTO transaction_categories (transaction_id, category_id, amount) VALUES ('shell-categorized', 3, 25)")
+	database.DB.Exec("INSERT INTO categorization_rules (user_id, match_field, match_op, match_value, category_id) VALUES (?, 'payTo', 'equals', 'shell', 1)", TestUserID)
+
+	applyRules := func(url string) models.ApplyRulesResult {
+		req := TestRequest("POST", url, nil)
+		w := httptest.NewRecorder()
+		ApplyCategorizationRules(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var result models.ApplyRulesResult
+		json.NewDecoder(w.Body).Decode(&result)
+		return result
+	}
+	categoryOf := func(id string) int {
+		assignments, err := loadTransactionCategories(id)
+		if err != nil {
+			t.Fatalf("Error loading categories: %v", err)
+		}
+		if len(assignments) != 1 {
+			return 0
+		}
+		return assignments[0].CategoryID
+	}
+
+	if result := applyRules("/transactions/apply-rules"); result.Checked != 2 || result.Categorized != 1 {
+		t.Errorf("Expected 1 of 2 transactions categorized, got %+v", result)
+	}
+	expected := map[string]int{"shell-uncategorized": 1, "shell-categorized": 3, "cafe-uncategorized": 0, "shell-someone-else": 0}
+	for id, category := range expected {
+		if got := categoryOf(id); got != category {
+			t.Errorf("%s: expected category %d, got %d", id, category, got)
+		}
+	}
+
+	// Including categorized transactions lets a rule replace their category
+	if result := applyRules("/transactions/apply-rules?onlyUncategorized=false"); result.Checked != 3 || result.Categorized != 2 {
+		t.Errorf("Expected 2 of 3 transactions categorized, got %+v", result)
+	}
+	if got := categoryOf("shell-categorized"); got != 1 {
+		t.Errorf("Expected shell-categorized to move to category 1, got %d", got)
+	}
+}
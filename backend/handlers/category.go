@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
 	"log"
 	"math/rand"
 	"net/http"
 	"strconv"
+	"time"
 
 	"bennwallet/backend/database"
 	"bennwallet/backend/middleware"
@@ -14,6 +16,9 @@ import (
 	"github.com/gorilla/mux"
 )
 
+// categoryRecoveryWindow is how long a deleted category can be restored
+const categoryRecoveryWindow = 30 * 24 * time.Hour
+
 func GetCategories(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from authentication context
 	userId := middleware.GetUserIDFromContext(r)
@@ -26,30 +31,87 @@ func GetCategories(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	rows, err := database.DB.Query("SELECT id, name, description, color FROM categories WHERE user_id = ? ORDER BY name", userId)
+	orderBy, ok := categorySortOrders[r.URL.Query().Get("sort")]
+	if !ok {
+		http.Error(w, "Invalid sort (expected custom, name, usage or created)", http.StatusBadRequest)
+		return
+	}
+
+	categories, err := loadCategories(userId, orderBy)
 	if err != nil {
 		log.Printf("Error querying categories: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(categories)
+}
+
+// GetAllCategories returns every user's categories grouped by owner (admin only)
+func GetAllCategories(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from authentication context
+	userId := middleware.GetUserIDFromContext(r)
+	if userId == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	isAdmin, err := middleware.IsUserAdmin(userId)
+	if err != nil {
+		http.Error(w, "Failed to check user permissions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Only admins can see other users' categories
+	if !isAdmin {
+		http.Error(w, "Unauthorized: Admin access required", http.StatusForbidden)
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT c.id, c.name, c.description, c.color, c.optional_default, c.user_id, COALESCE(u.name, '')
+		FROM categories c
+		LEFT JOIN users u ON u.id = c.user_id
+		WHERE c.archived = 0 AND c.deleted_at IS NULL
+		ORDER BY c.user_id, c.name
+	`)
+	if err != nil {
+		log.Printf("Error querying all categories: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	defer rows.Close()
 
-	var categories []models.Category
+	groups := []models.UserCategories{}
+	groupIndex := make(map[string]int)
 	for rows.Next() {
 		var c models.Category
-		err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.Color)
-		if err != nil {
+		var description, color sql.NullString
+		var ownerName string
+		if err := rows.Scan(&c.ID, &c.Name, &description, &color, &c.OptionalDefault, &c.UserID, &ownerName); err != nil {
 			log.Printf("Error scanning category: %v", err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		// Add userId to the response
-		c.UserID = userId
-		categories = append(categories, c)
+		c.Description = description.String
+		c.Color = color.String
+
+		idx, ok := groupIndex[c.UserID]
+		if !ok {
+			groups = append(groups, models.UserCategories{
+				UserID:     c.UserID,
+				UserName:   ownerName,
+				Categories: []models.Category{},
+			})
+			idx = len(groups) - 1
+			groupIndex[c.UserID] = idx
+		}
+		groups[idx].Categories = append(groups[idx].Categories, c)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(categories)
+	json.NewEncoder(w).Encode(groups)
 }
 
 func AddCategory(w http.ResponseWriter, r *http.Request) {
@@ -75,10 +137,19 @@ func AddCategory(w http.ResponseWriter, r *http.Request) {
 		c.Color = generateRandomColor()
 	}
 
+	// Recreating a category replaces any soft-deleted one with the same name
+	_, err = database.DB.Exec(`
+		DELETE FROM categories WHERE user_id = ? AND name = ? AND deleted_at IS NOT NULL
+	`, c.UserID, c.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	result, err := database.DB.Exec(`
-		INSERT INTO categories (name, description, user_id, color)
-		VALUES (?, ?, ?, ?)
-	`, c.Name, c.Description, c.UserID, c.Color)
+		INSERT INTO categories (name, description, user_id, color, optional_default)
+		VALUES (?, ?, ?, ?, ?)
+	`, c.Name, c.Description, c.UserID, c.Color, c.OptionalDefault)
 
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -107,25 +178,37 @@ func UpdateCategory(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	var c models.Category
-	err := json.NewDecoder(r.Body).Decode(&c)
+	// An omitted optionalDefault keeps the stored one, since older clients
+	// only send the name, description and color
+	var request struct {
+		models.Category
+		OptionalDefault *bool `json:"optionalDefault"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&request)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	c := request.Category
 
 	// Use the user ID from the authentication context
 	_, err = database.DB.Exec(`
 		UPDATE categories 
-		SET name = ?, description = ?, color = ?
-		WHERE id = ? AND user_id = ?
-	`, c.Name, c.Description, c.Color, id, userId)
+		SET name = ?, description = ?, color = ?, optional_default = COALESCE(?, optional_default)
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+	`, c.Name, c.Description, c.Color, request.OptionalDefault, id, userId)
 
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	if request.OptionalDefault != nil {
+		c.OptionalDefault = *request.OptionalDefault
+	} else {
+		database.DB.QueryRow("SELECT optional_default FROM categories WHERE id = ?", id).Scan(&c.OptionalDefault)
+	}
+
 	// Return the updated category
 	c.ID, _ = strconv.Atoi(id) // Convert id to int
 	c.UserID = userId
@@ -148,7 +231,12 @@ func DeleteCategory(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	_, err := database.DB.Exec("DELETE FROM categories WHERE id = ? AND user_id = ?", id, userId)
+	// Soft-delete so the category can be restored within the recovery window.
+	// Transactions keep referring to it by name in the meantime.
+	_, err := database.DB.Exec(`
+		UPDATE categories SET deleted_at = ?
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+	`, time.Now().UTC(), id, userId)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -157,6 +245,96 @@ func DeleteCategory(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// GetDeletedCategories returns the user's categories that can still be restored
+func GetDeletedCategories(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from authentication context
+	userId := middleware.GetUserIDFromContext(r)
+	if userId == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT id, name, description, color, optional_default, deleted_at
+		FROM categories
+		WHERE user_id = ? AND deleted_at IS NOT NULL AND deleted_at >= ?
+		ORDER BY deleted_at DESC
+	`, userId, categoryRecoveryCutoff())
+	if err != nil {
+		log.Printf("Error querying deleted categories: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	categories := []models.DeletedCategory{}
+	for rows.Next() {
+		var c models.DeletedCategory
+		var description, color sql.NullString
+		if err := rows.Scan(&c.ID, &c.Name, &description, &color, &c.OptionalDefault, &c.DeletedAt); err != nil {
+			log.Printf("Error scanning deleted category: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		c.Description = description.String
+		c.Color = color.String
+		c.UserID = userId
+		c.RestorableUntil = c.DeletedAt.Add(categoryRecoveryWindow)
+		categories = append(categories, c)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(categories)
+}
+
+// RestoreCategory undoes a soft delete made within the recovery window
+func RestoreCategory(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from authentication context
+	userId := middleware.GetUserIDFromContext(r)
+	if userId == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	result, err := database.DB.Exec(`
+		UPDATE categories SET deleted_at = NULL
+		WHERE id = ? AND user_id = ? AND deleted_at IS NOT NULL AND deleted_at >= ?
+	`, id, userId, categoryRecoveryCutoff())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		http.Error(w, "Deleted category not found or recovery window has expired", http.StatusNotFound)
+		return
+	}
+
+	var c models.Category
+	var description, color sql.NullString
+	err = database.DB.QueryRow(`
+		SELECT id, name, description, color, optional_default FROM categories WHERE id = ?
+	`, id).Scan(&c.ID, &c.Name, &description, &color, &c.OptionalDefault)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	c.Description = description.String
+	c.Color = color.String
+	c.UserID = userId
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// categoryRecoveryCutoff is the earliest deletion time that can still be restored
+func categoryRecoveryCutoff() time.Time {
+	return time.Now().UTC().Add(-categoryRecoveryWindow)
+}
+
 func generateRandomColor() string {
 	colors := []string{
 		"#FF6B6B", "#4ECDC4", "#45B7D1", "#96CEB4", "#FFEEAD",
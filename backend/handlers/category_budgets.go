@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+// ownedCategoryID parses the {id} route variable and checks that it names one
+// of the user's categories. On failure it also returns the HTTP status to
+// respond with.
+func ownedCategoryID(r *http.Request, userID string) (int, int, error) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return 0, http.StatusBadRequest, fmt.Errorf("Invalid category ID")
+	}
+
+	var exists bool
+	err = database.DB.QueryRow(`
+		SELECT COUNT(*) > 0 FROM categories
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+	`, id, userID).Scan(&exists)
+	if err != nil {
+		log.Printf("Error checking category %d: %v", id, err)
+		return 0, http.StatusInternalServerError, err
+	}
+	if !exists {
+		return 0, http.StatusNotFound, fmt.Errorf("Category not found")
+	}
+	return id, http.StatusOK, nil
+}
+
+// GetCategoryBudget returns the monthly budget of one of the user's categories
+func GetCategoryBudget(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	id, status, err := ownedCategoryID(r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	budget := models.CategoryBudget{CategoryID: id}
+	err = database.DB.QueryRow("SELECT amount FROM category_budgets WHERE category_id = ?", id).Scan(&budget.Amount)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Category has no budget", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error getting budget of category %d: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(budget)
+}
+
+// SetCategoryBudget sets the monthly budget of one of the user's categories,
+// replacing any previous one
+func SetCategoryBudget(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	id, status, err := ownedCategoryID(r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	var budget models.CategoryBudget
+	if err := json.NewDecoder(r.Body).Decode(&budget); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if budget.Amount <= 0 {
+		http.Error(w, "amount must be greater than zero", http.StatusBadRequest)
+		return
+	}
+	budget.CategoryID = id
+
+	_, err = database.DB.Exec(`
+		INSERT INTO category_budgets (user_id, category_id, amount, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(category_id) DO UPDATE SET amount = excluded.amount, updated_at = excluded.updated_at
+	`, userID, id, budget.Amount, time.Now())
+	if err != nil {
+		log.Printf("Error setting budget of category %d: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(budget)
+}
+
+// DeleteCategoryBudget removes the monthly budget of one of the user's categories
+func DeleteCategoryBudget(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	id, status, err := ownedCategoryID(r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	result, err := database.DB.Exec("DELETE FROM category_budgets WHERE category_id = ?", id)
+	if err != nil {
+		log.Printf("Error deleting budget of category %d: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if deleted, _ := result.RowsAffected(); deleted == 0 {
+		http.Error(w, "Category has no budget", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// budgetWarnings describes the budgeted categories a transaction is assigned
+// to whose spending in the transaction's month is over budget
+func budgetWarnings(t models.Transaction) ([]string, error) {
+	year, month, _ := t.Date.Date()
+	dateClause, dateArgs := monthRange(time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)).SQLConditions("t.date")
+
+	rows, err := database.DB.Query(`
+		SELECT c.name, b.amount,
+			(SELECT COALESCE(SUM(tc.amount), 0)
+			FROM transaction_categories tc
+			JOIN transactions t ON t.id = tc.transaction_id
+			WHERE tc.category_id = b.category_id AND t.deleted_at IS NULL`+dateClause+`)
+		FROM transaction_categories link
+		JOIN category_budgets b ON b.category_id = link.category_id
+		JOIN categories c ON c.id = b.category_id
+		WHERE link.transaction_id = ?
+		ORDER BY c.name
+	`, append(dateArgs, t.ID)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var warnings []string
+	for rows.Next() {
+		var name string
+		var budget, spent float64
+		if err := rows.Scan(&name, &budget, &spent); err != nil {
+			return nil, err
+		}
+		if over := spent - budget; over > 0.005 {
+			warnings = append(warnings, fmt.Sprintf("Category %s is now $%.2f over budget", name, over))
+		}
+	}
+	return warnings, rows.Err()
+}
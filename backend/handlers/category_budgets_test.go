@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+// setupCategoryBudgetTestDB adds budgets to the categorization rule fixtures,
+// with a rule filing Shell purchases under Gas (category 1)
+func setupCategoryBudgetTestDB(t *testing.T) {
+	setupCategorizationRuleTestDB()
+
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS category_budgets (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			category_id INTEGER NOT NULL UNIQUE,
+			amount REAL NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`INSERT INTO categorization_rules (user_id, match_field, match_op, match_value, category_id) VALUES ('` + TestUserID + `', 'payTo', 'contains', 'Shell', 1)`,
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to set up budgets: %v", err)
+		}
+	}
+}
+
+func setTestCategoryBudget(t *testing.T, id, body string) *httptest.ResponseRecorder {
+	req := TestRequest("PUT", "/categories/"+id+"/budget", &body)
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+	SetCategoryBudget(w, req)
+	return w
+}
+
+func addBudgetTestTransaction(t *testing.T, body string) models.CreatedTransaction {
+	req := TestRequest("POST", "/transactions", &body)
+	w := httptest.NewRecorder()
+	AddTransaction(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var created models.CreatedTransaction
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	return created
+}
+
+func TestCategoryBudgetCRUD(t *testing.T) {
+	setupCategoryBudgetTestDB(t)
+	defer CleanupTestDB()
+
+	if w := setTestCategoryBudget(t, "1", `{"amount": 100}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if w := setTestCategoryBudget(t, "1", `{"amount": 150}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d replacing the budget, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	req := TestRequest("GET", "/categories/1/budget", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+	GetCategoryBudget(w, req)
+	var budget models.CategoryBudget
+	json.NewDecoder(w.Body).Decode(&budget)
+	if w.Code != http.StatusOK || budget != (models.CategoryBudget{CategoryID: 1, Amount: 150}) {
+		t.Errorf("Expected a budget of 150 for category 1, got %d %+v", w.Code, budget)
+	}
+
+	req = TestRequest("DELETE", "/categories/1/budget", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	w = httptest.NewRecorder()
+	DeleteCategoryBudget(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status code %d, got %d", http.StatusNoContent, w.Code)
+	}
+
+	// Budgets need a positive amount and a category the caller owns
+	if w := setTestCategoryBudget(t, "1", `{"amount": 0}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for a zero budget, got %d", http.StatusBadRequest, w.Code)
+	}
+	if w := setTestCategoryBudget(t, "9", `{"amount": 50}`); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d for another user's category, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestAddTransactionWarnsWhenOverBudget(t *testing.T) {
+	setupCategoryBudgetTestDB(t)
+	defer CleanupTestDB()
+
+	if w := setTestCategoryBudget(t, "1", `{"amount": 100}`); w.Code != http.StatusOK {
+		t.Fatalf("Failed to set budget: %d %s", w.Code, w.Body.String())
+	}
+
+	under := addBudgetTestTransaction(t, `{"amount": 90, "description": "Fill up", "type": "Gas", "payTo": "Shell", "date": "2024-05-03T10:00:00Z"}`)
+	if len(under.Warnings) != 0 {
+		t.Errorf("Expected no warnings while under budget, got %v", under.Warnings)
+	}
+
+	over := addBudgetTestTransaction(t, `{"amount": 30, "description": "Top up", "type": "Gas", "payTo": "Shell", "date": "2024-05-20T10:00:00Z"}`)
+	expected := []string{"Category Gas is now $20.00 over budget"}
+	if !reflect.DeepEqual(over.Warnings, expected) {
+		t.Errorf("Expected warnings %v, got %v", expected, over.Warnings)
+	}
+	if over.ID == "" || over.Amount != 30 {
+		t.Errorf("Expected the created transaction alongside the warnings, got %+v", over.Transaction)
+	}
+
+	// Each month has its own budget
+	nextMonth := addBudgetTestTransaction(t, `{"amount": 30, "description": "Fill up", "type": "Gas", "payTo": "Shell", "date": "2024-06-01T10:00:00Z"}`)
+	if len(nextMonth.Warnings) != 0 {
+		t.Errorf("Expected no warnings in a new month, got %v", nextMonth.Warnings)
+	}
+}
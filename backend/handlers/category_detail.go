@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+// GetCategory returns one of the user's categories with a page of the
+// transactions assigned to it, newest first, and the total assigned amount
+func GetCategory(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		return
+	}
+
+	page, pageSize, err := parsePagination(r, pageResourceTransactions)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var detail models.CategoryDetail
+	var description, color sql.NullString
+	err = database.DB.QueryRow(`
+		SELECT id, name, description, color, optional_default FROM categories
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+	`, id, userID).Scan(&detail.ID, &detail.Name, &description, &color, &detail.OptionalDefault)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error getting category %d: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	detail.Description = description.String
+	detail.Color = color.String
+	detail.UserID = userID
+
+	const linked = `
+		FROM transaction_categories tc
+		JOIN transactions t ON t.id = tc.transaction_id
+		WHERE tc.category_id = ? AND t.deleted_at IS NULL
+	`
+
+	detail.Transactions = models.TransactionPage{
+		Transactions: []models.Transaction{},
+		Page:         page,
+		PageSize:     pageSize,
+	}
+
+	err = database.DB.QueryRow("SELECT COUNT(*), COALESCE(SUM(tc.amount), 0) "+linked, id).
+		Scan(&detail.Transactions.Total, &detail.Total)
+	if err != nil {
+		log.Printf("Error totalling category %d: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT t.id, t.amount, t.description, t.date, t.transaction_date, t.type, t.payTo, t.paid, t.paidDate, t.enteredBy, t.optional, t.userId
+	`+linked+`
+		ORDER BY t.date DESC, t.id
+		LIMIT ? OFFSET ?
+	`, id, pageSize, (page-1)*pageSize)
+	if err != nil {
+		log.Printf("Error querying transactions for category %d: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t models.Transaction
+		var payTo, paidDate, ownerID sql.NullString
+		var transactionDate sql.NullTime
+		err := rows.Scan(&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate, &t.Type, &payTo,
+			&t.Paid, &paidDate, &t.EnteredBy, &t.Optional, &ownerID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		t.PayTo = payTo.String
+		t.PaidDate = paidDate.String
+		t.UserID = ownerID.String
+		if transactionDate.Valid {
+			t.TransactionDate = transactionDate.Time
+		} else {
+			t.TransactionDate = t.Date
+		}
+		detail.Transactions.Transactions = append(detail.Transactions.Transactions, t)
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(detail)
+}
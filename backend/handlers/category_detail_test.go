@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+func getCategoryDetail(url, id string) *httptest.ResponseRecorder {
+	req := TestRequest("GET", url, nil)
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+	GetCategory(w, req)
+	return w
+}
+
+func TestGetCategory(t *testing.T) {
+	setupTransactionCategoryTestDB()
+	defer CleanupTestDB()
+
+	base := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	insertTestTransaction(t, "split", 100, base, TestUserID)
+	insertTestTransaction(t, "whole", 20, base.AddDate(0, 0, 1), TestUserID)
+	insertTestTransaction(t, "elsewhere", 30, base.AddDate(0, 0, 2), TestUserID)
+	insertTestTransaction(t, "deleted", 40, base.AddDate(0, 0, 3), TestUserID)
+	database.DB.Exec("UPDATE transactions SET deleted_at = ? WHERE id = 'deleted'", base)
+
+	database.DB.Exec("INSERT INTO categories (id, name, description, user_id) VALUES (1, 'Groceries', 'Food', ?), (2, 'Dining', NULL, ?)", TestUserID, TestUserID)
+	database.DB.Exec(`
+		INSERT INTO transaction_categories (transaction_id, category_id, amount) VALUES
+			('split', 1, 60), ('split', 2, 40), ('whole', 1, 20), ('elsewhere', 2, 30), ('deleted', 1, 40)
+	`)
+
+	w := getCategoryDetail("/categories/1", "1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var detail models.CategoryDetail
+	if err := json.NewDecoder(w.Body).Decode(&detail); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if detail.Name != "Groceries" || detail.Description != "Food" {
+		t.Errorf("Unexpected category: %+v", detail.Category)
+	}
+	if detail.Total != 80 {
+		t.Errorf("Expected total 80, got %.2f", detail.Total)
+	}
+	page := detail.Transactions
+	if page.Total != 2 || len(page.Transactions) != 2 {
+		t.Fatalf("Expected 2 transactions, got total=%d len=%d", page.Total, len(page.Transactions))
+	}
+	if page.Transactions[0].ID != "whole" || page.Transactions[1].ID != "split" {
+		t.Errorf("Unexpected transactions or order: %s, %s", page.Transactions[0].ID, page.Transactions[1].ID)
+	}
+
+	w = getCategoryDetail("/categories/1?page=2&pageSize=1", "1")
+	detail = models.CategoryDetail{}
+	json.NewDecoder(w.Body).Decode(&detail)
+	page = detail.Transactions
+	if page.Total != 2 || len(page.Transactions) != 1 || page.Transactions[0].ID != "split" || detail.Total != 80 {
+		t.Errorf("Unexpected second page: %+v", detail)
+	}
+}
+
+func TestGetCategoryInaccessible(t *testing.T) {
+	setupTransactionCategoryTestDB()
+	defer CleanupTestDB()
+
+	database.DB.Exec("INSERT INTO categories (id, name, user_id) VALUES (1, 'Theirs', 'other-user')")
+	database.DB.Exec("INSERT INTO categories (id, name, user_id, deleted_at) VALUES (2, 'Deleted', ?, ?)", TestUserID, time.Now())
+
+	for _, id := range []string{"1", "2", "99"} {
+		if w := getCategoryDetail("/categories/"+id, id); w.Code != http.StatusNotFound {
+			t.Errorf("Category %s: expected status code %d, got %d", id, http.StatusNotFound, w.Code)
+		}
+	}
+
+	if w := getCategoryDetail("/categories/abc", "abc"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an invalid ID, got %d", http.StatusBadRequest, w.Code)
+	}
+}
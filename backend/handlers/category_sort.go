@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// categorySortOrders maps the ?sort= values GetCategories accepts to their
+// ORDER BY clauses. The default, custom, follows the user's own ordering and
+// falls back to name for categories without a position, so it matches the
+// name order until the user reorders anything.
+var categorySortOrders = map[string]string{
+	"":       "sort_order IS NULL, sort_order, name",
+	"custom": "sort_order IS NULL, sort_order, name",
+	"name":   "name",
+	// Most linked transactions first
+	"usage": `(
+		SELECT COUNT(*) FROM transaction_categories tc
+		JOIN transactions t ON t.id = tc.transaction_id AND t.deleted_at IS NULL
+		WHERE tc.category_id = categories.id
+	) DESC, name`,
+	// IDs are assigned in creation order
+	"created": "id",
+}
+
+// loadCategories reads the user's active categories in the given order
+func loadCategories(userId, orderBy string) ([]models.Category, error) {
+	rows, err := database.DB.Query(`
+		SELECT id, name, description, color, optional_default, sort_order
+		FROM categories
+		WHERE user_id = ? AND archived = 0 AND deleted_at IS NULL
+		ORDER BY `+orderBy, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []models.Category
+	for rows.Next() {
+		var c models.Category
+		var sortOrder sql.NullInt64
+		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.Color, &c.OptionalDefault, &sortOrder); err != nil {
+			return nil, err
+		}
+		if sortOrder.Valid {
+			position := int(sortOrder.Int64)
+			c.SortOrder = &position
+		}
+		// Add userId to the response
+		c.UserID = userId
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+// ReorderCategories saves the user's custom category ordering. The listed
+// categories take positions in the order given and any others lose their
+// position, sorting after them by name. Responds with the categories in the
+// new order.
+func ReorderCategories(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CategoryReorderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	seen := map[int]bool{}
+	for _, id := range req.IDs {
+		if seen[id] {
+			http.Error(w, fmt.Sprintf("Category %d is listed more than once", id), http.StatusBadRequest)
+			return
+		}
+		seen[id] = true
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE categories SET sort_order = NULL WHERE user_id = ?", userID); err != nil {
+		log.Printf("Error clearing category order: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for position, id := range req.IDs {
+		result, err := tx.Exec(`
+			UPDATE categories SET sort_order = ?
+			WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+		`, position, id, userID)
+		if err != nil {
+			log.Printf("Error ordering category %d: %v", id, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			http.Error(w, fmt.Sprintf("Category %d not found", id), http.StatusBadRequest)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	categories, err := loadCategories(userID, categorySortOrders["custom"])
+	if err != nil {
+		log.Printf("Error querying categories: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(categories)
+}
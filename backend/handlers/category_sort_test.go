@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+func getSortedCategoryNames(t *testing.T, url string, expectedStatus int) []string {
+	t.Helper()
+
+	req := TestRequest("GET", url, nil)
+	w := httptest.NewRecorder()
+	GetCategories(w, req)
+	if w.Code != expectedStatus {
+		t.Fatalf("Expected status code %d, got %d: %s", expectedStatus, w.Code, w.Body.String())
+	}
+
+	var names []string
+	if expectedStatus == http.StatusOK {
+		var categories []models.Category
+		if err := json.NewDecoder(w.Body).Decode(&categories); err != nil {
+			t.Fatalf("Error decoding response: %v", err)
+		}
+		for _, c := range categories {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+func reorderCategories(t *testing.T, ids []int, expectedStatus int) {
+	t.Helper()
+
+	body, _ := json.Marshal(models.CategoryReorderRequest{IDs: ids})
+	bodyStr := string(body)
+	req := TestRequest("PUT", "/categories/order", &bodyStr)
+	w := httptest.NewRecorder()
+	ReorderCategories(w, req)
+	if w.Code != expectedStatus {
+		t.Fatalf("Expected status code %d, got %d: %s", expectedStatus, w.Code, w.Body.String())
+	}
+}
+
+func setupCategorySortTestDB(t *testing.T) {
+	setupTransactionCategoryTestDB()
+
+	// Created in a different order than their names sort
+	_, err := database.DB.Exec(`
+		INSERT INTO categories (id, name, description, user_id, color) VALUES
+		(1, 'Rent', '', ?, ''), (2, 'Groceries', '', ?, ''), (3, 'Utilities', '', ?, ''), (4, 'Dining', '', ?, ''),
+		(5, 'Theirs', '', 'other-user', '')
+	`, TestUserID, TestUserID, TestUserID, TestUserID)
+	if err != nil {
+		t.Fatalf("Failed to insert categories: %v", err)
+	}
+}
+
+func assertCategoryOrder(t *testing.T, label string, got []string, expected ...string) {
+	t.Helper()
+
+	if len(got) != len(expected) {
+		t.Fatalf("%s: expected %v, got %v", label, expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("%s: expected %v, got %v", label, expected, got)
+			return
+		}
+	}
+}
+
+func TestGetCategoriesSort(t *testing.T) {
+	setupCategorySortTestDB(t)
+	defer CleanupTestDB()
+
+	now := time.Now().UTC()
+	insertTestTransaction(t, "tx-1", 10, now, TestUserID)
+	insertTestTransaction(t, "tx-2", 10, now, TestUserID)
+	insertTestTransaction(t, "tx-3", 10, now, TestUserID)
+	database.DB.Exec(`
+		INSERT INTO transaction_categories (transaction_id, category_id, amount) VALUES
+		('tx-1', 3, 10), ('tx-2', 3, 10), ('tx-3', 1, 10)
+	`)
+
+	// Without a custom order the default is the name order
+	assertCategoryOrder(t, "default", getSortedCategoryNames(t, "/categories", http.StatusOK),
+		"Dining", "Groceries", "Rent", "Utilities")
+	assertCategoryOrder(t, "name", getSortedCategoryNames(t, "/categories?sort=name", http.StatusOK),
+		"Dining", "Groceries", "Rent", "Utilities")
+	assertCategoryOrder(t, "usage", getSortedCategoryNames(t, "/categories?sort=usage", http.StatusOK),
+		"Utilities", "Rent", "Dining", "Groceries")
+	assertCategoryOrder(t, "created", getSortedCategoryNames(t, "/categories?sort=created", http.StatusOK),
+		"Rent", "Groceries", "Utilities", "Dining")
+
+	getSortedCategoryNames(t, "/categories?sort=color", http.StatusBadRequest)
+}
+
+func TestReorderCategories(t *testing.T) {
+	setupCategorySortTestDB(t)
+	defer CleanupTestDB()
+
+	reorderCategories(t, []int{3, 1}, http.StatusOK)
+
+	// The custom order persists; unlisted categories follow by name
+	assertCategoryOrder(t, "custom", getSortedCategoryNames(t, "/categories", http.StatusOK),
+		"Utilities", "Rent", "Dining", "Groceries")
+	assertCategoryOrder(t, "name", getSortedCategoryNames(t, "/categories?sort=name", http.StatusOK),
+		"Dining", "Groceries", "Rent", "Utilities")
+
+	// Reordering replaces the previous order
+	reorderCategories(t, []int{2}, http.StatusOK)
+	assertCategoryOrder(t, "reordered", getSortedCategoryNames(t, "/categories?sort=custom", http.StatusOK),
+		"Groceries", "Dining", "Rent", "Utilities")
+
+	// Other users' categories, unknown IDs and duplicates are rejected
+	// without changing the saved order
+	reorderCategories(t, []int{4, 5}, http.StatusBadRequest)
+	reorderCategories(t, []int{4, 99}, http.StatusBadRequest)
+	reorderCategories(t, []int{4, 4}, http.StatusBadRequest)
+	assertCategoryOrder(t, "unchanged", getSortedCategoryNames(t, "/categories", http.StatusOK),
+		"Groceries", "Dining", "Rent", "Utilities")
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// defaultStaleCategoryDays is how long a category must go unused, by default,
+// to count as stale
+const defaultStaleCategoryDays = 180
+
+// GetStaleCategories returns the user's categories with no linked
+// transactions dated in the last ?days= days (default 180), including ones
+// that were never used, so unused categories can be cleaned up. Transactions
+// count by their transaction date, falling back to their date.
+func GetStaleCategories(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	days := defaultStaleCategoryDays
+	if daysParam := r.URL.Query().Get("days"); daysParam != "" {
+		parsed, err := strconv.Atoi(daysParam)
+		if err != nil || parsed < 1 {
+			http.Error(w, "Invalid days parameter (expected a positive number)", http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+	cutoff := startOfDay(time.Now()).AddDate(0, 0, -days).Format(dateLayout)
+
+	rows, err := database.DB.Query(`
+		SELECT c.id, c.name, c.description, c.color, c.optional_default,
+			MAX(COALESCE(t.transaction_date, t.date)) AS last_used
+		FROM categories c
+		LEFT JOIN transaction_categories tc ON tc.category_id = c.id
+		LEFT JOIN transactions t ON t.id = tc.transaction_id AND t.deleted_at IS NULL
+		WHERE c.user_id = ? AND c.deleted_at IS NULL
+		GROUP BY c.id
+		HAVING last_used IS NULL OR last_used < ?
+		ORDER BY c.name
+	`, userID, cutoff)
+	if err != nil {
+		log.Printf("Error querying stale categories: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	stale := []models.StaleCategory{}
+	for rows.Next() {
+		var c models.StaleCategory
+		var description, color, lastUsed sql.NullString
+		if err := rows.Scan(&c.ID, &c.Name, &description, &color, &c.OptionalDefault, &lastUsed); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		c.Description = description.String
+		c.Color = color.String
+		c.UserID = userID
+		if len(lastUsed.String) >= len(dateLayout) {
+			c.LastUsed = lastUsed.String[:len(dateLayout)]
+		}
+		stale = append(stale, c)
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stale)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+func getStaleCategories(t *testing.T, url string, expectedStatus int) []models.StaleCategory {
+	t.Helper()
+
+	req := TestRequest("GET", url, nil)
+	w := httptest.NewRecorder()
+	GetStaleCategories(w, req)
+	if w.Code != expectedStatus {
+		t.Fatalf("Expected status code %d, got %d: %s", expectedStatus, w.Code, w.Body.String())
+	}
+
+	var stale []models.StaleCategory
+	if expectedStatus == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&stale); err != nil {
+			t.Fatalf("Error decoding response: %v", err)
+		}
+	}
+	return stale
+}
+
+func TestGetStaleCategories(t *testing.T) {
+	setupTransactionCategoryTestDB()
+	defer CleanupTestDB()
+
+	database.DB.Exec(`
+		INSERT INTO categories (id, name, user_id) VALUES
+		(1, 'Active', ?), (2, 'Dormant', ?), (3, 'Unused', ?), (4, 'Removed', ?), (5, 'Theirs', 'other-user')
+	`, TestUserID, TestUserID, TestUserID, TestUserID)
+
+	now := time.Now().UTC()
+	recent := now.AddDate(0, 0, -10)
+	old := now.AddDate(0, 0, -400)
+	insertTestTransaction(t, "tx-active-old", 10, old, TestUserID)
+	insertTestTransaction(t, "tx-active", 10, recent, TestUserID)
+	insertTestTransaction(t, "tx-dormant", 10, old, TestUserID)
+	insertTestTransaction(t, "tx-removed", 10, recent, TestUserID)
+	database.DB.Exec("UPDATE transactions SET deleted_at = ? WHERE id = 'tx-removed'", now)
+	database.DB.Exec(`
+		INSERT INTO transaction_categories (transaction_id, category_id, amount) VALUES
+		('tx-active-old', 1, 10), ('tx-active', 1, 10), ('tx-dormant', 2, 10), ('tx-removed', 4, 10)
+	`)
+
+	// Deleted transactions don't count as activity and other users'
+	// categories are left out
+	stale := getStaleCategories(t, "/categories/stale", http.StatusOK)
+	expected := []struct {
+		name     string
+		lastUsed string
+	}{
+		{"Dormant", old.Format("2006-01-02")},
+		{"Removed", ""},
+		{"Unused", ""},
+	}
+	if len(stale) != len(expected) {
+		t.Fatalf("Expected %d stale categories, got %+v", len(expected), stale)
+	}
+	for i, e := range expected {
+		if stale[i].Name != e.name || stale[i].LastUsed != e.lastUsed {
+			t.Errorf("Category %d: expected %s last used %q, got %s last used %q", i, e.name, e.lastUsed, stale[i].Name, stale[i].LastUsed)
+		}
+	}
+
+	// A longer window makes the dormant category active again
+	stale = getStaleCategories(t, "/categories/stale?days=500", http.StatusOK)
+	if len(stale) != 2 || stale[0].Name != "Removed" || stale[1].Name != "Unused" {
+		t.Errorf("Expected only Removed and Unused with days=500, got %+v", stale)
+	}
+}
+
+func TestGetStaleCategoriesInvalidDays(t *testing.T) {
+	setupTransactionCategoryTestDB()
+	defer CleanupTestDB()
+
+	getStaleCategories(t, "/categories/stale?days=0", http.StatusBadRequest)
+	getStaleCategories(t, "/categories/stale?days=soon", http.StatusBadRequest)
+}
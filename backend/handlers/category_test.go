@@ -7,9 +7,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"bennwallet/backend/database"
 	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
 )
 
 func setupCategoryTestDB() {
@@ -27,7 +30,14 @@ func setupCategoryTestDB() {
 			name TEXT NOT NULL,
 			description TEXT,
 			user_id TEXT NOT NULL,
-			color TEXT
+			color TEXT,
+			last_updated DATETIME,
+			ynab_category_id TEXT,
+			archived BOOLEAN NOT NULL DEFAULT 0,
+			optional_default BOOLEAN NOT NULL DEFAULT 0,
+			sort_order INTEGER,
+			deleted_at DATETIME,
+			UNIQUE(name, user_id)
 		)
 	`)
 	if err != nil {
@@ -121,3 +131,257 @@ func TestGetCategories(t *testing.T) {
 		t.Errorf("Expected category name 'Test Category', got '%s'", response[0].Name)
 	}
 }
+
+func setupAllCategoriesTestDB() {
+	// Users and permissions come from the common test setup
+	SetupTestDB()
+
+	_, err := database.DB.Exec(`
+		CREATE TABLE categories (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			description TEXT,
+			user_id TEXT NOT NULL,
+			color TEXT,
+			last_updated DATETIME,
+			ynab_category_id TEXT,
+			archived BOOLEAN NOT NULL DEFAULT 0,
+			optional_default BOOLEAN NOT NULL DEFAULT 0,
+			deleted_at DATETIME,
+			UNIQUE(name, user_id)
+		)
+	`)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = database.DB.Exec(`
+		INSERT INTO users (id, username, name, isAdmin, role)
+		VALUES ('regular-user', 'regular', 'Regular User', 0, 'user')
+	`)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = database.DB.Exec(`
+		INSERT INTO categories (name, description, user_id, color) VALUES
+		('Groceries', 'Food', ?, '#FF0000'),
+		('Rent', 'Housing', ?, '#00FF00'),
+		('Fun Money', 'Personal', 'regular-user', '#0000FF')
+	`, TestUserID, TestUserID)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestGetAllCategoriesAsAdmin(t *testing.T) {
+	setupAllCategoriesTestDB()
+	defer CleanupTestDB()
+
+	req := MockAuthContext(httptest.NewRequest("GET", "/categories/all", nil), TestUserID)
+	w := httptest.NewRecorder()
+
+	GetAllCategories(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response []models.UserCategories
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	if len(response) != 2 {
+		t.Fatalf("Expected categories for 2 users, got %d", len(response))
+	}
+
+	counts := make(map[string]int)
+	for _, group := range response {
+		counts[group.UserID] = len(group.Categories)
+	}
+
+	if counts[TestUserID] != 2 {
+		t.Errorf("Expected 2 categories for %s, got %d", TestUserID, counts[TestUserID])
+	}
+	if counts["regular-user"] != 1 {
+		t.Errorf("Expected 1 category for regular-user, got %d", counts["regular-user"])
+	}
+}
+
+func TestGetAllCategoriesForbiddenForRegularUser(t *testing.T) {
+	setupAllCategoriesTestDB()
+	defer CleanupTestDB()
+
+	req := MockAuthContext(httptest.NewRequest("GET", "/categories/all", nil), "regular-user")
+	w := httptest.NewRecorder()
+
+	GetAllCategories(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", http.StatusForbidden, w.Code)
+	}
+}
+
+func TestDeleteAndRestoreCategory(t *testing.T) {
+	setupCategoryTestDB()
+	defer database.DB.Close()
+
+	_, err := database.DB.Exec(`
+		CREATE TABLE transactions (
+			id TEXT PRIMARY KEY,
+			amount REAL NOT NULL,
+			description TEXT NOT NULL,
+			type TEXT NOT NULL,
+			userId TEXT
+		)
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create transactions table: %v", err)
+	}
+
+	_, err = database.DB.Exec(`
+		INSERT INTO categories (id, name, description, user_id, color, optional_default)
+		VALUES (7, 'Fun Money', 'Treats', ?, '#9B59B6', 1)
+	`, TestUserID)
+	if err != nil {
+		t.Fatalf("Failed to insert category: %v", err)
+	}
+	_, err = database.DB.Exec(`
+		INSERT INTO transactions (id, amount, description, type, userId) VALUES
+		('tx-1', 10, 'Concert', 'Fun Money', ?),
+		('tx-2', 15, 'Arcade', 'Fun Money', ?)
+	`, TestUserID, TestUserID)
+	if err != nil {
+		t.Fatalf("Failed to insert transactions: %v", err)
+	}
+
+	// Delete the category
+	req := TestRequest("DELETE", "/categories/7", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "7"})
+	w := httptest.NewRecorder()
+	DeleteCategory(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d for delete, got %d", http.StatusOK, w.Code)
+	}
+
+	// It no longer shows up in the category list
+	req = TestRequest("GET", "/categories", nil)
+	w = httptest.NewRecorder()
+	GetCategories(w, req)
+	var categories []models.Category
+	json.NewDecoder(w.Body).Decode(&categories)
+	if len(categories) != 0 {
+		t.Errorf("Expected deleted category to be hidden, got %+v", categories)
+	}
+
+	// But it is listed as recently deleted
+	req = TestRequest("GET", "/categories/deleted", nil)
+	w = httptest.NewRecorder()
+	GetDeletedCategories(w, req)
+	var deleted []models.DeletedCategory
+	json.NewDecoder(w.Body).Decode(&deleted)
+	if len(deleted) != 1 || deleted[0].ID != 7 {
+		t.Fatalf("Expected category 7 in recently deleted, got %+v", deleted)
+	}
+
+	// Restore it
+	req = TestRequest("POST", "/categories/7/restore", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "7"})
+	w = httptest.NewRecorder()
+	RestoreCategory(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d for restore, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var restored models.Category
+	json.NewDecoder(w.Body).Decode(&restored)
+	if restored.ID != 7 || restored.Name != "Fun Money" || restored.Description != "Treats" ||
+		restored.Color != "#9B59B6" || !restored.OptionalDefault {
+		t.Errorf("Restored category does not match the original: %+v", restored)
+	}
+
+	// Its transactions are still linked by name
+	var linked int
+	database.DB.QueryRow("SELECT COUNT(*) FROM transactions WHERE type = ?", restored.Name).Scan(&linked)
+	if linked != 2 {
+		t.Errorf("Expected 2 linked transactions after restore, got %d", linked)
+	}
+
+	req = TestRequest("GET", "/categories", nil)
+	w = httptest.NewRecorder()
+	GetCategories(w, req)
+	categories = nil
+	json.NewDecoder(w.Body).Decode(&categories)
+	if len(categories) != 1 {
+		t.Errorf("Expected restored category to be listed, got %d categories", len(categories))
+	}
+}
+
+func TestRestoreCategoryAfterRecoveryWindow(t *testing.T) {
+	setupCategoryTestDB()
+	defer database.DB.Close()
+
+	deletedAt := time.Now().UTC().Add(-categoryRecoveryWindow - time.Hour)
+	_, err := database.DB.Exec(`
+		INSERT INTO categories (id, name, description, user_id, color, deleted_at)
+		VALUES (8, 'Old', '', ?, '#FFEEAD', ?)
+	`, TestUserID, deletedAt)
+	if err != nil {
+		t.Fatalf("Failed to insert category: %v", err)
+	}
+
+	req := TestRequest("POST", "/categories/8/restore", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "8"})
+	w := httptest.NewRecorder()
+	RestoreCategory(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestUpdateCategoryKeepsOmittedOptionalDefault(t *testing.T) {
+	setupCategoryTestDB()
+	defer database.DB.Close()
+
+	_, err := database.DB.Exec(`
+		INSERT INTO categories (id, name, description, user_id, color, optional_default)
+		VALUES (1, 'Dining', 'Eating out', 'test-user-id', '#ff0000', 1)
+	`)
+	if err != nil {
+		t.Fatalf("Failed to insert category: %v", err)
+	}
+
+	update := func(body string) models.Category {
+		req := httptest.NewRequest("PUT", "/categories/1", bytes.NewBufferString(body))
+		req = mux.SetURLVars(MockAuthContext(req, "test-user-id"), map[string]string{"id": "1"})
+		w := httptest.NewRecorder()
+		UpdateCategory(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var response models.Category
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Error decoding response: %v", err)
+		}
+		return response
+	}
+	storedOptionalDefault := func() bool {
+		var optionalDefault bool
+		database.DB.QueryRow("SELECT optional_default FROM categories WHERE id = 1").Scan(&optionalDefault)
+		return optionalDefault
+	}
+
+	// Without the field, as the existing frontend sends it
+	response := update(`{"name": "Restaurants", "description": "Eating out", "color": "#00ff00"}`)
+	if !storedOptionalDefault() || !response.OptionalDefault || response.Name != "Restaurants" {
+		t.Errorf("Expected the optional default to be kept, got %+v (stored %v)", response, storedOptionalDefault())
+	}
+
+	// Sending it explicitly still changes it
+	response = update(`{"name": "Restaurants", "optionalDefault": false}`)
+	if storedOptionalDefault() || response.OptionalDefault {
+		t.Errorf("Expected the optional default to be cleared, got %+v", response)
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+// categoryConfigKeys are the JSON keys in filter and report configs whose
+// values name or identify categories. Transactions store their category in
+// type, so filters on type count too.
+var categoryConfigKeys = map[string]bool{
+	"category":    true,
+	"categories":  true,
+	"categoryId":  true,
+	"categoryIds": true,
+	"type":        true,
+	"types":       true,
+}
+
+// GetCategoryReportUsage lists the caller's saved filters and custom reports
+// whose configuration references a category, so the impact of deleting or
+// merging it can be checked first
+func GetCategoryReportUsage(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		return
+	}
+
+	var name string
+	err = database.DB.QueryRow(`
+		SELECT name FROM categories
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+	`, id, userID).Scan(&name)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Category not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error getting category %d: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	usage := []models.CategoryReportUsage{}
+	for _, source := range []struct {
+		kind  string
+		query string
+	}{
+		{models.ReportUsageSavedFilter, "SELECT id, name, filter_config FROM saved_filters WHERE user_id = ? ORDER BY name, id"},
+		{models.ReportUsageCustomReport, "SELECT id, name, report_config FROM custom_reports WHERE user_id = ? ORDER BY name, id"},
+	} {
+		rows, err := database.DB.Query(source.query, userID)
+		if err != nil {
+			log.Printf("Error querying %s configs: %v", source.kind, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for rows.Next() {
+			var u models.CategoryReportUsage
+			var config string
+			if err := rows.Scan(&u.ID, &u.Name, &config); err != nil {
+				rows.Close()
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if configReferencesCategory(config, id, name) {
+				u.Kind = source.kind
+				usage = append(usage, u)
+			}
+		}
+		rows.Close()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(usage)
+}
+
+// configReferencesCategory reports whether a JSON filter or report config
+// refers to a category by ID or (case-insensitively) by name under one of the
+// category keys, at any depth
+func configReferencesCategory(config string, id int, name string) bool {
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(config), &parsed); err != nil {
+		log.Printf("Warning: skipping invalid config JSON: %v", err)
+		return false
+	}
+	return jsonReferencesCategory(parsed, id, name, false)
+}
+
+// jsonReferencesCategory walks a decoded JSON value. Values only count as
+// references when inCategoryKey is set, i.e. they sit under a category key.
+func jsonReferencesCategory(value interface{}, id int, name string, inCategoryKey bool) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if jsonReferencesCategory(child, id, name, categoryConfigKeys[key]) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if jsonReferencesCategory(child, id, name, inCategoryKey) {
+				return true
+			}
+		}
+	case string:
+		return inCategoryKey && (strings.EqualFold(strings.TrimSpace(v), name) || v == strconv.Itoa(id))
+	case float64:
+		return inCategoryKey && v == float64(id)
+	}
+	return false
+}
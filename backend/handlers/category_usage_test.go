@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+func setupSavedReportTestDB() {
+	setupTransactionCategoryTestDB()
+	createSavedReportTables()
+}
+
+// createSavedReportTables adds the saved_filters and custom_reports tables to
+// the current test database
+func createSavedReportTables() {
+	_, err := database.DB.Exec(`
+		CREATE TABLE IF NOT EXISTS saved_filters (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			filter_config TEXT NOT NULL,
+			is_default BOOLEAN NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		panic(err)
+	}
+	_, err = database.DB.Exec(`
+		CREATE TABLE IF NOT EXISTS custom_reports (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			description TEXT,
+			report_config TEXT NOT NULL,
+			is_public BOOLEAN NOT NULL DEFAULT 0,
+			public_until TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestGetCategoryReportUsage(t *testing.T) {
+	setupSavedReportTestDB()
+	defer CleanupTestDB()
+
+	database.DB.Exec("INSERT INTO categories (id, name, user_id) VALUES (3, 'Groceries', ?), (4, 'Dining', ?)", TestUserID, TestUserID)
+	database.DB.Exec(`
+		INSERT INTO saved_filters (id, user_id, name, filter_config) VALUES
+		('filter-by-name', ?, 'Food shopping', '{"category": "groceries", "paid": false}'),
+		('filter-other', ?, 'Dining out', '{"category": "Dining"}'),
+		('filter-payee', ?, 'Groceries store', '{"payTo": "Groceries"}'),
+		('filter-broken', ?, 'Broken', 'not json')
+	`, TestUserID, TestUserID, TestUserID, TestUserID)
+	database.DB.Exec(`
+		INSERT INTO custom_reports (id, user_id, name, report_config) VALUES
+		('report-by-id', ?, 'Monthly food', '{"groupBy": "category", "filters": {"categoryIds": [4, 3]}}'),
+		('report-someone-else', 'other-user', 'Their groceries', '{"categories": ["Groceries"]}')
+	`, TestUserID)
+
+	req := TestRequest("GET", "/categories/3/usage-in-reports", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "3"})
+	w := httptest.NewRecorder()
+	GetCategoryReportUsage(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var usage []models.CategoryReportUsage
+	if err := json.NewDecoder(w.Body).Decode(&usage); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	expected := []models.CategoryReportUsage{
+		{Kind: models.ReportUsageSavedFilter, ID: "filter-by-name", Name: "Food shopping"},
+		{Kind: models.ReportUsageCustomReport, ID: "report-by-id", Name: "Monthly food"},
+	}
+	if len(usage) != len(expected) {
+		t.Fatalf("Expected %d references, got %+v", len(expected), usage)
+	}
+	for i := range expected {
+		if usage[i] != expected[i] {
+			t.Errorf("Expected %+v, got %+v", expected[i], usage[i])
+		}
+	}
+}
+
+func TestGetCategoryReportUsageNotFound(t *testing.T) {
+	setupSavedReportTestDB()
+	defer CleanupTestDB()
+
+	database.DB.Exec("INSERT INTO categories (id, name, user_id) VALUES (5, 'Theirs', 'other-user')")
+
+	for _, id := range []string{"5", "99"} {
+		req := TestRequest("GET", "/categories/"+id+"/usage-in-reports", nil)
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		w := httptest.NewRecorder()
+		GetCategoryReportUsage(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status code %d for category %s, got %d", http.StatusNotFound, id, w.Code)
+		}
+	}
+}
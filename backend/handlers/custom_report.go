@@ -0,0 +1,396 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+// errInvalidReportConfig is wrapped by errors caused by a report's stored configuration
+var errInvalidReportConfig = errors.New("invalid report configuration")
+
+// errReportScopeForbidden is wrapped by errors for reports scoped to a user
+// whose transactions the user running them can't read
+var errReportScopeForbidden = errors.New("Forbidden")
+
+const customReportColumns = `id, user_id, name, description, report_config, is_public, public_until, created_at, updated_at`
+
+// customReportScanner is satisfied by both *sql.Row and *sql.Rows
+type customReportScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanCustomReport reads a custom_reports row selected with customReportColumns
+func scanCustomReport(s customReportScanner) (models.CustomReport, error) {
+	var report models.CustomReport
+	var description sql.NullString
+	var publicUntil sql.NullTime
+	err := s.Scan(&report.ID, &report.UserID, &report.Name, &description, &report.ReportConfig,
+		&report.IsPublic, &publicUntil, &report.CreatedAt, &report.UpdatedAt)
+	if err != nil {
+		return report, err
+	}
+	report.Description = description.String
+	if publicUntil.Valid {
+		report.PublicUntil = &publicUntil.Time
+	}
+	return report, nil
+}
+
+// GetAccessibleCustomReports returns the caller's own custom reports and the
+// reports other users currently share. Reports whose public_until has passed
+// are treated as private again.
+func GetAccessibleCustomReports(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT `+customReportColumns+`
+		FROM custom_reports
+		WHERE user_id = ? OR is_public = 1
+		ORDER BY name, id
+	`, userID)
+	if err != nil {
+		log.Printf("Error querying custom reports: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	reports := []models.CustomReport{}
+	for rows.Next() {
+		report, err := scanCustomReport(rows)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if report.UserID != userID && !report.SharedAt(now) {
+			continue
+		}
+		reports = append(reports, report)
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reports)
+}
+
+// ValidateCustomReportConfig checks a custom report configuration without
+// saving it, so the UI can validate as the user builds the report. Problems
+// are reported per field.
+func ValidateCustomReportConfig(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	problems := validateCustomReportConfig(body, time.Now())
+	result := models.ReportConfigValidation{Valid: len(problems) == 0, Errors: problems}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// customReportConfigFields returns pointers to the fields of a custom report
+// config, keyed by their JSON names
+func customReportConfigFields(config *models.CustomReportConfig) map[string]interface{} {
+	return map[string]interface{}{
+		"groupBy":     &config.GroupBy,
+		"startDate":   &config.StartDate,
+		"endDate":     &config.EndDate,
+		"range":       &config.Range,
+		"paid":        &config.Paid,
+		"optional":    &config.Optional,
+		"scope":       &config.Scope,
+		"scopeUserId": &config.ScopeUserID,
+		"categories":  &config.Categories,
+		"payTo":       &config.PayTo,
+		"aggregation": &config.Aggregation,
+	}
+}
+
+// customReportAggregates are the SQL aggregates of each aggregation, taking
+// the refund aware amount expression
+var customReportAggregates = map[string]string{
+	models.ReportAggregationSum:     "SUM(%s)",
+	models.ReportAggregationCount:   "COUNT(%s)",
+	models.ReportAggregationAverage: "AVG(%s)",
+}
+
+// validateCustomReportConfig lists every problem with a raw custom report
+// config, including keys that aren't config fields
+func validateCustomReportConfig(raw []byte, now time.Time) []models.ReportConfigFieldError {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &keys); err != nil || keys == nil {
+		return []models.ReportConfigFieldError{{Message: "config must be a JSON object"}}
+	}
+
+	problems := []models.ReportConfigFieldError{}
+	fields := customReportConfigFields(&models.CustomReportConfig{})
+	for key := range keys {
+		if _, ok := fields[key]; !ok {
+			problems = append(problems, models.ReportConfigFieldError{Field: key, Message: "unknown field"})
+		}
+	}
+	sort.Slice(problems, func(i, j int) bool { return problems[i].Field < problems[j].Field })
+
+	_, _, fieldProblems := parseCustomReportConfig(raw, now)
+	return append(problems, fieldProblems...)
+}
+
+// parseCustomReportConfig decodes a custom report config and resolves its
+// period, defaulting the grouping to category, the scope to accessible and
+// the aggregation to sum. Keys that aren't config fields are ignored. Any problems are returned per field, in field order.
+func parseCustomReportConfig(raw []byte, now time.Time) (models.CustomReportConfig, DateRange, []models.ReportConfigFieldError) {
+	var config models.CustomReportConfig
+	var dateRange DateRange
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &keys); err != nil || keys == nil {
+		return config, dateRange, []models.ReportConfigFieldError{{Message: "config must be a JSON object"}}
+	}
+
+	invalid := map[string]string{}
+	for key, target := range customReportConfigFields(&config) {
+		value, ok := keys[key]
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(value, target); err != nil {
+			switch target.(type) {
+			case *string:
+				invalid[key] = "must be a string"
+			case *[]string:
+				invalid[key] = "must be a list of strings"
+			default:
+				invalid[key] = "must be true or false"
+			}
+		}
+	}
+
+	if config.GroupBy == "" {
+		config.GroupBy = "category"
+	}
+	if _, ok := reportGroupColumns[config.GroupBy]; !ok && invalid["groupBy"] == "" {
+		invalid["groupBy"] = fmt.Sprintf("invalid groupBy %q (expected category, payTo or enteredBy)", config.GroupBy)
+	}
+
+	if config.Scope == "" {
+		config.Scope = models.ReportScopeAccessible
+	}
+	switch config.Scope {
+	case models.ReportScopeOwn, models.ReportScopeAccessible:
+		if config.ScopeUserID != "" && invalid["scopeUserId"] == "" {
+			invalid["scopeUserId"] = "only allowed when scope is user"
+		}
+	case models.ReportScopeUser:
+		if strings.TrimSpace(config.ScopeUserID) == "" && invalid["scopeUserId"] == "" {
+			invalid["scopeUserId"] = "required when scope is user"
+		}
+	default:
+		if invalid["scope"] == "" {
+			invalid["scope"] = fmt.Sprintf("invalid scope %q (expected own, accessible or user)", config.Scope)
+		}
+	}
+
+	if config.Aggregation == "" {
+		config.Aggregation = models.ReportAggregationSum
+	}
+	if _, ok := customReportAggregates[config.Aggregation]; !ok && invalid["aggregation"] == "" {
+		invalid["aggregation"] = fmt.Sprintf("invalid aggregation %q (expected sum, count or average)", config.Aggregation)
+	}
+
+	for _, category := range config.Categories {
+		if strings.TrimSpace(category) == "" && invalid["categories"] == "" {
+			invalid["categories"] = "must not contain blank categories"
+		}
+	}
+
+	// Check each part of the period on its own so errors name the field
+	for field, check := range map[string][3]string{
+		"startDate": {config.StartDate, "", ""},
+		"endDate":   {"", config.EndDate, ""},
+		"range":     {"", "", config.Range},
+	} {
+		if invalid[field] != "" {
+			continue
+		}
+		if _, err := ParseDateRange(check[0], check[1], check[2], now); err != nil {
+			invalid[field] = err.Error()
+		}
+	}
+	if invalid["startDate"] == "" && invalid["endDate"] == "" && invalid["range"] == "" {
+		var err error
+		dateRange, err = ParseDateRange(config.StartDate, config.EndDate, config.Range, now)
+		if err != nil {
+			field := "endDate"
+			if strings.TrimSpace(config.Range) != "" {
+				field = "range"
+			}
+			invalid[field] = err.Error()
+		}
+	}
+
+	var problems []models.ReportConfigFieldError
+	for _, field := range []string{"groupBy", "startDate", "endDate", "range", "paid", "optional", "scope", "scopeUserId",
+		"categories", "payTo", "aggregation"} {
+		if message := invalid[field]; message != "" {
+			problems = append(problems, models.ReportConfigFieldError{Field: field, Message: message})
+		}
+	}
+	return config, dateRange, problems
+}
+
+// runCustomReport aggregates each group of the transactions a custom report
+// selects. The scope picks the transactions: the user's own, all the user can
+// read, or those of the scope user; the period, flags, categories and payee
+// narrow them down. An invalid stored configuration is returned as an error wrapping
+// errInvalidReportConfig, and a scope user whose transactions the user can't
+// read as one wrapping errReportScopeForbidden.
+func runCustomReport(userID string, report models.CustomReport, now time.Time) (models.CustomReportResult, error) {
+	result := models.CustomReportResult{ReportID: report.ID, Name: report.Name, Rows: []models.CustomReportRow{}}
+
+	config, dateRange, problems := parseCustomReportConfig([]byte(report.ReportConfig), now)
+	if len(problems) > 0 {
+		return result, fmt.Errorf("%w: %s", errInvalidReportConfig, problems[0])
+	}
+	result.GroupBy = config.GroupBy
+	result.Scope = config.Scope
+	result.Aggregation = config.Aggregation
+
+	var ownerUserID string
+	switch config.Scope {
+	case models.ReportScopeOwn:
+		ownerUserID = userID
+	case models.ReportScopeUser:
+		if !middleware.CheckUserPermission(userID, config.ScopeUserID, models.ResourceTransactions, models.PermissionRead) {
+			return result, fmt.Errorf("%w: no read access to transactions of user %s", errReportScopeForbidden, config.ScopeUserID)
+		}
+		ownerUserID = config.ScopeUserID
+		result.ScopeUserID = config.ScopeUserID
+	}
+
+	filterClause, args := reportTransactionsClause(userID, ownerUserID, dateRange, config.Paid, config.Optional)
+	if len(config.Categories) > 0 {
+		category, _ := refundAwareExpressions(reportGroupColumns["category"])
+		placeholders := make([]string, len(config.Categories))
+		for i, name := range config.Categories {
+			placeholders[i] = "?"
+			args = append(args, strings.TrimSpace(name))
+		}
+		filterClause += fmt.Sprintf(" AND %s IN (%s)", category, strings.Join(placeholders, ","))
+	}
+	if config.PayTo != "" {
+		filterClause += " AND payTo LIKE ?"
+		args = append(args, "%"+config.PayTo+"%")
+	}
+
+	group, amount := refundAwareExpressions(reportGroupColumns[config.GroupBy])
+	aggregate := fmt.Sprintf(customReportAggregates[config.Aggregation], amount)
+	// The total aggregates the transactions themselves, since counts and
+	// averages of the groups don't add up
+	query := fmt.Sprintf(`
+		SELECT COALESCE(%s, ''), %s, 0
+		FROM transactions
+		WHERE deleted_at IS NULL %s
+		GROUP BY COALESCE(%s, '')
+		UNION ALL
+		SELECT NULL, COALESCE(%s, 0), 1
+		FROM transactions
+		WHERE deleted_at IS NULL %s
+	`, group, aggregate, filterClause, group, aggregate, filterClause)
+
+	rows, err := database.ReadDB().Query(query, append(args, args...)...)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var group sql.NullString
+		var value float64
+		var isTotal bool
+		if err := rows.Scan(&group, &value, &isTotal); err != nil {
+			return result, err
+		}
+		value = math.Round(value*100) / 100
+		if isTotal {
+			result.Total = models.Amount(value)
+		} else {
+			result.Rows = append(result.Rows, models.CustomReportRow{Group: group.String, Total: models.Amount(value)})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+
+	sort.Slice(result.Rows, func(i, j int) bool {
+		return result.Rows[i].Group < result.Rows[j].Group
+	})
+	return result, nil
+}
+
+// RunCustomReport runs one of the caller's own or shared custom reports and
+// returns each group's value along with the overall value. A report whose
+// stored configuration is invalid is a bad request.
+func RunCustomReport(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	now := time.Now()
+	reports, status, err := exportedReports(userID, []string{mux.Vars(r)["id"]}, now)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	result, err := runCustomReport(userID, reports[0], now)
+	if errors.Is(err, errInvalidReportConfig) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if errors.Is(err, errReportScopeForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	} else if err != nil {
+		log.Printf("Error running custom report %s: %v", reports[0].ID, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
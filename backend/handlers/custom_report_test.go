@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+func getAccessibleCustomReportIDs(t *testing.T, userID string) []string {
+	t.Helper()
+
+	req := TestRequest("GET", "/reports/custom", nil)
+	req = MockAuthContext(req, userID)
+	w := httptest.NewRecorder()
+	GetAccessibleCustomReports(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var reports []models.CustomReport
+	if err := json.NewDecoder(w.Body).Decode(&reports); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	ids := make([]string, len(reports))
+	for i, report := range reports {
+		ids[i] = report.ID
+	}
+	return ids
+}
+
+func TestGetAccessibleCustomReportsPublicUntil(t *testing.T) {
+	setupSavedReportTestDB()
+	defer CleanupTestDB()
+
+	now := time.Now()
+	_, err := database.DB.Exec(`
+		INSERT INTO custom_reports (id, user_id, name, report_config, is_public, public_until) VALUES
+		('a-forever', 'owner', 'A forever', '{}', 1, NULL),
+		('b-expired', 'owner', 'B expired', '{}', 1, ?),
+		('c-until-later', 'owner', 'C until later', '{}', 1, ?),
+		('d-private', 'owner', 'D private', '{}', 0, NULL)
+	`, now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to seed custom reports: %v", err)
+	}
+
+	tests := []struct {
+		userID   string
+		expected []string
+	}{
+		{"owner", []string{"a-forever", "b-expired", "c-until-later", "d-private"}},
+		{"viewer", []string{"a-forever", "c-until-later"}},
+	}
+	for _, tt := range tests {
+		ids := getAccessibleCustomReportIDs(t, tt.userID)
+		if len(ids) != len(tt.expected) {
+			t.Errorf("Expected %v for %s, got %v", tt.expected, tt.userID, ids)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != tt.expected[i] {
+				t.Errorf("Expected %v for %s, got %v", tt.expected, tt.userID, ids)
+				break
+			}
+		}
+	}
+}
+
+func TestValidateCustomReportConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   string
+		expected []models.ReportConfigFieldError
+	}{
+		{
+			name:   "valid with explicit dates",
+			config: `{"groupBy": "payTo", "startDate": "2024-01-01", "endDate": "2024-03-31", "paid": true}`,
+		},
+		{
+			name:   "valid with a range and default grouping",
+			config: `{"range": "ytd", "optional": false}`,
+		},
+		{
+			name:     "not an object",
+			config:   `["category"]`,
+			expected: []models.ReportConfigFieldError{{Message: "config must be a JSON object"}},
+		},
+		{
+			name:   "unknown grouping and field",
+			config: `{"groupBy": "amount", "colour": "red"}`,
+			expected: []models.ReportConfigFieldError{
+				{Field: "colour", Message: "unknown field"},
+				{Field: "groupBy", Message: `invalid groupBy "amount" (expected category, payTo or enteredBy)`},
+			},
+		},
+		{
+			name:   "wrong types",
+			config: `{"groupBy": 3, "paid": "yes"}`,
+			expected: []models.ReportConfigFieldError{
+				{Field: "groupBy", Message: "must be a string"},
+				{Field: "paid", Message: "must be true or false"},
+			},
+		},
+		{
+			name:   "bad dates",
+			config: `{"startDate": "01/02/2024", "endDate": "2024-02-30"}`,
+			expected: []models.ReportConfigFieldError{
+				{Field: "startDate", Message: `invalid startDate "01/02/2024" (expected YYYY-MM-DD)`},
+				{Field: "endDate", Message: `invalid endDate "2024-02-30" (expected YYYY-MM-DD)`},
+			},
+		},
+		{
+			name:     "start after end",
+			config:   `{"startDate": "2024-03-01", "endDate": "2024-02-01"}`,
+			expected: []models.ReportConfigFieldError{{Field: "endDate", Message: "startDate 2024-03-01 is after endDate 2024-02-01"}},
+		},
+		{
+			name:   "valid scopes",
+			config: `{"scope": "user", "scopeUserId": "partner"}`,
+		},
+		{
+			name:   "bad scopes",
+			config: `{"scope": "household", "scopeUserId": "partner"}`,
+			expected: []models.ReportConfigFieldError{
+				{Field: "scope", Message: `invalid scope "household" (expected own, accessible or user)`},
+			},
+		},
+		{
+			name:     "user scope without a user",
+			config:   `{"scope": "user"}`,
+			expected: []models.ReportConfigFieldError{{Field: "scopeUserId", Message: "required when scope is user"}},
+		},
+		{
+			name:     "scope user with another scope",
+			config:   `{"scope": "own", "scopeUserId": "partner"}`,
+			expected: []models.ReportConfigFieldError{{Field: "scopeUserId", Message: "only allowed when scope is user"}},
+		},
+		{
+			name:   "valid filters and aggregation",
+			config: `{"categories": ["Food", "Fun"], "payTo": "Sarah", "aggregation": "average"}`,
+		},
+		{
+			name:   "bad filters and aggregation",
+			config: `{"categories": "Food", "payTo": 3, "aggregation": "median"}`,
+			expected: []models.ReportConfigFieldError{
+				{Field: "categories", Message: "must be a list of strings"},
+				{Field: "payTo", Message: "must be a string"},
+				{Field: "aggregation", Message: `invalid aggregation "median" (expected sum, count or average)`},
+			},
+		},
+		{
+			name:     "blank category",
+			config:   `{"categories": ["Food", " "]}`,
+			expected: []models.ReportConfigFieldError{{Field: "categories", Message: "must not contain blank categories"}},
+		},
+		{
+			name:     "range combined with dates",
+			config:   `{"range": "thisMonth", "startDate": "2024-03-01"}`,
+			expected: []models.ReportConfigFieldError{{Field: "range", Message: "range cannot be combined with startDate or endDate"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := TestRequest("POST", "/reports/custom/validate", &tc.config)
+			w := httptest.NewRecorder()
+			ValidateCustomReportConfig(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+
+			var result models.ReportConfigValidation
+			if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+				t.Fatalf("Error decoding response: %v", err)
+			}
+			if result.Valid != (len(tc.expected) == 0) {
+				t.Errorf("Expected valid=%v, got %v", len(tc.expected) == 0, result.Valid)
+			}
+			if len(result.Errors) != len(tc.expected) {
+				t.Fatalf("Expected errors %+v, got %+v", tc.expected, result.Errors)
+			}
+			for i := range tc.expected {
+				if result.Errors[i] != tc.expected[i] {
+					t.Errorf("Expected %+v, got %+v", tc.expected[i], result.Errors[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRunCustomReportScopes(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	// The viewer can read the partner's transactions but not the stranger's
+	february, _ := time.Parse("2006-01-02", "2023-02-10")
+	for _, stmt := range []string{
+		`INSERT INTO users (id, username, name, isAdmin, role) VALUES
+			('viewer', 'viewer', 'Viewer', 0, 'user'),
+			('partner', 'partner', 'Partner', 0, 'user'),
+			('stranger', 'stranger', 'Stranger', 0, 'user')`,
+		`INSERT INTO permissions (granted_user_id, owner_user_id, resource_type, permission_type)
+			VALUES ('viewer', 'partner', 'transactions', 'read')`,
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to set up users: %v", err)
+		}
+	}
+	for _, tx := range []struct {
+		id     string
+		amount float64
+		userID string
+	}{
+		{"viewer-tx", 10, "viewer"},
+		{"partner-tx", 20, "partner"},
+		{"stranger-tx", 40, "stranger"},
+	} {
+		_, err := database.DB.Exec(`
+			INSERT INTO transactions (id, amount, description, date, type, payTo, paid, enteredBy, optional, userId)
+			VALUES (?, ?, 'Scoped', ?, 'Gifts', 'Shop', 1, ?, 0, ?)
+		`, tx.id, tx.amount, february, tx.userID, tx.userID)
+		if err != nil {
+			t.Fatalf("Failed to insert transaction: %v", err)
+		}
+	}
+
+	const period = `"startDate": "2023-02-01", "endDate": "2023-02-28", "groupBy": "payTo"`
+	now := time.Now()
+	testCases := []struct {
+		name          string
+		userID        string
+		config        string
+		expectedScope string
+		expectedTotal models.Amount
+		forbidden     bool
+	}{
+		{"own", "viewer", `{"scope": "own", ` + period + `}`, models.ReportScopeOwn, 10, false},
+		{"accessible by default", "viewer", `{` + period + `}`, models.ReportScopeAccessible, 30, false},
+		{"accessible", "viewer", `{"scope": "accessible", ` + period + `}`, models.ReportScopeAccessible, 30, false},
+		{"shared user", "viewer", `{"scope": "user", "scopeUserId": "partner", ` + period + `}`, models.ReportScopeUser, 20, false},
+		{"unshared user", "viewer", `{"scope": "user", "scopeUserId": "stranger", ` + period + `}`, models.ReportScopeUser, 0, true},
+		{"own for another runner", "partner", `{"scope": "own", ` + period + `}`, models.ReportScopeOwn, 20, false},
+		{"admin", testUserID, `{"scope": "user", "scopeUserId": "stranger", ` + period + `}`, models.ReportScopeUser, 40, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report := models.CustomReport{ID: "scoped", UserID: "viewer", Name: "Scoped", ReportConfig: tc.config, IsPublic: true}
+			result, err := runCustomReport(tc.userID, report, now)
+			if tc.forbidden {
+				if !errors.Is(err, errReportScopeForbidden) {
+					t.Fatalf("Expected a forbidden scope error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Error running report: %v", err)
+			}
+			if result.Scope != tc.expectedScope {
+				t.Errorf("Expected scope %s, got %s", tc.expectedScope, result.Scope)
+			}
+			if result.Total != tc.expectedTotal {
+				t.Errorf("Expected total %.2f, got %.2f (%+v)", tc.expectedTotal, result.Total, result.Rows)
+			}
+		})
+	}
+}
+
+func TestRunCustomReport(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+	_, err := database.DB.Exec(`
+		CREATE TABLE custom_reports (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			description TEXT,
+			report_config TEXT NOT NULL,
+			is_public BOOLEAN NOT NULL DEFAULT 0,
+			public_until TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create custom_reports table: %v", err)
+	}
+
+	// The period ends on the day of the March transactions, which are included
+	const period = `"startDate": "2023-01-01", "endDate": "2023-03-31", "paid": true`
+	testCases := []struct {
+		name           string
+		config         string
+		expectedStatus int
+		expectedRows   []models.CustomReportRow
+		expectedTotal  models.Amount
+	}{
+		{
+			name:           "several categories",
+			config:         `{"categories": ["Food", "Fun"], ` + period + `}`,
+			expectedStatus: http.StatusOK,
+			expectedRows:   []models.CustomReportRow{{Group: "Food", Total: 225}, {Group: "Fun", Total: 60}},
+			expectedTotal:  285,
+		},
+		{
+			name:           "count by payee",
+			config:         `{"payTo": "sar", "aggregation": "count", ` + period + `}`,
+			expectedStatus: http.StatusOK,
+			expectedRows:   []models.CustomReportRow{{Group: "Food", Total: 2}, {Group: "Fun", Total: 1}, {Group: "Housing", Total: 1}},
+			expectedTotal:  4,
+		},
+		{
+			name:           "average",
+			config:         `{"groupBy": "enteredBy", "categories": ["Food", "Housing"], "aggregation": "average", ` + period + `}`,
+			expectedStatus: http.StatusOK,
+			expectedRows:   []models.CustomReportRow{{Group: "Patrick", Total: 108.33}, {Group: "Sarah", Total: 125}},
+			expectedTotal:  115,
+		},
+		{
+			name:           "no matching transactions",
+			config:         `{"categories": ["Travel"], ` + period + `}`,
+			expectedStatus: http.StatusOK,
+			expectedRows:   []models.CustomReportRow{},
+		},
+		{
+			name:           "invalid config",
+			config:         `{"aggregation": "median", ` + period + `}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := database.DB.Exec("INSERT OR REPLACE INTO custom_reports (id, user_id, name, report_config) VALUES ('report-1', ?, 'Report', ?)",
+				testUserID, tc.config)
+			if err != nil {
+				t.Fatalf("Failed to insert report: %v", err)
+			}
+
+			req := TestRequest("GET", "/reports/custom/report-1/run", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "report-1"})
+			w := httptest.NewRecorder()
+			RunCustomReport(w, req)
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status code %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var result models.CustomReportResult
+			if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+				t.Fatalf("Error decoding response: %v", err)
+			}
+			if result.Rows == nil || len(result.Rows) != len(tc.expectedRows) {
+				t.Fatalf("Expected rows %+v, got %+v", tc.expectedRows, result.Rows)
+			}
+			for i := range tc.expectedRows {
+				if result.Rows[i] != tc.expectedRows[i] {
+					t.Errorf("Expected %+v, got %+v", tc.expectedRows[i], result.Rows[i])
+				}
+			}
+			if result.Total != tc.expectedTotal {
+				t.Errorf("Expected total %.2f, got %.2f", tc.expectedTotal, result.Total)
+			}
+		})
+	}
+
+	req := TestRequest("GET", "/reports/custom/missing/run", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "missing"})
+	w := httptest.NewRecorder()
+	RunCustomReport(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d for a missing report, got %d", http.StatusNotFound, w.Code)
+	}
+}
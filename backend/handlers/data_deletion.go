@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// ownedTransactions selects the IDs of a user's transactions, including
+// soft-deleted ones
+const ownedTransactions = "SELECT id FROM transactions WHERE userId = ?"
+
+// userDataDeletions are the statements removing a user's data, each taking
+// the user ID, in an order that removes dependent rows first
+var userDataDeletions = []struct {
+	table string
+	query string
+}{
+	{"transaction_categories", "DELETE FROM transaction_categories WHERE transaction_id IN (" + ownedTransactions + ")"},
+	{"transaction_tags", "DELETE FROM transaction_tags WHERE transaction_id IN (" + ownedTransactions + ")"},
+	{"transaction_history", "DELETE FROM transaction_history WHERE transaction_id IN (" + ownedTransactions + ")"},
+	{"ynab_sync_queue", "DELETE FROM ynab_sync_queue WHERE user_id = ?"},
+	{"transactions", "DELETE FROM transactions WHERE userId = ?"},
+	{"settlement_snapshots", "DELETE FROM settlement_snapshots WHERE user_id = ?"},
+	{"settlements", "DELETE FROM settlements WHERE from_user_id = ?"},
+	{"recurring_transactions", "DELETE FROM recurring_transactions WHERE user_id = ?"},
+	{"transaction_templates", "DELETE FROM transaction_templates WHERE user_id = ?"},
+	{"categorization_rules", "DELETE FROM categorization_rules WHERE user_id = ?"},
+	{"category_budgets", "DELETE FROM category_budgets WHERE user_id = ?"},
+	{"categories", "DELETE FROM categories WHERE user_id = ?"},
+	{"saved_filters", "DELETE FROM saved_filters WHERE user_id = ?"},
+	{"custom_reports", "DELETE FROM custom_reports WHERE user_id = ?"},
+	{"ynab_categories", "DELETE FROM ynab_categories WHERE user_id = ?"},
+	{"ynab_category_groups", "DELETE FROM ynab_category_groups WHERE user_id = ?"},
+	{"ynab_config", "DELETE FROM ynab_config WHERE user_id = ?"},
+	{"user_ynab_settings", "DELETE FROM user_ynab_settings WHERE user_id = ?"},
+}
+
+// DeleteMyData permanently removes all of the caller's own data: their
+// transactions and everything linked to them, categories, rules, templates,
+// saved filters, custom reports and YNAB configuration. The user record and
+// permissions are kept. The body must confirm with
+// {"confirm": "DELETE MY DATA"}. Everything is removed in one transaction.
+func DeleteMyData(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	var request models.DataDeletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Confirm != models.DataDeletionConfirmation {
+		http.Error(w, `Confirm by sending {"confirm": "`+models.DataDeletionConfirmation+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	result := models.DataDeletionResult{Deleted: map[string]int64{}}
+	for _, deletion := range userDataDeletions {
+		// Not every deployment has every table
+		exists, err := tableExists(tx, deletion.table)
+		if err != nil {
+			log.Printf("Error checking for table %s: %v", deletion.table, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !exists {
+			continue
+		}
+
+		deleted, err := tx.Exec(deletion.query, userID)
+		if err != nil {
+			log.Printf("Error deleting %s of user %s: %v", deletion.table, userID, err)
+			http.Error(w, "Error deleting "+deletion.table, http.StatusInternalServerError)
+			return
+		}
+		result.Deleted[deletion.table], _ = deleted.RowsAffected()
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Deleted all data of user %s: %v", userID, result.Deleted)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// tableExists reports whether the database has a table of the given name
+func tableExists(tx *sql.Tx, name string) (bool, error) {
+	var exists bool
+	err := tx.QueryRow("SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&exists)
+	return exists, err
+}
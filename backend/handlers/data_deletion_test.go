@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+)
+
+func TestDeleteMyData(t *testing.T) {
+	setupSavedReportTestDB()
+	defer CleanupTestDB()
+	if err := ensureYNABConfigTable(database.DB); err != nil {
+		t.Fatalf("Failed to create ynab_config table: %v", err)
+	}
+
+	date := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	insertTestTransaction(t, "tx-mine", 12.5, date, TestUserID)
+	insertTestTransaction(t, "tx-theirs", 99, date, "other-user")
+
+	for _, stmt := range []string{
+		"INSERT INTO categories (id, name, user_id) VALUES (1, 'Groceries', 'test-user-id'), (2, 'Groceries', 'other-user')",
+		"INSERT INTO transaction_categories (transaction_id, category_id, amount) VALUES ('tx-mine', 1, 12.5), ('tx-theirs', 2, 99)",
+		"INSERT INTO transaction_tags (transaction_id, tag) VALUES ('tx-mine', 'trip'), ('tx-theirs', 'trip')",
+		"INSERT INTO saved_filters (id, user_id, name, filter_config) VALUES ('filter-mine', 'test-user-id', 'Mine', '{}'), ('filter-theirs', 'other-user', 'Theirs', '{}')",
+		"INSERT INTO custom_reports (id, user_id, name, report_config) VALUES ('report-mine', 'test-user-id', 'Mine', '{}'), ('report-theirs', 'other-user', 'Theirs', '{}')",
+		"INSERT INTO ynab_config (user_id, encrypted_api_token, sync_frequency) VALUES ('test-user-id', 'token', 60), ('other-user', 'token', 60)",
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to insert test data: %v", err)
+		}
+	}
+
+	body := `{"confirm": "DELETE MY DATA"}`
+	req := TestRequest("DELETE", "/me/data", &body)
+	w := httptest.NewRecorder()
+	DeleteMyData(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	for _, check := range []struct {
+		query    string
+		expected int
+	}{
+		{"SELECT COUNT(*) FROM transactions WHERE userId = 'test-user-id'", 0},
+		{"SELECT COUNT(*) FROM transaction_categories WHERE transaction_id = 'tx-mine'", 0},
+		{"SELECT COUNT(*) FROM transaction_tags WHERE transaction_id = 'tx-mine'", 0},
+		{"SELECT COUNT(*) FROM categories WHERE user_id = 'test-user-id'", 0},
+		{"SELECT COUNT(*) FROM saved_filters WHERE user_id = 'test-user-id'", 0},
+		{"SELECT COUNT(*) FROM custom_reports WHERE user_id = 'test-user-id'", 0},
+		{"SELECT COUNT(*) FROM ynab_config WHERE user_id = 'test-user-id'", 0},
+		// The user record and other users' data are kept
+		{"SELECT COUNT(*) FROM users WHERE id = 'test-user-id'", 1},
+		{"SELECT COUNT(*) FROM transactions WHERE userId = 'other-user'", 1},
+		{"SELECT COUNT(*) FROM transaction_categories WHERE transaction_id = 'tx-theirs'", 1},
+		{"SELECT COUNT(*) FROM transaction_tags WHERE transaction_id = 'tx-theirs'", 1},
+		{"SELECT COUNT(*) FROM categories WHERE user_id = 'other-user'", 1},
+		{"SELECT COUNT(*) FROM saved_filters WHERE user_id = 'other-user'", 1},
+		{"SELECT COUNT(*) FROM custom_reports WHERE user_id = 'other-user'", 1},
+		{"SELECT COUNT(*) FROM ynab_config WHERE user_id = 'other-user'", 1},
+	} {
+		var count int
+		if err := database.DB.QueryRow(check.query).Scan(&count); err != nil {
+			t.Fatalf("%s failed: %v", check.query, err)
+		}
+		if count != check.expected {
+			t.Errorf("%s: expected %d, got %d", check.query, check.expected, count)
+		}
+	}
+}
+
+func TestDeleteMyDataRequiresConfirmation(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	insertTestTransaction(t, "tx-mine", 12.5, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), TestUserID)
+
+	for _, body := range []string{"", `{}`, `{"confirm": "yes"}`} {
+		body := body
+		req := TestRequest("DELETE", "/me/data", &body)
+		w := httptest.NewRecorder()
+		DeleteMyData(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Body %q: expected status code %d, got %d", body, http.StatusBadRequest, w.Code)
+		}
+	}
+
+	if count := countTransactions(t); count != 1 {
+		t.Errorf("Expected the transaction to be kept without confirmation, got %d", count)
+	}
+}
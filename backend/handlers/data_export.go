@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// ExportMyData returns everything stored about the caller as one JSON file
+// for data portability: their profile, own transactions, active categories,
+// saved filters, custom reports, the permissions they granted or received
+// and their YNAB configuration without the API token.
+func ExportMyData(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	export := models.DataExport{ExportedAt: time.Now()}
+
+	var username, name, status, role sql.NullString
+	var isAdmin sql.NullBool
+	err := database.DB.QueryRow("SELECT id, username, name, status, isAdmin, role FROM users WHERE id = ?", userID).Scan(
+		&export.User.ID, &username, &name, &status, &isAdmin, &role)
+	if err == sql.ErrNoRows {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error reading user %s for data export: %v", userID, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	export.User.Username = username.String
+	export.User.Name = name.String
+	export.User.Status = status.String
+	export.User.IsAdmin = isAdmin.Bool
+	export.User.Role = role.String
+
+	loaders := []struct {
+		name string
+		load func() error
+	}{
+		{"transactions", func() (err error) { export.Transactions, err = exportTransactions(userID); return }},
+		{"categories", func() (err error) { export.Categories, err = loadCategories(userID, "name, id"); return }},
+		{"saved filters", func() (err error) { export.SavedFilters, err = exportSavedFilters(userID); return }},
+		{"custom reports", func() (err error) {
+			export.CustomReports, _, err = exportedReports(userID, nil, export.ExportedAt)
+			return
+		}},
+		{"permissions", func() (err error) { export.Permissions, err = exportPermissions(userID); return }},
+		{"YNAB config", func() (err error) { export.YNABConfig, err = models.GetYNABConfig(database.DB, userID); return }},
+	}
+	for _, loader := range loaders {
+		if err := loader.load(); err != nil {
+			log.Printf("Error exporting %s for user %s: %v", loader.name, userID, err)
+			http.Error(w, "Error exporting "+loader.name, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Never export the token, not even masked
+	export.YNABConfig.APIToken = ""
+
+	// Empty lists rather than null, so every data type is present
+	if export.Transactions == nil {
+		export.Transactions = []models.Transaction{}
+	}
+	if export.Categories == nil {
+		export.Categories = []models.Category{}
+	}
+	if export.SavedFilters == nil {
+		export.SavedFilters = []models.SavedFilter{}
+	}
+	if export.CustomReports == nil {
+		export.CustomReports = []models.CustomReport{}
+	}
+	if export.Permissions == nil {
+		export.Permissions = []models.Permission{}
+	}
+
+	fileName := fmt.Sprintf("bennwallet-data-%s-%s.json",
+		unsafeFileNameChars.ReplaceAllString(export.User.Username, "_"), export.ExportedAt.Format(dateLayout))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	json.NewEncoder(w).Encode(export)
+}
+
+// exportTransactions returns the user's own transactions oldest first
+func exportTransactions(userID string) ([]models.Transaction, error) {
+	rows, err := database.DB.Query(`
+		SELECT id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional, userId,
+			source, status
+		FROM transactions
+		WHERE userId = ? AND deleted_at IS NULL
+		ORDER BY date, id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []models.Transaction
+	for rows.Next() {
+		var t models.Transaction
+		var payTo, paidDate, ownerID sql.NullString
+		var transactionDate sql.NullTime
+		err := rows.Scan(&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate, &t.Type, &payTo,
+			&t.Paid, &paidDate, &t.EnteredBy, &t.Optional, &ownerID, &t.Source, &t.Status)
+		if err != nil {
+			return nil, err
+		}
+		t.PayTo = payTo.String
+		t.PaidDate = paidDate.String
+		t.UserID = ownerID.String
+		if transactionDate.Valid {
+			t.TransactionDate = transactionDate.Time
+		} else {
+			t.TransactionDate = t.Date
+		}
+		transactions = append(transactions, t)
+	}
+	return transactions, rows.Err()
+}
+
+// exportSavedFilters returns the user's saved filters by name
+func exportSavedFilters(userID string) ([]models.SavedFilter, error) {
+	rows, err := database.DB.Query(`
+		SELECT id, user_id, name, filter_config, is_default, created_at, updated_at
+		FROM saved_filters
+		WHERE user_id = ?
+		ORDER BY name, id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var filters []models.SavedFilter
+	for rows.Next() {
+		var f models.SavedFilter
+		var createdAt, updatedAt sql.NullTime
+		if err := rows.Scan(&f.ID, &f.UserID, &f.Name, &f.FilterConfig, &f.IsDefault, &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		f.CreatedAt = createdAt.Time
+		f.UpdatedAt = updatedAt.Time
+		filters = append(filters, f)
+	}
+	return filters, rows.Err()
+}
+
+// exportPermissions returns the permissions the user granted or was granted
+func exportPermissions(userID string) ([]models.Permission, error) {
+	rows, err := database.DB.Query(`
+		SELECT id, owner_user_id, granted_user_id, permission_type, resource_type, created_at, expires_at
+		FROM permissions
+		WHERE owner_user_id = ? OR granted_user_id = ?
+		ORDER BY id
+	`, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var permissions []models.Permission
+	for rows.Next() {
+		var p models.Permission
+		var createdAt, expiresAt sql.NullTime
+		if err := rows.Scan(&p.ID, &p.OwnerUserID, &p.GrantedUserID, &p.PermissionType, &p.ResourceType, &createdAt, &expiresAt); err != nil {
+			return nil, err
+		}
+		p.CreatedAt = createdAt.Time
+		p.ExpiresAt = expiresAt.Time
+		permissions = append(permissions, p)
+	}
+	return permissions, rows.Err()
+}
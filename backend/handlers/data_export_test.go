@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+	"bennwallet/backend/security"
+)
+
+func TestExportMyData(t *testing.T) {
+	setupSavedReportTestDB()
+	defer CleanupTestDB()
+	security.InitializeEncryption("test-encryption-key")
+	if err := ensureYNABConfigTable(database.DB); err != nil {
+		t.Fatalf("Failed to create ynab_config table: %v", err)
+	}
+
+	insertTestTransaction(t, "tx-mine", 12.5, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), TestUserID)
+	insertTestTransaction(t, "tx-theirs", 99, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), "other-user")
+
+	var encrypted []string
+	for _, value := range []string{"secret-token", "budget-1", "account-1"} {
+		e, err := security.Encrypt(value)
+		if err != nil {
+			t.Fatalf("Failed to encrypt: %v", err)
+		}
+		encrypted = append(encrypted, e)
+	}
+
+	for _, stmt := range []struct {
+		query string
+		args  []interface{}
+	}{
+		{"INSERT INTO categories (name, description, color, user_id) VALUES ('Groceries', 'Food', '#00ff00', ?), ('Theirs', '', '', 'other-user')", []interface{}{TestUserID}},
+		{"INSERT INTO saved_filters (id, user_id, name, filter_config) VALUES ('filter-1', ?, 'Unpaid', '{\"paid\": false}')", []interface{}{TestUserID}},
+		{"INSERT INTO custom_reports (id, user_id, name, report_config) VALUES ('report-1', ?, 'Monthly', '{\"groupBy\": \"category\"}')", []interface{}{TestUserID}},
+		{`INSERT INTO permissions (granted_user_id, owner_user_id, resource_type, permission_type) VALUES
+			('other-user', ?, 'transactions', 'read'),
+			(?, 'other-user', 'transactions', 'write'),
+			('third-user', 'other-user', 'transactions', 'read')`, []interface{}{TestUserID, TestUserID}},
+		{`INSERT INTO ynab_config (user_id, encrypted_api_token, encrypted_budget_id, encrypted_account_id, sync_frequency, created_at, updated_at)
+			VALUES (?, ?, ?, ?, 60, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`, []interface{}{TestUserID, encrypted[0], encrypted[1], encrypted[2]}},
+	} {
+		if _, err := database.DB.Exec(stmt.query, stmt.args...); err != nil {
+			t.Fatalf("Failed to insert test data: %v", err)
+		}
+	}
+
+	req := TestRequest("GET", "/me/data-export", nil)
+	w := httptest.NewRecorder()
+	ExportMyData(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if disposition := w.Header().Get("Content-Disposition"); !strings.HasPrefix(disposition, "attachment;") {
+		t.Errorf("Expected an attachment, got Content-Disposition %q", disposition)
+	}
+	if strings.Contains(w.Body.String(), "secret-token") {
+		t.Error("Expected the YNAB API token to be left out of the export")
+	}
+
+	var export models.DataExport
+	if err := json.NewDecoder(w.Body).Decode(&export); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	if export.User.ID != TestUserID {
+		t.Errorf("Expected the caller's profile, got %+v", export.User)
+	}
+	if len(export.Transactions) != 1 || export.Transactions[0].ID != "tx-mine" {
+		t.Errorf("Expected only the caller's transaction, got %+v", export.Transactions)
+	}
+	if len(export.Categories) != 1 || export.Categories[0].Name != "Groceries" {
+		t.Errorf("Expected only the caller's category, got %+v", export.Categories)
+	}
+	if len(export.SavedFilters) != 1 || export.SavedFilters[0].ID != "filter-1" {
+		t.Errorf("Expected the saved filter, got %+v", export.SavedFilters)
+	}
+	if len(export.CustomReports) != 1 || export.CustomReports[0].ID != "report-1" {
+		t.Errorf("Expected the custom report, got %+v", export.CustomReports)
+	}
+	if len(export.Permissions) != 2 {
+		t.Errorf("Expected the permissions granted by and to the caller, got %+v", export.Permissions)
+	}
+	if export.YNABConfig == nil || export.YNABConfig.BudgetID != "budget-1" || export.YNABConfig.APIToken != "" {
+		t.Errorf("Expected the YNAB budget without the token, got %+v", export.YNABConfig)
+	}
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"bennwallet/backend/middleware"
+)
+
+// dateLayout is the only explicit date format accepted in date range parameters
+const dateLayout = "2006-01-02"
+
+// DateRange is an inclusive range of calendar days. A zero Start or End
+// means the range is open on that side.
+type DateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// dateRangeShortcuts maps relative range names to functions computing their bounds
+var dateRangeShortcuts = map[string]func(today time.Time) (time.Time, time.Time){
+	"thisMonth": func(today time.Time) (time.Time, time.Time) {
+		start := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1)
+	},
+	"lastMonth": func(today time.Time) (time.Time, time.Time) {
+		start := time.Date(today.Year(), today.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1)
+	},
+	"ytd": func(today time.Time) (time.Time, time.Time) {
+		return time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, time.UTC), today
+	},
+}
+
+// ParseDateRange validates and normalizes a date range. Either a relative
+// shortcut (thisMonth, lastMonth, ytd) or explicit YYYY-MM-DD bounds may be
+// given, but not both. Shortcuts are resolved relative to now.
+func ParseDateRange(startDate, endDate, shortcut string, now time.Time) (DateRange, error) {
+	var dr DateRange
+
+	startDate = strings.TrimSpace(startDate)
+	endDate = strings.TrimSpace(endDate)
+	shortcut = strings.TrimSpace(shortcut)
+
+	if shortcut != "" {
+		if startDate != "" || endDate != "" {
+			return dr, fmt.Errorf("range cannot be combined with startDate or endDate")
+		}
+
+		resolve, ok := dateRangeShortcuts[shortcut]
+		if !ok {
+			return dr, fmt.Errorf("unknown range %q (expected thisMonth, lastMonth or ytd)", shortcut)
+		}
+
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		dr.Start, dr.End = resolve(today)
+		return dr, nil
+	}
+
+	if startDate != "" {
+		start, err := time.Parse(dateLayout, startDate)
+		if err != nil {
+			return dr, fmt.Errorf("invalid startDate %q (expected YYYY-MM-DD)", startDate)
+		}
+		dr.Start = start
+	}
+
+	if endDate != "" {
+		end, err := time.Parse(dateLayout, endDate)
+		if err != nil {
+			return dr, fmt.Errorf("invalid endDate %q (expected YYYY-MM-DD)", endDate)
+		}
+		dr.End = end
+	}
+
+	if !dr.Start.IsZero() && !dr.End.IsZero() && dr.Start.After(dr.End) {
+		return dr, fmt.Errorf("startDate %s is after endDate %s", startDate, endDate)
+	}
+
+	return dr, nil
+}
+
+// StartString returns the start bound as YYYY-MM-DD, or "" when open
+func (dr DateRange) StartString() string {
+	if dr.Start.IsZero() {
+		return ""
+	}
+	return dr.Start.Format(dateLayout)
+}
+
+// EndString returns the inclusive end bound as YYYY-MM-DD, or "" when open
+func (dr DateRange) EndString() string {
+	if dr.End.IsZero() {
+		return ""
+	}
+	return dr.End.Format(dateLayout)
+}
+
+// EndExclusiveString returns the day after the end bound, for use in
+// "column < ?" comparisons so that timestamps on the last day are included
+func (dr DateRange) EndExclusiveString() string {
+	if dr.End.IsZero() {
+		return ""
+	}
+	return dr.End.AddDate(0, 0, 1).Format(dateLayout)
+}
+
+// SQLConditions returns the WHERE fragment and args restricting column to the range
+func (dr DateRange) SQLConditions(column string) (string, []interface{}) {
+	var clause string
+	var args []interface{}
+	if !dr.Start.IsZero() {
+		clause += fmt.Sprintf(" AND %s >= ?", column)
+		args = append(args, dr.StartString())
+	}
+	if !dr.End.IsZero() {
+		clause += fmt.Sprintf(" AND %s < ?", column)
+		args = append(args, dr.EndExclusiveString())
+	}
+	return clause, args
+}
+
+// NormalizeDateRange validates a date range and returns its concrete bounds
+func NormalizeDateRange(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	dr, err := ParseDateRange(query.Get("startDate"), query.Get("endDate"), query.Get("range"), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"startDate": dr.StartString(),
+		"endDate":   dr.EndString(),
+	})
+}
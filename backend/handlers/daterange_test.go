@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseDateRangeShortcuts(t *testing.T) {
+	now := time.Date(2024, time.March, 15, 18, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		shortcut      string
+		expectedStart string
+		expectedEnd   string
+	}{
+		{"thisMonth", "2024-03-01", "2024-03-31"},
+		{"lastMonth", "2024-02-01", "2024-02-29"},
+		{"ytd", "2024-01-01", "2024-03-15"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.shortcut, func(t *testing.T) {
+			dr, err := ParseDateRange("", "", tc.shortcut, now)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if dr.StartString() != tc.expectedStart {
+				t.Errorf("Expected start %s, got %s", tc.expectedStart, dr.StartString())
+			}
+			if dr.EndString() != tc.expectedEnd {
+				t.Errorf("Expected end %s, got %s", tc.expectedEnd, dr.EndString())
+			}
+		})
+	}
+}
+
+func TestParseDateRangeLastMonthAcrossYearBoundary(t *testing.T) {
+	now := time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC)
+
+	dr, err := ParseDateRange("", "", "lastMonth", now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if dr.StartString() != "2023-12-01" || dr.EndString() != "2023-12-31" {
+		t.Errorf("Expected 2023-12-01..2023-12-31, got %s..%s", dr.StartString(), dr.EndString())
+	}
+}
+
+func TestParseDateRangeExplicitBounds(t *testing.T) {
+	dr, err := ParseDateRange("2024-01-01", "2024-01-31", "", time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if dr.StartString() != "2024-01-01" || dr.EndString() != "2024-01-31" {
+		t.Errorf("Expected 2024-01-01..2024-01-31, got %s..%s", dr.StartString(), dr.EndString())
+	}
+	if dr.EndExclusiveString() != "2024-02-01" {
+		t.Errorf("Expected exclusive end 2024-02-01, got %s", dr.EndExclusiveString())
+	}
+
+	// Open-ended ranges are allowed
+	dr, err = ParseDateRange("2024-01-01", "", "", time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if dr.EndString() != "" {
+		t.Errorf("Expected open end, got %s", dr.EndString())
+	}
+}
+
+func TestParseDateRangeRejections(t *testing.T) {
+	testCases := []struct {
+		name      string
+		startDate string
+		endDate   string
+		shortcut  string
+	}{
+		{"start after end", "2024-02-01", "2024-01-01", ""},
+		{"bad start format", "01/02/2024", "", ""},
+		{"bad end format", "", "2024-13-01", ""},
+		{"unknown shortcut", "", "", "nextDecade"},
+		{"shortcut combined with dates", "2024-01-01", "", "thisMonth"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseDateRange(tc.startDate, tc.endDate, tc.shortcut, time.Now()); err == nil {
+				t.Errorf("Expected an error for %s", tc.name)
+			}
+		})
+	}
+}
+
+func TestNormalizeDateRange(t *testing.T) {
+	req := TestRequest("GET", "/date-range?startDate=2024-01-05&endDate=2024-01-20", nil)
+	w := httptest.NewRecorder()
+
+	NormalizeDateRange(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response map[string]string
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if response["startDate"] != "2024-01-05" || response["endDate"] != "2024-01-20" {
+		t.Errorf("Unexpected normalized range: %v", response)
+	}
+
+	req = TestRequest("GET", "/date-range?startDate=2024-02-01&endDate=2024-01-01", nil)
+	w = httptest.NewRecorder()
+
+	NormalizeDateRange(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for start > end, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestGetTransactionsRejectsInvalidDateRange(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	req := TestRequest("GET", "/transactions?startDate=2024-02-01&endDate=2024-01-01", nil)
+	w := httptest.NewRecorder()
+
+	GetTransactions(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+	"time"
+)
+
+// lockRetryAttempts is how many times execWithRetry runs a write that keeps
+// finding the database locked
+const lockRetryAttempts = 3
+
+// lockRetryBackoff is how long execWithRetry waits after the first locked
+// attempt; each further wait grows by as much
+var lockRetryBackoff = 100 * time.Millisecond
+
+// isDatabaseLocked reports whether err is SQLite's lock contention error
+func isDatabaseLocked(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "database is locked")
+}
+
+// execWithRetry runs write, retrying with a growing backoff while SQLite
+// reports the database is locked, like the YNAB sync does. Other errors are
+// returned at once. write may run several times, so a database transaction
+// must be begun and committed within it.
+func execWithRetry(write func() error) error {
+	var err error
+	for attempt := 0; attempt < lockRetryAttempts; attempt++ {
+		if err = write(); !isDatabaseLocked(err) {
+			return err
+		}
+		if attempt < lockRetryAttempts-1 {
+			log.Printf("Database locked, retry %d/%d", attempt+1, lockRetryAttempts-1)
+			time.Sleep(time.Duration(attempt+1) * lockRetryBackoff)
+		}
+	}
+	return err
+}
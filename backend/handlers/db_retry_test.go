@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExecWithRetryRetriesWhileLocked(t *testing.T) {
+	defer func(backoff time.Duration) { lockRetryBackoff = backoff }(lockRetryBackoff)
+	lockRetryBackoff = time.Millisecond
+
+	calls := 0
+	err := execWithRetry(func() error {
+		calls++
+		if calls < lockRetryAttempts {
+			return errors.New("database is locked")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected the write to succeed once unlocked, got %v", err)
+	}
+	if calls != lockRetryAttempts {
+		t.Errorf("Expected %d attempts, got %d", lockRetryAttempts, calls)
+	}
+
+	calls = 0
+	err = execWithRetry(func() error {
+		calls++
+		return errors.New("database is locked")
+	})
+	if !isDatabaseLocked(err) || calls != lockRetryAttempts {
+		t.Errorf("Expected the lock error after %d attempts, got %v after %d", lockRetryAttempts, err, calls)
+	}
+}
+
+func TestExecWithRetryReturnsOtherErrors(t *testing.T) {
+	calls := 0
+	err := execWithRetry(func() error {
+		calls++
+		return errors.New("UNIQUE constraint failed: transactions.id")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("Expected the error without retrying, got %v after %d attempts", err, calls)
+	}
+}
+
+func TestExecWithRetryUnderLockContention(t *testing.T) {
+	defer func(backoff time.Duration) { lockRetryBackoff = backoff }(lockRetryBackoff)
+	lockRetryBackoff = 100 * time.Millisecond
+
+	// Two connections to one file, neither waiting long for the other's lock
+	path := filepath.Join(t.TempDir(), "locked.db")
+	writer, err := sql.Open("sqlite3", path+"?_busy_timeout=1")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer writer.Close()
+	holder, err := sql.Open("sqlite3", path+"?_busy_timeout=1")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer holder.Close()
+
+	if _, err := writer.Exec("CREATE TABLE entries (id TEXT PRIMARY KEY)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	// Hold the write lock for a while, as a concurrent request would
+	lock, err := holder.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	if _, err := lock.Exec("INSERT INTO entries (id) VALUES ('held')"); err != nil {
+		t.Fatalf("Failed to take the write lock: %v", err)
+	}
+	released := make(chan error, 1)
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		released <- lock.Commit()
+	}()
+
+	calls := 0
+	err = execWithRetry(func() error {
+		calls++
+		_, err := writer.Exec("INSERT INTO entries (id) VALUES ('retried')")
+		return err
+	})
+	if err := <-released; err != nil {
+		t.Fatalf("Failed to release the write lock: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("Expected the write to succeed once the lock was released, got %v", err)
+	}
+	if calls < 2 {
+		t.Errorf("Expected the write to be retried, got %d attempts", calls)
+	}
+
+	var count int
+	if err := writer.QueryRow("SELECT COUNT(*) FROM entries").Scan(&count); err != nil {
+		t.Fatalf("Failed to count entries: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected both writes to be stored, got %d rows", count)
+	}
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// GetMyFeatures returns the feature flags in effect for the caller, global
+// flags overridden by their own
+func GetMyFeatures(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	flags, err := middleware.UserFeatureFlags(userID)
+	if err != nil {
+		log.Printf("Error loading feature flags for user %s: %v", userID, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(flags)
+}
+
+// requireAdmin responds with an error and returns false unless the user is an admin
+func requireAdmin(w http.ResponseWriter, userID string) bool {
+	isAdmin, err := middleware.IsUserAdmin(userID)
+	if err != nil {
+		http.Error(w, "Failed to check user permissions: "+err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if !isAdmin {
+		http.Error(w, "Unauthorized: Admin access required", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// GetFeatureFlags lists every global and per-user feature flag (admin only)
+func GetFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+	if !requireAdmin(w, userID) {
+		return
+	}
+
+	rows, err := database.DB.Query("SELECT flag, user_id, enabled FROM feature_flags ORDER BY flag, user_id")
+	if err != nil {
+		log.Printf("Error querying feature flags: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	flags := []models.FeatureFlag{}
+	for rows.Next() {
+		var f models.FeatureFlag
+		if err := rows.Scan(&f.Flag, &f.UserID, &f.Enabled); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		flags = append(flags, f)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(flags)
+}
+
+// SetFeatureFlag turns a flag on or off globally, or for one user when
+// userId is given (admin only)
+func SetFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+	if !requireAdmin(w, userID) {
+		return
+	}
+
+	var f models.FeatureFlag
+	if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	f.Flag = strings.TrimSpace(f.Flag)
+	f.UserID = strings.TrimSpace(f.UserID)
+	if f.Flag == "" {
+		http.Error(w, "flag is required", http.StatusBadRequest)
+		return
+	}
+
+	_, err := database.DB.Exec(`
+		INSERT INTO feature_flags (user_id, flag, enabled, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(user_id, flag) DO UPDATE SET enabled = excluded.enabled, updated_at = excluded.updated_at
+	`, f.UserID, f.Flag, f.Enabled, time.Now())
+	if err != nil {
+		log.Printf("Error setting feature flag %s: %v", f.Flag, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Admin %s set feature flag %s=%v for %q", userID, f.Flag, f.Enabled, f.UserID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(f)
+}
+
+// DeleteFeatureFlag removes the ?flag= flag, globally or for the ?userId=
+// user, so it falls back to the global value or off (admin only)
+func DeleteFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+	if !requireAdmin(w, userID) {
+		return
+	}
+
+	flag := r.URL.Query().Get("flag")
+	if flag == "" {
+		http.Error(w, "flag is required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := database.DB.Exec("DELETE FROM feature_flags WHERE flag = ? AND user_id = ?", flag, r.URL.Query().Get("userId"))
+	if err != nil {
+		log.Printf("Error deleting feature flag %s: %v", flag, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if deleted, _ := result.RowsAffected(); deleted == 0 {
+		http.Error(w, "Feature flag not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
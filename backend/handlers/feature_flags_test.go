@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"bennwallet/backend/database"
+)
+
+func setupFeatureFlagTestDB(t *testing.T) {
+	SetupTestDB()
+
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS feature_flags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL DEFAULT '',
+			flag TEXT NOT NULL,
+			enabled BOOLEAN NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(user_id, flag)
+		)`,
+		`INSERT INTO users (id, username, name, isAdmin, role) VALUES ('regular-user', 'regular', 'Regular', 0, 'user')`,
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to set up feature flags: %v", err)
+		}
+	}
+}
+
+func setTestFeatureFlag(t *testing.T, userID, body string) int {
+	req := MockAuthContext(TestRequest("PUT", "/admin/feature-flags", &body), userID)
+	w := httptest.NewRecorder()
+	SetFeatureFlag(w, req)
+	return w.Code
+}
+
+func getMyFeatures(t *testing.T, userID string) map[string]bool {
+	req := MockAuthContext(TestRequest("GET", "/me/features", nil), userID)
+	w := httptest.NewRecorder()
+	GetMyFeatures(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var flags map[string]bool
+	if err := json.NewDecoder(w.Body).Decode(&flags); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	return flags
+}
+
+func TestFeatureFlagsGlobalAndUserOverride(t *testing.T) {
+	setupFeatureFlagTestDB(t)
+	defer CleanupTestDB()
+
+	for _, body := range []string{
+		`{"flag": "ledger", "enabled": true}`,
+		`{"flag": "budgets", "enabled": true}`,
+		`{"flag": "budgets", "userId": "regular-user", "enabled": false}`,
+		`{"flag": "beta-import", "userId": "regular-user", "enabled": true}`,
+	} {
+		if status := setTestFeatureFlag(t, TestUserID, body); status != http.StatusOK {
+			t.Fatalf("Expected status code %d setting %s, got %d", http.StatusOK, body, status)
+		}
+	}
+
+	expected := map[string]bool{"ledger": true, "budgets": false, "beta-import": true}
+	if flags := getMyFeatures(t, "regular-user"); !reflect.DeepEqual(flags, expected) {
+		t.Errorf("Expected the user's overrides on top of the global flags %v, got %v", expected, flags)
+	}
+
+	expected = map[string]bool{"ledger": true, "budgets": true}
+	if flags := getMyFeatures(t, TestUserID); !reflect.DeepEqual(flags, expected) {
+		t.Errorf("Expected only the global flags %v, got %v", expected, flags)
+	}
+
+	// Removing the override falls back to the global flag
+	req := MockAuthContext(TestRequest("DELETE", "/admin/feature-flags?flag=budgets&userId=regular-user", nil), TestUserID)
+	w := httptest.NewRecorder()
+	DeleteFeatureFlag(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusNoContent, w.Code, w.Body.String())
+	}
+	if flags := getMyFeatures(t, "regular-user"); !flags["budgets"] {
+		t.Errorf("Expected budgets to follow the global flag again, got %v", flags)
+	}
+}
+
+func TestSetFeatureFlagRequiresAdmin(t *testing.T) {
+	setupFeatureFlagTestDB(t)
+	defer CleanupTestDB()
+
+	if status := setTestFeatureFlag(t, "regular-user", `{"flag": "ledger", "enabled": true}`); status != http.StatusForbidden {
+		t.Errorf("Expected status code %d for a regular user, got %d", http.StatusForbidden, status)
+	}
+	if status := setTestFeatureFlag(t, TestUserID, `{"flag": " ", "enabled": true}`); status != http.StatusBadRequest {
+		t.Errorf("Expected status code %d without a flag name, got %d", http.StatusBadRequest, status)
+	}
+}
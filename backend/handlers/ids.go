@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log"
+	"os"
+)
+
+// ID schemes selectable with ID_SCHEME
+const (
+	IDSchemeRandom = "random" // 16 alphanumeric characters (default)
+	IDSchemeUUID   = "uuid"   // RFC 4122 version 4 UUID
+)
+
+const idCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// idRandom is the source of randomness for IDs. It is always crypto/rand
+// outside of tests.
+var idRandom io.Reader = rand.Reader
+
+// generateID returns a new ID for a stored record in the scheme selected by ID_SCHEME
+func generateID() string {
+	switch scheme := os.Getenv("ID_SCHEME"); scheme {
+	case "", IDSchemeRandom:
+		return randomID()
+	case IDSchemeUUID:
+		return uuidV4()
+	default:
+		log.Printf("Warning: unknown ID_SCHEME %q, using %s", scheme, IDSchemeRandom)
+		return randomID()
+	}
+}
+
+// randomID returns 16 uniformly distributed alphanumeric characters
+func randomID() string {
+	// Bytes at or above the largest multiple of len(idCharset) are rejected
+	// so that every character is equally likely
+	const limit = 256 - 256%len(idCharset)
+
+	b := make([]byte, 0, 16)
+	buf := make([]byte, 32)
+	for len(b) < cap(b) {
+		if _, err := io.ReadFull(idRandom, buf); err != nil {
+			panic("failed to read random bytes: " + err.Error())
+		}
+		for _, v := range buf {
+			if int(v) < limit && len(b) < cap(b) {
+				b = append(b, idCharset[int(v)%len(idCharset)])
+			}
+		}
+	}
+	return string(b)
+}
+
+// uuidV4 returns a random (version 4) UUID
+func uuidV4() string {
+	var u [16]byte
+	if _, err := io.ReadFull(idRandom, u[:]); err != nil {
+		panic("failed to read random bytes: " + err.Error())
+	}
+	u[6] = (u[6] & 0x0f) | 0x40 // version 4
+	u[8] = (u[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	var s [36]byte
+	hex.Encode(s[0:8], u[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], u[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], u[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], u[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], u[10:])
+	return string(s[:])
+}
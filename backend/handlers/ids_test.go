@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+func TestGenerateIDUsesCryptoRand(t *testing.T) {
+	if idRandom != rand.Reader {
+		t.Fatal("Expected IDs to be generated from crypto/rand")
+	}
+
+	// IDs are derived entirely from the random source
+	original := idRandom
+	defer func() { idRandom = original }()
+	idRandom = bytes.NewReader(make([]byte, 64))
+	if id := generateID(); id != "aaaaaaaaaaaaaaaa" {
+		t.Errorf("Expected an ID derived from the random source, got %s", id)
+	}
+}
+
+func TestGenerateIDUniqueUnderConcurrency(t *testing.T) {
+	const workers, perWorker = 16, 500
+
+	var mu sync.Mutex
+	seen := make(map[string]bool, workers*perWorker)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				id := generateID()
+				mu.Lock()
+				if seen[id] {
+					t.Errorf("Duplicate ID generated: %s", id)
+				}
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != workers*perWorker {
+		t.Errorf("Expected %d unique IDs, got %d", workers*perWorker, len(seen))
+	}
+}
+
+func TestGenerateIDSchemes(t *testing.T) {
+	t.Setenv("ID_SCHEME", IDSchemeRandom)
+	if id := generateID(); !regexp.MustCompile(`^[a-zA-Z0-9]{16}$`).MatchString(id) {
+		t.Errorf("Unexpected random ID format: %s", id)
+	}
+
+	t.Setenv("ID_SCHEME", IDSchemeUUID)
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if id := generateID(); !uuidPattern.MatchString(id) {
+		t.Errorf("Unexpected UUID format: %s", id)
+	}
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec is the hand-maintained OpenAPI description of the API. Update
+// openapi.json alongside route changes in registerRoutes.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// GetOpenAPISpec serves the OpenAPI document for client generation
+func GetOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(openAPISpec)
+}
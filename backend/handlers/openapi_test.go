@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetOpenAPISpec(t *testing.T) {
+	req := httptest.NewRequest("GET", "/openapi.json", nil)
+	w := httptest.NewRecorder()
+
+	GetOpenAPISpec(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Expected application/json content type, got %q", contentType)
+	}
+
+	var spec struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("OpenAPI document is not valid JSON: %v", err)
+	}
+
+	if spec.OpenAPI == "" {
+		t.Error("Expected an openapi version field")
+	}
+	for _, path := range []string{"/transactions", "/categories", "/ynab/config"} {
+		if _, ok := spec.Paths[path]; !ok {
+			t.Errorf("Expected spec to include path %s", path)
+		}
+	}
+}
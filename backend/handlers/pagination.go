@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+// Resources with paginated listings, keys of paginationDefaults
+const (
+	pageResourceTransactions   = "transactions"
+	pageResourceYNABCategories = "ynabCategories"
+)
+
+// pageSizeLimits are the page size used when a request doesn't give one and
+// the largest it may ask for. Env names the variables overriding them.
+type pageSizeLimits struct {
+	Default int
+	Max     int
+	Env     string
+}
+
+// paginationDefaults holds the page sizes of each paginated resource.
+// Override them with <Env>_PAGE_SIZE and <Env>_MAX_PAGE_SIZE, e.g.
+// TRANSACTIONS_PAGE_SIZE.
+var paginationDefaults = map[string]pageSizeLimits{
+	pageResourceTransactions:   {Default: 50, Max: 200, Env: "TRANSACTIONS"},
+	pageResourceYNABCategories: {Default: 100, Max: 500, Env: "YNAB_CATEGORIES"},
+}
+
+// pageSizeEnv reads a positive page size from the environment, falling back
+// to the given value when it is unset or invalid
+func pageSizeEnv(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 1 {
+		log.Printf("Warning: ignoring invalid %s %q", name, value)
+		return fallback
+	}
+	return parsed
+}
+
+// pageLimits returns the page sizes of a resource with the environment
+// overrides applied. The default never exceeds the maximum.
+func pageLimits(resource string) pageSizeLimits {
+	limits, ok := paginationDefaults[resource]
+	if !ok {
+		log.Printf("Warning: no pagination defaults for %q, using %s", resource, pageResourceTransactions)
+		limits = paginationDefaults[pageResourceTransactions]
+	}
+	limits.Max = pageSizeEnv(limits.Env+"_MAX_PAGE_SIZE", limits.Max)
+	limits.Default = pageSizeEnv(limits.Env+"_PAGE_SIZE", limits.Default)
+	if limits.Default > limits.Max {
+		limits.Default = limits.Max
+	}
+	return limits
+}
+
+// parsePagination reads the page and pageSize query parameters of a
+// listing of the given resource
+func parsePagination(r *http.Request, resource string) (int, int, error) {
+	limits := pageLimits(resource)
+	page, pageSize := 1, limits.Default
+
+	if value := r.URL.Query().Get("page"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return 0, 0, fmt.Errorf("page must be a positive integer")
+		}
+		page = parsed
+	}
+
+	if value := r.URL.Query().Get("pageSize"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > limits.Max {
+			return 0, 0, fmt.Errorf("pageSize must be between 1 and %d", limits.Max)
+		}
+		pageSize = parsed
+	}
+
+	return page, pageSize, nil
+}
+
+// parseLimitOffset reads the limit and offset query parameters of a listing
+// of the given resource
+func parseLimitOffset(r *http.Request, resource string) (int, int, error) {
+	limits := pageLimits(resource)
+	limit, offset := limits.Default, 0
+
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > limits.Max {
+			return 0, 0, fmt.Errorf("limit must be between 1 and %d", limits.Max)
+		}
+		limit = parsed
+	}
+
+	if value := r.URL.Query().Get("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return 0, 0, fmt.Errorf("offset must be a non-negative integer")
+		}
+		offset = parsed
+	}
+
+	return limit, offset, nil
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPaginationDefaultsPerResource(t *testing.T) {
+	for resource, limits := range paginationDefaults {
+		_, pageSize, err := parsePagination(httptest.NewRequest("GET", "/", nil), resource)
+		if err != nil || pageSize != limits.Default {
+			t.Errorf("%s: expected default page size %d, got %d (%v)", resource, limits.Default, pageSize, err)
+		}
+		limit, _, err := parseLimitOffset(httptest.NewRequest("GET", "/", nil), resource)
+		if err != nil || limit != limits.Default {
+			t.Errorf("%s: expected default limit %d, got %d (%v)", resource, limits.Default, limit, err)
+		}
+
+		// The cap itself is allowed, one more is not
+		url := fmt.Sprintf("/?pageSize=%d&limit=%d", limits.Max, limits.Max)
+		if _, pageSize, err := parsePagination(httptest.NewRequest("GET", url, nil), resource); err != nil || pageSize != limits.Max {
+			t.Errorf("%s: expected page size %d to be allowed, got %d (%v)", resource, limits.Max, pageSize, err)
+		}
+		if limit, _, err := parseLimitOffset(httptest.NewRequest("GET", url, nil), resource); err != nil || limit != limits.Max {
+			t.Errorf("%s: expected limit %d to be allowed, got %d (%v)", resource, limits.Max, limit, err)
+		}
+		url = fmt.Sprintf("/?pageSize=%d&limit=%d", limits.Max+1, limits.Max+1)
+		if _, _, err := parsePagination(httptest.NewRequest("GET", url, nil), resource); err == nil {
+			t.Errorf("%s: expected page size %d to be rejected", resource, limits.Max+1)
+		}
+		if _, _, err := parseLimitOffset(httptest.NewRequest("GET", url, nil), resource); err == nil {
+			t.Errorf("%s: expected limit %d to be rejected", resource, limits.Max+1)
+		}
+	}
+}
+
+func TestPaginationEnvironmentOverrides(t *testing.T) {
+	t.Setenv("TRANSACTIONS_PAGE_SIZE", "20")
+	t.Setenv("TRANSACTIONS_MAX_PAGE_SIZE", "40")
+
+	_, pageSize, err := parsePagination(httptest.NewRequest("GET", "/", nil), pageResourceTransactions)
+	if err != nil || pageSize != 20 {
+		t.Errorf("Expected the configured default of 20, got %d (%v)", pageSize, err)
+	}
+	if _, _, err := parsePagination(httptest.NewRequest("GET", "/?pageSize=41", nil), pageResourceTransactions); err == nil {
+		t.Error("Expected a page size above the configured cap to be rejected")
+	}
+
+	// Other resources keep their own limits
+	limit, _, err := parseLimitOffset(httptest.NewRequest("GET", "/?limit=500", nil), pageResourceYNABCategories)
+	if err != nil || limit != 500 {
+		t.Errorf("Expected YNAB categories to keep their cap of 500, got %d (%v)", limit, err)
+	}
+
+	// A default above the cap is clamped, and invalid values are ignored
+	t.Setenv("TRANSACTIONS_PAGE_SIZE", "100")
+	t.Setenv("YNAB_CATEGORIES_PAGE_SIZE", "lots")
+	if limits := pageLimits(pageResourceTransactions); limits.Default != 40 || limits.Max != 40 {
+		t.Errorf("Expected the default to be clamped to 40, got %+v", limits)
+	}
+	if limits := pageLimits(pageResourceYNABCategories); limits.Default != 100 {
+		t.Errorf("Expected an invalid default to be ignored, got %+v", limits)
+	}
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// GetPermissionVocabulary returns the resource and permission types the
+// server recognizes so clients granting permissions don't have to hardcode them
+func GetPermissionVocabulary(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.PermissionVocabulary{
+		ResourceTypes:   models.ResourceTypes,
+		PermissionTypes: models.PermissionTypes,
+	})
+}
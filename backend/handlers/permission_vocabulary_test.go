@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bennwallet/backend/models"
+)
+
+func TestGetPermissionVocabulary(t *testing.T) {
+	SetupTestDB()
+	defer CleanupTestDB()
+
+	req := TestRequest("GET", "/permissions/vocabulary", nil)
+	w := httptest.NewRecorder()
+	GetPermissionVocabulary(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var vocabulary models.PermissionVocabulary
+	if err := json.NewDecoder(w.Body).Decode(&vocabulary); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	contains := func(values []string, value string) bool {
+		for _, v := range values {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	}
+	for _, resource := range []string{"transactions", "categories", "reports", "users", "all"} {
+		if !contains(vocabulary.ResourceTypes, resource) {
+			t.Errorf("Expected resource type %q, got %v", resource, vocabulary.ResourceTypes)
+		}
+	}
+	for _, permission := range []string{"read", "write", "admin"} {
+		if !contains(vocabulary.PermissionTypes, permission) {
+			t.Errorf("Expected permission type %q, got %v", permission, vocabulary.PermissionTypes)
+		}
+	}
+}
+
+func TestGetPermissionVocabularyRequiresAuth(t *testing.T) {
+	req := httptest.NewRequest("GET", "/permissions/vocabulary", nil)
+	w := httptest.NewRecorder()
+	GetPermissionVocabulary(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
@@ -0,0 +1,526 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+// recurringGenerationInterval is how often due recurring templates are checked
+const recurringGenerationInterval = 1 * time.Hour
+
+// Bounds for the upcoming recurring transactions window, in days
+const (
+	defaultUpcomingDays = 30
+	maxUpcomingDays     = 366
+)
+
+// startOfDay truncates t to midnight UTC so schedule comparisons are by calendar day
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// GetRecurringTransactions returns the user's recurring transaction templates
+func GetRecurringTransactions(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from authentication context
+	userId := middleware.GetUserIDFromContext(r)
+	if userId == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT id, user_id, amount, description, type, payTo, enteredBy, optional, frequency, next_date, anchor_day, active, created_at
+		FROM recurring_transactions
+		WHERE user_id = ?
+		ORDER BY next_date
+	`, userId)
+	if err != nil {
+		log.Printf("Error querying recurring transactions: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	templates := []models.RecurringTransaction{}
+	for rows.Next() {
+		rt, err := scanRecurringTransaction(rows)
+		if err != nil {
+			log.Printf("Error scanning recurring transaction: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		templates = append(templates, rt)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+// GetUpcomingRecurringTransactions projects when the user's active templates
+// will fire over the next N days (default 30) without creating any transactions
+func GetUpcomingRecurringTransactions(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from authentication context
+	userId := middleware.GetUserIDFromContext(r)
+	if userId == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	days := defaultUpcomingDays
+	if daysParam := r.URL.Query().Get("days"); daysParam != "" {
+		parsed, err := strconv.Atoi(daysParam)
+		if err != nil || parsed < 1 || parsed > maxUpcomingDays {
+			http.Error(w, "Invalid days parameter (expected 1-366)", http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT id, user_id, amount, description, type, payTo, enteredBy, optional, frequency, next_date, anchor_day, active, created_at
+		FROM recurring_transactions
+		WHERE user_id = ? AND active = 1
+	`, userId)
+	if err != nil {
+		log.Printf("Error querying recurring transactions: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	until := startOfDay(time.Now()).AddDate(0, 0, days)
+
+	upcoming := []models.UpcomingRecurringTransaction{}
+	for rows.Next() {
+		rt, err := scanRecurringTransaction(rows)
+		if err != nil {
+			log.Printf("Error scanning recurring transaction: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, date := range rt.ProjectOccurrences(until) {
+			upcoming = append(upcoming, models.UpcomingRecurringTransaction{
+				RecurringID: rt.ID,
+				Description: rt.Description,
+				Amount:      rt.Amount,
+				Type:        rt.Type,
+				PayTo:       rt.PayTo,
+				Frequency:   rt.Frequency,
+				Date:        date,
+			})
+		}
+	}
+
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].Date.Before(upcoming[j].Date)
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(upcoming)
+}
+
+// AddRecurringTransaction creates a new recurring transaction template
+func AddRecurringTransaction(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from authentication context
+	userId := middleware.GetUserIDFromContext(r)
+	if userId == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	var rt models.RecurringTransaction
+	if err := json.NewDecoder(r.Body).Decode(&rt); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if rt.Description == "" || rt.Type == "" || rt.EnteredBy == "" {
+		http.Error(w, "description, type and enteredBy are required", http.StatusBadRequest)
+		return
+	}
+	if !models.IsValidFrequency(rt.Frequency) {
+		http.Error(w, "Invalid frequency (expected daily, weekly, monthly or yearly)", http.StatusBadRequest)
+		return
+	}
+	var err error
+	rt.Type, err = normalizeTransactionType(rt.Type)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if rt.NextDate.IsZero() {
+		rt.NextDate = time.Now()
+	}
+
+	rt.ID = generateID()
+	rt.UserID = userId
+	rt.NextDate = startOfDay(rt.NextDate)
+	rt.AnchorDay = rt.NextDate.Day()
+	rt.Active = true
+
+	if err := insertRecurringTransaction(rt); err != nil {
+		log.Printf("Error inserting recurring transaction: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rt)
+}
+
+// MakeTransactionRecurring creates a recurring template, owned by the caller,
+// from an existing transaction. The source transaction is left unchanged.
+func MakeTransactionRecurring(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from authentication context
+	userId := middleware.GetUserIDFromContext(r)
+	if userId == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+
+	var request struct {
+		Frequency string    `json:"frequency"`
+		NextDate  time.Time `json:"nextDate"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !models.IsValidFrequency(request.Frequency) {
+		http.Error(w, "Invalid frequency (expected daily, weekly, monthly or yearly)", http.StatusBadRequest)
+		return
+	}
+
+	var t models.Transaction
+	var payTo, ownerID sql.NullString
+	var transactionDate sql.NullTime
+	err := database.DB.QueryRow(`
+		SELECT id, amount, description, date, transaction_date, type, payTo, enteredBy, optional, userId
+		FROM transactions
+		WHERE id = ? AND deleted_at IS NULL
+	`, id).Scan(&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate, &t.Type, &payTo, &t.EnteredBy, &t.Optional, &ownerID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error fetching transaction %s: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The caller must be able to see the source transaction
+	if ownerID.Valid && ownerID.String != userId &&
+		!middleware.CheckUserPermission(userId, ownerID.String, models.ResourceTransactions, models.PermissionRead) {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+
+	// Default the schedule to the occurrence after the source transaction,
+	// on the same day of the month
+	nextDate := startOfDay(request.NextDate)
+	anchorDay := nextDate.Day()
+	if request.NextDate.IsZero() {
+		from := t.Date
+		if transactionDate.Valid {
+			from = transactionDate.Time
+		}
+		anchorDay = from.Day()
+		nextDate = models.NextOccurrence(startOfDay(from), request.Frequency, anchorDay)
+	}
+
+	rt := models.RecurringTransaction{
+		ID:          generateID(),
+		UserID:      userId,
+		Amount:      t.Amount,
+		Description: t.Description,
+		Type:        t.Type,
+		PayTo:       payTo.String,
+		EnteredBy:   t.EnteredBy,
+		Optional:    t.Optional,
+		Frequency:   request.Frequency,
+		NextDate:    nextDate,
+		AnchorDay:   anchorDay,
+		Active:      true,
+	}
+
+	if err := insertRecurringTransaction(rt); err != nil {
+		log.Printf("Error inserting recurring transaction: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rt)
+}
+
+func insertRecurringTransaction(rt models.RecurringTransaction) error {
+	_, err := database.DB.Exec(`
+		INSERT INTO recurring_transactions (id, user_id, amount, description, type, payTo, enteredBy, optional, frequency, next_date, anchor_day, active)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, rt.ID, rt.UserID, rt.Amount, rt.Description, rt.Type, rt.PayTo, rt.EnteredBy, rt.Optional, rt.Frequency, rt.NextDate, rt.AnchorDay, rt.Active)
+	return err
+}
+
+// DeleteRecurringTransaction removes a recurring transaction template
+func DeleteRecurringTransaction(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from authentication context
+	userId := middleware.GetUserIDFromContext(r)
+	if userId == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+
+	result, err := database.DB.Exec("DELETE FROM recurring_transactions WHERE id = ? AND user_id = ?", id, userId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		http.Error(w, "Recurring transaction not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// PauseRecurringTransaction stops a template from generating transactions without deleting it
+func PauseRecurringTransaction(w http.ResponseWriter, r *http.Request) {
+	setRecurringTransactionActive(w, r, false)
+}
+
+// ResumeRecurringTransaction re-enables a paused template. Occurrences missed
+// while paused are skipped rather than generated all at once.
+func ResumeRecurringTransaction(w http.ResponseWriter, r *http.Request) {
+	setRecurringTransactionActive(w, r, true)
+}
+
+func setRecurringTransactionActive(w http.ResponseWriter, r *http.Request, active bool) {
+	// Get user ID from authentication context
+	userId := middleware.GetUserIDFromContext(r)
+	if userId == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+
+	row := database.DB.QueryRow(`
+		SELECT id, user_id, amount, description, type, payTo, enteredBy, optional, frequency, next_date, anchor_day, active, created_at
+		FROM recurring_transactions
+		WHERE id = ? AND user_id = ?
+	`, id, userId)
+	rt, err := scanRecurringTransaction(row)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Recurring transaction not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error fetching recurring transaction %s: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if active && !rt.Active {
+		// Move the schedule forward so the first generated occurrence is today or later
+		today := startOfDay(time.Now())
+		for rt.NextDate.Before(today) {
+			rt.NextDate = models.NextOccurrence(rt.NextDate, rt.Frequency, rt.AnchorDay)
+		}
+	}
+	rt.Active = active
+
+	_, err = database.DB.Exec(`
+		UPDATE recurring_transactions SET active = ?, next_date = ? WHERE id = ? AND user_id = ?
+	`, rt.Active, rt.NextDate, id, userId)
+	if err != nil {
+		log.Printf("Error updating recurring transaction %s: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rt)
+}
+
+// GenerateRecurringTransactions creates a transaction for every occurrence of an
+// active template that is due on or before now, then advances its next date.
+// Paused templates are skipped, as are templates whose type or description
+// is no longer valid; those are retried on the next run. It returns the
+// number of transactions created.
+func GenerateRecurringTransactions(now time.Time) (int, error) {
+	rows, err := database.DB.Query(`
+		SELECT id, user_id, amount, description, type, payTo, enteredBy, optional, frequency, next_date, anchor_day, active, created_at
+		FROM recurring_transactions
+		WHERE active = 1
+	`)
+	if err != nil {
+		return 0, err
+	}
+
+	var due []models.RecurringTransaction
+	for rows.Next() {
+		rt, err := scanRecurringTransaction(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if !rt.NextDate.After(now) {
+			due = append(due, rt)
+		}
+	}
+	rows.Close()
+
+	created := 0
+	for _, rt := range due {
+		transactions, err := prepareRecurringOccurrences(rt, now)
+		if err != nil {
+			log.Printf("Skipping recurring transaction %s: %v", rt.ID, err)
+			continue
+		}
+
+		generated, err := insertRecurringOccurrences(rt, transactions, now)
+		if err != nil {
+			return created, err
+		}
+		created += generated
+	}
+
+	return created, nil
+}
+
+// prepareRecurringOccurrences builds the transactions for a template's
+// occurrences due on or before now. Like AddTransaction, the type is
+// normalized and a category that defaults to optional makes them optional.
+func prepareRecurringOccurrences(rt models.RecurringTransaction, now time.Time) ([]models.Transaction, error) {
+	transactionType, err := normalizeTransactionType(rt.Type)
+	if err != nil {
+		return nil, err
+	}
+	description, err := normalizeDescription(rt.Description)
+	if err != nil {
+		return nil, err
+	}
+	optional := rt.Optional || categoryOptionalDefault(rt.UserID, transactionType)
+
+	var transactions []models.Transaction
+	for next := rt.NextDate; !next.After(now); next = models.NextOccurrence(next, rt.Frequency, rt.AnchorDay) {
+		transactions = append(transactions, models.Transaction{
+			ID:              generateID(),
+			Amount:          models.Amount(rt.Amount),
+			Description:     description,
+			Date:            now,
+			TransactionDate: next,
+			Type:            transactionType,
+			PayTo:           rt.PayTo,
+			EnteredBy:       rt.EnteredBy,
+			Optional:        optional,
+			UserID:          rt.UserID,
+			Source:          models.TransactionSourceRecurring,
+		})
+	}
+	return transactions, nil
+}
+
+// insertRecurringOccurrences stores a template's generated transactions and
+// advances its next date past them in one database transaction. When another
+// run already advanced the template nothing is stored and 0 is returned.
+func insertRecurringOccurrences(rt models.RecurringTransaction, transactions []models.Transaction, now time.Time) (int, error) {
+	nextDate := rt.NextDate
+	for range transactions {
+		nextDate = models.NextOccurrence(nextDate, rt.Frequency, rt.AnchorDay)
+	}
+
+	created := 0
+	err := execWithRetry(func() error {
+		created = 0
+		tx, err := database.DB.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		// Only the run that moves the next date on generates the occurrences
+		result, err := tx.Exec(`
+			UPDATE recurring_transactions SET next_date = ?
+			WHERE id = ? AND datetime(next_date) = datetime(?)
+		`, nextDate, rt.ID, rt.NextDate)
+		if err != nil {
+			return err
+		}
+		if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+			return err
+		}
+
+		for _, t := range transactions {
+			_, err := tx.Exec(`
+				INSERT INTO transactions (id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional, userId, source, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, 0, '', ?, ?, ?, ?, ?)
+			`, t.ID, t.Amount, t.Description, t.Date, t.TransactionDate, t.Type, t.PayTo, t.EnteredBy, t.Optional, t.UserID, t.Source, now)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		created = len(transactions)
+		return nil
+	})
+	return created, err
+}
+
+// StartRecurringTransactionGenerator periodically generates due recurring transactions
+func StartRecurringTransactionGenerator() {
+	go func() {
+		for {
+			count, err := GenerateRecurringTransactions(time.Now())
+			if err != nil {
+				log.Printf("Error generating recurring transactions: %v", err)
+			} else if count > 0 {
+				log.Printf("Generated %d recurring transactions", count)
+			}
+			time.Sleep(recurringGenerationInterval)
+		}
+	}()
+}
+
+// recurringScanner is satisfied by both *sql.Row and *sql.Rows
+type recurringScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRecurringTransaction(s recurringScanner) (models.RecurringTransaction, error) {
+	var rt models.RecurringTransaction
+	var payTo sql.NullString
+	var anchorDay sql.NullInt64
+	var createdAt sql.NullTime
+	err := s.Scan(&rt.ID, &rt.UserID, &rt.Amount, &rt.Description, &rt.Type, &payTo, &rt.EnteredBy,
+		&rt.Optional, &rt.Frequency, &rt.NextDate, &anchorDay, &rt.Active, &createdAt)
+	if err != nil {
+		return rt, err
+	}
+	rt.PayTo = payTo.String
+	rt.AnchorDay = int(anchorDay.Int64)
+	if createdAt.Valid {
+		rt.CreatedAt = createdAt.Time
+	}
+	return rt, nil
+}
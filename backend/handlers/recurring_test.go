@@ -0,0 +1,388 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+func setupRecurringTestDB() {
+	setupTransactionCategoryTestDB()
+
+	_, err := database.DB.Exec(`
+		CREATE TABLE IF NOT EXISTS recurring_transactions (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			amount REAL NOT NULL,
+			description TEXT NOT NULL,
+			type TEXT NOT NULL,
+			payTo TEXT,
+			enteredBy TEXT NOT NULL,
+			optional BOOLEAN NOT NULL DEFAULT 0,
+			frequency TEXT NOT NULL,
+			next_date DATETIME NOT NULL,
+			anchor_day INTEGER,
+			active BOOLEAN NOT NULL DEFAULT 1,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func insertRecurringTemplate(t *testing.T, id string, nextDate time.Time, active bool) {
+	_, err := database.DB.Exec(`
+		INSERT INTO recurring_transactions (id, user_id, amount, description, type, enteredBy, frequency, next_date, active)
+		VALUES (?, ?, 50, 'Rent', 'Housing', 'test-user', 'monthly', ?, ?)
+	`, id, TestUserID, nextDate, active)
+	if err != nil {
+		t.Fatalf("Failed to insert recurring template: %v", err)
+	}
+}
+
+func countTransactions(t *testing.T) int {
+	var count int
+	if err := database.DB.QueryRow("SELECT COUNT(*) FROM transactions WHERE deleted_at IS NULL").Scan(&count); err != nil {
+		t.Fatalf("Failed to count transactions: %v", err)
+	}
+	return count
+}
+
+func TestGenerateRecurringSkipsPausedTemplate(t *testing.T) {
+	setupRecurringTestDB()
+	defer CleanupTestDB()
+
+	now := time.Now()
+	insertRecurringTemplate(t, "paused-template", startOfDay(now).AddDate(0, 0, -1), false)
+
+	created, err := GenerateRecurringTransactions(now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if created != 0 {
+		t.Errorf("Expected no transactions from a paused template, got %d", created)
+	}
+	if count := countTransactions(t); count != 0 {
+		t.Errorf("Expected 0 transactions in the table, got %d", count)
+	}
+}
+
+func TestGenerateRecurringRecordsSource(t *testing.T) {
+	setupRecurringTestDB()
+	defer CleanupTestDB()
+
+	now := time.Now()
+	insertRecurringTemplate(t, "rent", startOfDay(now).AddDate(0, 0, -1), true)
+
+	if _, err := GenerateRecurringTransactions(now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var source string
+	if err := database.DB.QueryRow("SELECT source FROM transactions").Scan(&source); err != nil {
+		t.Fatalf("Error reading source: %v", err)
+	}
+	if source != models.TransactionSourceRecurring {
+		t.Errorf("Expected source %q, got %q", models.TransactionSourceRecurring, source)
+	}
+}
+
+func TestPauseAndResumeRecurringTransaction(t *testing.T) {
+	setupRecurringTestDB()
+	defer CleanupTestDB()
+
+	today := startOfDay(time.Now())
+	// Due three months ago; resuming should not catch up on the missed occurrences
+	insertRecurringTemplate(t, "rent-template", today.AddDate(0, -3, 0), true)
+
+	req := SetupTestAuth(httptest.NewRequest("POST", "/recurring/rent-template/pause", nil))
+	req = mux.SetURLVars(req, map[string]string{"id": "rent-template"})
+	w := httptest.NewRecorder()
+	PauseRecurringTransaction(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d for pause, got %d", http.StatusOK, w.Code)
+	}
+
+	created, err := GenerateRecurringTransactions(time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if created != 0 {
+		t.Errorf("Expected no transactions while paused, got %d", created)
+	}
+
+	req = SetupTestAuth(httptest.NewRequest("POST", "/recurring/rent-template/resume", nil))
+	req = mux.SetURLVars(req, map[string]string{"id": "rent-template"})
+	w = httptest.NewRecorder()
+	ResumeRecurringTransaction(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d for resume, got %d", http.StatusOK, w.Code)
+	}
+
+	var active bool
+	var nextDate time.Time
+	err = database.DB.QueryRow("SELECT active, next_date FROM recurring_transactions WHERE id = ?", "rent-template").Scan(&active, &nextDate)
+	if err != nil {
+		t.Fatalf("Failed to read template: %v", err)
+	}
+	if !active {
+		t.Error("Expected template to be active after resume")
+	}
+	if nextDate.Before(today) {
+		t.Errorf("Expected next date to be moved to today or later, got %v", nextDate)
+	}
+
+	// Generating once the next occurrence is due should produce exactly one transaction
+	created, err = GenerateRecurringTransactions(nextDate)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if created != 1 {
+		t.Errorf("Expected 1 transaction after resume, got %d", created)
+	}
+}
+
+func TestPauseRecurringTransactionNotFound(t *testing.T) {
+	setupRecurringTestDB()
+	defer CleanupTestDB()
+
+	req := SetupTestAuth(httptest.NewRequest("POST", "/recurring/missing/pause", nil))
+	req = mux.SetURLVars(req, map[string]string{"id": "missing"})
+	w := httptest.NewRecorder()
+	PauseRecurringTransaction(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestGenerateRecurringOnlyOnce(t *testing.T) {
+	setupRecurringTestDB()
+	defer CleanupTestDB()
+
+	now := time.Now()
+	insertRecurringTemplate(t, "rent", startOfDay(now).AddDate(0, 0, -1), true)
+
+	row := database.DB.QueryRow(`
+		SELECT id, user_id, amount, description, type, payTo, enteredBy, optional, frequency, next_date, anchor_day, active, created_at
+		FROM recurring_transactions WHERE id = 'rent'
+	`)
+	stale, err := scanRecurringTransaction(row)
+	if err != nil {
+		t.Fatalf("Failed to read template: %v", err)
+	}
+
+	if created, err := GenerateRecurringTransactions(now); err != nil || created != 1 {
+		t.Fatalf("Expected 1 transaction, got %d (%v)", created, err)
+	}
+
+	// An overlapping run that read the template before it was advanced
+	transactions, err := prepareRecurringOccurrences(stale, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	created, err := insertRecurringOccurrences(stale, transactions, now)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if created != 0 {
+		t.Errorf("Expected the overlapping run to create nothing, got %d", created)
+	}
+	if count := countTransactions(t); count != 1 {
+		t.Errorf("Expected 1 transaction in the table, got %d", count)
+	}
+}
+
+func TestGenerateRecurringPreparesLikeAddTransaction(t *testing.T) {
+	setupRecurringTestDB()
+	defer CleanupTestDB()
+	t.Setenv("TRANSACTION_TYPES", "Housing,Food")
+
+	now := time.Now()
+	insertRecurringTemplate(t, "rent", startOfDay(now).AddDate(0, 0, -1), true)
+	database.DB.Exec("UPDATE recurring_transactions SET type = ' housing ' WHERE id = 'rent'")
+	database.DB.Exec("INSERT INTO categories (name, user_id, optional_default) VALUES ('Housing', ?, 1)", TestUserID)
+
+	if _, err := GenerateRecurringTransactions(now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var transactionType string
+	var optional bool
+	if err := database.DB.QueryRow("SELECT type, optional FROM transactions").Scan(&transactionType, &optional); err != nil {
+		t.Fatalf("Error reading generated transaction: %v", err)
+	}
+	if transactionType != "Housing" || !optional {
+		t.Errorf("Expected an optional Housing transaction, got type %q optional=%v", transactionType, optional)
+	}
+}
+
+func TestGenerateRecurringKeepsDayOfMonth(t *testing.T) {
+	setupRecurringTestDB()
+	defer CleanupTestDB()
+
+	jan31 := time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)
+	insertRecurringTemplate(t, "month-end", jan31, true)
+	database.DB.Exec("UPDATE recurring_transactions SET anchor_day = 31 WHERE id = 'month-end'")
+
+	created, err := GenerateRecurringTransactions(time.Date(2024, time.March, 31, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if created != 3 {
+		t.Fatalf("Expected 3 transactions, got %d", created)
+	}
+
+	rows, err := database.DB.Query("SELECT transaction_date FROM transactions ORDER BY transaction_date")
+	if err != nil {
+		t.Fatalf("Failed to query transactions: %v", err)
+	}
+	defer rows.Close()
+	var dates []string
+	for rows.Next() {
+		var date time.Time
+		rows.Scan(&date)
+		dates = append(dates, date.Format("2006-01-02"))
+	}
+	if expected := []string{"2024-01-31", "2024-02-29", "2024-03-31"}; !reflect.DeepEqual(dates, expected) {
+		t.Errorf("Expected occurrences %v, got %v", expected, dates)
+	}
+
+	var next time.Time
+	database.DB.QueryRow("SELECT next_date FROM recurring_transactions WHERE id = 'month-end'").Scan(&next)
+	if next.Format("2006-01-02") != "2024-04-30" {
+		t.Errorf("Expected the next date to be 2024-04-30, got %s", next.Format("2006-01-02"))
+	}
+}
+
+func TestGetUpcomingRecurringTransactions(t *testing.T) {
+	setupRecurringTestDB()
+	defer CleanupTestDB()
+
+	today := startOfDay(time.Now())
+	insertRecurringTemplate(t, "monthly-template", today.AddDate(0, 0, 1), true)
+	insertRecurringTemplate(t, "paused-template", today.AddDate(0, 0, 1), false)
+
+	req := SetupTestAuth(httptest.NewRequest("GET", "/recurring/upcoming?days=45", nil))
+	w := httptest.NewRecorder()
+	GetUpcomingRecurringTransactions(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var upcoming []models.UpcomingRecurringTransaction
+	if err := json.NewDecoder(w.Body).Decode(&upcoming); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	// A monthly template starting tomorrow fires twice in a 45-day window; paused templates are excluded
+	if len(upcoming) != 2 {
+		t.Fatalf("Expected 2 upcoming occurrences, got %d", len(upcoming))
+	}
+	for _, u := range upcoming {
+		if u.RecurringID != "monthly-template" {
+			t.Errorf("Unexpected template in projection: %s", u.RecurringID)
+		}
+	}
+	if second := models.NextOccurrence(today.AddDate(0, 0, 1), models.FrequencyMonthly, 0); !upcoming[1].Date.Equal(second) {
+		t.Errorf("Expected second occurrence one month after the first, got %v", upcoming[1].Date)
+	}
+
+	// Projection must not create transactions
+	if count := countTransactions(t); count != 0 {
+		t.Errorf("Expected 0 transactions after projection, got %d", count)
+	}
+
+	req = SetupTestAuth(httptest.NewRequest("GET", "/recurring/upcoming?days=0", nil))
+	w = httptest.NewRecorder()
+	GetUpcomingRecurringTransactions(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for invalid days, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestMakeTransactionRecurring(t *testing.T) {
+	setupRecurringTestDB()
+	defer CleanupTestDB()
+
+	txDate := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+	_, err := database.DB.Exec(`
+		INSERT INTO transactions (id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional, userId)
+		VALUES ('source-tx', 12.99, 'Streaming', ?, ?, 'Entertainment', 'Sarah', 1, '2024-03-05', 'Patrick', 1, ?)
+	`, txDate, txDate, TestUserID)
+	if err != nil {
+		t.Fatalf("Failed to insert source transaction: %v", err)
+	}
+
+	body := `{"frequency": "monthly"}`
+	req := TestRequest("POST", "/transactions/source-tx/make-recurring", &body)
+	req = mux.SetURLVars(req, map[string]string{"id": "source-tx"})
+	w := httptest.NewRecorder()
+	MakeTransactionRecurring(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	var rt models.RecurringTransaction
+	if err := json.NewDecoder(w.Body).Decode(&rt); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	if rt.UserID != TestUserID || rt.Amount != 12.99 || rt.Description != "Streaming" ||
+		rt.Type != "Entertainment" || rt.PayTo != "Sarah" || rt.EnteredBy != "Patrick" || !rt.Optional {
+		t.Errorf("Template does not mirror the source transaction: %+v", rt)
+	}
+	if rt.Frequency != models.FrequencyMonthly || !rt.Active {
+		t.Errorf("Expected an active monthly template, got %+v", rt)
+	}
+	if !rt.NextDate.Equal(txDate.AddDate(0, 1, 0)) {
+		t.Errorf("Expected next date %v, got %v", txDate.AddDate(0, 1, 0), rt.NextDate)
+	}
+
+	var stored int
+	database.DB.QueryRow("SELECT COUNT(*) FROM recurring_transactions WHERE id = ?", rt.ID).Scan(&stored)
+	if stored != 1 {
+		t.Errorf("Expected template to be stored, found %d rows", stored)
+	}
+
+	// The source transaction must be untouched
+	var amount float64
+	var description string
+	var paid bool
+	err = database.DB.QueryRow("SELECT amount, description, paid FROM transactions WHERE id = 'source-tx'").Scan(&amount, &description, &paid)
+	if err != nil {
+		t.Fatalf("Failed to read source transaction: %v", err)
+	}
+	if amount != 12.99 || description != "Streaming" || !paid {
+		t.Errorf("Source transaction was modified: amount=%v description=%s paid=%v", amount, description, paid)
+	}
+	if count := countTransactions(t); count != 1 {
+		t.Errorf("Expected 1 transaction, got %d", count)
+	}
+}
+
+func TestMakeTransactionRecurringInvalidFrequency(t *testing.T) {
+	setupRecurringTestDB()
+	defer CleanupTestDB()
+
+	body := `{"frequency": "hourly"}`
+	req := TestRequest("POST", "/transactions/any/make-recurring", &body)
+	req = mux.SetURLVars(req, map[string]string{"id": "any"})
+	w := httptest.NewRecorder()
+	MakeTransactionRecurring(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
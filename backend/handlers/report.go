@@ -6,12 +6,26 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"bennwallet/backend/database"
 	"bennwallet/backend/middleware"
 	"bennwallet/backend/models"
 )
 
+// reportOwnerUserID returns the ownerUserId a report is scoped to, or "" for
+// all accessible users. On failure it also returns the HTTP status to respond with.
+func reportOwnerUserID(r *http.Request, userID string) (string, int, error) {
+	ownerUserID := r.URL.Query().Get("ownerUserId")
+	if ownerUserID == "" {
+		return "", http.StatusOK, nil
+	}
+	if !middleware.CheckUserPermission(userID, ownerUserID, models.ResourceTransactions, models.PermissionRead) {
+		return "", http.StatusForbidden, fmt.Errorf("Forbidden: no read access to transactions of user %s", ownerUserID)
+	}
+	return ownerUserID, http.StatusOK, nil
+}
+
 func GetYNABSplits(w http.ResponseWriter, r *http.Request) {
 	// Get the user ID from the authentication context
 	userID := middleware.GetUserIDFromContext(r)
@@ -31,6 +45,31 @@ func GetYNABSplits(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("Received request: %+v", request)
 
+	results, status, err := ynabSplitTotals(r, userID, request)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	log.Printf("Returning %d results", len(results))
+
+	// Always return an array, even if empty
+	if results == nil {
+		results = []models.CategoryTotal{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		log.Printf("Error encoding response: %v", err)
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// ynabSplitTotals sums the transactions matching a splits report filter by
+// category, largest total first. On failure it also returns the HTTP status
+// to respond with.
+func ynabSplitTotals(r *http.Request, userID string, request models.ReportFilter) ([]models.CategoryTotal, int, error) {
 	// Check if the optional column exists
 	var hasOptionalColumn bool
 	err := database.DB.QueryRow(`
@@ -70,17 +109,28 @@ func GetYNABSplits(w http.ResponseWriter, r *http.Request) {
 		hasUserIdColumn = false
 	}
 
-	// Build the base query
+	// Build the base query. Refunds count against the category of the
+	// transaction they refund.
+	category, amount := refundAwareExpressions("type")
 	var query string
 	query = `
-		SELECT type as category, SUM(amount) as total
+		SELECT ` + category + ` as category, SUM(` + amount + `) as total
 		FROM transactions
-		WHERE 1=1
+		WHERE deleted_at IS NULL
 	`
 	var args []interface{}
 
+	ownerUserID, status, err := reportOwnerUserID(r, userID)
+	if err != nil {
+		return nil, status, err
+	}
+
 	// Add user permissions filtering
-	if hasUserIdColumn {
+	if hasUserIdColumn && ownerUserID != "" {
+		// Scoped to a single owner the caller can read
+		query += " AND userId = ?"
+		args = append(args, ownerUserID)
+	} else if hasUserIdColumn {
 		// Get accessible user IDs through permissions system
 		accessibleUsers, err := middleware.GetUserAccessibleResources(userID, models.ResourceTransactions, models.PermissionRead)
 		if err != nil {
@@ -105,15 +155,14 @@ func GetYNABSplits(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Add date filters
-	if request.StartDate != "" {
-		query += " AND date >= ?"
-		args = append(args, request.StartDate)
-	}
-	if request.EndDate != "" {
-		query += " AND date <= ?"
-		args = append(args, request.EndDate)
+	// Add date filters (end date is inclusive)
+	dateRange, err := ParseDateRange(request.StartDate, request.EndDate, request.Range, time.Now())
+	if err != nil {
+		return nil, http.StatusBadRequest, err
 	}
+	dateClause, dateArgs := dateRange.SQLConditions("date")
+	query += dateClause
+	args = append(args, dateArgs...)
 
 	// Add transaction date filters if column exists and filters are provided
 	if hasTransactionDateColumn && request.TransactionDateMonth != nil && request.TransactionDateYear != nil {
@@ -125,10 +174,20 @@ func GetYNABSplits(w http.ResponseWriter, r *http.Request) {
 		args = append(args, startDate, endDate)
 	}
 
-	// Add category filter
-	if request.Category != "" {
-		query += " AND type = ?"
-		args = append(args, request.Category)
+	// Add category filter, matching any of the listed categories
+	if names := request.CategoryNames(); len(names) > 0 {
+		placeholders := make([]string, len(names))
+		for i, name := range names {
+			placeholders[i] = "?"
+			args = append(args, name)
+		}
+		query += fmt.Sprintf(" AND %s IN (%s)", category, strings.Join(placeholders, ","))
+	}
+
+	// Add tag filter; tags are stored normalized
+	if tags := normalizeTags([]string{request.Tag}); len(tags) > 0 {
+		query += " AND EXISTS (SELECT 1 FROM transaction_tags tt WHERE tt.transaction_id = transactions.id AND tt.tag = ?)"
+		args = append(args, tags[0])
 	}
 
 	// Add PayTo filter with proper SQL query structuring
@@ -152,21 +211,20 @@ func GetYNABSplits(w http.ResponseWriter, r *http.Request) {
 		query += " AND paid = 1"
 	}
 
-	// Add optional filter if the column exists
-	if hasOptionalColumn && (request.Optional == nil || *request.Optional == false) {
+	// Add optional filter if the column exists, falling back to the user's default
+	if optional := reportOptional(userID, request.Optional); hasOptionalColumn && (optional == nil || *optional == false) {
 		query += " AND (optional = 0 OR optional IS NULL)"
 	}
 
 	// Add grouping and ordering
-	query += " GROUP BY type ORDER BY total DESC"
+	query += " GROUP BY " + category + " ORDER BY total DESC"
 	log.Printf("Executing query: %s with args: %v", query, args)
 
 	// Run the query
-	rows, err := database.DB.Query(query, args...)
+	rows, err := database.ReadDB().Query(query, args...)
 	if err != nil {
 		log.Printf("Error executing query: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return nil, http.StatusInternalServerError, err
 	}
 	defer rows.Close()
 
@@ -176,8 +234,7 @@ func GetYNABSplits(w http.ResponseWriter, r *http.Request) {
 		err := rows.Scan(&ct.Category, &ct.Total)
 		if err != nil {
 			log.Printf("Error scanning result: %v", err)
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+			return nil, http.StatusInternalServerError, err
 		}
 		results = append(results, ct)
 	}
@@ -185,21 +242,8 @@ func GetYNABSplits(w http.ResponseWriter, r *http.Request) {
 	// Check for any errors from iterating over rows
 	if err = rows.Err(); err != nil {
 		log.Printf("Error after scanning all rows: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return nil, http.StatusInternalServerError, err
 	}
 
-	log.Printf("Returning %d results", len(results))
-
-	// Always return an array, even if empty
-	if results == nil {
-		results = []models.CategoryTotal{}
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(results); err != nil {
-		log.Printf("Error encoding response: %v", err)
-		http.Error(w, "Error encoding response", http.StatusInternalServerError)
-		return
-	}
+	return results, http.StatusOK, nil
 }
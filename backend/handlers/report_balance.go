@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// GetBalanceAsOf returns the net (income minus expense) of the accessible
+// transactions dated on or before ?date=, for point-in-time statements. It
+// counts transactions like the net worth trend: INCOME_TYPES add, other
+// types subtract, refunds count the other way, and paid and unpaid
+// transactions all count.
+func GetBalanceAsOf(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	ownerUserID, status, err := reportOwnerUserID(r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	dateParam := r.URL.Query().Get("date")
+	if dateParam == "" {
+		http.Error(w, "date is required", http.StatusBadRequest)
+		return
+	}
+	asOf, err := time.Parse(dateLayout, dateParam)
+	if err != nil {
+		http.Error(w, "Invalid date: expected YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+
+	sqlQuery := "SELECT type, amount, " + isRefundExpression() + " FROM transactions WHERE deleted_at IS NULL"
+	var args []interface{}
+	if ownerUserID != "" {
+		sqlQuery += " AND userId = ?"
+		args = append(args, ownerUserID)
+	} else {
+		var accessClause string
+		accessClause, args = accessibleTransactionsClause(userID)
+		sqlQuery += accessClause
+	}
+	endClause, endArgs := DateRange{End: asOf}.SQLConditions("date")
+	sqlQuery += endClause
+	args = append(args, endArgs...)
+
+	rows, err := database.ReadDB().Query(sqlQuery, args...)
+	if err != nil {
+		log.Printf("Error querying balance transactions: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	// Work in cents so long histories don't drift
+	income := incomeTypes()
+	var incomeCents, expenseCents int64
+	for rows.Next() {
+		var transactionType string
+		var amount float64
+		var isRefund bool
+		if err := rows.Scan(&transactionType, &amount, &isRefund); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Refunds undo part of an expense
+		cents := int64(math.Round(amount * 100))
+		if isRefund {
+			cents = -cents
+		}
+		if isIncomeType(transactionType, income) {
+			incomeCents += cents
+		} else {
+			expenseCents += cents
+		}
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.BalanceAsOf{
+		Date:    asOf.Format(dateLayout),
+		Income:  models.Amount(incomeCents) / 100,
+		Expense: models.Amount(expenseCents) / 100,
+		Balance: models.Amount(incomeCents-expenseCents) / 100,
+	})
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+func getBalanceAsOf(t *testing.T, url string, expectedStatus int) models.BalanceAsOf {
+	t.Helper()
+
+	req := TestRequest("GET", url, nil)
+	req = MockAuthContext(req, testUserID)
+	w := httptest.NewRecorder()
+	GetBalanceAsOf(w, req)
+	if w.Code != expectedStatus {
+		t.Fatalf("Expected status code %d, got %d: %s", expectedStatus, w.Code, w.Body.String())
+	}
+
+	var balance models.BalanceAsOf
+	if expectedStatus == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&balance); err != nil {
+			t.Fatalf("Error decoding response: %v", err)
+		}
+	}
+	return balance
+}
+
+func TestGetBalanceAsOf(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	insertNetWorthIncome(t, "inc1", 1000, "2023-01-15")
+	insertNetWorthIncome(t, "inc2", 500, "2023-05-01")
+
+	// Sample expenses: 100 on Jan 1, 385 on Feb 15 and 260 on Mar 31.
+	// The cutoff day itself is included.
+	balance := getBalanceAsOf(t, "/reports/balance-as-of?date=2023-02-15", http.StatusOK)
+	if balance.Date != "2023-02-15" || balance.Income != 1000 || balance.Expense != 485 || balance.Balance != 515 {
+		t.Errorf("Expected 1000 - 485 = 515 as of Feb 15, got %+v", balance)
+	}
+
+	// Transactions after the cutoff don't count
+	balance = getBalanceAsOf(t, "/reports/balance-as-of?date=2023-02-14", http.StatusOK)
+	if balance.Income != 1000 || balance.Expense != 100 || balance.Balance != 900 {
+		t.Errorf("Expected 1000 - 100 = 900 as of Feb 14, got %+v", balance)
+	}
+
+	balance = getBalanceAsOf(t, "/reports/balance-as-of?date=2022-12-31", http.StatusOK)
+	if balance.Income != 0 || balance.Expense != 0 || balance.Balance != 0 {
+		t.Errorf("Expected nothing before the first transaction, got %+v", balance)
+	}
+}
+
+func TestGetBalanceAsOfRequiresDate(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	getBalanceAsOf(t, "/reports/balance-as-of", http.StatusBadRequest)
+	getBalanceAsOf(t, "/reports/balance-as-of?date=15/02/2023", http.StatusBadRequest)
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// reportGroupColumns maps the groupings reports accept to transaction columns
+var reportGroupColumns = map[string]string{
+	"category":  "type",
+	"payTo":     "payTo",
+	"enteredBy": "enteredBy",
+}
+
+// accessibleTransactionsClause returns the WHERE fragment limiting transactions
+// to those the user can read
+func accessibleTransactionsClause(userID string) (string, []interface{}) {
+	accessibleUsers, err := middleware.GetUserAccessibleResources(userID, models.ResourceTransactions, models.PermissionRead)
+	if err != nil {
+		log.Printf("Error getting accessible resources: %v", err)
+		// Fallback to only showing the user's own transactions
+		return " AND userId = ?", []interface{}{userID}
+	}
+	if len(accessibleUsers) == 0 {
+		return " AND userId = ?", []interface{}{userID}
+	}
+
+	placeholders := make([]string, len(accessibleUsers))
+	args := make([]interface{}, len(accessibleUsers))
+	for i := range accessibleUsers {
+		placeholders[i] = "?"
+		args[i] = accessibleUsers[i]
+	}
+	return fmt.Sprintf(" AND (userId IN (%s) OR userId IS NULL)", strings.Join(placeholders, ",")), args
+}
+
+// reportTransactionsClause returns the WHERE fragment selecting the
+// transactions reports count: those the user can read, or only ownerUserID's
+// when set, dated within the range. Only paid transactions count unless
+// paid says otherwise, and optional ones only if optional or, when it is
+// nil, the user's report settings include them.
+func reportTransactionsClause(userID, ownerUserID string, dateRange DateRange, paid, optional *bool) (string, []interface{}) {
+	var query string
+	var args []interface{}
+	if ownerUserID != "" {
+		query += " AND userId = ?"
+		args = append(args, ownerUserID)
+	} else {
+		query, args = accessibleTransactionsClause(userID)
+	}
+
+	dateClause, dateArgs := dateRange.SQLConditions("date")
+	query += dateClause
+	args = append(args, dateArgs...)
+
+	if paid != nil {
+		query += " AND paid = ?"
+		args = append(args, *paid)
+	} else {
+		query += " AND paid = 1"
+	}
+
+	if optional = reportOptional(userID, optional); optional == nil || !*optional {
+		query += " AND (optional = 0 OR optional IS NULL)"
+	}
+	return query, args
+}
+
+// groupTotals sums the amounts of the user's accessible transactions in a
+// date range by the given column, restricted to ownerUserID when it is set.
+// Like the splits report, only paid, non-optional transactions are counted
+// unless paid or optional say otherwise. Refunds are subtracted from the
+// group of the transaction they refund.
+func groupTotals(userID, ownerUserID, column string, dateRange DateRange, paid, optional *bool) (map[string]float64, error) {
+	filterClause, args := reportTransactionsClause(userID, ownerUserID, dateRange, paid, optional)
+	group, amount := refundAwareExpressions(column)
+	query := fmt.Sprintf(`
+		SELECT COALESCE(%s, ''), SUM(%s)
+		FROM transactions
+		WHERE deleted_at IS NULL
+	`, group, amount) + filterClause + fmt.Sprintf(" GROUP BY COALESCE(%s, '')", group)
+
+	rows, err := database.ReadDB().Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := map[string]float64{}
+	for rows.Next() {
+		var group string
+		var total float64
+		if err := rows.Scan(&group, &total); err != nil {
+			return nil, err
+		}
+		totals[group] = total
+	}
+	return totals, rows.Err()
+}
+
+// parseReportFlags reads the optional ?paid= and ?optional= filters that
+// groupTotals takes; unset filters are nil
+func parseReportFlags(query url.Values) (paid, optional *bool, err error) {
+	var flags [2]*bool
+	for i, name := range []string{"paid", "optional"} {
+		if param := query.Get(name); param != "" {
+			value, err := strconv.ParseBool(param)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Invalid %s: expected true or false", name)
+			}
+			flags[i] = &value
+		}
+	}
+	return flags[0], flags[1], nil
+}
+
+// ComparePeriods returns each group's total for two periods along with the
+// change from period A to period B
+func ComparePeriods(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	ownerUserID, status, err := reportOwnerUserID(r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	var request models.PeriodComparisonRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.GroupBy == "" {
+		request.GroupBy = "category"
+	}
+	column, ok := reportGroupColumns[request.GroupBy]
+	if !ok {
+		http.Error(w, fmt.Sprintf("Invalid groupBy %q (expected category, payTo or enteredBy)", request.GroupBy), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	rangeA, err := ParseDateRange(request.PeriodA.StartDate, request.PeriodA.EndDate, request.PeriodA.Range, now)
+	if err != nil {
+		http.Error(w, "Invalid periodA: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	rangeB, err := ParseDateRange(request.PeriodB.StartDate, request.PeriodB.EndDate, request.PeriodB.Range, now)
+	if err != nil {
+		http.Error(w, "Invalid periodB: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	totalsA, err := groupTotals(userID, ownerUserID, column, rangeA, request.Paid, request.Optional)
+	if err != nil {
+		log.Printf("Error computing period A totals: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	totalsB, err := groupTotals(userID, ownerUserID, column, rangeB, request.Paid, request.Optional)
+	if err != nil {
+		log.Printf("Error computing period B totals: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Groups present in only one period are reported with a zero total for the other
+	groupNames := map[string]bool{}
+	for group := range totalsA {
+		groupNames[group] = true
+	}
+	for group := range totalsB {
+		groupNames[group] = true
+	}
+
+	result := models.PeriodComparison{
+		GroupBy: request.GroupBy,
+		PeriodA: models.ReportPeriod{StartDate: rangeA.StartString(), EndDate: rangeA.EndString()},
+		PeriodB: models.ReportPeriod{StartDate: rangeB.StartString(), EndDate: rangeB.EndString()},
+		Groups:  []models.PeriodComparisonGroup{},
+	}
+	for group := range groupNames {
+		g := models.PeriodComparisonGroup{
+			Group:  group,
+			TotalA: models.Amount(totalsA[group]),
+			TotalB: models.Amount(totalsB[group]),
+		}
+		g.Delta = models.Amount(math.Round(float64(g.TotalB-g.TotalA)*100) / 100)
+		if g.TotalA != 0 {
+			percent := math.Round(float64(g.Delta)/math.Abs(float64(g.TotalA))*10000) / 100
+			g.PercentChange = &percent
+		}
+		result.Groups = append(result.Groups, g)
+	}
+	sort.Slice(result.Groups, func(i, j int) bool {
+		return result.Groups[i].Group < result.Groups[j].Group
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+func TestComparePeriods(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	body := `{
+		"periodA": {"startDate": "2023-02-01", "endDate": "2023-02-28"},
+		"periodB": {"startDate": "2023-03-01", "endDate": "2023-03-31"},
+		"groupBy": "category"
+	}`
+	req := TestRequest("POST", "/reports/compare", &body)
+	w := httptest.NewRecorder()
+	ComparePeriods(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var result models.PeriodComparison
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	// February has Food and Housing; March has Housing and Fun. The optional
+	// and unpaid February transactions are excluded by default.
+	expected := []struct {
+		group   string
+		totalA  models.Amount
+		totalB  models.Amount
+		delta   models.Amount
+		percent *float64
+	}{
+		{"Food", 125, 0, -125, floatPtr(-100)},
+		{"Fun", 0, 60, 60, nil},
+		{"Housing", 150, 200, 50, floatPtr(33.33)},
+	}
+
+	if len(result.Groups) != len(expected) {
+		t.Fatalf("Expected %d groups, got %d: %+v", len(expected), len(result.Groups), result.Groups)
+	}
+	for i, e := range expected {
+		g := result.Groups[i]
+		if g.Group != e.group || g.TotalA != e.totalA || g.TotalB != e.totalB || g.Delta != e.delta {
+			t.Errorf("Unexpected group %d: %+v", i, g)
+		}
+		if (e.percent == nil) != (g.PercentChange == nil) || (e.percent != nil && *e.percent != *g.PercentChange) {
+			t.Errorf("Unexpected percent change for %s: %v", g.Group, g.PercentChange)
+		}
+	}
+}
+
+func TestComparePeriodsRejectsInvalidInput(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	for _, body := range []string{
+		`{"periodA": {"range": "thisMonth"}, "periodB": {"range": "lastMonth"}, "groupBy": "description"}`,
+		`{"periodA": {"startDate": "2023-03-01", "endDate": "2023-02-01"}, "periodB": {"range": "lastMonth"}}`,
+	} {
+		req := TestRequest("POST", "/reports/compare", &body)
+		w := httptest.NewRecorder()
+		ComparePeriods(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d for %s, got %d", http.StatusBadRequest, body, w.Code)
+		}
+	}
+}
+
+func TestComparePeriodsForbiddenOwner(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	if _, err := database.DB.Exec(`INSERT INTO users (id, username, name, isAdmin, role) VALUES ('viewer', 'viewer', 'Viewer', 0, 'user')`); err != nil {
+		t.Fatalf("Failed to insert user: %v", err)
+	}
+
+	body := `{"periodA": {"range": "lastMonth"}, "periodB": {"range": "thisMonth"}}`
+	req := httptest.NewRequest("POST", "/reports/compare?ownerUserId="+TestUserID, strings.NewReader(body))
+	req = MockAuthContext(req, "viewer")
+	w := httptest.NewRecorder()
+	ComparePeriods(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", http.StatusForbidden, w.Code)
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
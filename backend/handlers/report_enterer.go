@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// GetEntererTotals returns how much each person entered: the totals of the
+// accessible transactions in the period grouped by enteredBy, largest first.
+// Like the other reports, only paid, non-optional transactions count unless
+// ?paid= or ?optional= say otherwise.
+func GetEntererTotals(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	ownerUserID, status, err := reportOwnerUserID(r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	query := r.URL.Query()
+	dateRange, err := ParseDateRange(query.Get("startDate"), query.Get("endDate"), query.Get("range"), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	paid, optional, err := parseReportFlags(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	totals, err := groupTotals(userID, ownerUserID, reportGroupColumns["enteredBy"], dateRange, paid, optional)
+	if err != nil {
+		log.Printf("Error computing enterer totals: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := []models.EntererTotal{}
+	for enteredBy, total := range totals {
+		result = append(result, models.EntererTotal{EnteredBy: enteredBy, Total: models.Amount(math.Round(total*100) / 100)})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Total != result[j].Total {
+			return result[i].Total > result[j].Total
+		}
+		return result[i].EnteredBy < result[j].EnteredBy
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+func getEntererTotals(t *testing.T, url, userID string) []models.EntererTotal {
+	t.Helper()
+
+	req := TestRequest("GET", url, nil)
+	req = MockAuthContext(req, userID)
+	w := httptest.NewRecorder()
+	GetEntererTotals(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var totals []models.EntererTotal
+	if err := json.NewDecoder(w.Body).Decode(&totals); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	return totals
+}
+
+func TestGetEntererTotals(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	// A partner whose transactions the viewer may read, and a stranger's the
+	// viewer may not
+	for _, stmt := range []string{
+		`INSERT INTO users (id, username, name, isAdmin, role) VALUES ('partner', 'partner', 'Partner', 0, 'user')`,
+		`INSERT INTO users (id, username, name, isAdmin, role) VALUES ('viewer', 'viewer', 'Viewer', 0, 'user')`,
+		`INSERT INTO permissions (granted_user_id, owner_user_id, resource_type, permission_type) VALUES ('viewer', 'partner', 'transactions', 'read')`,
+		`INSERT INTO transactions (id, amount, description, date, type, payTo, paid, enteredBy, optional, userId)
+			VALUES ('partner-tx', 42, 'Gym', '2023-02-01', 'Health', 'Partner', 1, 'Partner', 0, 'partner')`,
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to seed data: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		url      string
+		caller   string
+		expected []models.EntererTotal
+	}{
+		{
+			name:     "all paid, non-optional transactions",
+			url:      "/reports/by-enterer",
+			caller:   testUserID,
+			expected: []models.EntererTotal{{EnteredBy: "Patrick", Total: 325}, {EnteredBy: "Sarah", Total: 310}, {EnteredBy: "Partner", Total: 42}},
+		},
+		{
+			name:     "date range",
+			url:      "/reports/by-enterer?startDate=2023-02-01&endDate=2023-02-28",
+			caller:   testUserID,
+			expected: []models.EntererTotal{{EnteredBy: "Patrick", Total: 225}, {EnteredBy: "Sarah", Total: 50}, {EnteredBy: "Partner", Total: 42}},
+		},
+		{
+			name:     "including optional and unpaid",
+			url:      "/reports/by-enterer?paid=false&optional=true",
+			caller:   testUserID,
+			expected: []models.EntererTotal{{EnteredBy: "Patrick", Total: 80}},
+		},
+		{
+			name:     "only transactions the caller can read",
+			url:      "/reports/by-enterer",
+			caller:   "viewer",
+			expected: []models.EntererTotal{{EnteredBy: "Partner", Total: 42}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			totals := getEntererTotals(t, tc.url, tc.caller)
+			if len(totals) != len(tc.expected) {
+				t.Fatalf("Expected %+v, got %+v", tc.expected, totals)
+			}
+			for i := range tc.expected {
+				if totals[i] != tc.expected[i] {
+					t.Errorf("Expected %+v at position %d, got %+v", tc.expected[i], i, totals[i])
+				}
+			}
+		})
+	}
+
+	req := TestRequest("GET", "/reports/by-enterer?paid=maybe", nil)
+	w := httptest.NewRecorder()
+	GetEntererTotals(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an invalid paid flag, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// unsafeFileNameChars matches characters kept out of export file names
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// writeCustomReportCSV writes a report result as CSV with a header row
+func writeCustomReportCSV(w *csv.Writer, result models.CustomReportResult) error {
+	if err := w.Write([]string{result.GroupBy, "total"}); err != nil {
+		return err
+	}
+	for _, row := range result.Rows {
+		if err := w.Write([]string{row.Group, strconv.FormatFloat(float64(row.Total), 'f', 2, 64)}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// exportedReports returns the custom reports to export: every report the user
+// owns, or the given IDs when there are any. Selected reports must be owned
+// by the user or currently shared. On failure it also returns the HTTP
+// status to respond with.
+func exportedReports(userID string, ids []string, now time.Time) ([]models.CustomReport, int, error) {
+	var reports []models.CustomReport
+	if len(ids) == 0 {
+		rows, err := database.DB.Query("SELECT "+customReportColumns+" FROM custom_reports WHERE user_id = ? ORDER BY name, id", userID)
+		if err != nil {
+			log.Printf("Error querying custom reports: %v", err)
+			return nil, http.StatusInternalServerError, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			report, err := scanCustomReport(rows)
+			if err != nil {
+				return nil, http.StatusInternalServerError, err
+			}
+			reports = append(reports, report)
+		}
+		return reports, http.StatusOK, rows.Err()
+	}
+
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		report, err := scanCustomReport(database.DB.QueryRow("SELECT "+customReportColumns+" FROM custom_reports WHERE id = ?", id))
+		if err == sql.ErrNoRows || (err == nil && report.UserID != userID && !report.SharedAt(now)) {
+			return nil, http.StatusNotFound, fmt.Errorf("Report %s not found", id)
+		} else if err != nil {
+			log.Printf("Error getting custom report %s: %v", id, err)
+			return nil, http.StatusInternalServerError, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, http.StatusOK, nil
+}
+
+// ExportAllReports runs every custom report the caller owns, or those listed
+// in ?ids= (comma separated), and returns the results as a zip holding one
+// CSV per report
+func ExportAllReports(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	var ids []string
+	for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	now := time.Now()
+	reports, status, err := exportedReports(userID, ids, now)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	// The zip is built in memory so a failing report doesn't leave a
+	// truncated download behind
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	usedNames := map[string]bool{}
+	for _, report := range reports {
+		result, err := runCustomReport(userID, report, now)
+		if errors.Is(err, errInvalidReportConfig) {
+			http.Error(w, fmt.Sprintf("Report %s: %v", report.ID, err), http.StatusBadRequest)
+			return
+		} else if errors.Is(err, errReportScopeForbidden) {
+			http.Error(w, fmt.Sprintf("Report %s: %v", report.ID, err), http.StatusForbidden)
+			return
+		} else if err != nil {
+			log.Printf("Error running custom report %s: %v", report.ID, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		name := strings.Trim(unsafeFileNameChars.ReplaceAllString(report.Name, "_"), "_")
+		if name == "" || usedNames[name] {
+			name = strings.TrimPrefix(name+"-"+report.ID, "-")
+		}
+		usedNames[name] = true
+
+		entry, err := archive.Create(name + ".csv")
+		if err == nil {
+			err = writeCustomReportCSV(csv.NewWriter(entry), result)
+		}
+		if err != nil {
+			log.Printf("Error writing export of custom report %s: %v", report.ID, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := archive.Close(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Exported %d custom reports for user %s", len(reports), userID)
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="reports-`+now.Format(dateLayout)+`.zip"`)
+	w.Write(buf.Bytes())
+}
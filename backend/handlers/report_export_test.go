@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bennwallet/backend/database"
+)
+
+func readExportZip(t *testing.T, body []byte) map[string]string {
+	t.Helper()
+
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("Error reading zip: %v", err)
+	}
+	entries := map[string]string{}
+	for _, f := range archive.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Error opening %s: %v", f.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("Error reading %s: %v", f.Name, err)
+		}
+		entries[f.Name] = string(content)
+	}
+	return entries
+}
+
+func TestExportAllReports(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+	createSavedReportTables()
+
+	_, err := database.DB.Exec(`
+		INSERT INTO custom_reports (id, user_id, name, report_config, is_public) VALUES
+		('by-category', ?, 'February by category', '{"startDate": "2023-02-01", "endDate": "2023-02-28"}', 0),
+		('by-payee', ?, 'Q1 by payee', '{"groupBy": "payTo", "startDate": "2023-01-01", "endDate": "2023-03-31"}', 0),
+		('theirs', 'other-user', 'Private', '{}', 0)
+	`, testUserID, testUserID)
+	if err != nil {
+		t.Fatalf("Failed to seed custom reports: %v", err)
+	}
+
+	req := TestRequest("GET", "/reports/export-all", nil)
+	w := httptest.NewRecorder()
+	ExportAllReports(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/zip" {
+		t.Errorf("Expected a zip, got %s", ct)
+	}
+
+	// Only paid, non-optional transactions are counted, as in the other reports
+	expected := map[string]string{
+		"February_by_category.csv": "category,total\nFood,125.00\nHousing,150.00\n",
+		"Q1_by_payee.csv":          "payTo,total\nPatrick,200.00\nSarah,435.00\n",
+	}
+	entries := readExportZip(t, w.Body.Bytes())
+	if len(entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got %v", len(expected), entries)
+	}
+	for name, content := range expected {
+		if entries[name] != content {
+			t.Errorf("Expected %s to be %q, got %q", name, content, entries[name])
+		}
+	}
+
+	// Selecting IDs exports just those reports
+	req = TestRequest("GET", "/reports/export-all?ids=by-payee", nil)
+	w = httptest.NewRecorder()
+	ExportAllReports(w, req)
+	entries = readExportZip(t, w.Body.Bytes())
+	if len(entries) != 1 || entries["Q1_by_payee.csv"] != expected["Q1_by_payee.csv"] {
+		t.Errorf("Expected only the selected report, got %v", entries)
+	}
+
+	// Other users' private reports can't be selected
+	req = TestRequest("GET", "/reports/export-all?ids=by-payee,theirs", nil)
+	w = httptest.NewRecorder()
+	ExportAllReports(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// GetLedger returns the transactions in the period in date order, each with
+// the running balance (cumulative sum of amounts, less refunds) after it. It
+// counts the same transactions as the other reports, and ?paid= and
+// ?optional= override it.
+func GetLedger(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	ownerUserID, status, err := reportOwnerUserID(r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	query := r.URL.Query()
+	dateRange, err := ParseDateRange(query.Get("startDate"), query.Get("endDate"), query.Get("range"), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	paid, optional, err := parseReportFlags(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filterClause, args := reportTransactionsClause(userID, ownerUserID, dateRange, paid, optional)
+	rows, err := database.ReadDB().Query(`
+		SELECT id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional, userId, `+isRefundExpression()+`
+		FROM transactions
+		WHERE deleted_at IS NULL
+	`+filterClause+" ORDER BY date, id", args...)
+	if err != nil {
+		log.Printf("Error querying ledger transactions: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	ledger := models.Ledger{
+		StartDate: dateRange.StartString(),
+		EndDate:   dateRange.EndString(),
+		Entries:   []models.LedgerEntry{},
+	}
+	// Accumulate in cents so long ledgers don't drift
+	var balanceCents int64
+	for rows.Next() {
+		var e models.LedgerEntry
+		var payTo, paidDate, ownerID sql.NullString
+		var transactionDate sql.NullTime
+		var isRefund bool
+		err := rows.Scan(&e.ID, &e.Amount, &e.Description, &e.Date, &transactionDate, &e.Type, &payTo,
+			&e.Paid, &paidDate, &e.EnteredBy, &e.Optional, &ownerID, &isRefund)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		e.PayTo = payTo.String
+		e.PaidDate = paidDate.String
+		e.UserID = ownerID.String
+		if transactionDate.Valid {
+			e.TransactionDate = transactionDate.Time
+		} else {
+			e.TransactionDate = e.Date
+		}
+
+		// Refunds bring the balance back down
+		if isRefund {
+			balanceCents -= int64(math.Round(float64(e.Amount) * 100))
+		} else {
+			balanceCents += int64(math.Round(float64(e.Amount) * 100))
+		}
+		e.Balance = models.Amount(balanceCents) / 100
+		ledger.Entries = append(ledger.Entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	ledger.Balance = models.Amount(balanceCents) / 100
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ledger)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+func getLedger(t *testing.T, url string) models.Ledger {
+	t.Helper()
+
+	req := TestRequest("GET", url, nil)
+	req = MockAuthContext(req, testUserID)
+	w := httptest.NewRecorder()
+	GetLedger(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var ledger models.Ledger
+	if err := json.NewDecoder(w.Body).Decode(&ledger); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	return ledger
+}
+
+func TestGetLedgerRunningBalance(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	ledger := getLedger(t, "/reports/ledger?startDate=2023-01-01&endDate=2023-12-31")
+
+	// Paid, non-optional sample transactions by date, then ID
+	expected := []struct {
+		id      string
+		balance models.Amount
+	}{
+		{"tx1", 100}, {"tx2", 150}, {"tx4", 225}, {"tx5", 375}, {"tx3", 575}, {"tx6", 635},
+	}
+	if len(ledger.Entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d: %+v", len(expected), len(ledger.Entries), ledger.Entries)
+	}
+	for i, e := range expected {
+		if ledger.Entries[i].ID != e.id || ledger.Entries[i].Balance != e.balance {
+			t.Errorf("Entry %d: expected %s with balance %v, got %s with %v", i, e.id, e.balance, ledger.Entries[i].ID, ledger.Entries[i].Balance)
+		}
+	}
+	if ledger.Balance != 635 {
+		t.Errorf("Expected a closing balance of 635, got %v", ledger.Balance)
+	}
+}
+
+func TestGetLedgerPeriodStartsFromZero(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	// Unpaid transactions only: tx8 is the single one
+	ledger := getLedger(t, "/reports/ledger?startDate=2023-02-01&endDate=2023-02-28&paid=false")
+	if len(ledger.Entries) != 1 || ledger.Entries[0].ID != "tx8" || ledger.Entries[0].Balance != 80 {
+		t.Errorf("Expected only tx8 with a balance of 80, got %+v", ledger.Entries)
+	}
+	if ledger.StartDate != "2023-02-01" || ledger.EndDate != "2023-02-28" {
+		t.Errorf("Expected the period to be echoed back, got %s..%s", ledger.StartDate, ledger.EndDate)
+	}
+}
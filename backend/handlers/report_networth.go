@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// defaultIncomeTypes are the transaction types counted as income; every other
+// type is an expense. Override with INCOME_TYPES (comma separated).
+var defaultIncomeTypes = []string{"Income"}
+
+// incomeTypes returns the configured income transaction types
+func incomeTypes() []string {
+	var types []string
+	for _, value := range strings.Split(os.Getenv("INCOME_TYPES"), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			types = append(types, value)
+		}
+	}
+	if len(types) == 0 {
+		return defaultIncomeTypes
+	}
+	return types
+}
+
+// isIncomeType reports whether a transaction type counts as income, ignoring case
+func isIncomeType(transactionType string, income []string) bool {
+	for _, candidate := range income {
+		if strings.EqualFold(candidate, strings.TrimSpace(transactionType)) {
+			return true
+		}
+	}
+	return false
+}
+
+// netWorthIntervals maps each supported interval to functions finding the
+// start of the bucket holding a day, the start of the next bucket and the
+// bucket's label
+var netWorthIntervals = map[string]struct {
+	start func(day time.Time) time.Time
+	next  func(start time.Time) time.Time
+	label func(start time.Time) string
+}{
+	"week": {
+		// Weeks start on Monday
+		start: func(day time.Time) time.Time { return day.AddDate(0, 0, -(int(day.Weekday())+6)%7) },
+		next:  func(start time.Time) time.Time { return start.AddDate(0, 0, 7) },
+		label: func(start time.Time) string { return start.Format(dateLayout) },
+	},
+	"month": {
+		start: func(day time.Time) time.Time { return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC) },
+		next:  func(start time.Time) time.Time { return start.AddDate(0, 1, 0) },
+		label: func(start time.Time) string { return start.Format("2006-01") },
+	},
+	"year": {
+		start: func(day time.Time) time.Time { return time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC) },
+		next:  func(start time.Time) time.Time { return start.AddDate(1, 0, 0) },
+		label: func(start time.Time) string { return start.Format("2006") },
+	},
+}
+
+// GetNetWorthTrend returns the cumulative net (income minus expense) of the
+// accessible transactions per ?interval= (week, month or year; default
+// month). Transactions of the INCOME_TYPES types add to the net and all
+// others subtract from it, while refunds count the other way. Paid and
+// unpaid transactions all count. With a date range the buckets cover just
+// the range, and the transactions before it are summed into the opening
+// balance. Buckets without transactions are included so the trend has no
+// gaps.
+func GetNetWorthTrend(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	ownerUserID, status, err := reportOwnerUserID(r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	query := r.URL.Query()
+	intervalName := query.Get("interval")
+	if intervalName == "" {
+		intervalName = "month"
+	}
+	interval, ok := netWorthIntervals[intervalName]
+	if !ok {
+		http.Error(w, fmt.Sprintf("Invalid interval %q (expected week, month or year)", intervalName), http.StatusBadRequest)
+		return
+	}
+
+	dateRange, err := ParseDateRange(query.Get("startDate"), query.Get("endDate"), query.Get("range"), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Everything up to the end of the range counts towards the running total
+	sqlQuery := "SELECT date, type, amount, " + isRefundExpression() + " FROM transactions WHERE deleted_at IS NULL"
+	var args []interface{}
+	if ownerUserID != "" {
+		sqlQuery += " AND userId = ?"
+		args = append(args, ownerUserID)
+	} else {
+		var accessClause string
+		accessClause, args = accessibleTransactionsClause(userID)
+		sqlQuery += accessClause
+	}
+	endClause, endArgs := DateRange{End: dateRange.End}.SQLConditions("date")
+	sqlQuery += endClause + " ORDER BY date"
+	args = append(args, endArgs...)
+
+	rows, err := database.ReadDB().Query(sqlQuery, args...)
+	if err != nil {
+		log.Printf("Error querying net worth transactions: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	// Work in cents so long histories don't drift
+	type bucketCents struct{ income, expense int64 }
+	income := incomeTypes()
+	buckets := map[time.Time]*bucketCents{}
+	var openingCents int64
+	var first, last time.Time
+	for rows.Next() {
+		var date time.Time
+		var transactionType string
+		var amount float64
+		var isRefund bool
+		if err := rows.Scan(&date, &transactionType, &amount, &isRefund); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Refunds undo part of an expense
+		cents := int64(math.Round(amount * 100))
+		if isRefund {
+			cents = -cents
+		}
+		isIncome := isIncomeType(transactionType, income)
+		day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		if !dateRange.Start.IsZero() && day.Before(dateRange.Start) {
+			if isIncome {
+				openingCents += cents
+			} else {
+				openingCents -= cents
+			}
+			continue
+		}
+
+		start := interval.start(day)
+		if first.IsZero() || start.Before(first) {
+			first = start
+		}
+		if start.After(last) {
+			last = start
+		}
+		bucket, ok := buckets[start]
+		if !ok {
+			bucket = &bucketCents{}
+			buckets[start] = bucket
+		}
+		if isIncome {
+			bucket.income += cents
+		} else {
+			bucket.expense += cents
+		}
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Explicit bounds widen the trend to the whole range
+	if !dateRange.Start.IsZero() {
+		first = interval.start(dateRange.Start)
+	}
+	if !dateRange.End.IsZero() {
+		last = interval.start(dateRange.End)
+	}
+
+	trend := models.NetWorthTrend{
+		Interval: intervalName,
+		Opening:  models.Amount(openingCents) / 100,
+		Buckets:  []models.NetWorthBucket{},
+	}
+	cumulative := openingCents
+	for start := first; !first.IsZero() && !start.After(last); start = interval.next(start) {
+		b := models.NetWorthBucket{Period: interval.label(start)}
+		if bucket, ok := buckets[start]; ok {
+			net := bucket.income - bucket.expense
+			cumulative += net
+			b.Income = models.Amount(bucket.income) / 100
+			b.Expense = models.Amount(bucket.expense) / 100
+			b.Net = models.Amount(net) / 100
+		}
+		b.Cumulative = models.Amount(cumulative) / 100
+		trend.Buckets = append(trend.Buckets, b)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trend)
+}
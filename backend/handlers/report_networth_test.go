@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+// insertNetWorthIncome adds an income transaction to the report test data
+func insertNetWorthIncome(t *testing.T, id string, amount float64, date string) {
+	t.Helper()
+
+	day, _ := time.Parse("2006-01-02", date)
+	_, err := database.DB.Exec(`
+		INSERT INTO transactions (id, amount, description, date, type, paid, enteredBy, optional, userId)
+		VALUES (?, ?, 'Paycheck', ?, 'Income', 1, ?, 0, ?)
+	`, id, amount, day, testUserID, testUserID)
+	if err != nil {
+		t.Fatalf("Error inserting income: %v", err)
+	}
+}
+
+func getNetWorthTrend(t *testing.T, url string, expectedStatus int) models.NetWorthTrend {
+	t.Helper()
+
+	req := TestRequest("GET", url, nil)
+	req = MockAuthContext(req, testUserID)
+	w := httptest.NewRecorder()
+	GetNetWorthTrend(w, req)
+	if w.Code != expectedStatus {
+		t.Fatalf("Expected status code %d, got %d: %s", expectedStatus, w.Code, w.Body.String())
+	}
+
+	var trend models.NetWorthTrend
+	if expectedStatus == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&trend); err != nil {
+			t.Fatalf("Error decoding response: %v", err)
+		}
+	}
+	return trend
+}
+
+func TestGetNetWorthTrendAccumulatesAcrossMonths(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	insertNetWorthIncome(t, "inc1", 1000, "2023-01-15")
+	insertNetWorthIncome(t, "inc2", 500, "2023-05-01")
+
+	trend := getNetWorthTrend(t, "/reports/networth?interval=month", http.StatusOK)
+
+	// Sample expenses: 100 in January, 385 in February and 260 in March.
+	// April has no transactions but still gets a bucket.
+	expected := []models.NetWorthBucket{
+		{Period: "2023-01", Income: 1000, Expense: 100, Net: 900, Cumulative: 900},
+		{Period: "2023-02", Expense: 385, Net: -385, Cumulative: 515},
+		{Period: "2023-03", Expense: 260, Net: -260, Cumulative: 255},
+		{Period: "2023-04", Cumulative: 255},
+		{Period: "2023-05", Income: 500, Net: 500, Cumulative: 755},
+	}
+	if trend.Interval != "month" || trend.Opening != 0 {
+		t.Errorf("Expected a month trend opening at 0, got %s opening at %v", trend.Interval, trend.Opening)
+	}
+	if len(trend.Buckets) != len(expected) {
+		t.Fatalf("Expected %d buckets, got %d: %+v", len(expected), len(trend.Buckets), trend.Buckets)
+	}
+	for i, e := range expected {
+		if trend.Buckets[i] != e {
+			t.Errorf("Bucket %d: expected %+v, got %+v", i, e, trend.Buckets[i])
+		}
+	}
+}
+
+func TestGetNetWorthTrendOpeningBalance(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	insertNetWorthIncome(t, "inc1", 1000, "2023-01-15")
+
+	// January is summed into the opening balance and the range caps the end
+	trend := getNetWorthTrend(t, "/reports/networth?startDate=2023-02-01&endDate=2023-02-28", http.StatusOK)
+	if trend.Opening != 900 {
+		t.Errorf("Expected an opening balance of 900, got %v", trend.Opening)
+	}
+	if len(trend.Buckets) != 1 || trend.Buckets[0].Period != "2023-02" || trend.Buckets[0].Cumulative != 515 {
+		t.Errorf("Expected a single February bucket ending at 515, got %+v", trend.Buckets)
+	}
+
+	yearly := getNetWorthTrend(t, "/reports/networth?interval=year", http.StatusOK)
+	if len(yearly.Buckets) != 1 || yearly.Buckets[0].Period != "2023" || yearly.Buckets[0].Cumulative != 255 {
+		t.Errorf("Expected a single 2023 bucket ending at 255, got %+v", yearly.Buckets)
+	}
+}
+
+func TestGetNetWorthTrendInvalidInterval(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	getNetWorthTrend(t, "/reports/networth?interval=fortnight", http.StatusBadRequest)
+}
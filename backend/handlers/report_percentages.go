@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// GetCategoryPercentages returns each category's total in the period and its
+// percentage of the grand total, largest first. Totals count the same
+// transactions as the other reports, and ?paid= and ?optional= override it.
+func GetCategoryPercentages(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	ownerUserID, status, err := reportOwnerUserID(r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	query := r.URL.Query()
+	dateRange, err := ParseDateRange(query.Get("startDate"), query.Get("endDate"), query.Get("range"), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	paid, optional, err := parseReportFlags(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	totals, err := groupTotals(userID, ownerUserID, reportGroupColumns["category"], dateRange, paid, optional)
+	if err != nil {
+		log.Printf("Error computing category totals: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := models.CategoryPercentages{
+		StartDate:  dateRange.StartString(),
+		EndDate:    dateRange.EndString(),
+		Categories: []models.CategoryPercentage{},
+	}
+	for category, total := range totals {
+		total = math.Round(total*100) / 100
+		result.Total += models.Amount(total)
+		result.Categories = append(result.Categories, models.CategoryPercentage{Category: category, Total: models.Amount(total)})
+	}
+	result.Total = models.Amount(math.Round(float64(result.Total)*100) / 100)
+	sort.Slice(result.Categories, func(i, j int) bool {
+		if result.Categories[i].Total != result.Categories[j].Total {
+			return result.Categories[i].Total > result.Categories[j].Total
+		}
+		return result.Categories[i].Category < result.Categories[j].Category
+	})
+
+	amounts := make([]float64, len(result.Categories))
+	for i, c := range result.Categories {
+		amounts[i] = float64(c.Total)
+	}
+	for i, percentage := range percentagesOf(amounts) {
+		result.Categories[i].Percentage = percentage
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// percentagesOf returns each amount's share of their sum as a percentage
+// with two decimals. Rounding uses the largest remainder method, so the
+// percentages add up to exactly 100. A non-positive sum gives all zeros.
+func percentagesOf(amounts []float64) []float64 {
+	percentages := make([]float64, len(amounts))
+
+	var sum float64
+	for _, amount := range amounts {
+		sum += amount
+	}
+	if sum <= 0 {
+		return percentages
+	}
+
+	// Work in hundredths of a percent, handing the units lost to flooring to
+	// the shares that lost the most
+	const whole = 10000
+	units := make([]int, len(amounts))
+	remainders := make([]int, len(amounts))
+	allocated := 0
+	for i, amount := range amounts {
+		exact := amount / sum * whole
+		units[i] = int(math.Floor(exact))
+		allocated += units[i]
+		remainders[i] = i
+		percentages[i] = exact - float64(units[i])
+	}
+	sort.SliceStable(remainders, func(a, b int) bool {
+		return percentages[remainders[a]] > percentages[remainders[b]]
+	})
+	for k := 0; k < whole-allocated && k < len(remainders); k++ {
+		units[remainders[k]]++
+	}
+
+	for i := range percentages {
+		percentages[i] = float64(units[i]) / 100
+	}
+	return percentages
+}
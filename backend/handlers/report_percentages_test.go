@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+func TestGetCategoryPercentages(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	req := TestRequest("GET", "/reports/category-percentages?startDate=2023-01-01&endDate=2023-12-31", nil)
+	req = MockAuthContext(req, testUserID)
+	w := httptest.NewRecorder()
+	GetCategoryPercentages(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var result models.CategoryPercentages
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	// Paid, non-optional sample data: Housing 350, Food 225 and Fun 60 of 635
+	expected := []models.CategoryPercentage{
+		{Category: "Housing", Total: 350, Percentage: 55.12},
+		{Category: "Food", Total: 225, Percentage: 35.43},
+		{Category: "Fun", Total: 60, Percentage: 9.45},
+	}
+	if result.Total != 635 {
+		t.Errorf("Expected a grand total of 635, got %v", result.Total)
+	}
+	if !reflect.DeepEqual(result.Categories, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result.Categories)
+	}
+
+	var sum float64
+	for _, c := range result.Categories {
+		sum += c.Percentage
+	}
+	if math.Abs(sum-100) > 0.001 {
+		t.Errorf("Expected percentages to add up to 100, got %v", sum)
+	}
+}
+
+func TestGetCategoryPercentagesInvalidFlag(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	req := TestRequest("GET", "/reports/category-percentages?paid=maybe", nil)
+	req = MockAuthContext(req, testUserID)
+	w := httptest.NewRecorder()
+	GetCategoryPercentages(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestPercentagesOf(t *testing.T) {
+	testCases := []struct {
+		name     string
+		amounts  []float64
+		expected []float64
+	}{
+		{"thirds", []float64{1, 1, 1}, []float64{33.34, 33.33, 33.33}},
+		{"single", []float64{42}, []float64{100}},
+		{"zero total", []float64{0, 0}, []float64{0, 0}},
+		{"empty", []float64{}, []float64{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := percentagesOf(tc.amounts); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
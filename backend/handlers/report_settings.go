@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// reportOptional returns the optional filter a report uses: the requested
+// one, or else the user's default. Reports leave optional transactions out
+// when the user has no default stored.
+func reportOptional(userID string, optional *bool) *bool {
+	if optional != nil {
+		return optional
+	}
+	var include sql.NullBool
+	err := database.DB.QueryRow("SELECT reports_include_optional FROM users WHERE id = ?", userID).Scan(&include)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error getting report settings for user %s: %v", userID, err)
+		}
+		return nil
+	}
+	if !include.Valid {
+		return nil
+	}
+	return &include.Bool
+}
+
+// GetReportSettings returns the caller's report defaults
+func GetReportSettings(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	var settings models.ReportSettings
+	if optional := reportOptional(userID, nil); optional != nil {
+		settings.IncludeOptional = *optional
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+// UpdateReportSettings replaces the caller's report defaults
+func UpdateReportSettings(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	var settings models.ReportSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	result, err := database.DB.Exec("UPDATE users SET reports_include_optional = ? WHERE id = ?", settings.IncludeOptional, userID)
+	if err != nil {
+		log.Printf("Error updating report settings for user %s: %v", userID, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+// splitsTotal runs the splits report for a filter and sums the category totals
+func splitsTotal(t *testing.T, filter models.ReportFilter) float64 {
+	body, _ := json.Marshal(filter)
+	bodyString := string(body)
+	req := MockAuthContext(TestRequest("POST", "/reports/ynab-splits", &bodyString), testUserID)
+	w := httptest.NewRecorder()
+	GetYNABSplits(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var totals []models.CategoryTotal
+	if err := json.NewDecoder(w.Body).Decode(&totals); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	var sum float64
+	for _, total := range totals {
+		sum += float64(total.Total)
+	}
+	return sum
+}
+
+func TestReportSettingsIncludeOptional(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+	if _, err := database.DB.Exec("ALTER TABLE users ADD COLUMN reports_include_optional BOOLEAN NOT NULL DEFAULT 0"); err != nil {
+		t.Fatalf("Failed to add reports_include_optional column: %v", err)
+	}
+
+	// Optional transactions are left out by default
+	req := MockAuthContext(TestRequest("GET", "/me/report-settings", nil), testUserID)
+	w := httptest.NewRecorder()
+	GetReportSettings(w, req)
+	var settings models.ReportSettings
+	json.NewDecoder(w.Body).Decode(&settings)
+	if w.Code != http.StatusOK || settings.IncludeOptional {
+		t.Fatalf("Expected optional transactions to be excluded by default, got %d %+v", w.Code, settings)
+	}
+	if total := splitsTotal(t, models.ReportFilter{Paid: boolPtr(true)}); total != 635 {
+		t.Errorf("Expected 635 without the optional expense, got %v", total)
+	}
+
+	body := `{"includeOptional": true}`
+	req = MockAuthContext(TestRequest("PUT", "/me/report-settings", &body), testUserID)
+	w = httptest.NewRecorder()
+	UpdateReportSettings(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// The user's default applies when the filter omits optional...
+	if total := splitsTotal(t, models.ReportFilter{Paid: boolPtr(true)}); total != 665 {
+		t.Errorf("Expected 665 with the optional expense, got %v", total)
+	}
+	// ...but not when the filter sets it
+	if total := splitsTotal(t, models.ReportFilter{Paid: boolPtr(true), Optional: boolPtr(false)}); total != 635 {
+		t.Errorf("Expected an explicit optional=false to win, got %v", total)
+	}
+
+	// The other reports honor it too
+	req = MockAuthContext(TestRequest("GET", "/reports/category-percentages?startDate=2023-01-01&endDate=2023-12-31", nil), testUserID)
+	w = httptest.NewRecorder()
+	GetCategoryPercentages(w, req)
+	var percentages models.CategoryPercentages
+	json.NewDecoder(w.Body).Decode(&percentages)
+	if percentages.Total != 665 {
+		t.Errorf("Expected the percentages report to include the optional expense, got a total of %v", percentages.Total)
+	}
+}
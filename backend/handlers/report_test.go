@@ -81,7 +81,10 @@ func setupReportTestDB() {
 			paidDate TEXT,
 			enteredBy TEXT NOT NULL,
 			optional BOOLEAN NOT NULL DEFAULT 0,
-			userId TEXT
+			userId TEXT,
+			updated_at DATETIME,
+			deleted_at DATETIME,
+			refund_of TEXT
 		)
 	`)
 	if err != nil {
@@ -171,6 +174,27 @@ func TestGetYNABSplits(t *testing.T) {
 			expectedTotal: 225.00, // 100 + 50 + 75
 			expectedFirst: "Food",
 		},
+		{
+			name: "Several categories",
+			filter: models.ReportFilter{
+				Categories: []string{"Food", "Fun"},
+				Paid:       boolPtr(true),
+			},
+			expectedCount: 2,
+			expectedTotal: 285.00, // Food 225 + Fun 60
+			expectedFirst: "Food",
+		},
+		{
+			name: "Category and categories combined",
+			filter: models.ReportFilter{
+				Category:   "Housing",
+				Categories: []string{"Fun", "Housing", ""},
+				Paid:       boolPtr(true),
+			},
+			expectedCount: 2,
+			expectedTotal: 410.00, // Housing 350 + Fun 60
+			expectedFirst: "Housing",
+		},
 		{
 			name: "Entered by Patrick",
 			filter: models.ReportFilter{
@@ -259,7 +283,7 @@ func TestGetYNABSplits(t *testing.T) {
 			// Calculate total amount
 			var total float64
 			for _, cat := range response {
-				total += cat.Total
+				total += float64(cat.Total)
 			}
 
 			// Check with a small tolerance for floating point comparisons
@@ -272,6 +296,111 @@ func TestGetYNABSplits(t *testing.T) {
 }
 
 // Helper function to create a bool pointer
+func TestGetYNABSplitsScopedToOwner(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	// A partner whose data the admin test user can read, and a non-admin viewer
+	// who was granted read access to the partner only
+	for _, stmt := range []string{
+		`INSERT INTO users (id, username, name, isAdmin, role) VALUES ('partner', 'partner', 'Partner', 0, 'user')`,
+		`INSERT INTO users (id, username, name, isAdmin, role) VALUES ('viewer', 'viewer', 'Viewer', 0, 'user')`,
+		`INSERT INTO permissions (granted_user_id, owner_user_id, resource_type, permission_type) VALUES ('viewer', 'partner', 'transactions', 'read')`,
+		`INSERT INTO transactions (id, amount, description, date, type, payTo, paid, enteredBy, optional, userId)
+			VALUES ('partner-tx', 42, 'Gym', '2023-02-01', 'Health', 'Partner', 1, 'Partner', 0, 'partner')`,
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to seed data: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name           string
+		caller         string
+		owner          string
+		expectedStatus int
+	}{
+		{"admin scoped to partner", testUserID, "partner", http.StatusOK},
+		{"viewer scoped to granted owner", "viewer", "partner", http.StatusOK},
+		{"viewer scoped to inaccessible owner", "viewer", testUserID, http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/reports/ynab-splits?ownerUserId="+tc.owner, bytes.NewBufferString(`{"paid": true}`))
+			req = MockAuthContext(req, tc.caller)
+			w := httptest.NewRecorder()
+
+			GetYNABSplits(w, req)
+
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response []models.CategoryTotal
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if len(response) != 1 || response[0].Category != "Health" || response[0].Total != 42 {
+				t.Errorf("Expected only the partner's Health total, got %+v", response)
+			}
+		})
+	}
+}
+
+func TestGetYNABSplitsByTag(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	for _, stmt := range []string{
+		`CREATE TABLE transaction_tags (
+			transaction_id TEXT NOT NULL,
+			tag TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (transaction_id, tag)
+		)`,
+		`INSERT INTO transaction_tags (transaction_id, tag) VALUES
+			('tx2', 'vacation'), ('tx6', 'vacation'), ('tx8', 'vacation'), ('tx6', 'weekend'), ('tx1', 'weekend')`,
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to seed tags: %v", err)
+		}
+	}
+
+	// Tags are matched normalized; the unpaid tx8 is still excluded by the paid filter
+	req := httptest.NewRequest("POST", "/reports/ynab-splits", bytes.NewBufferString(`{"paid": true, "tag": " Vacation "}`))
+	req = MockAuthContext(req, testUserID)
+	w := httptest.NewRecorder()
+
+	GetYNABSplits(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status OK, got %d: %s", w.Code, w.Body.String())
+	}
+	var response []models.CategoryTotal
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	expected := []models.CategoryTotal{{Category: "Fun", Total: 60}, {Category: "Food", Total: 50}}
+	if len(response) != len(expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, response)
+	}
+	for i := range expected {
+		if response[i] != expected[i] {
+			t.Errorf("Expected %+v at position %d, got %+v", expected[i], i, response[i])
+		}
+	}
+}
+
 func boolPtr(b bool) *bool {
 	return &b
 }
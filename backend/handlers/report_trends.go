@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+const (
+	// monthLayout is the format of the month parameter of trend reports
+	monthLayout = "2006-01"
+
+	defaultTrendTrailingMonths = 3
+	maxTrendTrailingMonths     = 24
+	defaultTrendThreshold      = 50.0
+)
+
+// monthRange returns the calendar month starting at start as a DateRange
+func monthRange(start time.Time) DateRange {
+	return DateRange{Start: start, End: start.AddDate(0, 1, -1)}
+}
+
+// GetCategoryTrends flags the categories whose spending in a month (?month=,
+// YYYY-MM, default the current month) is more than ?threshold= percent
+// (default 50) above their average over the ?months= months before it
+// (default 3). Totals count the same transactions as the other reports.
+// Categories with no spending in the trailing months have no baseline and
+// are never flagged.
+func GetCategoryTrends(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	ownerUserID, status, err := reportOwnerUserID(r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	query := r.URL.Query()
+
+	now := time.Now()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if param := query.Get("month"); param != "" {
+		month, err = time.Parse(monthLayout, param)
+		if err != nil {
+			http.Error(w, "Invalid month: expected YYYY-MM", http.StatusBadRequest)
+			return
+		}
+	}
+
+	trailingMonths := defaultTrendTrailingMonths
+	if param := query.Get("months"); param != "" {
+		trailingMonths, err = strconv.Atoi(param)
+		if err != nil || trailingMonths < 1 || trailingMonths > maxTrendTrailingMonths {
+			http.Error(w, "Invalid months: expected a number from 1 to "+strconv.Itoa(maxTrendTrailingMonths), http.StatusBadRequest)
+			return
+		}
+	}
+
+	threshold := defaultTrendThreshold
+	if param := query.Get("threshold"); param != "" {
+		threshold, err = strconv.ParseFloat(param, 64)
+		if err != nil || threshold < 0 {
+			http.Error(w, "Invalid threshold: expected a non-negative percentage", http.StatusBadRequest)
+			return
+		}
+	}
+
+	column := reportGroupColumns["category"]
+
+	current, err := groupTotals(userID, ownerUserID, column, monthRange(month), nil, nil)
+	if err != nil {
+		log.Printf("Error computing category totals for %s: %v", month.Format(monthLayout), err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	trailing := map[string]float64{}
+	for i := 1; i <= trailingMonths; i++ {
+		start := month.AddDate(0, -i, 0)
+		totals, err := groupTotals(userID, ownerUserID, column, monthRange(start), nil, nil)
+		if err != nil {
+			log.Printf("Error computing category totals for %s: %v", start.Format(monthLayout), err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for category, total := range totals {
+			trailing[category] += total
+		}
+	}
+
+	result := models.CategoryTrendReport{
+		Month:          month.Format(monthLayout),
+		TrailingMonths: trailingMonths,
+		Threshold:      threshold,
+		Flagged:        []models.CategoryTrend{},
+	}
+	for category, total := range current {
+		// Months without spending count as zero towards the average
+		average := trailing[category] / float64(trailingMonths)
+		if average <= 0 || total <= average*(1+threshold/100) {
+			continue
+		}
+		delta := total - average
+		result.Flagged = append(result.Flagged, models.CategoryTrend{
+			Category:        category,
+			CurrentTotal:    models.Amount(total),
+			TrailingAverage: models.Amount(math.Round(average*100) / 100),
+			Delta:           models.Amount(math.Round(delta*100) / 100),
+			PercentChange:   math.Round(delta/average*10000) / 100,
+		})
+	}
+	sort.Slice(result.Flagged, func(i, j int) bool {
+		return result.Flagged[i].Category < result.Flagged[j].Category
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
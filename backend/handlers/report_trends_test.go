@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+func insertTrendTransactions(t *testing.T) {
+	t.Helper()
+
+	// Monthly totals for January to April 2024. Travel spikes in April while
+	// Groceries stays close to its average. Gifts only appears in April.
+	monthly := []struct {
+		category string
+		totals   []float64
+	}{
+		{"Travel", []float64{100, 120, 80, 400}},
+		{"Groceries", []float64{200, 210, 190, 230}},
+		{"Gifts", []float64{0, 0, 0, 50}},
+	}
+	for _, m := range monthly {
+		for i, total := range m.totals {
+			if total == 0 {
+				continue
+			}
+			date := time.Date(2024, time.Month(i+1), 10, 0, 0, 0, 0, time.UTC)
+			_, err := database.DB.Exec(`
+				INSERT INTO transactions (id, amount, description, date, type, payTo, paid, enteredBy, optional, userId)
+				VALUES (?, ?, ?, ?, ?, 'Shop', 1, 'Patrick', 0, ?)
+			`, generateID(), total, m.category, date, m.category, testUserID)
+			if err != nil {
+				t.Fatalf("Failed to insert transaction: %v", err)
+			}
+		}
+	}
+}
+
+func TestGetCategoryTrends(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+	insertTrendTransactions(t)
+
+	req := TestRequest("GET", "/reports/category-trends?month=2024-04", nil)
+	w := httptest.NewRecorder()
+	GetCategoryTrends(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var result models.CategoryTrendReport
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	if result.Month != "2024-04" || result.TrailingMonths != 3 || result.Threshold != 50 {
+		t.Errorf("Unexpected report parameters: %+v", result)
+	}
+	if len(result.Flagged) != 1 {
+		t.Fatalf("Expected only Travel to be flagged, got %+v", result.Flagged)
+	}
+	expected := models.CategoryTrend{Category: "Travel", CurrentTotal: 400, TrailingAverage: 100, Delta: 300, PercentChange: 300}
+	if result.Flagged[0] != expected {
+		t.Errorf("Expected %+v, got %+v", expected, result.Flagged[0])
+	}
+
+	// A low enough threshold flags the steady category too
+	req = TestRequest("GET", "/reports/category-trends?month=2024-04&threshold=10", nil)
+	w = httptest.NewRecorder()
+	GetCategoryTrends(w, req)
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if len(result.Flagged) != 2 || result.Flagged[0].Category != "Groceries" || result.Flagged[1].Category != "Travel" {
+		t.Errorf("Expected Groceries and Travel to be flagged, got %+v", result.Flagged)
+	}
+}
+
+func TestGetCategoryTrendsRejectsInvalidParameters(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	for _, query := range []string{"month=April", "months=0", "months=abc", "threshold=-5"} {
+		req := TestRequest("GET", "/reports/category-trends?"+query, nil)
+		w := httptest.NewRecorder()
+		GetCategoryTrends(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d for %s, got %d", http.StatusBadRequest, query, w.Code)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// unmappedYNABGroupName names the group collecting categories that don't
+// resolve to a YNAB category
+const unmappedYNABGroupName = "Unmapped"
+
+// ynabCategoryGroup finds the YNAB category group a local category name falls
+// in, resolving the name the way syncing splits does. Names that don't
+// resolve, or resolve to a category whose group isn't synced, are unmapped.
+func ynabCategoryGroup(userID, category string) (string, string, error) {
+	categoryID, err := models.ResolveYNABCategoryID(userID, category)
+	if errors.Is(err, models.ErrNoYNABCategoryMatch) || errors.Is(err, models.ErrAmbiguousYNABCategory) {
+		return models.UnmappedYNABCategory, unmappedYNABGroupName, nil
+	} else if err != nil {
+		return "", "", err
+	}
+
+	var groupID, groupName string
+	err = database.DB.QueryRow(`
+		SELECT g.id, g.name
+		FROM ynab_categories c
+		JOIN ynab_category_groups g ON g.id = c.group_id AND g.user_id = c.user_id
+		WHERE c.user_id = ? AND c.id = ?
+	`, userID, categoryID).Scan(&groupID, &groupName)
+	if err == sql.ErrNoRows {
+		return models.UnmappedYNABCategory, unmappedYNABGroupName, nil
+	}
+	return groupID, groupName, err
+}
+
+// GetYNABGroupTotals returns the period's category totals rolled up by YNAB
+// category group, largest group first, with each group's categories.
+// Totals count the same transactions as the other reports, and ?paid= and
+// ?optional= override it.
+func GetYNABGroupTotals(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	ownerUserID, status, err := reportOwnerUserID(r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	query := r.URL.Query()
+	dateRange, err := ParseDateRange(query.Get("startDate"), query.Get("endDate"), query.Get("range"), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	paid, optional, err := parseReportFlags(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	totals, err := groupTotals(userID, ownerUserID, reportGroupColumns["category"], dateRange, paid, optional)
+	if err != nil {
+		log.Printf("Error computing category totals: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	groups := map[string]*models.YNABGroupTotal{}
+	var grandTotal float64
+	for category, total := range totals {
+		groupID, groupName, err := ynabCategoryGroup(userID, category)
+		if err != nil {
+			log.Printf("Error resolving YNAB category group for '%s': %v", category, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		group, ok := groups[groupID]
+		if !ok {
+			group = &models.YNABGroupTotal{GroupID: groupID, GroupName: groupName}
+			groups[groupID] = group
+		}
+		total = math.Round(total*100) / 100
+		group.Total += models.Amount(total)
+		group.Categories = append(group.Categories, models.CategoryTotal{Category: category, Total: models.Amount(total)})
+		grandTotal += total
+	}
+
+	result := models.YNABGroupTotals{
+		StartDate: dateRange.StartString(),
+		EndDate:   dateRange.EndString(),
+		Total:     models.Amount(math.Round(grandTotal*100) / 100),
+		Groups:    []models.YNABGroupTotal{},
+	}
+	for _, group := range groups {
+		group.Total = models.Amount(math.Round(float64(group.Total)*100) / 100)
+		sort.Slice(group.Categories, func(i, j int) bool {
+			if group.Categories[i].Total != group.Categories[j].Total {
+				return group.Categories[i].Total > group.Categories[j].Total
+			}
+			return group.Categories[i].Category < group.Categories[j].Category
+		})
+		result.Groups = append(result.Groups, *group)
+	}
+	sort.Slice(result.Groups, func(i, j int) bool {
+		if result.Groups[i].Total != result.Groups[j].Total {
+			return result.Groups[i].Total > result.Groups[j].Total
+		}
+		return result.Groups[i].GroupName < result.Groups[j].GroupName
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+func TestGetYNABGroupTotals(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	for _, stmt := range []string{
+		`CREATE TABLE ynab_category_groups (
+			id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			last_updated DATETIME,
+			PRIMARY KEY (id, user_id)
+		)`,
+		`CREATE TABLE ynab_categories (
+			id TEXT NOT NULL,
+			group_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			last_updated DATETIME,
+			PRIMARY KEY (id, user_id)
+		)`,
+		`INSERT INTO ynab_category_groups (id, name, user_id) VALUES
+			('group-everyday', 'Everyday', 'test-user-id'),
+			('group-bills', 'Bills', 'test-user-id')`,
+		`INSERT INTO ynab_categories (id, group_id, name, user_id) VALUES
+			('ynab-food', 'group-everyday', 'Food', 'test-user-id'),
+			('ynab-fun', 'group-everyday', 'Fun', 'test-user-id'),
+			('ynab-housing', 'group-bills', 'Housing', 'test-user-id')`,
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to set up YNAB categories: %v", err)
+		}
+	}
+
+	// Including optional transactions brings in Misc, which YNAB doesn't know
+	req := TestRequest("GET", "/reports/ynab-groups?startDate=2023-01-01&endDate=2023-12-31&optional=true", nil)
+	req = MockAuthContext(req, testUserID)
+	w := httptest.NewRecorder()
+	GetYNABGroupTotals(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var result models.YNABGroupTotals
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	if result.Total != 665 {
+		t.Errorf("Expected a grand total of 665, got %v", result.Total)
+	}
+	if len(result.Groups) != 3 {
+		t.Fatalf("Expected 3 groups, got %+v", result.Groups)
+	}
+
+	bills, everyday, unmapped := result.Groups[0], result.Groups[1], result.Groups[2]
+	if bills.GroupID != "group-bills" || bills.GroupName != "Bills" || bills.Total != 350 || len(bills.Categories) != 1 {
+		t.Errorf("Expected Bills to hold Housing's 350, got %+v", bills)
+	}
+	if everyday.GroupID != "group-everyday" || everyday.Total != 285 || len(everyday.Categories) != 2 {
+		t.Errorf("Expected Everyday to total 285 over 2 categories, got %+v", everyday)
+	} else if everyday.Categories[0].Category != "Food" || everyday.Categories[0].Total != 225 ||
+		everyday.Categories[1].Category != "Fun" || everyday.Categories[1].Total != 60 {
+		t.Errorf("Expected Food 225 and Fun 60 in Everyday, got %+v", everyday.Categories)
+	}
+	if unmapped.GroupID != models.UnmappedYNABCategory || unmapped.Total != 30 ||
+		len(unmapped.Categories) != 1 || unmapped.Categories[0].Category != "Misc" {
+		t.Errorf("Expected Misc to be unmapped, got %+v", unmapped)
+	}
+}
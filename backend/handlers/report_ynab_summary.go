@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// GetYNABSplitsSummary previews a YNAB splits sync. It takes the same filter
+// as GetYNABSplits and returns each category's total with the milliunits that
+// syncing it would send, plus the grand totals, so the UI can show exactly
+// what will reach YNAB.
+func GetYNABSplitsSummary(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	var request models.ReportFilter
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	totals, status, err := ynabSplitTotals(r, userID, request)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	summary := models.YNABSplitSummary{Categories: []models.YNABSplitSummaryLine{}}
+	var total float64
+	for _, ct := range totals {
+		amount := float64(ct.Total)
+		milliunits := models.DollarsToMilliunits(amount)
+		summary.Categories = append(summary.Categories, models.YNABSplitSummaryLine{
+			Category:   ct.Category,
+			Total:      ct.Total,
+			Milliunits: milliunits,
+		})
+		total += amount
+		summary.TotalMilliunits += milliunits
+	}
+	summary.Total = models.Amount(math.Round(total*100) / 100)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+func getYNABSplitsSummary(t *testing.T, filter models.ReportFilter, expectedStatus int) models.YNABSplitSummary {
+	t.Helper()
+
+	body, _ := json.Marshal(filter)
+	bodyStr := string(body)
+	req := TestRequest("POST", "/reports/ynab-splits/summary", &bodyStr)
+	w := httptest.NewRecorder()
+	GetYNABSplitsSummary(w, req)
+	if w.Code != expectedStatus {
+		t.Fatalf("Expected status code %d, got %d: %s", expectedStatus, w.Code, w.Body.String())
+	}
+
+	var summary models.YNABSplitSummary
+	if expectedStatus == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&summary); err != nil {
+			t.Fatalf("Error decoding response: %v", err)
+		}
+	}
+	return summary
+}
+
+func TestGetYNABSplitsSummary(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	// Amounts that don't convert exactly in floating point
+	april, _ := time.Parse("2006-01-02", "2023-04-10")
+	for _, tx := range []struct {
+		id     string
+		amount float64
+		txType string
+	}{
+		{"cents-1", 19.99, "Gifts"},
+		{"cents-2", 0.07, "Gifts"},
+		{"cents-3", 4.35, "Fees"},
+	} {
+		_, err := database.DB.Exec(`
+			INSERT INTO transactions (id, amount, description, date, type, payTo, paid, enteredBy, optional, userId)
+			VALUES (?, ?, 'Cents', ?, ?, 'Shop', 1, 'Sarah', 0, ?)
+		`, tx.id, tx.amount, april, tx.txType, testUserID)
+		if err != nil {
+			t.Fatalf("Failed to insert transaction: %v", err)
+		}
+	}
+
+	summary := getYNABSplitsSummary(t, models.ReportFilter{StartDate: "2023-04-01", EndDate: "2023-04-30"}, http.StatusOK)
+	expected := []models.YNABSplitSummaryLine{
+		{Category: "Gifts", Total: 20.06, Milliunits: 20060},
+		{Category: "Fees", Total: 4.35, Milliunits: 4350},
+	}
+	if len(summary.Categories) != len(expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, summary.Categories)
+	}
+	for i, e := range expected {
+		got := summary.Categories[i]
+		if got.Category != e.Category || got.Milliunits != e.Milliunits || models.DollarsToMilliunits(float64(got.Total)) != e.Milliunits {
+			t.Errorf("Expected %+v, got %+v", e, got)
+		}
+	}
+	if summary.Total != 24.41 || summary.TotalMilliunits != 24410 {
+		t.Errorf("Expected a total of 24.41 (24410 milliunits), got %.2f (%d)", summary.Total, summary.TotalMilliunits)
+	}
+
+	// The milliunit totals match the dollar totals of the splits report
+	summary = getYNABSplitsSummary(t, models.ReportFilter{StartDate: "2023-01-01", EndDate: "2023-03-31"}, http.StatusOK)
+	if len(summary.Categories) != 3 {
+		t.Fatalf("Expected 3 categories, got %+v", summary.Categories)
+	}
+	var lineMilliunits int64
+	for _, line := range summary.Categories {
+		if line.Milliunits != models.DollarsToMilliunits(float64(line.Total)) {
+			t.Errorf("Expected %s to convert %.2f, got %d milliunits", line.Category, line.Total, line.Milliunits)
+		}
+		lineMilliunits += line.Milliunits
+	}
+	if summary.Total != 635 || summary.TotalMilliunits != 635000 || lineMilliunits != summary.TotalMilliunits {
+		t.Errorf("Expected a total of 635.00 (635000 milliunits), got %.2f (%d)", summary.Total, summary.TotalMilliunits)
+	}
+
+	getYNABSplitsSummary(t, models.ReportFilter{StartDate: "2023-04-31"}, http.StatusBadRequest)
+}
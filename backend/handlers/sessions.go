@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"bennwallet/backend/middleware"
+)
+
+// LogoutAll signs the user out everywhere: every token issued to them so far
+// is rejected from now on, and clients have to sign in again
+func LogoutAll(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	cutoff, err := middleware.RevokeUserTokens(userID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error revoking tokens for user %s: %v", userID, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Revoked all tokens for user %s issued before %s", userID, cutoff)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tokensValidAfter": cutoff,
+	})
+}
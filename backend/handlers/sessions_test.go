@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bennwallet/backend/database"
+)
+
+func TestLogoutAll(t *testing.T) {
+	SetupTestDB()
+	defer CleanupTestDB()
+
+	if _, err := database.DB.Exec("ALTER TABLE users ADD COLUMN tokens_valid_after TIMESTAMP"); err != nil {
+		t.Fatalf("Failed to add tokens_valid_after column: %v", err)
+	}
+
+	req := TestRequest("POST", "/me/logout-all", nil)
+	w := httptest.NewRecorder()
+	LogoutAll(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var validAfter sql.NullTime
+	database.DB.QueryRow("SELECT tokens_valid_after FROM users WHERE id = ?", TestUserID).Scan(&validAfter)
+	if !validAfter.Valid {
+		t.Error("Expected a token cutoff to be stored")
+	}
+}
+
+func TestLogoutAllUnknownUser(t *testing.T) {
+	SetupTestDB()
+	defer CleanupTestDB()
+
+	if _, err := database.DB.Exec("ALTER TABLE users ADD COLUMN tokens_valid_after TIMESTAMP"); err != nil {
+		t.Fatalf("Failed to add tokens_valid_after column: %v", err)
+	}
+
+	req := MockAuthContext(TestRequest("POST", "/me/logout-all", nil), "nobody")
+	w := httptest.NewRecorder()
+	LogoutAll(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
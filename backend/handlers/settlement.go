@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+const settlementColumns = `id, from_user_id, to_user_id, amount, month, note, created_at, settled_at`
+
+// settlementScanner is satisfied by both *sql.Row and *sql.Rows
+type settlementScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSettlement reads a settlements row selected with settlementColumns
+func scanSettlement(s settlementScanner) (models.Settlement, error) {
+	var settlement models.Settlement
+	var note sql.NullString
+	var settledAt sql.NullTime
+	err := s.Scan(&settlement.ID, &settlement.FromUserID, &settlement.ToUserID, &settlement.Amount, &settlement.Month,
+		&note, &settlement.CreatedAt, &settledAt)
+	if err != nil {
+		return settlement, err
+	}
+	settlement.Note = note.String
+	if settledAt.Valid {
+		settlement.SettledAt = &settledAt.Time
+	}
+	return settlement, nil
+}
+
+// settlementBalanceKey identifies who owes whom for which period
+type settlementBalanceKey struct {
+	from, to, period string
+}
+
+// GetSettlements returns who owes whom: for each month, or ISO week with
+// ?period=week, what the owners of the accessible transactions owe each payee
+// on their unpaid, non-optional transactions, merged with the settlements
+// recorded between them for that period. ?month= (YYYY-MM) or, with weeks,
+// ?week= (YYYY-Www) narrows the listing to one period.
+func GetSettlements(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	period, err := parseSettlementPeriod(r.URL.Query().Get("period"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The period filter is named after the period: ?month= or ?week=
+	var key string
+	var keyRange DateRange
+	if param := r.URL.Query().Get(period); param != "" {
+		keyPeriod, start, err := parseSettlementPeriodKey(param)
+		if err != nil || keyPeriod != period {
+			expected := map[string]string{SettlementPeriodMonth: "YYYY-MM", SettlementPeriodWeek: "YYYY-Www"}[period]
+			http.Error(w, fmt.Sprintf("Invalid %s: expected %s", period, expected), http.StatusBadRequest)
+			return
+		}
+		key = param
+		keyRange = settlementPeriodRange(start, period)
+	}
+
+	balances := map[settlementBalanceKey]*models.SettlementBalance{}
+	balanceOf := func(key settlementBalanceKey) *models.SettlementBalance {
+		if balances[key] == nil {
+			balances[key] = &models.SettlementBalance{FromUserID: key.from, ToUserID: key.to, Month: key.period,
+				Period: period, Settlements: []models.Settlement{}}
+		}
+		return balances[key]
+	}
+
+	// Sum per day, then bucket the days into periods
+	payTo, amount := refundAwareExpressions("payTo")
+	transactionDay := "substr(COALESCE(transaction_date, date), 1, 10)"
+	query := fmt.Sprintf(`
+		SELECT COALESCE(userId, ''), %s, %s, SUM(%s)
+		FROM transactions
+		WHERE deleted_at IS NULL AND paid = 0 AND optional = 0 AND COALESCE(%s, '') != ''
+	`, payTo, transactionDay, amount, payTo)
+	accessClause, args := accessibleTransactionsClause(userID)
+	query += accessClause
+	rangeClause, rangeArgs := keyRange.SQLConditions(transactionDay)
+	query += rangeClause
+	args = append(args, rangeArgs...)
+	query += " GROUP BY 1, 2, 3"
+
+	rows, err := database.ReadDB().Query(query, args...)
+	if err != nil {
+		log.Printf("Error querying outstanding transactions: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var from, to, day string
+		var outstanding float64
+		if err := rows.Scan(&from, &to, &day, &outstanding); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		date, err := time.Parse(dateLayout, day)
+		if err != nil {
+			log.Printf("Skipping outstanding transactions with unreadable date %q: %v", day, err)
+			continue
+		}
+		balanceOf(settlementBalanceKey{from, to, settlementPeriodKey(date, period)}).Outstanding += models.Amount(outstanding)
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	settlements, err := accessibleSettlements(userID, key)
+	if err != nil {
+		log.Printf("Error querying settlements: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, settlement := range settlements {
+		// Settlements recorded for the other kind of period are listed with it
+		if settlementPeriod, _, err := parseSettlementPeriodKey(settlement.Month); err != nil || settlementPeriod != period {
+			continue
+		}
+		balance := balanceOf(settlementBalanceKey{settlement.FromUserID, settlement.ToUserID, settlement.Month})
+		balance.Settlements = append(balance.Settlements, settlement)
+		if settlement.SettledAt != nil {
+			balance.Settled += settlement.Amount
+		} else {
+			balance.Pending += settlement.Amount
+		}
+	}
+
+	result := []models.SettlementBalance{}
+	for _, balance := range balances {
+		balance.Outstanding = models.Amount(math.Round(float64(balance.Outstanding)*100) / 100)
+		balance.Settled = models.Amount(math.Round(float64(balance.Settled)*100) / 100)
+		balance.Pending = models.Amount(math.Round(float64(balance.Pending)*100) / 100)
+		balance.Remaining = models.Amount(math.Round(float64(balance.Outstanding-balance.Settled)*100) / 100)
+		result = append(result, *balance)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Month != result[j].Month {
+			return result[i].Month < result[j].Month
+		}
+		if result[i].FromUserID != result[j].FromUserID {
+			return result[i].FromUserID < result[j].FromUserID
+		}
+		return result[i].ToUserID < result[j].ToUserID
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// accessibleSettlements returns the settlements paid by the users whose
+// transactions the user can read, of one period or every period when empty
+func accessibleSettlements(userID, period string) ([]models.Settlement, error) {
+	accessibleUsers, err := middleware.GetUserAccessibleResources(userID, models.ResourceTransactions, models.PermissionRead)
+	if err != nil || len(accessibleUsers) == 0 {
+		accessibleUsers = []string{userID}
+	}
+	placeholders := make([]string, len(accessibleUsers))
+	args := make([]interface{}, len(accessibleUsers))
+	for i := range accessibleUsers {
+		placeholders[i] = "?"
+		args[i] = accessibleUsers[i]
+	}
+
+	query := "SELECT " + settlementColumns + " FROM settlements WHERE from_user_id IN (" + strings.Join(placeholders, ",") + ")"
+	if period != "" {
+		query += " AND month = ?"
+		args = append(args, period)
+	}
+	rows, err := database.DB.Query(query+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var settlements []models.Settlement
+	for rows.Next() {
+		settlement, err := scanSettlement(rows)
+		if err != nil {
+			return nil, err
+		}
+		settlements = append(settlements, settlement)
+	}
+	return settlements, rows.Err()
+}
+
+// CreateSettlement records a settlement of what fromUserId (default the
+// caller) owes toUserId for a month, or an ISO week given as YYYY-Www.
+// Recording one for another user takes write access to their transactions.
+// It stays outstanding until marked paid.
+func CreateSettlement(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	var settlement models.Settlement
+	if err := json.NewDecoder(r.Body).Decode(&settlement); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	settlement.FromUserID = strings.TrimSpace(settlement.FromUserID)
+	settlement.ToUserID = strings.TrimSpace(settlement.ToUserID)
+	settlement.Note = strings.TrimSpace(settlement.Note)
+	if settlement.FromUserID == "" {
+		settlement.FromUserID = userID
+	}
+	if settlement.ToUserID == "" {
+		http.Error(w, "toUserId is required", http.StatusBadRequest)
+		return
+	}
+	if settlement.Amount <= 0 {
+		http.Error(w, "amount must be positive", http.StatusBadRequest)
+		return
+	}
+	if _, _, err := parseSettlementPeriodKey(settlement.Month); err != nil {
+		http.Error(w, "Invalid month: expected YYYY-MM, or YYYY-Www for a weekly settlement", http.StatusBadRequest)
+		return
+	}
+	if settlement.FromUserID != userID &&
+		!middleware.CheckUserPermission(userID, settlement.FromUserID, models.ResourceTransactions, models.PermissionWrite) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	settlement.CreatedAt = time.Now().UTC()
+	settlement.SettledAt = nil
+	result, err := database.DB.Exec(`
+		INSERT INTO settlements (from_user_id, to_user_id, amount, month, note, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, settlement.FromUserID, settlement.ToUserID, settlement.Amount, settlement.Month, settlement.Note, settlement.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating settlement: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	settlement.ID, _ = result.LastInsertId()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(settlement)
+}
+
+// MarkSettlementPaid marks a settlement paid now, which takes write access to
+// the payer's transactions. A settlement is only paid once.
+func MarkSettlementPaid(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	settlement, err := scanSettlement(database.DB.QueryRow("SELECT "+settlementColumns+" FROM settlements WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		http.Error(w, "Settlement not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error getting settlement %s: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if settlement.FromUserID != userID &&
+		!middleware.CheckUserPermission(userID, settlement.FromUserID, models.ResourceTransactions, models.PermissionWrite) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if settlement.SettledAt != nil {
+		http.Error(w, "Settlement is already paid", http.StatusConflict)
+		return
+	}
+
+	// Only the first of two concurrent requests marks it paid
+	settledAt := time.Now().UTC()
+	result, err := database.DB.Exec("UPDATE settlements SET settled_at = ? WHERE id = ? AND settled_at IS NULL", settledAt, settlement.ID)
+	if err != nil {
+		log.Printf("Error marking settlement %s paid: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		http.Error(w, "Settlement is already paid", http.StatusConflict)
+		return
+	}
+	settlement.SettledAt = &settledAt
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settlement)
+}
+
+// GetSinceLastSettlement returns what the accessible transactions with a
+// counterpart (?withUser=, their payTo) come to since the caller last paid a
+// settlement to them, rather than over a fixed month. Transactions
+// dated at or before the settlement are left out; refunds count negatively.
+func GetSinceLastSettlement(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	balance := models.SinceLastSettlement{WithUser: strings.TrimSpace(r.URL.Query().Get("withUser"))}
+	if balance.WithUser == "" {
+		http.Error(w, "withUser is required", http.StatusBadRequest)
+		return
+	}
+
+	var since time.Time
+	err := database.DB.QueryRow(`
+		SELECT settled_at FROM settlements
+		WHERE from_user_id = ? AND to_user_id = ? AND settled_at IS NOT NULL
+		ORDER BY julianday(settled_at) DESC LIMIT 1
+	`, userID, balance.WithUser).Scan(&since)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Error getting last settlement with %s: %v", balance.WithUser, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	counterpart, amount := refundAwareExpressions("payTo")
+	query := fmt.Sprintf(`
+		SELECT COALESCE(SUM(%s), 0), COALESCE(SUM(CASE WHEN paid THEN 0 ELSE %s END), 0), COUNT(*)
+		FROM transactions
+		WHERE deleted_at IS NULL AND %s = ?
+	`, amount, amount, counterpart)
+	args := []interface{}{balance.WithUser}
+	if err == nil {
+		balance.Since = &since
+		// Compare instants: dates and settled_at are stored in different formats
+		query += " AND julianday(COALESCE(transaction_date, date)) > julianday(?)"
+		args = append(args, since)
+	}
+	accessClause, accessArgs := accessibleTransactionsClause(userID)
+	query += accessClause
+	args = append(args, accessArgs...)
+
+	err = database.ReadDB().QueryRow(query, args...).Scan(&balance.Net, &balance.Outstanding, &balance.Transactions)
+	if err != nil {
+		log.Printf("Error computing balance since last settlement with %s: %v", balance.WithUser, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	balance.Net = models.Amount(math.Round(float64(balance.Net)*100) / 100)
+	balance.Outstanding = models.Amount(math.Round(float64(balance.Outstanding)*100) / 100)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balance)
+}
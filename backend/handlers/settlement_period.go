@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Periods settlements can be grouped by
+const (
+	SettlementPeriodMonth = "month"
+	SettlementPeriodWeek  = "week"
+)
+
+// parseSettlementPeriod validates a ?period= value, defaulting to month
+func parseSettlementPeriod(value string) (string, error) {
+	switch period := strings.TrimSpace(value); period {
+	case "", SettlementPeriodMonth:
+		return SettlementPeriodMonth, nil
+	case SettlementPeriodWeek:
+		return SettlementPeriodWeek, nil
+	default:
+		return "", fmt.Errorf("invalid period %q (expected week or month)", period)
+	}
+}
+
+// settlementPeriodKey returns the period a date is settled in: YYYY-MM for
+// months, or the ISO 8601 week as YYYY-Www. ISO weeks start on Monday and
+// belong to the year holding their Thursday, so the first days of January
+// can fall in the previous year's last week.
+func settlementPeriodKey(date time.Time, period string) string {
+	if period == SettlementPeriodWeek {
+		year, week := date.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	}
+	return date.Format(monthLayout)
+}
+
+// settlementPeriodRange returns the days covered by the period containing date
+func settlementPeriodRange(date time.Time, period string) DateRange {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	if period == SettlementPeriodWeek {
+		// Weekday counts from Sunday; ISO weeks start on Monday
+		offset := (int(day.Weekday()) + 6) % 7
+		start := day.AddDate(0, 0, -offset)
+		return DateRange{Start: start, End: start.AddDate(0, 0, 6)}
+	}
+	return monthRange(time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC))
+}
+
+// parseSettlementPeriodKey validates a key as returned by settlementPeriodKey
+// and returns its period and first day
+func parseSettlementPeriodKey(key string) (string, time.Time, error) {
+	if month, err := time.Parse(monthLayout, key); err == nil {
+		return SettlementPeriodMonth, month, nil
+	}
+	var year, week int
+	if _, err := fmt.Sscanf(key, "%4d-W%2d", &year, &week); err == nil {
+		// January 4th is always in the year's first ISO week
+		jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+		start := settlementPeriodRange(jan4, SettlementPeriodWeek).Start.AddDate(0, 0, 7*(week-1))
+		// Rejects week 0, a 53rd week the year doesn't have and padding variants
+		if settlementPeriodKey(start, SettlementPeriodWeek) == key {
+			return SettlementPeriodWeek, start, nil
+		}
+	}
+	return "", time.Time{}, fmt.Errorf("invalid period %q (expected YYYY-MM or YYYY-Www)", key)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSettlementPeriodKeyWeekly(t *testing.T) {
+	tests := []struct {
+		date      string
+		week      string
+		weekStart string
+		weekEnd   string
+		month     string
+	}{
+		{"2024-01-01", "2024-W01", "2024-01-01", "2024-01-07", "2024-01"},
+		{"2024-01-07", "2024-W01", "2024-01-01", "2024-01-07", "2024-01"},
+		{"2024-01-08", "2024-W02", "2024-01-08", "2024-01-14", "2024-01"},
+		// Late December can belong to the next year's first week
+		{"2024-12-30", "2025-W01", "2024-12-30", "2025-01-05", "2024-12"},
+		// and early January to the previous year's last week
+		{"2021-01-03", "2020-W53", "2020-12-28", "2021-01-03", "2021-01"},
+		{"2023-01-01", "2022-W52", "2022-12-26", "2023-01-01", "2023-01"},
+	}
+
+	for _, tt := range tests {
+		date, _ := time.Parse(dateLayout, tt.date)
+
+		if key := settlementPeriodKey(date, SettlementPeriodWeek); key != tt.week {
+			t.Errorf("Expected %s to be in week %s, got %s", tt.date, tt.week, key)
+		}
+		weekRange := settlementPeriodRange(date, SettlementPeriodWeek)
+		if weekRange.StartString() != tt.weekStart || weekRange.EndString() != tt.weekEnd {
+			t.Errorf("Expected the week of %s to run %s to %s, got %s to %s",
+				tt.date, tt.weekStart, tt.weekEnd, weekRange.StartString(), weekRange.EndString())
+		}
+		if key := settlementPeriodKey(date, SettlementPeriodMonth); key != tt.month {
+			t.Errorf("Expected %s to be in month %s, got %s", tt.date, tt.month, key)
+		}
+	}
+}
+
+func TestParseSettlementPeriod(t *testing.T) {
+	for value, expected := range map[string]string{"": SettlementPeriodMonth, "month": SettlementPeriodMonth, "week": SettlementPeriodWeek} {
+		period, err := parseSettlementPeriod(value)
+		if err != nil || period != expected {
+			t.Errorf("Expected %q to parse as %s, got %s (%v)", value, expected, period, err)
+		}
+	}
+	if _, err := parseSettlementPeriod("fortnight"); err == nil {
+		t.Error("Expected an error for an unknown period")
+	}
+}
+
+func TestParseSettlementPeriodKey(t *testing.T) {
+	tests := []struct {
+		key    string
+		period string
+		start  string
+	}{
+		{"2024-03", SettlementPeriodMonth, "2024-03-01"},
+		{"2024-W01", SettlementPeriodWeek, "2024-01-01"},
+		{"2025-W01", SettlementPeriodWeek, "2024-12-30"},
+		{"2020-W53", SettlementPeriodWeek, "2020-12-28"},
+	}
+	for _, tt := range tests {
+		period, start, err := parseSettlementPeriodKey(tt.key)
+		if err != nil || period != tt.period || start.Format(dateLayout) != tt.start {
+			t.Errorf("Expected %s to be the %s starting %s, got %s starting %s (%v)",
+				tt.key, tt.period, tt.start, period, start.Format(dateLayout), err)
+		}
+	}
+
+	for _, key := range []string{"", "March", "2024-W00", "2024-W53", "2024-W1", "2024-W01x", "2024-13"} {
+		if _, _, err := parseSettlementPeriodKey(key); err == nil {
+			t.Errorf("Expected an error for %q", key)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// GetSettlementSnapshot returns the caller's settlement of a month (?month=,
+// YYYY-MM, default the current month) from the settlement_snapshots cache.
+// Changing a transaction drops its month's snapshots, so a missing snapshot
+// is computed and cached on the way.
+func GetSettlementSnapshot(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	month, err := settlementMonth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	snapshot, err := cachedSettlementSnapshot(userID, month)
+	if err == sql.ErrNoRows {
+		snapshot, err = refreshSettlementSnapshot(userID, month)
+	}
+	if err != nil {
+		log.Printf("Error getting settlement snapshot of %s for user %s: %v", month.Format(monthLayout), userID, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// RefreshSettlementSnapshot recomputes and caches the caller's settlement of
+// a month (?month=, YYYY-MM, default the current month). Snapshots don't
+// follow permission changes on their own, so this picks those up.
+func RefreshSettlementSnapshot(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	month, err := settlementMonth(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	snapshot, err := refreshSettlementSnapshot(userID, month)
+	if err != nil {
+		log.Printf("Error refreshing settlement snapshot of %s for user %s: %v", month.Format(monthLayout), userID, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// settlementMonth reads the ?month= of a settlement request
+func settlementMonth(r *http.Request) (time.Time, error) {
+	param := r.URL.Query().Get("month")
+	if param == "" {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	month, err := time.Parse(monthLayout, param)
+	if err != nil {
+		return month, errors.New("Invalid month: expected YYYY-MM")
+	}
+	return month, nil
+}
+
+// cachedSettlementSnapshot returns the user's snapshot of a month, or
+// sql.ErrNoRows when there is none
+func cachedSettlementSnapshot(userID string, month time.Time) (models.SettlementSnapshot, error) {
+	snapshot := models.SettlementSnapshot{Month: settlementPeriodKey(month, SettlementPeriodMonth)}
+	var figures string
+	err := database.DB.QueryRow("SELECT figures, computed_at FROM settlement_snapshots WHERE user_id = ? AND month = ?",
+		userID, snapshot.Month).Scan(&figures, &snapshot.ComputedAt)
+	if err != nil {
+		return snapshot, err
+	}
+	err = json.Unmarshal([]byte(figures), &snapshot.Counterparts)
+	return snapshot, err
+}
+
+// refreshSettlementSnapshot computes the user's settlement of a month over
+// the transactions they can read and stores it as their snapshot. Computing
+// and storing share a database transaction, so a change to the month can't
+// slip in between and be missed.
+func refreshSettlementSnapshot(userID string, month time.Time) (models.SettlementSnapshot, error) {
+	snapshot := models.SettlementSnapshot{
+		Month:        settlementPeriodKey(month, SettlementPeriodMonth),
+		ComputedAt:   time.Now().UTC(),
+		Counterparts: []models.SettlementFigure{},
+	}
+
+	accessClause, args := accessibleTransactionsClause(userID)
+	args = append([]interface{}{snapshot.Month}, args...)
+	counterpart, amount := refundAwareExpressions("payTo")
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return snapshot, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(fmt.Sprintf(`
+		SELECT COALESCE(%s, ''), SUM(%s), SUM(CASE WHEN paid THEN 0 ELSE %s END), COUNT(*)
+		FROM transactions
+		WHERE deleted_at IS NULL AND substr(COALESCE(transaction_date, date), 1, 7) = ?
+	`, counterpart, amount, amount)+accessClause+fmt.Sprintf(" GROUP BY COALESCE(%s, '') ORDER BY 1", counterpart), args...)
+	if err != nil {
+		return snapshot, err
+	}
+	for rows.Next() {
+		var figure models.SettlementFigure
+		if err := rows.Scan(&figure.Counterpart, &figure.Net, &figure.Outstanding, &figure.Transactions); err != nil {
+			rows.Close()
+			return snapshot, err
+		}
+		figure.Net = models.Amount(math.Round(float64(figure.Net)*100) / 100)
+		figure.Outstanding = models.Amount(math.Round(float64(figure.Outstanding)*100) / 100)
+		snapshot.Counterparts = append(snapshot.Counterparts, figure)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return snapshot, err
+	}
+
+	figures, err := json.Marshal(snapshot.Counterparts)
+	if err != nil {
+		return snapshot, err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO settlement_snapshots (user_id, month, figures, computed_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(user_id, month) DO UPDATE SET figures = excluded.figures, computed_at = excluded.computed_at
+	`, userID, snapshot.Month, string(figures), snapshot.ComputedAt)
+	if err != nil {
+		return snapshot, err
+	}
+
+	return snapshot, tx.Commit()
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/migrations"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+func getSettlementSnapshot(t *testing.T, method, url string) models.SettlementSnapshot {
+	req := TestRequest(method, url, nil)
+	w := httptest.NewRecorder()
+	if method == "POST" {
+		RefreshSettlementSnapshot(w, req)
+	} else {
+		GetSettlementSnapshot(w, req)
+	}
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var snapshot models.SettlementSnapshot
+	if err := json.NewDecoder(w.Body).Decode(&snapshot); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	return snapshot
+}
+
+func cachedSettlementMonths(t *testing.T) map[string]bool {
+	rows, err := database.DB.Query("SELECT month FROM settlement_snapshots WHERE user_id = ?", TestUserID)
+	if err != nil {
+		t.Fatalf("Failed to query snapshots: %v", err)
+	}
+	defer rows.Close()
+
+	months := map[string]bool{}
+	for rows.Next() {
+		var month string
+		rows.Scan(&month)
+		months[month] = true
+	}
+	return months
+}
+
+func TestSettlementSnapshots(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+	if err := migrations.AddSettlementSnapshotsTable(database.DB); err != nil {
+		t.Fatalf("Failed to create settlement_snapshots table: %v", err)
+	}
+
+	march := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	insertTestTransaction(t, "tx-sarah", 40, march, TestUserID)
+	insertTestTransaction(t, "tx-patrick", 10, march.AddDate(0, 0, 15), TestUserID)
+	insertTestTransaction(t, "tx-april", 25, time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC), TestUserID)
+	for _, stmt := range []string{
+		"UPDATE transactions SET payTo = 'Sarah' WHERE id IN ('tx-sarah', 'tx-april')",
+		"UPDATE transactions SET payTo = 'Patrick', paid = 1 WHERE id = 'tx-patrick'",
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to update test data: %v", err)
+		}
+	}
+
+	snapshot := getSettlementSnapshot(t, "GET", "/settlements/snapshot?month=2024-03")
+	expected := []models.SettlementFigure{
+		{Counterpart: "Patrick", Net: 10, Outstanding: 0, Transactions: 1},
+		{Counterpart: "Sarah", Net: 40, Outstanding: 40, Transactions: 1},
+	}
+	if len(snapshot.Counterparts) != len(expected) || snapshot.Counterparts[0] != expected[0] || snapshot.Counterparts[1] != expected[1] {
+		t.Fatalf("Expected %+v, got %+v", expected, snapshot.Counterparts)
+	}
+	getSettlementSnapshot(t, "GET", "/settlements/snapshot?month=2024-04")
+	if months := cachedSettlementMonths(t); !months["2024-03"] || !months["2024-04"] {
+		t.Fatalf("Expected both months to be cached, got %v", months)
+	}
+
+	// Editing a March transaction drops only March's snapshot
+	body := `{"amount": 55, "description": "Test", "type": "Groceries", "payTo": "Sarah", "date": "` +
+		march.Format(time.RFC3339) + `", "transactionDate": "` + march.Format(time.RFC3339) + `"}`
+	req := TestRequest("PUT", "/transactions/tx-sarah", &body)
+	req = mux.SetURLVars(req, map[string]string{"id": "tx-sarah"})
+	w := httptest.NewRecorder()
+	UpdateTransaction(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d updating, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if months := cachedSettlementMonths(t); months["2024-03"] || !months["2024-04"] {
+		t.Errorf("Expected only March's snapshot to be dropped, got %v", months)
+	}
+
+	// A refresh recomputes and caches it again
+	snapshot = getSettlementSnapshot(t, "POST", "/settlements/refresh?month=2024-03")
+	if len(snapshot.Counterparts) != 2 || snapshot.Counterparts[1].Net != 55 || snapshot.Counterparts[1].Outstanding != 55 {
+		t.Errorf("Expected Sarah's figure to be recomputed as 55, got %+v", snapshot.Counterparts)
+	}
+	cached, err := cachedSettlementSnapshot(TestUserID, march)
+	if err != nil || len(cached.Counterparts) != 2 || cached.Counterparts[1].Net != 55 {
+		t.Errorf("Expected the refreshed snapshot to be cached, got %+v (%v)", cached, err)
+	}
+
+	// Moving a transaction to another month drops both months
+	if _, err := database.DB.Exec("UPDATE transactions SET date = ?, transaction_date = ? WHERE id = 'tx-april'", march, march); err != nil {
+		t.Fatalf("Failed to move transaction: %v", err)
+	}
+	if months := cachedSettlementMonths(t); len(months) != 0 {
+		t.Errorf("Expected the snapshots of both months to be dropped, got %v", months)
+	}
+}
+
+func TestSettlementSnapshotInvalidMonth(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	req := TestRequest("GET", "/settlements/snapshot?month=March", nil)
+	w := httptest.NewRecorder()
+	GetSettlementSnapshot(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
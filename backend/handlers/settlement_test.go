@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/migrations"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+func getSinceLastSettlement(t *testing.T, withUser string) models.SinceLastSettlement {
+	req := TestRequest("GET", "/reports/since-last-settlement?withUser="+withUser, nil)
+	w := httptest.NewRecorder()
+	GetSinceLastSettlement(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var balance models.SinceLastSettlement
+	if err := json.NewDecoder(w.Body).Decode(&balance); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	return balance
+}
+
+func setupSettlementTestDB(t *testing.T) {
+	setupTransactionTestDB()
+	for _, migrate := range []func(*sql.DB) error{migrations.AddSettlementsTable, migrations.ExtendSettlementsTable} {
+		if err := migrate(database.DB); err != nil {
+			t.Fatalf("Failed to create settlements table: %v", err)
+		}
+	}
+}
+
+func TestGetSinceLastSettlement(t *testing.T) {
+	setupSettlementTestDB(t)
+	defer CleanupTestDB()
+
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	insertTestTransaction(t, "tx-before", 100, day(1), TestUserID)
+	insertTestTransaction(t, "tx-settled-day", 20, day(10), TestUserID)
+	insertTestTransaction(t, "tx-after", 30, day(12), TestUserID)
+	insertTestTransaction(t, "tx-after-paid", 15, day(14), TestUserID)
+	insertTestTransaction(t, "tx-someone-else", 70, day(14), TestUserID)
+	for _, stmt := range []string{
+		"UPDATE transactions SET payTo = 'Sarah' WHERE id != 'tx-someone-else'",
+		"UPDATE transactions SET payTo = 'Patrick' WHERE id = 'tx-someone-else'",
+		"UPDATE transactions SET paid = 1 WHERE id = 'tx-after-paid'",
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to update test data: %v", err)
+		}
+	}
+
+	// Without a settlement every transaction with Sarah counts
+	balance := getSinceLastSettlement(t, "Sarah")
+	if balance.Since != nil || balance.Net != 165 || balance.Transactions != 4 {
+		t.Errorf("Expected all 4 transactions totalling 165, got %+v", balance)
+	}
+
+	// Settle up twice; the latest paid settlement starts the window, while
+	// an unpaid one doesn't count
+	_, err := database.DB.Exec(`
+		INSERT INTO settlements (from_user_id, to_user_id, amount, month, created_at, settled_at) VALUES
+			(?, 'Sarah', 100, '2024-03', ?, ?),
+			(?, 'Sarah', 20, '2024-03', ?, ?),
+			(?, 'Sarah', 30, '2024-03', ?, NULL)
+	`, TestUserID, day(5), day(5), TestUserID, day(10), day(10).Add(18*time.Hour), TestUserID, day(13))
+	if err != nil {
+		t.Fatalf("Failed to insert settlements: %v", err)
+	}
+
+	balance = getSinceLastSettlement(t, "Sarah")
+	if balance.Since == nil || !balance.Since.Equal(day(10).Add(18*time.Hour)) {
+		t.Errorf("Expected the window to start at the last settlement, got %v", balance.Since)
+	}
+	if balance.Net != 45 || balance.Outstanding != 30 || balance.Transactions != 2 {
+		t.Errorf("Expected the 2 transactions after the settlement totalling 45 with 30 outstanding, got %+v", balance)
+	}
+
+	// Settling with Sarah doesn't touch the balance with Patrick
+	if balance := getSinceLastSettlement(t, "Patrick"); balance.Since != nil || balance.Net != 70 {
+		t.Errorf("Expected Patrick's balance to be unaffected, got %+v", balance)
+	}
+}
+
+func TestGetSinceLastSettlementComparesInstants(t *testing.T) {
+	setupSettlementTestDB(t)
+	defer CleanupTestDB()
+
+	// Dates written as RFC 3339 text sort after the driver's "2024-03-10 18:00"
+	// as strings even when they're earlier in the day
+	settledAt := time.Date(2024, 3, 10, 18, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		id, date string
+		amount   float64
+	}{
+		{"tx-morning", "2024-03-10T09:00:00Z", 40},
+		{"tx-evening", "2024-03-10T20:00:00Z", 25},
+		{"tx-day", "2024-03-10", 10},
+	} {
+		_, err := database.DB.Exec(`
+			INSERT INTO transactions (id, amount, description, date, transaction_date, type, payTo, enteredBy, userId)
+			VALUES (?, ?, 'Test', ?, ?, 'Groceries', 'Sarah', 'test-user', ?)
+		`, tc.id, tc.amount, tc.date, tc.date, TestUserID)
+		if err != nil {
+			t.Fatalf("Failed to insert transaction: %v", err)
+		}
+	}
+	_, err := database.DB.Exec(`
+		INSERT INTO settlements (from_user_id, to_user_id, amount, month, created_at, settled_at)
+		VALUES (?, 'Sarah', 75, '2024-03', ?, ?)
+	`, TestUserID, settledAt, settledAt)
+	if err != nil {
+		t.Fatalf("Failed to insert settlement: %v", err)
+	}
+
+	balance := getSinceLastSettlement(t, "Sarah")
+	if balance.Net != 25 || balance.Transactions != 1 {
+		t.Errorf("Expected only the transaction after the settlement, got %+v", balance)
+	}
+}
+
+func TestGetSinceLastSettlementRequiresUser(t *testing.T) {
+	setupSettlementTestDB(t)
+	defer CleanupTestDB()
+
+	req := TestRequest("GET", "/reports/since-last-settlement", nil)
+	w := httptest.NewRecorder()
+	GetSinceLastSettlement(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func createSettlement(t *testing.T, body string) *httptest.ResponseRecorder {
+	req := TestRequest("POST", "/settlements", &body)
+	w := httptest.NewRecorder()
+	CreateSettlement(w, req)
+	return w
+}
+
+func markSettlementPaid(id string) *httptest.ResponseRecorder {
+	req := TestRequest("POST", "/settlements/"+id+"/paid", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+	MarkSettlementPaid(w, req)
+	return w
+}
+
+func getSettlements(t *testing.T, url string) []models.SettlementBalance {
+	req := TestRequest("GET", url, nil)
+	w := httptest.NewRecorder()
+	GetSettlements(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var balances []models.SettlementBalance
+	if err := json.NewDecoder(w.Body).Decode(&balances); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	return balances
+}
+
+func TestSettlements(t *testing.T) {
+	setupSettlementTestDB(t)
+	defer CleanupTestDB()
+
+	march := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	april := time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC)
+	insertTestTransaction(t, "tx-rent", 500, march, TestUserID)
+	insertTestTransaction(t, "tx-power", 80.5, march, TestUserID)
+	insertTestTransaction(t, "tx-paid", 40, march, TestUserID)
+	insertTestTransaction(t, "tx-optional", 25, march, TestUserID)
+	insertTestTransaction(t, "tx-no-payee", 10, march, TestUserID)
+	insertTestTransaction(t, "tx-april", 60, april, TestUserID)
+	for _, stmt := range []string{
+		"UPDATE transactions SET payTo = 'Sarah' WHERE id != 'tx-no-payee'",
+		"UPDATE transactions SET paid = 1 WHERE id = 'tx-paid'",
+		"UPDATE transactions SET optional = 1 WHERE id = 'tx-optional'",
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to update test data: %v", err)
+		}
+	}
+
+	// Only unpaid, non-optional transactions with a payee are owed
+	balances := getSettlements(t, "/settlements?month=2024-03")
+	if len(balances) != 1 || balances[0].ToUserID != "Sarah" || balances[0].FromUserID != TestUserID ||
+		balances[0].Outstanding != 580.5 || balances[0].Remaining != 580.5 || len(balances[0].Settlements) != 0 {
+		t.Fatalf("Expected 580.5 owed to Sarah for March, got %+v", balances)
+	}
+
+	w := createSettlement(t, `{"toUserId": "Sarah", "amount": 500, "month": "2024-03", "note": "Rent"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var created models.Settlement
+	json.NewDecoder(w.Body).Decode(&created)
+	if created.FromUserID != TestUserID || created.SettledAt != nil {
+		t.Errorf("Expected an outstanding settlement from the caller, got %+v", created)
+	}
+
+	balances = getSettlements(t, "/settlements?month=2024-03")
+	if len(balances) != 1 || balances[0].Pending != 500 || balances[0].Settled != 0 || balances[0].Remaining != 580.5 {
+		t.Errorf("Expected the settlement to be pending, got %+v", balances)
+	}
+
+	id := strconv.FormatInt(created.ID, 10)
+	if w := markSettlementPaid(id); w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if w := markSettlementPaid(id); w.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d paying twice, got %d", http.StatusConflict, w.Code)
+	}
+	if w := markSettlementPaid("999"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d for a missing settlement, got %d", http.StatusNotFound, w.Code)
+	}
+
+	// Without a month every month is listed, settled or not
+	balances = getSettlements(t, "/settlements")
+	if len(balances) != 2 || balances[0].Month != "2024-03" || balances[1].Month != "2024-04" {
+		t.Fatalf("Expected March and April, got %+v", balances)
+	}
+	if march := balances[0]; march.Settled != 500 || march.Pending != 0 || march.Remaining != 80.5 ||
+		len(march.Settlements) != 1 || march.Settlements[0].SettledAt == nil || march.Settlements[0].Note != "Rent" {
+		t.Errorf("Expected 80.5 left for March after paying 500, got %+v", march)
+	}
+	if balances[1].Outstanding != 60 {
+		t.Errorf("Expected 60 owed for April, got %+v", balances[1])
+	}
+}
+
+func TestSettlementsWeekly(t *testing.T) {
+	setupSettlementTestDB(t)
+	defer CleanupTestDB()
+
+	day := func(date string) time.Time {
+		parsed, _ := time.Parse(dateLayout, date)
+		return parsed
+	}
+	// 2024-12-29 is a Sunday, closing 2024-W52; the next Monday starts
+	// 2025-W01, which runs into January
+	insertTestTransaction(t, "tx-sunday", 40, day("2024-12-29"), TestUserID)
+	insertTestTransaction(t, "tx-monday", 100, day("2024-12-30"), TestUserID)
+	insertTestTransaction(t, "tx-january", 25.5, day("2025-01-05"), TestUserID)
+	insertTestTransaction(t, "tx-next-week", 10, day("2025-01-06"), TestUserID)
+	if _, err := database.DB.Exec("UPDATE transactions SET payTo = 'Sarah'"); err != nil {
+		t.Fatalf("Failed to update test data: %v", err)
+	}
+
+	w := createSettlement(t, `{"toUserId": "Sarah", "amount": 100, "month": "2025-W01"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	balances := getSettlements(t, "/settlements?period=week")
+	expected := []struct {
+		week        string
+		outstanding models.Amount
+		pending     models.Amount
+	}{
+		{"2024-W52", 40, 0},
+		{"2025-W01", 125.5, 100},
+		{"2025-W02", 10, 0},
+	}
+	if len(balances) != len(expected) {
+		t.Fatalf("Expected %d weeks, got %+v", len(expected), balances)
+	}
+	for i, e := range expected {
+		if b := balances[i]; b.Month != e.week || b.Period != SettlementPeriodWeek || b.Outstanding != e.outstanding || b.Pending != e.pending {
+			t.Errorf("Expected %s with %v outstanding and %v pending, got %+v", e.week, e.outstanding, e.pending, b)
+		}
+	}
+
+	// One week spanning the new year
+	balances = getSettlements(t, "/settlements?period=week&week=2025-W01")
+	if len(balances) != 1 || balances[0].Outstanding != 125.5 || len(balances[0].Settlements) != 1 {
+		t.Errorf("Expected only 2025-W01, got %+v", balances)
+	}
+
+	// Monthly listings leave the weekly settlement out
+	balances = getSettlements(t, "/settlements")
+	if len(balances) != 2 || balances[0].Month != "2024-12" || balances[0].Outstanding != 140 ||
+		balances[1].Month != "2025-01" || balances[1].Outstanding != 35.5 || balances[1].Pending != 0 {
+		t.Errorf("Expected December and January without settlements, got %+v", balances)
+	}
+
+	for _, url := range []string{"/settlements?period=fortnight", "/settlements?period=week&week=2025-01", "/settlements?month=2025-W01"} {
+		req := TestRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+		GetSettlements(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", url, http.StatusBadRequest, w.Code)
+		}
+	}
+}
+
+func TestCreateSettlementValidation(t *testing.T) {
+	setupSettlementTestDB(t)
+	defer CleanupTestDB()
+
+	for _, body := range []string{
+		`{"amount": 10, "month": "2024-03"}`,
+		`{"toUserId": "Sarah", "amount": 0, "month": "2024-03"}`,
+		`{"toUserId": "Sarah", "amount": 10, "month": "March"}`,
+		`{"toUserId": "Sarah", "amount": 10, "month": "2024-W54"}`,
+	} {
+		if w := createSettlement(t, body); w.Code != http.StatusBadRequest {
+			t.Errorf("Body %s: expected status code %d, got %d", body, http.StatusBadRequest, w.Code)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+// GetTransactionTemplates returns the user's transaction templates by name
+func GetTransactionTemplates(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from authentication context
+	userId := middleware.GetUserIDFromContext(r)
+	if userId == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT id, user_id, name, amount, description, type, payTo, enteredBy, optional, created_at
+		FROM transaction_templates
+		WHERE user_id = ?
+		ORDER BY name, id
+	`, userId)
+	if err != nil {
+		log.Printf("Error querying transaction templates: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	templates := []models.TransactionTemplate{}
+	for rows.Next() {
+		tt, err := scanTransactionTemplate(rows)
+		if err != nil {
+			log.Printf("Error scanning transaction template: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		templates = append(templates, tt)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+// AddTransactionTemplate saves a new transaction template. The name defaults
+// to the description.
+func AddTransactionTemplate(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from authentication context
+	userId := middleware.GetUserIDFromContext(r)
+	if userId == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	var tt models.TransactionTemplate
+	if err := json.NewDecoder(r.Body).Decode(&tt); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	tt.Description, err = normalizeDescription(tt.Description)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if tt.Description == "" || tt.Type == "" || tt.EnteredBy == "" {
+		http.Error(w, "description, type and enteredBy are required", http.StatusBadRequest)
+		return
+	}
+	tt.Type, err = normalizeTransactionType(tt.Type)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if tt.Name == "" {
+		tt.Name = tt.Description
+	}
+
+	tt.ID = generateID()
+	tt.UserID = userId
+	tt.CreatedAt = time.Now()
+
+	_, err = database.DB.Exec(`
+		INSERT INTO transaction_templates (id, user_id, name, amount, description, type, payTo, enteredBy, optional, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, tt.ID, tt.UserID, tt.Name, tt.Amount, tt.Description, tt.Type, tt.PayTo, tt.EnteredBy, tt.Optional, tt.CreatedAt)
+	if err != nil {
+		log.Printf("Error inserting transaction template: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tt)
+}
+
+// DeleteTransactionTemplate removes a transaction template. Transactions
+// created from it are kept.
+func DeleteTransactionTemplate(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from authentication context
+	userId := middleware.GetUserIDFromContext(r)
+	if userId == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+
+	result, err := database.DB.Exec("DELETE FROM transaction_templates WHERE id = ? AND user_id = ?", id, userId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		http.Error(w, "Transaction template not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// InstantiateTransactionTemplate creates a transaction from a template, dated
+// today unless the body gives a date. The transaction is independent of the
+// template: later changes to either don't affect the other.
+func InstantiateTransactionTemplate(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from authentication context
+	userId := middleware.GetUserIDFromContext(r)
+	if userId == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+
+	// The body is optional
+	var request models.InstantiateTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	row := database.DB.QueryRow(`
+		SELECT id, user_id, name, amount, description, type, payTo, enteredBy, optional, created_at
+		FROM transaction_templates
+		WHERE id = ? AND user_id = ?
+	`, id, userId)
+	tt, err := scanTransactionTemplate(row)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Transaction template not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error fetching transaction template %s: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	t := models.Transaction{
+		ID:          generateID(),
+		Amount:      models.Amount(tt.Amount),
+		Description: tt.Description,
+		Date:        now,
+		Type:        tt.Type,
+		PayTo:       tt.PayTo,
+		EnteredBy:   tt.EnteredBy,
+		Optional:    tt.Optional,
+		UserID:      userId,
+		Source:      models.TransactionSourceTemplate,
+	}
+	if request.Amount != nil {
+		t.Amount = models.Amount(*request.Amount)
+	}
+	t.TransactionDate = request.Date
+	if t.TransactionDate.IsZero() {
+		t.TransactionDate = now
+	}
+	if err := validateTransactionDates(t, now); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	_, err = database.DB.Exec(`
+		INSERT INTO transactions (id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional, userId, source, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 0, '', ?, ?, ?, ?, ?)
+	`, t.ID, t.Amount, t.Description, t.Date, t.TransactionDate, t.Type, t.PayTo, t.EnteredBy, t.Optional, t.UserID, t.Source, now)
+	if err != nil {
+		log.Printf("Error creating transaction from template %s: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(t)
+}
+
+func scanTransactionTemplate(s recurringScanner) (models.TransactionTemplate, error) {
+	var tt models.TransactionTemplate
+	var payTo sql.NullString
+	var createdAt sql.NullTime
+	err := s.Scan(&tt.ID, &tt.UserID, &tt.Name, &tt.Amount, &tt.Description, &tt.Type, &payTo, &tt.EnteredBy,
+		&tt.Optional, &createdAt)
+	if err != nil {
+		return tt, err
+	}
+	tt.PayTo = payTo.String
+	if createdAt.Valid {
+		tt.CreatedAt = createdAt.Time
+	}
+	return tt, nil
+}
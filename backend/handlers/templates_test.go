@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTemplateTestDB() {
+	setupTransactionTestDB()
+
+	_, err := database.DB.Exec(`
+		CREATE TABLE IF NOT EXISTS transaction_templates (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			amount REAL NOT NULL,
+			description TEXT NOT NULL,
+			type TEXT NOT NULL,
+			payTo TEXT,
+			enteredBy TEXT NOT NULL,
+			optional BOOLEAN NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func instantiateTemplate(t *testing.T, id string, body *string) models.Transaction {
+	req := mux.SetURLVars(TestRequest("POST", "/templates/"+id+"/instantiate", body), map[string]string{"id": id})
+	w := httptest.NewRecorder()
+	InstantiateTransactionTemplate(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var created models.Transaction
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	return created
+}
+
+func TestInstantiateTransactionTemplate(t *testing.T) {
+	setupTemplateTestDB()
+	defer CleanupTestDB()
+
+	body := `{"name": "Coffee run", "amount": 4.5, "description": "Latte", "type": "Dining", "payTo": "Cafe", "enteredBy": "test-user"}`
+	req := TestRequest("POST", "/templates", &body)
+	w := httptest.NewRecorder()
+	AddTransactionTemplate(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var template models.TransactionTemplate
+	json.NewDecoder(w.Body).Decode(&template)
+
+	// Saving a template doesn't create a transaction
+	if count := countTransactions(t); count != 0 {
+		t.Fatalf("Expected no transactions after saving a template, got %d", count)
+	}
+
+	first := instantiateTemplate(t, template.ID, nil)
+	override := `{"amount": 6.25, "date": "2024-05-01T00:00:00Z"}`
+	second := instantiateTemplate(t, template.ID, &override)
+
+	if first.ID == "" || first.ID == template.ID || first.ID == second.ID {
+		t.Errorf("Expected each transaction to get a new ID, got %q and %q from template %q", first.ID, second.ID, template.ID)
+	}
+	if first.Amount != 4.5 || first.Description != "Latte" || first.PayTo != "Cafe" || first.Source != models.TransactionSourceTemplate {
+		t.Errorf("Expected the template's values, got %+v", first)
+	}
+	if second.Amount != 6.25 || second.TransactionDate.Format(dateLayout) != "2024-05-01" {
+		t.Errorf("Expected the overridden amount and date, got %+v", second)
+	}
+	if count := countTransactions(t); count != 2 {
+		t.Errorf("Expected 2 transactions, got %d", count)
+	}
+
+	// The transactions are independent of the template
+	req = mux.SetURLVars(TestRequest("DELETE", "/templates/"+template.ID, nil), map[string]string{"id": template.ID})
+	w = httptest.NewRecorder()
+	DeleteTransactionTemplate(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	var amount float64
+	if err := database.DB.QueryRow("SELECT amount FROM transactions WHERE id = ?", first.ID).Scan(&amount); err != nil || amount != 4.5 {
+		t.Errorf("Expected the transaction to outlive its template, got %v (%v)", amount, err)
+	}
+}
+
+func TestInstantiateTransactionTemplateNotFound(t *testing.T) {
+	setupTemplateTestDB()
+	defer CleanupTestDB()
+
+	_, err := database.DB.Exec(`
+		INSERT INTO transaction_templates (id, user_id, name, amount, description, type, enteredBy)
+		VALUES ('theirs', 'other-user', 'Gym', 30, 'Gym', 'Health', 'other-user')
+	`)
+	if err != nil {
+		t.Fatalf("Failed to insert template: %v", err)
+	}
+
+	// Another user's template can't be instantiated
+	req := mux.SetURLVars(TestRequest("POST", "/templates/theirs/instantiate", nil), map[string]string{"id": "theirs"})
+	w := httptest.NewRecorder()
+	InstantiateTransactionTemplate(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
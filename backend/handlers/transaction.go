@@ -3,10 +3,13 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
-	"math/rand"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -51,23 +54,55 @@ func GetTransactions(w http.ResponseWriter, r *http.Request) {
 		hasUserIdColumn = false
 	}
 
+	// Original currency columns are only selected once they exist
+	hasOriginalAmountColumns := transactionsHaveColumn("original_amount")
+	originalColumns := ""
+	if hasOriginalAmountColumns {
+		originalColumns = ", original_amount, original_currency"
+	}
+	// So is the status column
+	hasStatusColumn := transactionsHaveColumn("status")
+	if hasStatusColumn {
+		originalColumns += ", status"
+	}
+	// And the locked flag
+	hasLockedColumn := transactionsHaveColumn("locked")
+	if hasLockedColumn {
+		originalColumns += ", locked"
+	}
+	// And the refund link
+	hasRefundOfColumn := transactionsHaveColumn("refund_of")
+	if hasRefundOfColumn {
+		originalColumns += ", refund_of"
+	}
+	// Owner names are looked up on request
+	includeOwner, err := parseIncludeOwner(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	includeOwner = includeOwner && hasUserIdColumn
+	if includeOwner {
+		originalColumns += ", " + ownerNameColumn
+	}
+
 	// Base query with the appropriate columns
 	var query string
 	if hasOptionalColumn && hasUserIdColumn {
 		query = `
-			SELECT id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional, userId 
+			SELECT id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional, userId` + originalColumns + `
 			FROM transactions 
 			WHERE 1=1
 		`
 	} else if hasOptionalColumn {
 		query = `
-			SELECT id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional 
+			SELECT id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional` + originalColumns + `
 			FROM transactions 
 			WHERE 1=1
 		`
 	} else {
 		query = `
-			SELECT id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy 
+			SELECT id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy` + originalColumns + `
 			FROM transactions 
 			WHERE 1=1
 		`
@@ -75,6 +110,11 @@ func GetTransactions(w http.ResponseWriter, r *http.Request) {
 
 	args := []interface{}{}
 
+	// Leave out deleted transactions
+	if transactionsHaveColumn("deleted_at") {
+		query += " AND deleted_at IS NULL"
+	}
+
 	// Add user ID filter if the column exists
 	if hasUserIdColumn {
 		// Get list of user IDs the current user can access using the permissions system
@@ -104,8 +144,17 @@ func GetTransactions(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Parse query parameters
-	payTo := r.URL.Query().Get("payTo")
+	// Parse query parameters, or the user's default filter when asked for
+	filters, defaultFilterID, code, err := transactionFilters(r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	if defaultFilterID != "" {
+		w.Header().Set("X-Default-Filter", defaultFilterID)
+	}
+
+	payTo := filters.Get("payTo")
 	if payTo != "" {
 		query += " AND payTo LIKE ?"
 		search := "%" + payTo + "%"
@@ -113,7 +162,7 @@ func GetTransactions(w http.ResponseWriter, r *http.Request) {
 		log.Printf("Added PayTo LIKE filter: '%s' (as %s)", payTo, search)
 	}
 
-	enteredBy := r.URL.Query().Get("enteredBy")
+	enteredBy := filters.Get("enteredBy")
 	if enteredBy != "" {
 		query += " AND enteredBy LIKE ?"
 		search := "%" + enteredBy + "%"
@@ -121,15 +170,100 @@ func GetTransactions(w http.ResponseWriter, r *http.Request) {
 		log.Printf("Added EnteredBy LIKE filter: '%s' (as %s)", enteredBy, search)
 	}
 
-	paid := r.URL.Query().Get("paid")
+	if value := filters.Get("enteredByMe"); value != "" {
+		enteredByMe, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Invalid enteredByMe: expected true or false", http.StatusBadRequest)
+			return
+		}
+
+		identities := enteredByIdentities(userID)
+		placeholders := make([]string, len(identities))
+		for i, identity := range identities {
+			placeholders[i] = "?"
+			args = append(args, identity)
+		}
+		if enteredByMe {
+			query += fmt.Sprintf(" AND enteredBy IN (%s)", strings.Join(placeholders, ","))
+		} else {
+			query += fmt.Sprintf(" AND enteredBy NOT IN (%s)", strings.Join(placeholders, ","))
+		}
+	}
+
+	source := filters.Get("source")
+	if source != "" {
+		if !models.IsValidTransactionSource(source) {
+			http.Error(w, fmt.Sprintf("Invalid source %q (expected manual, import, ynab or recurring)", source), http.StatusBadRequest)
+			return
+		}
+		query += " AND source = ?"
+		args = append(args, source)
+	}
+
+	if status := filters.Get("status"); status != "" {
+		if !models.IsValidTransactionStatus(status) {
+			http.Error(w, fmt.Sprintf("Invalid status %q (expected %s)", status, strings.Join(models.TransactionStatuses, ", ")), http.StatusBadRequest)
+			return
+		}
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+
+	paid := filters.Get("paid")
 	if paid != "" {
 		query += " AND paid = ?"
 		args = append(args, paid == "true")
 	}
 
+	minAmount, hasMinAmount, err := parseAmountParam(filters.Get("minAmount"))
+	if err != nil {
+		http.Error(w, "Invalid minAmount: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	maxAmount, hasMaxAmount, err := parseAmountParam(filters.Get("maxAmount"))
+	if err != nil {
+		http.Error(w, "Invalid maxAmount: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if hasMinAmount && hasMaxAmount && minAmount > maxAmount {
+		http.Error(w, "minAmount cannot be greater than maxAmount", http.StatusBadRequest)
+		return
+	}
+	if hasMinAmount {
+		query += " AND amount >= ?"
+		args = append(args, minAmount)
+	}
+	if hasMaxAmount {
+		query += " AND amount <= ?"
+		args = append(args, maxAmount)
+	}
+
+	dateRange, err := ParseDateRange(filters.Get("startDate"), filters.Get("endDate"), filters.Get("range"), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	dateClause, dateArgs := dateRange.SQLConditions("date")
+	query += dateClause
+	args = append(args, dateArgs...)
+
+	// HEAD requests only get the number of matching transactions
+	if r.Method == http.MethodHead {
+		var total int
+		if err := database.ReadDB().QueryRow("SELECT COUNT(*) FROM ("+query+")", args...).Scan(&total); err != nil {
+			log.Printf("Error counting transactions: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("X-Total-Count", strconv.Itoa(total))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
 	query += " ORDER BY date DESC"
 
-	rows, err := database.DB.Query(query, args...)
+	// Listing only reads, so a configured read replica can serve it
+	rows, err := database.ReadDB().Query(query, args...)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -142,17 +276,38 @@ func GetTransactions(w http.ResponseWriter, r *http.Request) {
 		var paidDate sql.NullString
 		var transactionDate sql.NullTime
 		var userId sql.NullString
+		var originalAmount sql.NullFloat64
+		var originalCurrency sql.NullString
+		var status sql.NullString
+		original := []interface{}{}
+		if hasOriginalAmountColumns {
+			original = []interface{}{&originalAmount, &originalCurrency}
+		}
+		if hasStatusColumn {
+			original = append(original, &status)
+		}
+		if hasLockedColumn {
+			original = append(original, &t.Locked)
+		}
+		var refundOf sql.NullString
+		if hasRefundOfColumn {
+			original = append(original, &refundOf)
+		}
+		var ownerName sql.NullString
+		if includeOwner {
+			original = append(original, &ownerName)
+		}
 
 		var err error
 		if hasOptionalColumn && hasUserIdColumn {
-			err = rows.Scan(&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate, &t.Type, &t.PayTo, &t.Paid, &paidDate, &t.EnteredBy, &t.Optional, &userId)
+			err = rows.Scan(append([]interface{}{&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate, &t.Type, &t.PayTo, &t.Paid, &paidDate, &t.EnteredBy, &t.Optional, &userId}, original...)...)
 			if userId.Valid {
 				t.UserID = userId.String
 			}
 		} else if hasOptionalColumn {
-			err = rows.Scan(&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate, &t.Type, &t.PayTo, &t.Paid, &paidDate, &t.EnteredBy, &t.Optional)
+			err = rows.Scan(append([]interface{}{&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate, &t.Type, &t.PayTo, &t.Paid, &paidDate, &t.EnteredBy, &t.Optional}, original...)...)
 		} else {
-			err = rows.Scan(&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate, &t.Type, &t.PayTo, &t.Paid, &paidDate, &t.EnteredBy)
+			err = rows.Scan(append([]interface{}{&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate, &t.Type, &t.PayTo, &t.Paid, &paidDate, &t.EnteredBy}, original...)...)
 			// Set default value for optional
 			t.Optional = false
 		}
@@ -164,6 +319,10 @@ func GetTransactions(w http.ResponseWriter, r *http.Request) {
 		if paidDate.Valid {
 			t.PaidDate = paidDate.String
 		}
+		applyOriginalAmount(&t, originalAmount, originalCurrency)
+		t.Status = status.String
+		t.RefundOf = refundOf.String
+		t.OwnerName = ownerName.String
 		if transactionDate.Valid {
 			t.TransactionDate = transactionDate.Time
 		} else {
@@ -173,6 +332,7 @@ func GetTransactions(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(transactions)))
 	json.NewEncoder(w).Encode(transactions)
 }
 
@@ -187,6 +347,11 @@ func GetTransaction(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	if _, status, err := AuthorizeTransactionAccess(userID, id, models.PermissionRead); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
 	// First check if the optional column exists
 	var hasOptionalColumn bool
 	err := database.DB.QueryRow(`
@@ -217,116 +382,107 @@ func GetTransaction(w http.ResponseWriter, r *http.Request) {
 	var paidDate sql.NullString
 	var transactionDate sql.NullTime
 	var userId sql.NullString
+	var originalAmount sql.NullFloat64
+	var originalCurrency sql.NullString
+	var status sql.NullString
+
+	// Original currency columns are only selected once they exist
+	hasOriginalAmountColumns := transactionsHaveColumn("original_amount")
+	originalColumns := ""
+	original := []interface{}{}
+	if hasOriginalAmountColumns {
+		originalColumns = ", original_amount, original_currency"
+		original = []interface{}{&originalAmount, &originalCurrency}
+	}
+	// So is the status column
+	if transactionsHaveColumn("status") {
+		originalColumns += ", status"
+		original = append(original, &status)
+	}
+	// And the locked flag
+	if transactionsHaveColumn("locked") {
+		originalColumns += ", locked"
+		original = append(original, &t.Locked)
+	}
+	// And the refund link
+	var refundOf sql.NullString
+	if transactionsHaveColumn("refund_of") {
+		originalColumns += ", refund_of"
+		original = append(original, &refundOf)
+	}
+	// Owner names are looked up on request
+	var ownerName sql.NullString
+	includeOwner, err := parseIncludeOwner(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if includeOwner && hasUserIdColumn {
+		originalColumns += ", " + ownerNameColumn
+		original = append(original, &ownerName)
+	}
 
 	var query string
 	if hasOptionalColumn && hasUserIdColumn {
 		query = `
-			SELECT id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional, userId 
+			SELECT id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional, userId` + originalColumns + `
 			FROM transactions 
 			WHERE id = ?
 		`
 	} else if hasOptionalColumn {
 		query = `
-			SELECT id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional 
+			SELECT id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional` + originalColumns + `
 			FROM transactions 
 			WHERE id = ?
 		`
 	} else {
 		query = `
-			SELECT id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy 
+			SELECT id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy` + originalColumns + `
 			FROM transactions 
 			WHERE id = ?
 		`
 	}
 
-	// Add user ID check if the column exists
-	if hasUserIdColumn {
-		// Check if the user has permission to view this transaction
-		// First, get the owner of the transaction
-		var transactionOwnerID sql.NullString
-		ownerErr := database.DB.QueryRow("SELECT userId FROM transactions WHERE id = ?", id).Scan(&transactionOwnerID)
-
-		if ownerErr != nil && ownerErr != sql.ErrNoRows {
-			log.Printf("Error getting transaction owner: %v", ownerErr)
-			http.Error(w, "Error checking transaction access", http.StatusInternalServerError)
-			return
-		}
-
-		var resourceOwnerID string
-		if ownerErr == sql.ErrNoRows || !transactionOwnerID.Valid {
-			// Transaction doesn't exist or has no owner - allow access to continue with normal query
-			// This will be filtered properly in the next step
-			resourceOwnerID = userID // Default to the current user
-		} else {
-			resourceOwnerID = transactionOwnerID.String
-		}
+	// Deleted transactions are not found
+	if transactionsHaveColumn("deleted_at") {
+		query += " AND deleted_at IS NULL"
+	}
 
-		// Check if the user has permission to access this transaction
-		hasAccess := middleware.CheckUserPermission(userID, resourceOwnerID, models.ResourceTransactions, models.PermissionRead)
+	args := []interface{}{id}
+	if hasOptionalColumn && hasUserIdColumn {
+		err = database.DB.QueryRow(query, args...).Scan(append([]interface{}{
+			&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate,
+			&t.Type, &t.PayTo, &t.Paid, &paidDate, &t.EnteredBy, &t.Optional, &userId}, original...)...)
+	} else if hasOptionalColumn {
+		err = database.DB.QueryRow(query, args...).Scan(append([]interface{}{
+			&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate,
+			&t.Type, &t.PayTo, &t.Paid, &paidDate, &t.EnteredBy, &t.Optional}, original...)...)
+	} else {
+		err = database.DB.QueryRow(query, args...).Scan(append([]interface{}{
+			&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate,
+			&t.Type, &t.PayTo, &t.Paid, &paidDate, &t.EnteredBy}, original...)...)
+	}
 
-		if !hasAccess && userID != resourceOwnerID {
-			log.Printf("User %s does not have permission to access transaction %s owned by %s",
-				userID, id, resourceOwnerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
 			http.Error(w, "Transaction not found", http.StatusNotFound)
-			return
-		}
-
-		// Build the query with access control
-		query += " AND (userId = ? OR userId IS NULL)"
-		args := []interface{}{id, resourceOwnerID}
-
-		if hasOptionalColumn && hasUserIdColumn {
-			err = database.DB.QueryRow(query, args...).Scan(
-				&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate,
-				&t.Type, &t.PayTo, &t.Paid, &paidDate, &t.EnteredBy, &t.Optional, &userId)
-		} else if hasOptionalColumn {
-			err = database.DB.QueryRow(query, args...).Scan(
-				&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate,
-				&t.Type, &t.PayTo, &t.Paid, &paidDate, &t.EnteredBy, &t.Optional)
-		} else {
-			err = database.DB.QueryRow(query, args...).Scan(
-				&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate,
-				&t.Type, &t.PayTo, &t.Paid, &paidDate, &t.EnteredBy)
-		}
-
-		if err != nil {
-			if err == sql.ErrNoRows {
-				http.Error(w, "Transaction not found", http.StatusNotFound)
-			} else {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-			}
-			return
-		}
-
-		if userId.Valid {
-			t.UserID = userId.String
-		}
-	} else {
-		// No userId column, just query by ID
-		args := []interface{}{id}
-		if hasOptionalColumn {
-			err = database.DB.QueryRow(query, args...).Scan(
-				&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate,
-				&t.Type, &t.PayTo, &t.Paid, &paidDate, &t.EnteredBy, &t.Optional)
 		} else {
-			err = database.DB.QueryRow(query, args...).Scan(
-				&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate,
-				&t.Type, &t.PayTo, &t.Paid, &paidDate, &t.EnteredBy)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
+		return
+	}
 
-		if err != nil {
-			if err == sql.ErrNoRows {
-				http.Error(w, "Transaction not found", http.StatusNotFound)
-			} else {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-			}
-			return
-		}
+	if userId.Valid {
+		t.UserID = userId.String
 	}
 
 	if paidDate.Valid {
 		t.PaidDate = paidDate.String
 	}
+	applyOriginalAmount(&t, originalAmount, originalCurrency)
+	t.Status = status.String
+	t.RefundOf = refundOf.String
+	t.OwnerName = ownerName.String
 	if transactionDate.Valid {
 		t.TransactionDate = transactionDate.Time
 	} else {
@@ -345,8 +501,14 @@ func AddTransaction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	var t models.Transaction
-	err := json.NewDecoder(r.Body).Decode(&t)
+	err = json.Unmarshal(body, &t)
 	if err != nil {
 		log.Printf("Error decoding transaction: %v", err)
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -358,24 +520,127 @@ func AddTransaction(w http.ResponseWriter, r *http.Request) {
 		t.ID = generateID()
 	}
 
-	// Set current time if date is not provided
-	if t.Date.IsZero() {
-		t.Date = time.Now()
+	t.Type, err = normalizeTransactionType(t.Type)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	// Set transaction date to date if not provided
-	if t.TransactionDate.IsZero() {
-		t.TransactionDate = t.Date
+	t.Description, err = normalizeDescription(t.Description)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	t.Status, err = normalizeTransactionStatus(t.Status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	// Set the user ID from the authentication context
-	t.UserID = userID
+	if err := normalizeOriginalAmount(&t); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Transactions belong to the caller unless another owner is given, which
+	// requires write access to that user's transactions
+	if t.UserID == "" || t.UserID == userID {
+		t.UserID = userID
+	} else {
+		var ownerExists bool
+		err := database.DB.QueryRow("SELECT COUNT(*) > 0 FROM users WHERE id = ?", t.UserID).Scan(&ownerExists)
+		if err != nil {
+			log.Printf("Error checking user %s: %v", t.UserID, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !ownerExists {
+			http.Error(w, fmt.Sprintf("User %s not found", t.UserID), http.StatusBadRequest)
+			return
+		}
+		if !middleware.CheckUserPermission(userID, t.UserID, models.ResourceTransactions, models.PermissionWrite) {
+			log.Printf("User %s may not add transactions for user %s", userID, t.UserID)
+			http.Error(w, fmt.Sprintf("Forbidden: no write access to transactions of user %s", t.UserID), http.StatusForbidden)
+			return
+		}
+	}
+
+	if status, err := validateRefund(t, userID); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
 
 	// If EnteredBy is not explicitly provided, use the user ID
 	if t.EnteredBy == "" {
 		t.EnteredBy = userID
 	}
 
+	// The owner's highest priority matching rule picks the category, which
+	// also becomes the type when none was given
+	rule, err := matchCategorizationRule(t)
+	if err != nil {
+		log.Printf("Error matching categorization rules: %v", err)
+	}
+	if rule != nil && t.Type == "" {
+		t.Type = rule.CategoryName
+	}
+
+	// Track which fields were sent so defaults only fill in missing ones
+	var explicit struct {
+		Optional        *bool            `json:"optional"`
+		Date            *json.RawMessage `json:"date"`
+		TransactionDate *json.RawMessage `json:"transactionDate"`
+		CategoryID      *int             `json:"categoryId"`
+		CategoryName    string           `json:"categoryName"`
+	}
+	json.Unmarshal(body, &explicit)
+
+	// A category given with the transaction is linked instead of the rule's,
+	// and becomes the type when none was given
+	var categoryID int
+	if explicit.CategoryID != nil || explicit.CategoryName != "" {
+		var categoryName string
+		var status int
+		categoryID, categoryName, status, err = resolveTransactionCategory(t.UserID, explicit.CategoryID, explicit.CategoryName)
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		if t.Type == "" {
+			t.Type = categoryName
+		}
+	} else if rule != nil {
+		categoryID = rule.CategoryID
+	}
+
+	// Without an explicit optional flag, inherit the category's default
+	if explicit.Optional == nil {
+		t.Optional = categoryOptionalDefault(t.UserID, t.Type)
+	}
+
+	// A zero date that was sent explicitly (e.g. year 0001 from an import) is
+	// rejected below rather than being treated as missing
+	explicitZeroDate := explicit.Date != nil && t.Date.IsZero()
+	explicitZeroTransactionDate := explicit.TransactionDate != nil && t.TransactionDate.IsZero()
+
+	// Set current time if date is not provided
+	if t.Date.IsZero() && !explicitZeroDate {
+		t.Date = time.Now()
+	}
+
+	// Set transaction date to date if not provided
+	if t.TransactionDate.IsZero() && !explicitZeroTransactionDate {
+		t.TransactionDate = t.Date
+	}
+
+	if err := validateTransactionDates(t, time.Now()); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	t.Source = models.TransactionSourceManual
+
 	// Check if the optional column exists
 	var hasOptionalColumn bool
 	err = database.DB.QueryRow(`
@@ -468,19 +733,84 @@ func AddTransaction(w http.ResponseWriter, r *http.Request) {
 		insertArgs = append(insertArgs, t.UserID)
 	}
 
+	if transactionsHaveColumn("source") {
+		insertQuery += `, source`
+		insertValues += `, ?`
+		insertArgs = append(insertArgs, t.Source)
+	}
+
+	if transactionsHaveColumn("status") {
+		insertQuery += `, status`
+		insertValues += `, ?`
+		insertArgs = append(insertArgs, t.Status)
+	}
+
+	if transactionsHaveColumn("original_amount") {
+		insertQuery += `, original_amount, original_currency`
+		insertValues += `, ?, ?`
+		insertArgs = append(insertArgs, originalAmountArgs(t)...)
+	}
+
+	if transactionsHaveColumn("refund_of") {
+		insertQuery += `, refund_of`
+		insertValues += `, ?`
+		insertArgs = append(insertArgs, refundOfArg(t))
+	}
+
+	if transactionsHaveColumn("updated_at") {
+		insertQuery += `, updated_at`
+		insertValues += `, ?`
+		insertArgs = append(insertArgs, time.Now())
+	}
+
 	insertQuery += `) VALUES (` + insertValues + `)`
 
 	log.Printf("Executing query: %s with %d args", insertQuery, len(insertArgs))
 
-	_, err = database.DB.Exec(insertQuery, insertArgs...)
+	// The transaction and its category link are stored together
+	err = execWithRetry(func() error {
+		tx, err := database.DB.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		_, err = tx.Exec(insertQuery, insertArgs...)
+		if err != nil {
+			log.Printf("Error inserting transaction: %v", err)
+			return err
+		}
+
+		if categoryID != 0 {
+			_, err := tx.Exec(`
+				INSERT INTO transaction_categories (transaction_id, category_id, amount)
+				VALUES (?, ?, ?)
+			`, t.ID, categoryID, t.Amount)
+			if err != nil && explicit.CategoryID == nil && explicit.CategoryName == "" {
+				// A rule failing to apply doesn't stop the transaction from being created
+				log.Printf("Error applying categorization rule %d to transaction %s: %v", rule.ID, t.ID, err)
+			} else if err != nil {
+				log.Printf("Error linking category %d to transaction %s: %v", categoryID, t.ID, err)
+				return err
+			}
+		}
+
+		return tx.Commit()
+	})
 	if err != nil {
-		log.Printf("Error inserting transaction: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	// Going over budget doesn't stop the transaction from being created
+	created := models.CreatedTransaction{Transaction: t}
+	created.Warnings, err = budgetWarnings(t)
+	if err != nil {
+		log.Printf("Error checking category budgets for transaction %s: %v", t.ID, err)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(t)
+	json.NewEncoder(w).Encode(created)
 }
 
 func UpdateTransaction(w http.ResponseWriter, r *http.Request) {
@@ -502,6 +832,59 @@ func UpdateTransaction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Set transaction date to date if not provided
+	if t.TransactionDate.IsZero() {
+		t.TransactionDate = t.Date
+	}
+
+	t.Type, err = normalizeTransactionType(t.Type)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	t.Description, err = normalizeDescription(t.Description)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	t.Status, err = normalizeTransactionStatus(t.Status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := normalizeOriginalAmount(&t); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validateTransactionDates(t, time.Now()); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ownerID, status, err := AuthorizeTransactionAccess(userID, id, models.PermissionWrite)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if status, err := checkTransactionLock(r, userID, id); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	t.ID = id
+	if status, err := validateRefund(t, userID); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	// Users editing someone else's transaction leave it with its owner, while
+	// unowned transactions are claimed by whoever edits them
+	if ownerID == "" {
+		ownerID = userID
+	}
+
 	// Check if the optional column exists
 	var hasOptionalColumn bool
 	err = database.DB.QueryRow(`
@@ -589,38 +972,95 @@ func UpdateTransaction(w http.ResponseWriter, r *http.Request) {
 
 	if hasUserIdColumn {
 		updateQuery += `, userId = ?`
-		updateArgs = append(updateArgs, userID) // Use the authenticated user ID
+		updateArgs = append(updateArgs, ownerID)
+	}
+
+	hasDeletedAtColumn := transactionsHaveColumn("deleted_at")
+	if transactionsHaveColumn("original_amount") {
+		updateQuery += `, original_amount = ?, original_currency = ?`
+		updateArgs = append(updateArgs, originalAmountArgs(t)...)
+	}
+	if transactionsHaveColumn("status") {
+		updateQuery += `, status = ?`
+		updateArgs = append(updateArgs, t.Status)
+	}
+	if transactionsHaveColumn("refund_of") {
+		updateQuery += `, refund_of = ?`
+		updateArgs = append(updateArgs, refundOfArg(t))
+	}
+	if transactionsHaveColumn("updated_at") {
+		updateQuery += `, updated_at = ?`
+		updateArgs = append(updateArgs, time.Now())
 	}
 
 	updateQuery += ` WHERE id = ?`
 	updateArgs = append(updateArgs, id)
 
-	// If userId column exists, also check that user owns this transaction or has admin permission
-	if hasUserIdColumn {
-		updateQuery += ` AND (userId = ? OR userId IS NULL)`
-		updateArgs = append(updateArgs, userID)
+	// Deleted transactions can't be updated
+	if hasDeletedAtColumn {
+		updateQuery += ` AND deleted_at IS NULL`
 	}
 
 	log.Printf("Executing update query: %s with %d args", updateQuery, len(updateArgs))
 
-	result, err := database.DB.Exec(updateQuery, updateArgs...)
-	if err != nil {
-		log.Printf("Error updating transaction: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+	// Run the update and its history entry in one transaction
+	status = http.StatusInternalServerError
+	err = execWithRetry(func() error {
+		dbTx, err := database.DB.Begin()
+		if err != nil {
+			log.Printf("Error starting transaction: %v", err)
+			return err
+		}
+		defer dbTx.Rollback()
+
+		// Without the before snapshot the change couldn't be recorded
+		before, err := loadTransactionSnapshot(dbTx, id)
+		if err == sql.ErrNoRows {
+			log.Printf("No transaction found with id %s for user %s", id, userID)
+			status = http.StatusNotFound
+			return errors.New("Transaction not found")
+		} else if err != nil {
+			log.Printf("Error loading transaction before update: %v", err)
+			return err
+		}
 
-	// Check if any rows were affected
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Printf("Error getting rows affected: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+		result, err := dbTx.Exec(updateQuery, updateArgs...)
+		if err != nil {
+			log.Printf("Error updating transaction: %v", err)
+			return err
+		}
 
-	if rowsAffected == 0 {
-		log.Printf("No transaction found with id %s for user %s", id, userID)
-		http.Error(w, "Transaction not found or you don't have permission to modify it", http.StatusNotFound)
+		// Check if any rows were affected
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			log.Printf("Error getting rows affected: %v", err)
+			return err
+		}
+
+		if rowsAffected == 0 {
+			log.Printf("No transaction found with id %s for user %s", id, userID)
+			status = http.StatusNotFound
+			return errors.New("Transaction not found")
+		}
+
+		// Record who changed what
+		after, err := loadTransactionSnapshot(dbTx, id)
+		if err == nil {
+			err = recordTransactionHistory(dbTx, before, after, userID)
+		}
+		if err != nil {
+			log.Printf("Error recording transaction history: %v", err)
+			return err
+		}
+
+		if err := dbTx.Commit(); err != nil {
+			log.Printf("Error committing transaction update: %v", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), status)
 		return
 	}
 
@@ -638,31 +1078,43 @@ func DeleteTransaction(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	// Check if the userId column exists
-	var hasUserIdColumn bool
-	err := database.DB.QueryRow(`
-		SELECT COUNT(*) > 0 
-		FROM pragma_table_info('transactions') 
-		WHERE name = 'userId'
-	`).Scan(&hasUserIdColumn)
-
-	if err != nil {
-		log.Printf("Error checking for userId column: %v", err)
-		hasUserIdColumn = false
+	if _, status, err := AuthorizeTransactionAccess(userID, id, models.PermissionWrite); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if status, err := checkTransactionLock(r, userID, id); err != nil {
+		http.Error(w, err.Error(), status)
+		return
 	}
 
-	// Build delete query
-	deleteQuery := "DELETE FROM transactions WHERE id = ?"
-	deleteArgs := []interface{}{id}
+	// Transactions are soft-deleted when possible so syncing clients learn
+	// about the deletion
+	softDelete := transactionsHaveColumn("deleted_at")
 
-	// If userId column exists, also check that user owns this transaction
-	if hasUserIdColumn {
-		deleteQuery += " AND (userId = ? OR userId IS NULL)"
-		deleteArgs = append(deleteArgs, userID)
-	}
+	var deleted bool
+	err := execWithRetry(func() error {
+		tx, err := database.DB.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
 
-	log.Printf("Executing delete query: %s", deleteQuery)
-	result, err := database.DB.Exec(deleteQuery, deleteArgs...)
+		if softDelete {
+			deleted, err = softDeleteTransaction(tx, id, time.Now())
+		} else {
+			var result sql.Result
+			result, err = tx.Exec("DELETE FROM transactions WHERE id = ?", id)
+			if err == nil {
+				var affected int64
+				affected, err = result.RowsAffected()
+				deleted = affected > 0
+			}
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
 
 	if err != nil {
 		log.Printf("Error deleting transaction: %v", err)
@@ -670,30 +1122,73 @@ func DeleteTransaction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if any rows were affected
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Printf("Error getting rows affected: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	if rowsAffected == 0 {
+	if !deleted {
 		log.Printf("No transaction found with id %s for user %s", id, userID)
-		http.Error(w, "Transaction not found or you don't have permission to delete it", http.StatusNotFound)
+		http.Error(w, "Transaction not found", http.StatusNotFound)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
 
-func generateID() string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, 16)
-	for i := range b {
-		b[i] = charset[rand.Intn(len(charset))]
+// transactionsHaveColumn reports whether the transactions table has a column
+func transactionsHaveColumn(name string) bool {
+	var exists bool
+	err := database.DB.QueryRow(`
+		SELECT COUNT(*) > 0 
+		FROM pragma_table_info('transactions') 
+		WHERE name = ?
+	`, name).Scan(&exists)
+	if err != nil {
+		log.Printf("Error checking for %s column: %v", name, err)
+		return false
+	}
+	return exists
+}
+
+// enteredByIdentities returns the values enteredBy may hold for a user: the
+// user ID (set when no enteredBy is given) plus their username and name
+func enteredByIdentities(userID string) []string {
+	identities := []string{userID}
+
+	var username, name sql.NullString
+	err := database.DB.QueryRow("SELECT username, name FROM users WHERE id = ?", userID).Scan(&username, &name)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Error looking up user %s: %v", userID, err)
 	}
-	return string(b)
+	for _, identity := range []sql.NullString{username, name} {
+		if identity.Valid && identity.String != "" {
+			identities = append(identities, identity.String)
+		}
+	}
+	return identities
+}
+
+// parseAmountParam parses an optional decimal amount query parameter. The
+// boolean result is false when the parameter was not provided.
+func parseAmountParam(value string) (float64, bool, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false, nil
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, false, fmt.Errorf("%q is not a valid number", value)
+	}
+	return amount, true, nil
+}
+
+// categoryOptionalDefault reports whether the user's category with the given
+// name defaults new transactions to optional
+func categoryOptionalDefault(userID, categoryName string) bool {
+	var optionalDefault bool
+	err := database.DB.QueryRow(`
+		SELECT optional_default FROM categories WHERE user_id = ? AND name = ? AND deleted_at IS NULL
+	`, userID, categoryName).Scan(&optionalDefault)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Error checking category optional default: %v", err)
+	}
+	return optionalDefault
 }
 
 // GetUniqueTransactionFields returns unique values for PayTo and EnteredBy fields
@@ -744,6 +1239,11 @@ func GetUniqueTransactionFields(w http.ResponseWriter, r *http.Request) {
 		WHERE enteredBy IS NOT NULL
 	`
 
+	if transactionsHaveColumn("deleted_at") {
+		payToQuery += " AND deleted_at IS NULL"
+		enteredByQuery += " AND deleted_at IS NULL"
+	}
+
 	args := []interface{}{}
 
 	// Add user ID filter if the column exists
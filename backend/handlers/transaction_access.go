@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// AuthorizeTransactionAccess checks that a user holds the given permission on
+// a transaction: they own it, are an admin, or have been granted the
+// permission by its owner. Transactions without an owner are open to
+// everyone. It returns the owner's user ID ("" when there is none).
+//
+// On failure it also returns the HTTP status to respond with. Every
+// transaction handler follows the same policy:
+//   - 404 when the transaction doesn't exist or the user can't read it, with
+//     the same message either way so its existence isn't leaked
+//   - 403 when the user can read the transaction but lacks the permission
+//     asked for, since they already know it exists
+func AuthorizeTransactionAccess(userID, txID, permission string) (string, int, error) {
+	hasUserIdColumn := transactionsHaveColumn("userId")
+	query := "SELECT NULL FROM transactions WHERE id = ?"
+	if hasUserIdColumn {
+		query = "SELECT userId FROM transactions WHERE id = ?"
+	}
+	if transactionsHaveColumn("deleted_at") {
+		query += " AND deleted_at IS NULL"
+	}
+
+	var ownerID sql.NullString
+	err := database.DB.QueryRow(query, txID).Scan(&ownerID)
+	if err == sql.ErrNoRows {
+		return "", http.StatusNotFound, fmt.Errorf("Transaction not found")
+	} else if err != nil {
+		log.Printf("Error getting transaction owner: %v", err)
+		return "", http.StatusInternalServerError, fmt.Errorf("Error checking transaction access")
+	}
+
+	if !ownerID.Valid || ownerID.String == "" {
+		return "", http.StatusOK, nil
+	}
+
+	if !middleware.CheckUserPermission(userID, ownerID.String, models.ResourceTransactions, permission) {
+		log.Printf("User %s does not have %s permission on transaction %s owned by %s",
+			userID, permission, txID, ownerID.String)
+		if permission != models.PermissionRead &&
+			middleware.CheckUserPermission(userID, ownerID.String, models.ResourceTransactions, models.PermissionRead) {
+			return "", http.StatusForbidden, fmt.Errorf("Forbidden: no %s access to this transaction", permission)
+		}
+		return "", http.StatusNotFound, fmt.Errorf("Transaction not found")
+	}
+	return ownerID.String, http.StatusOK, nil
+}
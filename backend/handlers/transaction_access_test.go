@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+func TestAuthorizeTransactionAccessAcrossOperations(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	database.DB.Exec(`
+		INSERT INTO users (id, username, name, isAdmin, role) VALUES
+		('household', 'household', 'Household', 0, 'user'),
+		('reader', 'reader', 'Reader', 0, 'user'),
+		('writer', 'writer', 'Writer', 0, 'user'),
+		('stranger', 'stranger', 'Stranger', 0, 'user')
+	`)
+	database.DB.Exec(`
+		INSERT INTO permissions (granted_user_id, owner_user_id, resource_type, permission_type) VALUES
+		('reader', 'household', 'transactions', 'read'),
+		('writer', 'household', 'transactions', 'write')
+	`)
+
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	updateBody := `{"amount": 42, "description": "Updated", "date": "2024-05-01T00:00:00Z", "type": "Groceries", "payTo": "Store", "enteredBy": "household"}`
+
+	operations := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		body    *string
+	}{
+		{"get", GetTransaction, "GET", nil},
+		{"update", UpdateTransaction, "PUT", &updateBody},
+		{"delete", DeleteTransaction, "DELETE", nil},
+	}
+
+	callers := []struct {
+		name     string
+		callerID string
+		// expected status per operation: get, update, delete
+		expected [3]int
+	}{
+		{"owner", "household", [3]int{http.StatusOK, http.StatusOK, http.StatusOK}},
+		{"granted read", "reader", [3]int{http.StatusOK, http.StatusForbidden, http.StatusForbidden}},
+		{"granted write", "writer", [3]int{http.StatusOK, http.StatusOK, http.StatusOK}},
+		{"admin", TestUserID, [3]int{http.StatusOK, http.StatusOK, http.StatusOK}},
+		{"denied", "stranger", [3]int{http.StatusNotFound, http.StatusNotFound, http.StatusNotFound}},
+	}
+
+	for _, caller := range callers {
+		for i, op := range operations {
+			t.Run(caller.name+" "+op.name, func(t *testing.T) {
+				id := generateID()
+				_, err := database.DB.Exec(`
+					INSERT INTO transactions (id, amount, description, date, transaction_date, type, payTo, enteredBy, userId)
+					VALUES (?, 10, 'Original', ?, ?, 'Groceries', 'Store', 'household', 'household')
+				`, id, date, date)
+				if err != nil {
+					t.Fatalf("Failed to insert transaction: %v", err)
+				}
+
+				req := TestRequest(op.method, "/transactions/"+id, op.body)
+				req = MockAuthContext(req, caller.callerID)
+				req = mux.SetURLVars(req, map[string]string{"id": id})
+				w := httptest.NewRecorder()
+				op.handler(w, req)
+
+				if w.Code != caller.expected[i] {
+					t.Fatalf("Expected status code %d, got %d: %s", caller.expected[i], w.Code, w.Body.String())
+				}
+
+				var description, owner string
+				var deleted bool
+				err = database.DB.QueryRow("SELECT description, userId, deleted_at IS NOT NULL FROM transactions WHERE id = ?", id).
+					Scan(&description, &owner, &deleted)
+				if err != nil {
+					t.Fatalf("Error reading transaction: %v", err)
+				}
+				if owner != "household" {
+					t.Errorf("Expected the transaction to stay owned by household, got %s", owner)
+				}
+
+				succeeded := caller.expected[i] == http.StatusOK
+				switch op.name {
+				case "update":
+					if (description == "Updated") != succeeded {
+						t.Errorf("Unexpected description after update: %s", description)
+					}
+				case "delete":
+					if deleted != succeeded {
+						t.Errorf("Expected deleted=%v, got %v", succeeded, deleted)
+					}
+				}
+			})
+		}
+	}
+}
+
+// Callers who can't read a transaction must get exactly the response they
+// would get for one that doesn't exist
+func TestInaccessibleTransactionLooksMissing(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	database.DB.Exec("INSERT INTO users (id, username, name, isAdmin, role) VALUES ('household', 'household', 'Household', 0, 'user'), ('stranger', 'stranger', 'Stranger', 0, 'user')")
+	database.DB.Exec(`
+		INSERT INTO transactions (id, amount, description, date, transaction_date, type, payTo, enteredBy, userId)
+		VALUES ('hidden', 10, 'Hidden', '2024-05-01', '2024-05-01', 'Groceries', 'Store', 'household', 'household')
+	`)
+
+	updateBody := `{"amount": 42, "description": "Updated", "date": "2024-05-01T00:00:00Z", "type": "Groceries"}`
+	operations := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		body    *string
+	}{
+		{"get", GetTransaction, "GET", nil},
+		{"update", UpdateTransaction, "PUT", &updateBody},
+		{"delete", DeleteTransaction, "DELETE", nil},
+	}
+
+	for _, op := range operations {
+		var responses []string
+		for _, id := range []string{"hidden", "missing"} {
+			req := TestRequest(op.method, "/transactions/"+id, op.body)
+			req = MockAuthContext(req, "stranger")
+			req = mux.SetURLVars(req, map[string]string{"id": id})
+			w := httptest.NewRecorder()
+			op.handler(w, req)
+			if w.Code != http.StatusNotFound {
+				t.Errorf("%s %s: expected status code %d, got %d", op.name, id, http.StatusNotFound, w.Code)
+			}
+			responses = append(responses, w.Body.String())
+		}
+		if responses[0] != responses[1] {
+			t.Errorf("%s: inaccessible and missing transactions got different responses: %q vs %q", op.name, responses[0], responses[1])
+		}
+	}
+
+	if _, status, err := AuthorizeTransactionAccess(TestUserID, "missing", models.PermissionRead); err == nil || status != http.StatusNotFound {
+		t.Errorf("Expected not found for a missing transaction, got status %d and error %v", status, err)
+	}
+}
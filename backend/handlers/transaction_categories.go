@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+// GetUncategorizedTransactions returns the user's own transactions that have
+// not been assigned to any category yet, newest first
+func GetUncategorizedTransactions(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	page, pageSize, err := parsePagination(r, pageResourceTransactions)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	const uncategorized = `
+		FROM transactions t
+		WHERE t.userId = ? AND t.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM transaction_categories tc WHERE tc.transaction_id = t.id)
+	`
+
+	result := models.TransactionPage{
+		Transactions: []models.Transaction{},
+		Page:         page,
+		PageSize:     pageSize,
+	}
+
+	if err := database.DB.QueryRow("SELECT COUNT(*) "+uncategorized, userID).Scan(&result.Total); err != nil {
+		log.Printf("Error counting uncategorized transactions: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT t.id, t.amount, t.description, t.date, t.transaction_date, t.type, t.payTo, t.paid, t.paidDate, t.enteredBy, t.optional, t.userId
+	`+uncategorized+`
+		ORDER BY t.date DESC, t.id
+		LIMIT ? OFFSET ?
+	`, userID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		log.Printf("Error querying uncategorized transactions: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t models.Transaction
+		var payTo, paidDate, userId sql.NullString
+		var transactionDate sql.NullTime
+		err := rows.Scan(&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate, &t.Type, &payTo,
+			&t.Paid, &paidDate, &t.EnteredBy, &t.Optional, &userId)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		t.PayTo = payTo.String
+		t.PaidDate = paidDate.String
+		t.UserID = userId.String
+		if transactionDate.Valid {
+			t.TransactionDate = transactionDate.Time
+		} else {
+			t.TransactionDate = t.Date
+		}
+		result.Transactions = append(result.Transactions, t)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetTransactionCategories returns the categories a transaction is assigned to
+func GetTransactionCategories(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if _, _, status, err := transactionForAccess(id, userID, models.PermissionRead); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	assignments, err := loadTransactionCategories(id)
+	if err != nil {
+		log.Printf("Error loading transaction categories: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(assignments)
+}
+
+// SetTransactionCategories replaces the category assignments of a transaction.
+// The assigned amounts must add up to the transaction amount; a single
+// assignment without an amount covers the whole transaction. An empty list
+// clears the assignments. The categories must be the transaction owner's.
+// Locked transactions need an admin override.
+func SetTransactionCategories(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	ownerID, amount, status, err := transactionForAccess(id, userID, models.PermissionWrite)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	// The categories are the owner's, who may not be the caller
+	if ownerID == "" {
+		ownerID = userID
+	}
+	if status, err := checkTransactionLock(r, userID, id); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	var assignments []models.TransactionCategory
+	if err := json.NewDecoder(r.Body).Decode(&assignments); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(assignments) == 1 && assignments[0].Amount == 0 {
+		assignments[0].Amount = models.Amount(amount)
+	}
+
+	seen := map[int]bool{}
+	var total float64
+	for _, a := range assignments {
+		if seen[a.CategoryID] {
+			http.Error(w, fmt.Sprintf("Category %d is assigned more than once", a.CategoryID), http.StatusBadRequest)
+			return
+		}
+		seen[a.CategoryID] = true
+
+		var exists bool
+		err := database.DB.QueryRow(`
+			SELECT COUNT(*) > 0 FROM categories
+			WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+		`, a.CategoryID, ownerID).Scan(&exists)
+		if err != nil {
+			log.Printf("Error checking category %d: %v", a.CategoryID, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !exists {
+			http.Error(w, fmt.Sprintf("Category %d not found", a.CategoryID), http.StatusBadRequest)
+			return
+		}
+		total += float64(a.Amount)
+	}
+
+	if len(assignments) > 0 && math.Abs(total-amount) > 0.005 {
+		http.Error(w, fmt.Sprintf("Category amounts add up to %.2f but the transaction amount is %.2f", total, amount), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM transaction_categories WHERE transaction_id = ?", id); err != nil {
+		log.Printf("Error clearing transaction categories: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, a := range assignments {
+		_, err := tx.Exec(`
+			INSERT INTO transaction_categories (transaction_id, category_id, amount)
+			VALUES (?, ?, ?)
+		`, id, a.CategoryID, a.Amount)
+		if err != nil {
+			log.Printf("Error assigning category %d: %v", a.CategoryID, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	saved, err := loadTransactionCategories(id)
+	if err != nil {
+		log.Printf("Error loading transaction categories: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(saved)
+}
+
+// transactionForAccess looks up a transaction's owner ("" when there is none)
+// and amount after checking that the user holds the given permission on it.
+// On failure it also returns the HTTP status to respond with.
+func transactionForAccess(id, userID, permission string) (string, float64, int, error) {
+	ownerID, status, err := AuthorizeTransactionAccess(userID, id, permission)
+	if err != nil {
+		return "", 0, status, err
+	}
+	var amount float64
+	if err := database.DB.QueryRow("SELECT amount FROM transactions WHERE id = ?", id).Scan(&amount); err != nil {
+		log.Printf("Error getting transaction amount: %v", err)
+		return "", 0, http.StatusInternalServerError, fmt.Errorf("Error checking transaction access")
+	}
+	return ownerID, amount, http.StatusOK, nil
+}
+
+// loadTransactionCategories reads the category assignments of a transaction
+func loadTransactionCategories(id string) ([]models.TransactionCategory, error) {
+	rows, err := database.DB.Query(`
+		SELECT tc.transaction_id, tc.category_id, COALESCE(c.name, ''), tc.amount
+		FROM transaction_categories tc
+		LEFT JOIN categories c ON c.id = tc.category_id
+		WHERE tc.transaction_id = ?
+		ORDER BY tc.id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := []models.TransactionCategory{}
+	for rows.Next() {
+		var a models.TransactionCategory
+		if err := rows.Scan(&a.TransactionID, &a.CategoryID, &a.CategoryName, &a.Amount); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, a)
+	}
+	return assignments, rows.Err()
+}
+
+// resolveTransactionCategory finds the owner's category given by ID or by
+// (case-insensitive) name, returning its ID and name. On failure it also
+// returns the HTTP status to respond with.
+func resolveTransactionCategory(ownerID string, id *int, name string) (int, string, int, error) {
+	if id != nil && name != "" {
+		return 0, "", http.StatusBadRequest, fmt.Errorf("Give categoryId or categoryName, not both")
+	}
+
+	var row *sql.Row
+	if id != nil {
+		row = database.DB.QueryRow(`
+			SELECT id, name FROM categories
+			WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+		`, *id, ownerID)
+	} else {
+		row = database.DB.QueryRow(`
+			SELECT id, name FROM categories
+			WHERE name = ? COLLATE NOCASE AND user_id = ? AND deleted_at IS NULL
+			ORDER BY name = ? DESC
+			LIMIT 1
+		`, strings.TrimSpace(name), ownerID, strings.TrimSpace(name))
+	}
+
+	var categoryID int
+	var categoryName string
+	err := row.Scan(&categoryID, &categoryName)
+	if err == sql.ErrNoRows {
+		if id != nil {
+			return 0, "", http.StatusBadRequest, fmt.Errorf("Category %d not found", *id)
+		}
+		return 0, "", http.StatusBadRequest, fmt.Errorf("Category %q not found", name)
+	} else if err != nil {
+		log.Printf("Error resolving category: %v", err)
+		return 0, "", http.StatusInternalServerError, fmt.Errorf("Error checking category")
+	}
+	return categoryID, categoryName, http.StatusOK, nil
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTransactionCategoryTestDB() {
+	setupTransactionTestDB()
+
+	_, err := database.DB.Exec(`
+		CREATE TABLE IF NOT EXISTS categories (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			description TEXT,
+			user_id TEXT NOT NULL,
+			color TEXT,
+			last_updated DATETIME,
+			ynab_category_id TEXT,
+			archived BOOLEAN NOT NULL DEFAULT 0,
+			optional_default BOOLEAN NOT NULL DEFAULT 0,
+			sort_order INTEGER,
+			deleted_at DATETIME,
+			UNIQUE(name, user_id)
+		)
+	`)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func insertTestTransaction(t *testing.T, id string, amount float64, date time.Time, userID string) {
+	_, err := database.DB.Exec(`
+		INSERT INTO transactions (id, amount, description, date, transaction_date, type, enteredBy, userId)
+		VALUES (?, ?, 'Test', ?, ?, 'Groceries', 'test-user', ?)
+	`, id, amount, date, date, userID)
+	if err != nil {
+		t.Fatalf("Failed to insert transaction: %v", err)
+	}
+}
+
+func getUncategorized(t *testing.T, url string) models.TransactionPage {
+	req := TestRequest("GET", url, nil)
+	w := httptest.NewRecorder()
+	GetUncategorizedTransactions(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var page models.TransactionPage
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	return page
+}
+
+func TestGetUncategorizedTransactions(t *testing.T) {
+	setupTransactionCategoryTestDB()
+	defer CleanupTestDB()
+
+	base := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	insertTestTransaction(t, "uncategorized-old", 10, base, TestUserID)
+	insertTestTransaction(t, "uncategorized-new", 20, base.AddDate(0, 0, 1), TestUserID)
+	insertTestTransaction(t, "categorized", 30, base.AddDate(0, 0, 2), TestUserID)
+	insertTestTransaction(t, "someone-else", 40, base, "other-user")
+
+	database.DB.Exec("INSERT INTO categories (id, name, user_id) VALUES (1, 'Groceries', ?)", TestUserID)
+	database.DB.Exec("INSERT INTO transaction_categories (transaction_id, category_id, amount) VALUES ('categorized', 1, 30)")
+
+	page := getUncategorized(t, "/transactions/uncategorized")
+	if page.Total != 2 || len(page.Transactions) != 2 {
+		t.Fatalf("Expected 2 uncategorized transactions, got total=%d len=%d", page.Total, len(page.Transactions))
+	}
+	if page.Transactions[0].ID != "uncategorized-new" || page.Transactions[1].ID != "uncategorized-old" {
+		t.Errorf("Unexpected transactions or order: %s, %s", page.Transactions[0].ID, page.Transactions[1].ID)
+	}
+
+	// Pagination keeps the total but limits the page
+	page = getUncategorized(t, "/transactions/uncategorized?page=2&pageSize=1")
+	if page.Total != 2 || len(page.Transactions) != 1 || page.Transactions[0].ID != "uncategorized-old" {
+		t.Errorf("Unexpected second page: %+v", page)
+	}
+
+	req := TestRequest("GET", "/transactions/uncategorized?pageSize=0", nil)
+	w := httptest.NewRecorder()
+	GetUncategorizedTransactions(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for invalid pageSize, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestAssigningCategoryRemovesFromUncategorized(t *testing.T) {
+	setupTransactionCategoryTestDB()
+	defer CleanupTestDB()
+
+	insertTestTransaction(t, "tx-1", 42.5, time.Now(), TestUserID)
+	database.DB.Exec("INSERT INTO categories (id, name, user_id) VALUES (7, 'Dining', ?)", TestUserID)
+
+	if page := getUncategorized(t, "/transactions/uncategorized"); page.Total != 1 {
+		t.Fatalf("Expected 1 uncategorized transaction before assignment, got %d", page.Total)
+	}
+
+	body := `[{"categoryId": 7}]`
+	req := TestRequest("PUT", "/transactions/tx-1/categories", &body)
+	req = mux.SetURLVars(req, map[string]string{"id": "tx-1"})
+	w := httptest.NewRecorder()
+	SetTransactionCategories(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var assignments []models.TransactionCategory
+	if err := json.NewDecoder(w.Body).Decode(&assignments); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if len(assignments) != 1 || assignments[0].Amount != 42.5 || assignments[0].CategoryName != "Dining" {
+		t.Errorf("Expected the full amount assigned to Dining, got %+v", assignments)
+	}
+
+	if page := getUncategorized(t, "/transactions/uncategorized"); page.Total != 0 {
+		t.Errorf("Expected no uncategorized transactions after assignment, got %d", page.Total)
+	}
+}
+
+func TestSetTransactionCategoriesRejectsMismatchedSplit(t *testing.T) {
+	setupTransactionCategoryTestDB()
+	defer CleanupTestDB()
+
+	insertTestTransaction(t, "tx-1", 100, time.Now(), TestUserID)
+	database.DB.Exec("INSERT INTO categories (id, name, user_id) VALUES (1, 'A', ?), (2, 'B', ?)", TestUserID, TestUserID)
+
+	testCases := []struct {
+		name string
+		body string
+	}{
+		{"amounts do not add up", `[{"categoryId": 1, "amount": 60}, {"categoryId": 2, "amount": 30}]`},
+		{"unknown category", `[{"categoryId": 99}]`},
+		{"duplicate category", `[{"categoryId": 1, "amount": 50}, {"categoryId": 1, "amount": 50}]`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body := tc.body
+			req := TestRequest("PUT", "/transactions/tx-1/categories", &body)
+			req = mux.SetURLVars(req, map[string]string{"id": "tx-1"})
+			w := httptest.NewRecorder()
+			SetTransactionCategories(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+
+	var count int
+	database.DB.QueryRow("SELECT COUNT(*) FROM transaction_categories").Scan(&count)
+	if count != 0 {
+		t.Errorf("Expected no assignments to be stored, found %d", count)
+	}
+}
+
+func TestSetTransactionCategoriesUsesOwnersCategories(t *testing.T) {
+	setupTransactionCategoryTestDB()
+	defer CleanupTestDB()
+
+	// The caller can write the partner's transactions, which take the
+	// partner's categories rather than the caller's
+	for _, stmt := range []string{
+		`INSERT INTO users (id, username, name, isAdmin, role) VALUES ('partner', 'partner', 'Partner', 0, 'user')`,
+		`INSERT INTO permissions (granted_user_id, owner_user_id, resource_type, permission_type) VALUES ('` + TestUserID + `', 'partner', 'transactions', 'write')`,
+		`INSERT INTO categories (id, name, user_id) VALUES (1, 'Mine', '` + TestUserID + `'), (2, 'Theirs', 'partner')`,
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to set up test data: %v", err)
+		}
+	}
+	insertTestTransaction(t, "partner-tx", 100, time.Now(), "partner")
+
+	testCases := []struct {
+		name           string
+		categoryID     int
+		expectedStatus int
+	}{
+		{"caller's category", 1, http.StatusBadRequest},
+		{"owner's category", 2, http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body := fmt.Sprintf(`[{"categoryId": %d}]`, tc.categoryID)
+			req := TestRequest("PUT", "/transactions/partner-tx/categories", &body)
+			req = mux.SetURLVars(req, map[string]string{"id": "partner-tx"})
+			w := httptest.NewRecorder()
+			SetTransactionCategories(w, req)
+			if w.Code != tc.expectedStatus {
+				t.Errorf("Expected status code %d, got %d: %s", tc.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAddTransactionWithCategory(t *testing.T) {
+	setupTransactionCategoryTestDB()
+	defer CleanupTestDB()
+
+	database.DB.Exec(`
+		INSERT INTO categories (id, name, user_id) VALUES
+		(7, 'Dining', ?), (8, 'Travel', ?), (9, 'Theirs', 'other-user')
+	`, TestUserID, TestUserID)
+
+	categoryLinks := func(id string) []models.TransactionCategory {
+		t.Helper()
+		links, err := loadTransactionCategories(id)
+		if err != nil {
+			t.Fatalf("Error loading categories of %s: %v", id, err)
+		}
+		return links
+	}
+
+	// By ID, with the full amount
+	created := addTestTransaction(t, `{"amount": 42.5, "description": "Dinner", "payTo": "Bistro", "categoryId": 7}`)
+	if links := categoryLinks(created.ID); len(links) != 1 || links[0].CategoryID != 7 || links[0].Amount != 42.5 {
+		t.Errorf("Expected the full amount linked to Dining, got %+v", links)
+	}
+	if created.Type != "Dining" {
+		t.Errorf("Expected the category to become the type, got %q", created.Type)
+	}
+
+	// By name, case-insensitively, keeping an explicit type
+	created = addTestTransaction(t, `{"amount": 300, "description": "Flight", "type": "Trips", "categoryName": "travel"}`)
+	if links := categoryLinks(created.ID); len(links) != 1 || links[0].CategoryID != 8 || links[0].Amount != 300 {
+		t.Errorf("Expected the full amount linked to Travel, got %+v", links)
+	}
+	if created.Type != "Trips" {
+		t.Errorf("Expected the explicit type to be kept, got %q", created.Type)
+	}
+
+	// Without a category the transaction stays uncategorized
+	created = addTestTransaction(t, `{"amount": 5, "description": "Coffee", "type": "Dining"}`)
+	if links := categoryLinks(created.ID); len(links) != 0 {
+		t.Errorf("Expected no category links, got %+v", links)
+	}
+
+	// Unknown, other users' and ambiguous categories are rejected without
+	// creating the transaction
+	for _, body := range []string{
+		`{"amount": 5, "description": "Unknown", "categoryId": 99}`,
+		`{"amount": 5, "description": "Unknown", "categoryName": "Groceries"}`,
+		`{"amount": 5, "description": "Not mine", "categoryId": 9}`,
+		`{"amount": 5, "description": "Both", "categoryId": 7, "categoryName": "Travel"}`,
+	} {
+		req := TestRequest("POST", "/transactions", &body)
+		w := httptest.NewRecorder()
+		AddTransaction(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d for %s, got %d: %s", http.StatusBadRequest, body, w.Code, w.Body.String())
+		}
+	}
+
+	var count int
+	database.DB.QueryRow("SELECT COUNT(*) FROM transactions").Scan(&count)
+	if count != 3 {
+		t.Errorf("Expected 3 transactions, got %d", count)
+	}
+}
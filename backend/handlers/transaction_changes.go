@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// softDeleteTransaction marks a transaction deleted, keeping the row as a
+// tombstone for syncing clients, and removes its category links and tags.
+// It reports false when there was no transaction left to delete.
+func softDeleteTransaction(tx *sql.Tx, id string, now time.Time) (bool, error) {
+	result, err := tx.Exec("UPDATE transactions SET deleted_at = ?, updated_at = ? WHERE id = ? AND deleted_at IS NULL", now, now, id)
+	if err != nil {
+		return false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return false, err
+	}
+
+	for _, stmt := range []string{
+		"DELETE FROM transaction_categories WHERE transaction_id = ?",
+		"DELETE FROM transaction_tags WHERE transaction_id = ?",
+	} {
+		if _, err := tx.Exec(stmt, id); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// GetTransactionChanges returns the accessible transactions created, updated
+// or deleted after ?cursor=, in the order the changes were committed.
+// Deleted transactions are flagged so clients can drop them. The returned
+// cursor is the one to pass on the next call. A first sync can start from
+// ?since=, an RFC 3339 timestamp, instead of a cursor.
+func GetTransactionChanges(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	query := `
+		SELECT id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional, userId,
+			source, status, updated_at, deleted_at, original_amount, original_currency
+		FROM transactions
+		WHERE change_seq <= ?
+	`
+	var args []interface{}
+
+	if cursorParam := r.URL.Query().Get("cursor"); cursorParam != "" {
+		cursor, err := strconv.ParseInt(cursorParam, 10, 64)
+		if err != nil || cursor < 0 {
+			http.Error(w, "Invalid cursor: expected the cursor of a previous call", http.StatusBadRequest)
+			return
+		}
+		query += " AND change_seq > ?"
+		args = append(args, cursor)
+	} else if sinceParam := r.URL.Query().Get("since"); sinceParam != "" {
+		since, err := time.Parse(time.RFC3339, sinceParam)
+		if err != nil {
+			http.Error(w, "Invalid since: expected an RFC 3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		// Compare instants, not text, since updated_at is stored with the
+		// writer's offset
+		query += " AND julianday(updated_at) > julianday(?)"
+		args = append(args, since)
+	} else {
+		http.Error(w, "cursor or since is required", http.StatusBadRequest)
+		return
+	}
+
+	accessClause, accessArgs := accessibleTransactionsClause(userID)
+	query += accessClause + " ORDER BY change_seq"
+	args = append(args, accessArgs...)
+
+	// Read the latest sequence number and the changes up to it from one
+	// snapshot, so the next call continues exactly where this one ends
+	tx, err := database.DB.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	result := models.TransactionChanges{Changes: []models.TransactionChange{}, Until: time.Now()}
+	if err := tx.QueryRow("SELECT value FROM transaction_change_sequence WHERE id = 1").Scan(&result.Cursor); err != nil {
+		log.Printf("Error reading the transaction change sequence: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	args = append([]interface{}{result.Cursor}, args...)
+
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		log.Printf("Error querying transaction changes: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c models.TransactionChange
+		var payTo, paidDate, ownerID, originalCurrency sql.NullString
+		var transactionDate, updatedAt, deletedAt sql.NullTime
+		var originalAmount sql.NullFloat64
+		err := rows.Scan(&c.ID, &c.Amount, &c.Description, &c.Date, &transactionDate, &c.Type, &payTo, &c.Paid,
+			&paidDate, &c.EnteredBy, &c.Optional, &ownerID, &c.Source, &c.Status, &updatedAt, &deletedAt, &originalAmount, &originalCurrency)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		c.PayTo = payTo.String
+		c.PaidDate = paidDate.String
+		c.UserID = ownerID.String
+		applyOriginalAmount(&c.Transaction, originalAmount, originalCurrency)
+		if transactionDate.Valid {
+			c.TransactionDate = transactionDate.Time
+		} else {
+			c.TransactionDate = c.Date
+		}
+		c.UpdatedAt = updatedAt.Time
+		if deletedAt.Valid {
+			c.Deleted = true
+			c.DeletedAt = &deletedAt.Time
+		}
+		result.Changes = append(result.Changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/migrations"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTransactionChangesTestDB(t *testing.T) {
+	setupTransactionTestDB()
+	if err := migrations.AddTransactionChangeSeq(database.DB); err != nil {
+		t.Fatalf("Failed to add change_seq: %v", err)
+	}
+}
+
+func getTransactionChanges(t *testing.T, since time.Time) models.TransactionChanges {
+	return getTransactionChangesURL(t, "/transactions/changes?since="+since.UTC().Format(time.RFC3339))
+}
+
+func getTransactionChangesAfter(t *testing.T, cursor int64) models.TransactionChanges {
+	return getTransactionChangesURL(t, "/transactions/changes?cursor="+strconv.FormatInt(cursor, 10))
+}
+
+func getTransactionChangesURL(t *testing.T, url string) models.TransactionChanges {
+	t.Helper()
+
+	req := TestRequest("GET", url, nil)
+	w := httptest.NewRecorder()
+	GetTransactionChanges(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var changes models.TransactionChanges
+	if err := json.NewDecoder(w.Body).Decode(&changes); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	return changes
+}
+
+func TestGetTransactionChanges(t *testing.T) {
+	setupTransactionChangesTestDB(t)
+	defer CleanupTestDB()
+
+	lastSync := time.Now().Add(-time.Hour)
+	before := lastSync.Add(-time.Hour)
+	for _, id := range []string{"unchanged", "to-update", "to-delete"} {
+		_, err := database.DB.Exec(`
+			INSERT INTO transactions (id, amount, description, date, transaction_date, type, payTo, paidDate, enteredBy, userId, updated_at)
+			VALUES (?, 10, 'Test', ?, ?, 'Groceries', 'Store', '', 'test-user', ?, ?)
+		`, id, before, before, TestUserID, before)
+		if err != nil {
+			t.Fatalf("Failed to insert transaction: %v", err)
+		}
+	}
+
+	body := `{"amount": 20, "description": "New", "type": "Groceries"}`
+	req := TestRequest("POST", "/transactions", &body)
+	w := httptest.NewRecorder()
+	AddTransaction(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d adding, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var created models.Transaction
+	json.NewDecoder(w.Body).Decode(&created)
+
+	body = `{"amount": 15, "description": "Updated", "type": "Groceries", "date": "` + before.Format(time.RFC3339) + `"}`
+	req = TestRequest("PUT", "/transactions/to-update", &body)
+	req = mux.SetURLVars(req, map[string]string{"id": "to-update"})
+	w = httptest.NewRecorder()
+	UpdateTransaction(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d updating, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	req = TestRequest("DELETE", "/transactions/to-delete", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "to-delete"})
+	w = httptest.NewRecorder()
+	DeleteTransaction(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d deleting, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	changes := getTransactionChanges(t, lastSync)
+	got := map[string]models.TransactionChange{}
+	for _, change := range changes.Changes {
+		got[change.ID] = change
+	}
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 changes, got %+v", changes.Changes)
+	}
+	if _, ok := got["unchanged"]; ok {
+		t.Errorf("Did not expect the unchanged transaction")
+	}
+	if change, ok := got[created.ID]; !ok || change.Deleted {
+		t.Errorf("Expected the created transaction as a live change, got %+v", change)
+	}
+	if change, ok := got["to-update"]; !ok || change.Deleted || change.Amount != 15 {
+		t.Errorf("Expected the updated transaction with its new amount, got %+v", change)
+	}
+	if change, ok := got["to-delete"]; !ok || !change.Deleted || change.DeletedAt == nil {
+		t.Errorf("Expected the deleted transaction as a tombstone, got %+v", change)
+	}
+
+	// Syncing again from the returned cursor finds nothing new
+	again := getTransactionChangesAfter(t, changes.Cursor)
+	if len(again.Changes) != 0 || again.Cursor != changes.Cursor {
+		t.Errorf("Expected no changes after the cursor, got %+v", again)
+	}
+
+	// A write stamped before the last sync but committed after it is still
+	// picked up
+	database.DB.Exec("UPDATE transactions SET amount = 11, updated_at = ? WHERE id = 'unchanged'", before)
+	late := getTransactionChangesAfter(t, changes.Cursor)
+	if len(late.Changes) != 1 || late.Changes[0].ID != "unchanged" || late.Cursor <= changes.Cursor {
+		t.Errorf("Expected the late write after the cursor, got %+v", late)
+	}
+}
+
+func TestGetTransactionChangesComparesInstants(t *testing.T) {
+	setupTransactionChangesTestDB(t)
+	defer CleanupTestDB()
+
+	// Stored with a local offset: 10:00 at UTC-5 is 15:00 UTC
+	eastern := time.FixedZone("EST", -5*60*60)
+	updatedAt := time.Date(2024, time.May, 1, 10, 0, 0, 0, eastern)
+	_, err := database.DB.Exec(`
+		INSERT INTO transactions (id, amount, description, date, transaction_date, type, enteredBy, userId, updated_at)
+		VALUES ('tx-1', 10, 'Test', ?, ?, 'Groceries', 'test-user', ?, ?)
+	`, updatedAt, updatedAt, TestUserID, updatedAt)
+	if err != nil {
+		t.Fatalf("Failed to insert transaction: %v", err)
+	}
+
+	if changes := getTransactionChanges(t, time.Date(2024, time.May, 1, 14, 30, 0, 0, time.UTC)); len(changes.Changes) != 1 {
+		t.Errorf("Expected the change at 15:00 UTC after 14:30 UTC, got %+v", changes.Changes)
+	}
+	if changes := getTransactionChanges(t, time.Date(2024, time.May, 1, 15, 30, 0, 0, time.UTC)); len(changes.Changes) != 0 {
+		t.Errorf("Expected no change after 15:30 UTC, got %+v", changes.Changes)
+	}
+}
+
+func TestDeletedTransactionIsHidden(t *testing.T) {
+	setupTransactionCategoryTestDB()
+	defer CleanupTestDB()
+
+	insertTestTransaction(t, "tx-1", 10, time.Now(), TestUserID)
+	database.DB.Exec("INSERT INTO categories (id, name, user_id) VALUES (1, 'Groceries', ?)", TestUserID)
+	database.DB.Exec("INSERT INTO transaction_categories (transaction_id, category_id, amount) VALUES ('tx-1', 1, 10)")
+	database.DB.Exec("INSERT INTO transaction_tags (transaction_id, tag) VALUES ('tx-1', 'trip')")
+
+	req := TestRequest("DELETE", "/transactions/tx-1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "tx-1"})
+	w := httptest.NewRecorder()
+	DeleteTransaction(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	req = TestRequest("GET", "/transactions/tx-1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "tx-1"})
+	w = httptest.NewRecorder()
+	GetTransaction(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d for a deleted transaction, got %d", http.StatusNotFound, w.Code)
+	}
+
+	// Its category links and tags go with it, as with every other deletion
+	var links, tags int
+	database.DB.QueryRow("SELECT COUNT(*) FROM transaction_categories WHERE transaction_id = 'tx-1'").Scan(&links)
+	database.DB.QueryRow("SELECT COUNT(*) FROM transaction_tags WHERE transaction_id = 'tx-1'").Scan(&tags)
+	if links != 0 || tags != 0 {
+		t.Errorf("Expected the category links and tags to be removed, got %d links and %d tags", links, tags)
+	}
+
+	// Deleting again finds nothing
+	req = TestRequest("DELETE", "/transactions/tx-1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "tx-1"})
+	w = httptest.NewRecorder()
+	DeleteTransaction(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d deleting twice, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestGetTransactionChangesRequiresSince(t *testing.T) {
+	setupTransactionChangesTestDB(t)
+	defer CleanupTestDB()
+
+	for _, url := range []string{"/transactions/changes", "/transactions/changes?since=yesterday", "/transactions/changes?cursor=-1"} {
+		req := TestRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+		GetTransactionChanges(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d for %s, got %d", http.StatusBadRequest, url, w.Code)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+
+	"bennwallet/backend/models"
+)
+
+// defaultHomeCurrency is the currency transaction amounts are recorded in.
+// Override with HOME_CURRENCY.
+const defaultHomeCurrency = "USD"
+
+// homeCurrency returns the configured home currency code
+func homeCurrency() string {
+	if value := strings.ToUpper(strings.TrimSpace(os.Getenv("HOME_CURRENCY"))); value != "" {
+		return value
+	}
+	return defaultHomeCurrency
+}
+
+// normalizeOriginalAmount checks that a transaction's original amount and
+// currency are given together and uppercases the currency code. Recording an
+// original amount in the home currency is rejected since amount already holds it.
+func normalizeOriginalAmount(t *models.Transaction) error {
+	t.OriginalCurrency = strings.ToUpper(strings.TrimSpace(t.OriginalCurrency))
+
+	if t.OriginalAmount == nil && t.OriginalCurrency == "" {
+		return nil
+	}
+	if t.OriginalAmount == nil || t.OriginalCurrency == "" {
+		return fmt.Errorf("originalAmount and originalCurrency must be given together")
+	}
+	if len(t.OriginalCurrency) != 3 || strings.Trim(t.OriginalCurrency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return fmt.Errorf("invalid originalCurrency %q (expected a 3-letter code such as EUR)", t.OriginalCurrency)
+	}
+	if t.OriginalCurrency == homeCurrency() {
+		return fmt.Errorf("originalCurrency %s is the home currency; set amount only", t.OriginalCurrency)
+	}
+	return nil
+}
+
+// applyOriginalAmount copies scanned original amount columns onto t
+func applyOriginalAmount(t *models.Transaction, amount sql.NullFloat64, currency sql.NullString) {
+	if amount.Valid {
+		t.OriginalAmount = &amount.Float64
+	}
+	t.OriginalCurrency = currency.String
+}
+
+// originalAmountArgs returns the original amount and currency as query
+// arguments, NULL when not set
+func originalAmountArgs(t models.Transaction) []interface{} {
+	if t.OriginalAmount == nil {
+		return []interface{}{nil, nil}
+	}
+	return []interface{}{*t.OriginalAmount, t.OriginalCurrency}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+func getTestTransaction(t *testing.T, id string) models.Transaction {
+	req := TestRequest("GET", "/transactions/"+id, nil)
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+	GetTransaction(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var tx models.Transaction
+	if err := json.NewDecoder(w.Body).Decode(&tx); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	return tx
+}
+
+func TestOriginalAmountRoundTrip(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	body := `{"amount": 54, "description": "Dinner in Paris", "type": "Dining", "originalAmount": 50, "originalCurrency": "eur"}`
+	req := TestRequest("POST", "/transactions", &body)
+	w := httptest.NewRecorder()
+	AddTransaction(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var created models.Transaction
+	json.NewDecoder(w.Body).Decode(&created)
+
+	got := getTestTransaction(t, created.ID)
+	if got.Amount != 54 || got.OriginalAmount == nil || *got.OriginalAmount != 50 || got.OriginalCurrency != "EUR" {
+		t.Errorf("Expected 54 home and 50 EUR original, got %v and %v %s", got.Amount, got.OriginalAmount, got.OriginalCurrency)
+	}
+
+	req = TestRequest("GET", "/transactions", nil)
+	w = httptest.NewRecorder()
+	GetTransactions(w, req)
+	var listed []models.Transaction
+	json.NewDecoder(w.Body).Decode(&listed)
+	if len(listed) != 1 || listed[0].OriginalAmount == nil || *listed[0].OriginalAmount != 50 || listed[0].OriginalCurrency != "EUR" {
+		t.Errorf("Expected the original amount in the listing, got %+v", listed)
+	}
+
+	// Updating without the pair clears it
+	body = `{"amount": 54, "description": "Dinner in Paris", "type": "Dining", "date": "` + created.Date.Format(time.RFC3339) + `"}`
+	req = TestRequest("PUT", "/transactions/"+created.ID, &body)
+	req = mux.SetURLVars(req, map[string]string{"id": created.ID})
+	w = httptest.NewRecorder()
+	UpdateTransaction(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if got := getTestTransaction(t, created.ID); got.OriginalAmount != nil || got.OriginalCurrency != "" {
+		t.Errorf("Expected the original amount to be cleared, got %v %s", got.OriginalAmount, got.OriginalCurrency)
+	}
+}
+
+func TestOriginalAmountValidation(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+	t.Setenv("HOME_CURRENCY", "usd")
+
+	testCases := []struct {
+		name     string
+		original string
+	}{
+		{"amount without currency", `"originalAmount": 50`},
+		{"currency without amount", `"originalCurrency": "EUR"`},
+		{"invalid currency", `"originalAmount": 50, "originalCurrency": "EURO"`},
+		{"home currency", `"originalAmount": 50, "originalCurrency": "USD"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body := `{"amount": 54, "description": "Test", "type": "Dining", ` + tc.original + `}`
+			req := TestRequest("POST", "/transactions", &body)
+			w := httptest.NewRecorder()
+			AddTransaction(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+
+	if count := countTransactions(t); count != 0 {
+		t.Errorf("Expected no transactions to be stored, found %d", count)
+	}
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"bennwallet/backend/models"
+)
+
+// Defaults for the window of accepted transaction dates. Override with
+// TRANSACTION_DATE_MIN (YYYY-MM-DD) and TRANSACTION_DATE_MAX_FUTURE_DAYS.
+const (
+	defaultMinTransactionDate       = "1970-01-01"
+	defaultMaxTransactionFutureDays = 365
+)
+
+// transactionDateWindow returns the earliest and latest acceptable transaction dates
+func transactionDateWindow(now time.Time) (time.Time, time.Time) {
+	minDate, _ := time.Parse(dateLayout, defaultMinTransactionDate)
+	if value := os.Getenv("TRANSACTION_DATE_MIN"); value != "" {
+		if parsed, err := time.Parse(dateLayout, value); err == nil {
+			minDate = parsed
+		} else {
+			log.Printf("Warning: ignoring invalid TRANSACTION_DATE_MIN %q", value)
+		}
+	}
+
+	futureDays := defaultMaxTransactionFutureDays
+	if value := os.Getenv("TRANSACTION_DATE_MAX_FUTURE_DAYS"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed >= 0 {
+			futureDays = parsed
+		} else {
+			log.Printf("Warning: ignoring invalid TRANSACTION_DATE_MAX_FUTURE_DAYS %q", value)
+		}
+	}
+
+	return minDate, now.AddDate(0, 0, futureDays)
+}
+
+// validateTransactionDate rejects dates outside the sane window, such as the
+// zero year or far-future dates produced by bad imports
+func validateTransactionDate(field string, date time.Time, now time.Time) error {
+	minDate, maxDate := transactionDateWindow(now)
+	if date.Before(minDate) {
+		return fmt.Errorf("%s %s is before the earliest allowed date %s", field, date.Format(dateLayout), minDate.Format(dateLayout))
+	}
+	if date.After(maxDate) {
+		return fmt.Errorf("%s %s is after the latest allowed date %s", field, date.Format(dateLayout), maxDate.Format(dateLayout))
+	}
+	return nil
+}
+
+// validateTransactionDates checks both the entered and transaction dates of t
+func validateTransactionDates(t models.Transaction, now time.Time) error {
+	if err := validateTransactionDate("date", t.Date, now); err != nil {
+		return err
+	}
+	return validateTransactionDate("transactionDate", t.TransactionDate, now)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+
+	"github.com/gorilla/mux"
+)
+
+func TestAddTransactionDateValidation(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	farFuture := time.Now().AddDate(2, 0, 0).Format(time.RFC3339)
+	recent := time.Now().AddDate(0, 0, -3).Format(time.RFC3339)
+
+	testCases := []struct {
+		name         string
+		body         string
+		expectedCode int
+	}{
+		{"too old", `{"amount": 1, "description": "Old", "type": "Test", "date": "0001-01-01T00:00:00Z", "transactionDate": "0001-01-01T00:00:00Z"}`, http.StatusBadRequest},
+		{"too old transaction date", `{"amount": 1, "description": "Old", "type": "Test", "date": "` + recent + `", "transactionDate": "1969-12-31T00:00:00Z"}`, http.StatusBadRequest},
+		{"too far in the future", `{"amount": 1, "description": "Future", "type": "Test", "date": "` + farFuture + `"}`, http.StatusBadRequest},
+		{"valid", `{"amount": 1, "description": "Valid", "type": "Test", "date": "` + recent + `"}`, http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := TestRequest("POST", "/transactions", &tc.body)
+			w := httptest.NewRecorder()
+
+			AddTransaction(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("Expected status code %d, got %d: %s", tc.expectedCode, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	var count int
+	database.DB.QueryRow("SELECT COUNT(*) FROM transactions").Scan(&count)
+	if count != 1 {
+		t.Errorf("Expected only the valid transaction to be stored, got %d", count)
+	}
+}
+
+func TestUpdateTransactionRejectsAbsurdDate(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	_, err := database.DB.Exec(`
+		INSERT INTO transactions (id, amount, description, date, type, payTo, paid, paidDate, enteredBy, optional, userId)
+		VALUES ('tx-date', 10, 'Test', ?, 'Test', '', 0, '', 'test-user', 0, ?)
+	`, time.Now(), TestUserID)
+	if err != nil {
+		t.Fatalf("Failed to insert transaction: %v", err)
+	}
+
+	body := `{"amount": 10, "description": "Test", "type": "Test", "date": "3024-01-01T00:00:00Z"}`
+	req := TestRequest("PUT", "/transactions/tx-date", &body)
+	req = mux.SetURLVars(req, map[string]string{"id": "tx-date"})
+	w := httptest.NewRecorder()
+
+	UpdateTransaction(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestTransactionDateWindowConfigurable(t *testing.T) {
+	t.Setenv("TRANSACTION_DATE_MIN", "2000-01-01")
+	t.Setenv("TRANSACTION_DATE_MAX_FUTURE_DAYS", "30")
+
+	now := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	if err := validateTransactionDate("date", time.Date(1999, time.December, 31, 0, 0, 0, 0, time.UTC), now); err == nil {
+		t.Error("Expected a date before TRANSACTION_DATE_MIN to be rejected")
+	}
+	if err := validateTransactionDate("date", now.AddDate(0, 0, 31), now); err == nil {
+		t.Error("Expected a date beyond TRANSACTION_DATE_MAX_FUTURE_DAYS to be rejected")
+	}
+	if err := validateTransactionDate("date", now.AddDate(0, 0, 30), now); err != nil {
+		t.Errorf("Expected a date within the window to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// findDuplicateTransactions groups the user's own transactions that share an
+// amount, payee and day. The first transaction of each group is the one to
+// keep: the one with the most category links, then the earliest entered.
+func findDuplicateTransactions(q rowsQuerier, userID string) ([]models.DuplicateGroup, error) {
+	rows, err := q.Query(`
+		SELECT t.id, t.amount, COALESCE(t.payTo, ''), substr(t.date, 1, 10) AS day
+		FROM transactions t
+		WHERE t.userId = ? AND t.deleted_at IS NULL
+		ORDER BY day, t.amount, COALESCE(t.payTo, ''),
+			(SELECT COUNT(*) FROM transaction_categories tc WHERE tc.transaction_id = t.id) DESC,
+			t.date, t.id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []models.DuplicateGroup{}
+	var current *models.DuplicateGroup
+	for rows.Next() {
+		var id, payTo, day string
+		var amount float64
+		if err := rows.Scan(&id, &amount, &payTo, &day); err != nil {
+			return nil, err
+		}
+
+		if current != nil && current.Amount == models.Amount(amount) && current.PayTo == payTo && current.Date == day {
+			current.TransactionIDs = append(current.TransactionIDs, id)
+			continue
+		}
+		if current != nil && len(current.TransactionIDs) > 1 {
+			groups = append(groups, *current)
+		}
+		current = &models.DuplicateGroup{Amount: models.Amount(amount), PayTo: payTo, Date: day, TransactionIDs: []string{id}, KeptID: id}
+	}
+	if current != nil && len(current.TransactionIDs) > 1 {
+		groups = append(groups, *current)
+	}
+	return groups, rows.Err()
+}
+
+// DedupeTransactions finds groups of the user's own transactions with the same
+// amount, payee and day. With ?apply=true one transaction per group is kept
+// (the one with category links, if any) and the others are deleted. Locked
+// duplicates are kept and listed as skipped.
+func DedupeTransactions(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	apply := r.URL.Query().Get("apply") == "true"
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	groups, err := findDuplicateTransactions(tx, userID)
+	if err != nil {
+		log.Printf("Error finding duplicate transactions: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := models.DedupeResult{Groups: groups, Applied: apply}
+	if apply {
+		var duplicates []string
+		for _, group := range groups {
+			duplicates = append(duplicates, group.TransactionIDs[1:]...)
+		}
+		locked, err := lockedTransactionIDs(tx, duplicates)
+		if err != nil {
+			log.Printf("Error checking locked transactions: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		now := time.Now()
+		for _, group := range groups {
+			for _, id := range group.TransactionIDs[1:] {
+				if locked[id] {
+					result.Skipped = append(result.Skipped, id)
+					continue
+				}
+				if _, err := softDeleteTransaction(tx, id, now); err != nil {
+					log.Printf("Error deleting duplicate transaction %s: %v", id, err)
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				result.Deleted++
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Printf("Removed %d duplicate transactions in %d groups for user %s", result.Deleted, len(groups), userID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
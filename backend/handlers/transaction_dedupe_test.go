@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+func seedDuplicateTransactions(t *testing.T) {
+	morning := time.Date(2024, time.May, 1, 9, 0, 0, 0, time.UTC)
+	evening := time.Date(2024, time.May, 1, 18, 0, 0, 0, time.UTC)
+
+	for _, tx := range []struct {
+		id     string
+		amount float64
+		payTo  string
+		date   time.Time
+		userID string
+	}{
+		{"dup-a", 25, "Sarah", morning, TestUserID},
+		{"dup-b", 25, "Sarah", evening, TestUserID},
+		{"dup-c", 25, "Sarah", morning, TestUserID},
+		{"other-amount", 26, "Sarah", morning, TestUserID},
+		{"other-payee", 25, "Patrick", morning, TestUserID},
+		{"other-day", 25, "Sarah", morning.AddDate(0, 0, 1), TestUserID},
+		{"other-user", 25, "Sarah", morning, "other-user"},
+	} {
+		_, err := database.DB.Exec(`
+			INSERT INTO transactions (id, amount, description, date, transaction_date, type, payTo, enteredBy, userId)
+			VALUES (?, ?, 'Imported', ?, ?, 'Food', ?, 'import', ?)
+		`, tx.id, tx.amount, tx.date, tx.date, tx.payTo, tx.userID)
+		if err != nil {
+			t.Fatalf("Failed to insert transaction: %v", err)
+		}
+	}
+
+	// The later duplicate is the only categorized one, so it should be kept
+	database.DB.Exec("INSERT INTO categories (id, name, user_id) VALUES (1, 'Food', ?)", TestUserID)
+	database.DB.Exec("INSERT INTO transaction_categories (transaction_id, category_id, amount) VALUES ('dup-b', 1, 25)")
+}
+
+func dedupe(t *testing.T, url string) models.DedupeResult {
+	req := TestRequest("POST", url, nil)
+	w := httptest.NewRecorder()
+	DedupeTransactions(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var result models.DedupeResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	return result
+}
+
+func TestDedupeTransactionsDetectsGroups(t *testing.T) {
+	setupTransactionCategoryTestDB()
+	defer CleanupTestDB()
+	seedDuplicateTransactions(t)
+
+	result := dedupe(t, "/transactions/dedupe")
+
+	if result.Applied || result.Deleted != 0 {
+		t.Errorf("Expected a dry run, got %+v", result)
+	}
+	if len(result.Groups) != 1 {
+		t.Fatalf("Expected 1 duplicate group, got %d: %+v", len(result.Groups), result.Groups)
+	}
+	group := result.Groups[0]
+	if len(group.TransactionIDs) != 3 || group.KeptID != "dup-b" || group.Date != "2024-05-01" {
+		t.Errorf("Unexpected duplicate group: %+v", group)
+	}
+	if count := countTransactions(t); count != 7 {
+		t.Errorf("Expected a dry run to keep all 7 transactions, got %d", count)
+	}
+}
+
+func TestDedupeTransactionsApply(t *testing.T) {
+	setupTransactionCategoryTestDB()
+	defer CleanupTestDB()
+	seedDuplicateTransactions(t)
+
+	result := dedupe(t, "/transactions/dedupe?apply=true")
+
+	if !result.Applied || result.Deleted != 2 {
+		t.Errorf("Expected 2 duplicates to be deleted, got %+v", result)
+	}
+
+	var remaining int
+	database.DB.QueryRow("SELECT COUNT(*) FROM transactions WHERE id IN ('dup-a', 'dup-b', 'dup-c') AND deleted_at IS NULL").Scan(&remaining)
+	if remaining != 1 {
+		t.Errorf("Expected exactly one transaction of the group to remain, got %d", remaining)
+	}
+
+	var links int
+	database.DB.QueryRow("SELECT COUNT(*) FROM transaction_categories WHERE transaction_id = 'dup-b'").Scan(&links)
+	if links != 1 {
+		t.Errorf("Expected the kept transaction to keep its category link, got %d links", links)
+	}
+
+	if count := countTransactions(t); count != 5 {
+		t.Errorf("Expected 5 transactions after dedupe, got %d", count)
+	}
+	if again := dedupe(t, "/transactions/dedupe"); len(again.Groups) != 0 {
+		t.Errorf("Expected no duplicate groups after applying, got %+v", again.Groups)
+	}
+}
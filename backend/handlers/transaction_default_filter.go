@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"bennwallet/backend/database"
+)
+
+// transactionFilterParams returns the names of the query parameters
+// GetTransactions filters on, as listed in the filter schema
+func transactionFilterParams() map[string]bool {
+	params := map[string]bool{}
+	for _, field := range transactionFilterSchema() {
+		for _, param := range field.Params {
+			params[param] = true
+		}
+	}
+	return params
+}
+
+// transactionFilters returns the filters GetTransactions applies. These are
+// the request's query parameters, unless ?applyDefault=true is set and none
+// of them is a filter: then the user's default saved filter is used, and its
+// ID returned. A default filter config is a JSON object keyed by the filter
+// parameters; other keys are ignored. On failure it also returns the HTTP
+// status to respond with.
+func transactionFilters(r *http.Request, userID string) (url.Values, string, int, error) {
+	query := r.URL.Query()
+
+	applyDefault := false
+	if value := query.Get("applyDefault"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, "", http.StatusBadRequest, fmt.Errorf("Invalid applyDefault: expected true or false")
+		}
+		applyDefault = parsed
+	}
+	if !applyDefault {
+		return query, "", http.StatusOK, nil
+	}
+
+	// Explicit filters always win over the default
+	params := transactionFilterParams()
+	for param := range params {
+		if query.Get(param) != "" {
+			return query, "", http.StatusOK, nil
+		}
+	}
+
+	var id, config string
+	err := database.DB.QueryRow(`
+		SELECT id, filter_config FROM saved_filters
+		WHERE user_id = ? AND is_default = 1
+		ORDER BY updated_at DESC, id
+		LIMIT 1
+	`, userID).Scan(&id, &config)
+	if err == sql.ErrNoRows {
+		return query, "", http.StatusOK, nil
+	} else if err != nil {
+		log.Printf("Error getting default filter of user %s: %v", userID, err)
+		return nil, "", http.StatusInternalServerError, fmt.Errorf("Error loading default filter")
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(config), &values); err != nil || values == nil {
+		log.Printf("Invalid config in default filter %s: %v", id, err)
+		return nil, "", http.StatusInternalServerError, fmt.Errorf("Default filter %s is not a valid filter config", id)
+	}
+
+	filters := url.Values{}
+	for key, value := range query {
+		filters[key] = value
+	}
+	for key, value := range values {
+		if !params[key] {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			filters.Set(key, v)
+		case bool:
+			filters.Set(key, strconv.FormatBool(v))
+		case float64:
+			filters.Set(key, strconv.FormatFloat(v, 'f', -1, 64))
+		case nil:
+			// Left unset
+		default:
+			return nil, "", http.StatusInternalServerError, fmt.Errorf("Default filter %s has an invalid %s", id, key)
+		}
+	}
+	return filters, id, http.StatusOK, nil
+}
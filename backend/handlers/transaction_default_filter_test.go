@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+func listTransactionDescriptions(t *testing.T, url string, expectedStatus int) ([]string, string) {
+	t.Helper()
+
+	req := TestRequest("GET", url, nil)
+	w := httptest.NewRecorder()
+	GetTransactions(w, req)
+	if w.Code != expectedStatus {
+		t.Fatalf("Expected status code %d, got %d: %s", expectedStatus, w.Code, w.Body.String())
+	}
+
+	var descriptions []string
+	if expectedStatus == http.StatusOK {
+		var transactions []models.Transaction
+		if err := json.NewDecoder(w.Body).Decode(&transactions); err != nil {
+			t.Fatalf("Error decoding response: %v", err)
+		}
+		for _, tx := range transactions {
+			descriptions = append(descriptions, tx.Description)
+		}
+		sort.Strings(descriptions)
+	}
+	return descriptions, w.Header().Get("X-Default-Filter")
+}
+
+func TestGetTransactionsApplyDefaultFilter(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+	createSavedReportTables()
+
+	addTestTransaction(t, `{"amount": 12, "description": "Coffee", "payTo": "Cafe", "type": "Dining", "paid": true}`)
+	addTestTransaction(t, `{"amount": 80, "description": "Dinner", "payTo": "Bistro", "type": "Dining", "paid": false}`)
+	addTestTransaction(t, `{"amount": 150, "description": "Groceries", "payTo": "Market", "type": "Groceries", "paid": true}`)
+
+	_, err := database.DB.Exec(`
+		INSERT INTO saved_filters (id, user_id, name, filter_config, is_default) VALUES
+		('unpaid', ?, 'Unpaid', '{"paid": false}', 0),
+		('paid-small', ?, 'Paid under 100', '{"paid": true, "maxAmount": 100, "columns": ["payTo"]}', 1),
+		('theirs', 'other-user', 'Theirs', '{"payTo": "Market"}', 1)
+	`, TestUserID, TestUserID)
+	if err != nil {
+		t.Fatalf("Failed to seed saved filters: %v", err)
+	}
+
+	assertDescriptions := func(label string, got []string, expected ...string) {
+		t.Helper()
+		if len(got) != len(expected) {
+			t.Fatalf("%s: expected %v, got %v", label, expected, got)
+		}
+		for i := range expected {
+			if got[i] != expected[i] {
+				t.Errorf("%s: expected %v, got %v", label, expected, got)
+				return
+			}
+		}
+	}
+
+	// The default is only applied when asked for
+	descriptions, applied := listTransactionDescriptions(t, "/transactions", http.StatusOK)
+	assertDescriptions("without applyDefault", descriptions, "Coffee", "Dinner", "Groceries")
+	if applied != "" {
+		t.Errorf("Expected no default filter applied, got %s", applied)
+	}
+
+	// The user's own default filter's conditions apply; unknown keys are ignored
+	descriptions, applied = listTransactionDescriptions(t, "/transactions?applyDefault=true", http.StatusOK)
+	assertDescriptions("applyDefault", descriptions, "Coffee")
+	if applied != "paid-small" {
+		t.Errorf("Expected the paid-small filter to be applied, got %q", applied)
+	}
+
+	// Explicit filters replace the default entirely
+	descriptions, applied = listTransactionDescriptions(t, "/transactions?applyDefault=true&payTo=Bistro", http.StatusOK)
+	assertDescriptions("explicit filter", descriptions, "Dinner")
+	if applied != "" {
+		t.Errorf("Expected the default filter to be ignored, got %s", applied)
+	}
+
+	// Without a default filter nothing is filtered
+	database.DB.Exec("UPDATE saved_filters SET is_default = 0 WHERE user_id = ?", TestUserID)
+	descriptions, _ = listTransactionDescriptions(t, "/transactions?applyDefault=true", http.StatusOK)
+	assertDescriptions("no default", descriptions, "Coffee", "Dinner", "Groceries")
+
+	listTransactionDescriptions(t, "/transactions?applyDefault=maybe", http.StatusBadRequest)
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultMaxDescriptionLength caps transaction descriptions, in characters.
+// Override with TRANSACTION_DESCRIPTION_MAX_LENGTH.
+const defaultMaxDescriptionLength = 255
+
+// maxDescriptionLength returns the configured description length limit
+func maxDescriptionLength() int {
+	if value := os.Getenv("TRANSACTION_DESCRIPTION_MAX_LENGTH"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			return parsed
+		}
+		log.Printf("Warning: ignoring invalid TRANSACTION_DESCRIPTION_MAX_LENGTH %q", value)
+	}
+	return defaultMaxDescriptionLength
+}
+
+// normalizeDescription trims surrounding whitespace from a transaction
+// description and rejects descriptions longer than the configured limit
+func normalizeDescription(description string) (string, error) {
+	description = strings.TrimSpace(description)
+	if limit := maxDescriptionLength(); utf8.RuneCountInString(description) > limit {
+		return "", fmt.Errorf("description is longer than %d characters", limit)
+	}
+	return description, nil
+}
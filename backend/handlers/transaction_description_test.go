@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+func TestTransactionDescriptionTrimmed(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	body := `{"amount": 12, "description": "  Coffee beans \n", "type": "Groceries"}`
+	req := TestRequest("POST", "/transactions", &body)
+	w := httptest.NewRecorder()
+	AddTransaction(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var created models.Transaction
+	json.NewDecoder(w.Body).Decode(&created)
+
+	if got := getTestTransaction(t, created.ID); got.Description != "Coffee beans" {
+		t.Errorf("Expected the description to be trimmed, got %q", got.Description)
+	}
+
+	body = `{"amount": 12, "description": "\tGround coffee  ", "type": "Groceries", "date": "` + created.Date.Format(time.RFC3339) + `"}`
+	req = TestRequest("PUT", "/transactions/"+created.ID, &body)
+	req = mux.SetURLVars(req, map[string]string{"id": created.ID})
+	w = httptest.NewRecorder()
+	UpdateTransaction(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	if got := getTestTransaction(t, created.ID); got.Description != "Ground coffee" {
+		t.Errorf("Expected the updated description to be trimmed, got %q", got.Description)
+	}
+}
+
+func TestTransactionDescriptionTooLong(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+	t.Setenv("TRANSACTION_DESCRIPTION_MAX_LENGTH", "10")
+
+	// Surrounding whitespace doesn't count towards the limit
+	body := `{"amount": 12, "description": "  0123456789  ", "type": "Groceries"}`
+	req := TestRequest("POST", "/transactions", &body)
+	w := httptest.NewRecorder()
+	AddTransaction(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected a 10 character description to be accepted, got %d: %s", w.Code, w.Body.String())
+	}
+
+	body = `{"amount": 12, "description": "01234567890", "type": "Groceries"}`
+	req = TestRequest("POST", "/transactions", &body)
+	w = httptest.NewRecorder()
+	AddTransaction(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an 11 character description, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestMaxDescriptionLengthDefault(t *testing.T) {
+	t.Setenv("TRANSACTION_DESCRIPTION_MAX_LENGTH", "not-a-number")
+	if got := maxDescriptionLength(); got != defaultMaxDescriptionLength {
+		t.Errorf("Expected invalid values to fall back to %d, got %d", defaultMaxDescriptionLength, got)
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// transactionFilterSchema describes the filters GetTransactions supports.
+// Keep it in step with the query parameters GetTransactions reads.
+func transactionFilterSchema() []models.TransactionFilterField {
+	shortcuts := make([]string, 0, len(dateRangeShortcuts))
+	for name := range dateRangeShortcuts {
+		shortcuts = append(shortcuts, name)
+	}
+	sort.Strings(shortcuts)
+
+	return []models.TransactionFilterField{
+		{
+			Field:     "payTo",
+			Type:      "string",
+			Operators: []string{"contains"},
+			Params:    map[string]string{"contains": "payTo"},
+		},
+		{
+			Field:     "enteredBy",
+			Type:      "string",
+			Operators: []string{"contains", "isMe"},
+			Params:    map[string]string{"contains": "enteredBy", "isMe": "enteredByMe"},
+		},
+		{
+			Field:     "paid",
+			Type:      "boolean",
+			Operators: []string{"eq"},
+			Params:    map[string]string{"eq": "paid"},
+		},
+		{
+			Field:     "source",
+			Type:      "enum",
+			Operators: []string{"eq"},
+			Params:    map[string]string{"eq": "source"},
+			Values:    models.TransactionSources,
+		},
+		{
+			Field:     "status",
+			Type:      "enum",
+			Operators: []string{"eq"},
+			Params:    map[string]string{"eq": "status"},
+			Values:    models.TransactionStatuses,
+		},
+		{
+			Field:     "amount",
+			Type:      "number",
+			Operators: []string{"gte", "lte"},
+			Params:    map[string]string{"gte": "minAmount", "lte": "maxAmount"},
+		},
+		{
+			Field:     "date",
+			Type:      "date",
+			Operators: []string{"gte", "lte", "range"},
+			Params:    map[string]string{"gte": "startDate", "lte": "endDate", "range": "range"},
+			Values:    shortcuts,
+		},
+	}
+}
+
+// GetTransactionFilterSchema returns the filterable transaction fields so
+// filter builders don't have to hardcode them
+func GetTransactionFilterSchema(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transactionFilterSchema())
+}
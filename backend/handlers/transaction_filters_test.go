@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bennwallet/backend/models"
+)
+
+func TestGetTransactionFilterSchema(t *testing.T) {
+	SetupTestDB()
+	defer CleanupTestDB()
+
+	req := TestRequest("GET", "/transactions/filter-schema", nil)
+	w := httptest.NewRecorder()
+	GetTransactionFilterSchema(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var schema []models.TransactionFilterField
+	if err := json.NewDecoder(w.Body).Decode(&schema); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	fields := map[string]models.TransactionFilterField{}
+	for _, field := range schema {
+		fields[field.Field] = field
+	}
+
+	expected := map[string]struct {
+		fieldType string
+		params    []string
+	}{
+		"payTo":     {"string", []string{"payTo"}},
+		"enteredBy": {"string", []string{"enteredBy", "enteredByMe"}},
+		"paid":      {"boolean", []string{"paid"}},
+		"amount":    {"number", []string{"minAmount", "maxAmount"}},
+		"date":      {"date", []string{"startDate", "endDate", "range"}},
+	}
+	for name, want := range expected {
+		field, ok := fields[name]
+		if !ok {
+			t.Errorf("Expected filter %s in the schema", name)
+			continue
+		}
+		if field.Type != want.fieldType {
+			t.Errorf("Expected %s to be a %s filter, got %s", name, want.fieldType, field.Type)
+		}
+
+		params := map[string]bool{}
+		for _, operator := range field.Operators {
+			params[field.Params[operator]] = true
+		}
+		for _, param := range want.params {
+			if !params[param] {
+				t.Errorf("Expected %s to be filterable with ?%s", name, param)
+			}
+		}
+	}
+
+	// Every advertised date range shortcut must be accepted by GetTransactions
+	for _, shortcut := range fields["date"].Values {
+		if _, ok := dateRangeShortcuts[shortcut]; !ok {
+			t.Errorf("Schema lists unsupported range %q", shortcut)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+// rowQuerier is satisfied by both *sql.DB and *sql.Tx
+type rowQuerier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// rowsQuerier is satisfied by both *sql.DB and *sql.Tx
+type rowsQuerier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// loadTransactionSnapshot reads the full stored state of a transaction
+func loadTransactionSnapshot(q rowQuerier, id string) (models.Transaction, error) {
+	var t models.Transaction
+	var payTo, paidDate, userId sql.NullString
+	var transactionDate sql.NullTime
+	err := q.QueryRow(`
+		SELECT id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional, userId
+		FROM transactions
+		WHERE id = ?
+	`, id).Scan(&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate, &t.Type, &payTo,
+		&t.Paid, &paidDate, &t.EnteredBy, &t.Optional, &userId)
+	if err != nil {
+		return t, err
+	}
+	t.PayTo = payTo.String
+	t.PaidDate = paidDate.String
+	t.UserID = userId.String
+	if transactionDate.Valid {
+		t.TransactionDate = transactionDate.Time
+	}
+	return t, nil
+}
+
+// changedTransactionFields returns the JSON names of the fields that differ
+// between two snapshots of a transaction
+func changedTransactionFields(before, after models.Transaction) []string {
+	var beforeFields, afterFields map[string]interface{}
+	beforeJSON, _ := json.Marshal(before)
+	afterJSON, _ := json.Marshal(after)
+	json.Unmarshal(beforeJSON, &beforeFields)
+	json.Unmarshal(afterJSON, &afterFields)
+
+	changed := []string{}
+	for name, value := range afterFields {
+		if !reflect.DeepEqual(beforeFields[name], value) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range beforeFields {
+		if _, ok := afterFields[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// recordTransactionHistory stores a before/after snapshot of an edit. Edits
+// that don't change any field are not recorded.
+func recordTransactionHistory(tx *sql.Tx, before, after models.Transaction, actor string) error {
+	changed := changedTransactionFields(before, after)
+	if len(changed) == 0 {
+		return nil
+	}
+
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO transaction_history (transaction_id, changed_by, changed_at, changed_fields, before_snapshot, after_snapshot)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, after.ID, actor, time.Now().UTC(), strings.Join(changed, ","), string(beforeJSON), string(afterJSON))
+	return err
+}
+
+// GetTransactionHistory returns the recorded edits of a transaction, newest first
+func GetTransactionHistory(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+
+	// Only users who can see the transaction can see its history
+	var ownerID sql.NullString
+	err := database.DB.QueryRow("SELECT userId FROM transactions WHERE id = ?", id).Scan(&ownerID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error getting transaction owner: %v", err)
+		http.Error(w, "Error checking transaction access", http.StatusInternalServerError)
+		return
+	}
+	if ownerID.Valid && ownerID.String != userID &&
+		!middleware.CheckUserPermission(userID, ownerID.String, models.ResourceTransactions, models.PermissionRead) {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT id, transaction_id, changed_by, changed_at, changed_fields, before_snapshot, after_snapshot
+		FROM transaction_history
+		WHERE transaction_id = ?
+		ORDER BY changed_at DESC, id DESC
+	`, id)
+	if err != nil {
+		log.Printf("Error querying transaction history: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	history := []models.TransactionHistoryEntry{}
+	for rows.Next() {
+		var entry models.TransactionHistoryEntry
+		var changedFields, before, after string
+		if err := rows.Scan(&entry.ID, &entry.TransactionID, &entry.ChangedBy, &entry.ChangedAt,
+			&changedFields, &before, &after); err != nil {
+			log.Printf("Error scanning transaction history: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		entry.ChangedFields = strings.Split(changedFields, ",")
+		if err := json.Unmarshal([]byte(before), &entry.Before); err != nil {
+			log.Printf("Error decoding history snapshot %d: %v", entry.ID, err)
+		}
+		if err := json.Unmarshal([]byte(after), &entry.After); err != nil {
+			log.Printf("Error decoding history snapshot %d: %v", entry.ID, err)
+		}
+		history = append(history, entry)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
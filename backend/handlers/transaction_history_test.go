@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+func TestUpdateTransactionRecordsHistory(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	date := time.Date(2024, time.April, 2, 0, 0, 0, 0, time.UTC)
+	_, err := database.DB.Exec(`
+		INSERT INTO transactions (id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional, userId)
+		VALUES ('tx-history', 25, 'Groceries', ?, ?, 'Food', 'Sarah', 0, '', 'Patrick', 0, ?)
+	`, date, date, TestUserID)
+	if err != nil {
+		t.Fatalf("Failed to insert transaction: %v", err)
+	}
+
+	update := models.Transaction{
+		Amount:          30,
+		Description:     "Groceries",
+		Date:            date,
+		TransactionDate: date,
+		Type:            "Food",
+		PayTo:           "Sarah",
+		Paid:            true,
+		PaidDate:        "2024-04-03",
+		EnteredBy:       "Patrick",
+	}
+	body, _ := json.Marshal(update)
+	bodyStr := string(body)
+
+	req := TestRequest("PUT", "/transactions/tx-history", &bodyStr)
+	req = mux.SetURLVars(req, map[string]string{"id": "tx-history"})
+	w := httptest.NewRecorder()
+	UpdateTransaction(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	req = TestRequest("GET", "/transactions/tx-history/history", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "tx-history"})
+	w = httptest.NewRecorder()
+	GetTransactionHistory(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var history []models.TransactionHistoryEntry
+	if err := json.NewDecoder(w.Body).Decode(&history); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("Expected 1 history entry, got %d", len(history))
+	}
+
+	entry := history[0]
+	if entry.ChangedBy != TestUserID {
+		t.Errorf("Expected change by %s, got %s", TestUserID, entry.ChangedBy)
+	}
+	expectedFields := []string{"amount", "paid", "paidDate"}
+	if len(entry.ChangedFields) != len(expectedFields) {
+		t.Fatalf("Expected changed fields %v, got %v", expectedFields, entry.ChangedFields)
+	}
+	for i, field := range expectedFields {
+		if entry.ChangedFields[i] != field {
+			t.Errorf("Expected changed fields %v, got %v", expectedFields, entry.ChangedFields)
+			break
+		}
+	}
+	if entry.Before.Amount != 25 || entry.After.Amount != 30 {
+		t.Errorf("Expected amount 25 -> 30, got %v -> %v", entry.Before.Amount, entry.After.Amount)
+	}
+	if entry.Before.Paid || !entry.After.Paid {
+		t.Errorf("Expected paid false -> true, got %v -> %v", entry.Before.Paid, entry.After.Paid)
+	}
+
+	// Saving again without changes doesn't add an entry
+	req = TestRequest("PUT", "/transactions/tx-history", &bodyStr)
+	req = mux.SetURLVars(req, map[string]string{"id": "tx-history"})
+	w = httptest.NewRecorder()
+	UpdateTransaction(w, req)
+
+	var count int
+	database.DB.QueryRow("SELECT COUNT(*) FROM transaction_history WHERE transaction_id = 'tx-history'").Scan(&count)
+	if count != 1 {
+		t.Errorf("Expected no-op update to leave 1 history entry, got %d", count)
+	}
+}
+
+func TestUpdateTransactionFailsWithoutHistory(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	// A stored value that can't be read leaves nothing to record the change against
+	date := time.Date(2024, time.April, 2, 0, 0, 0, 0, time.UTC)
+	_, err := database.DB.Exec(`
+		INSERT INTO transactions (id, amount, description, date, type, payTo, paid, enteredBy, optional, userId)
+		VALUES ('tx-unreadable', 25, 'Groceries', ?, 'Food', 'Sarah', 'maybe', 'Patrick', 0, ?)
+	`, date, TestUserID)
+	if err != nil {
+		t.Fatalf("Failed to insert transaction: %v", err)
+	}
+
+	body, _ := json.Marshal(models.Transaction{Amount: 30, Description: "Groceries", Date: date, Type: "Food", EnteredBy: "Patrick"})
+	bodyStr := string(body)
+	req := TestRequest("PUT", "/transactions/tx-unreadable", &bodyStr)
+	req = mux.SetURLVars(req, map[string]string{"id": "tx-unreadable"})
+	w := httptest.NewRecorder()
+	UpdateTransaction(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusInternalServerError, w.Code, w.Body.String())
+	}
+	var amount float64
+	if err := database.DB.QueryRow("SELECT amount FROM transactions WHERE id = 'tx-unreadable'").Scan(&amount); err != nil {
+		t.Fatalf("Failed to read transaction: %v", err)
+	}
+	if amount != 25 {
+		t.Errorf("Expected the update to be rolled back, got amount %v", amount)
+	}
+}
+
+func TestGetTransactionHistoryNotFound(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	req := TestRequest("GET", "/transactions/missing/history", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "missing"})
+	w := httptest.NewRecorder()
+	GetTransactionHistory(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
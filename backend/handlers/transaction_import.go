@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+// ImportTransactions creates a batch of transactions for the user. Every
+// transaction is validated before any is stored, and all of them are tagged
+// with a new batch ID so the import can be rolled back as a whole. A CSV body
+// (Content-Type text/csv) is imported row by row instead; see
+// importCSVTransactions.
+func ImportTransactions(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "text/csv" {
+		importCSVTransactions(w, r, userID)
+		return
+	}
+
+	var transactions []models.Transaction
+	if err := json.NewDecoder(r.Body).Decode(&transactions); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(transactions) == 0 {
+		http.Error(w, "No transactions to import", http.StatusBadRequest)
+		return
+	}
+
+	batchID := generateID()
+	now := time.Now()
+	for i := range transactions {
+		if err := prepareImportedTransaction(&transactions[i], userID, batchID, now); err != nil {
+			http.Error(w, fmt.Sprintf("Transaction %d: %v", i+1, err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := insertImportedTransactions(transactions, now); err != nil {
+		log.Printf("Error importing transactions: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Imported %d transactions for user %s in batch %s", len(transactions), userID, batchID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.ImportResult{BatchID: batchID, Imported: len(transactions), Errors: []models.ImportRowError{}})
+}
+
+// prepareImportedTransaction validates a transaction to import and fills in
+// its defaults, ID, owner and batch
+func prepareImportedTransaction(t *models.Transaction, userID, batchID string, now time.Time) error {
+	var err error
+	t.Type, err = normalizeTransactionType(t.Type)
+	if err != nil {
+		return err
+	}
+	t.Description, err = normalizeDescription(t.Description)
+	if err != nil {
+		return err
+	}
+	t.Status, err = normalizeTransactionStatus(t.Status)
+	if err != nil {
+		return err
+	}
+
+	if t.Date.IsZero() {
+		t.Date = now
+	}
+	if t.TransactionDate.IsZero() {
+		t.TransactionDate = t.Date
+	}
+	if err := validateTransactionDates(*t, now); err != nil {
+		return err
+	}
+	if err := normalizeOriginalAmount(t); err != nil {
+		return err
+	}
+
+	t.ID = generateID()
+	t.UserID = userID
+	t.Source = models.TransactionSourceImport
+	t.ImportBatchID = batchID
+	if t.EnteredBy == "" {
+		t.EnteredBy = userID
+	}
+	return nil
+}
+
+// insertImportedTransactions stores prepared transactions in one database
+// transaction, so either all of them are stored or none are
+func insertImportedTransactions(transactions []models.Transaction, now time.Time) error {
+	return execWithRetry(func() error {
+		tx, err := database.DB.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		for _, t := range transactions {
+			_, err := tx.Exec(`
+				INSERT INTO transactions (id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional, userId, source, import_batch_id, updated_at,
+					status, original_amount, original_currency)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`, append([]interface{}{t.ID, t.Amount, t.Description, t.Date, t.TransactionDate, t.Type, t.PayTo, t.Paid, t.PaidDate, t.EnteredBy,
+				t.Optional, t.UserID, t.Source, t.ImportBatchID, now, t.Status}, originalAmountArgs(t)...)...)
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Commit()
+	})
+}
+
+// RollbackImport deletes every transaction the user imported in a batch,
+// along with their category links and tags. Locked transactions are kept and
+// listed as skipped.
+func RollbackImport(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	batchID := mux.Vars(r)["batchId"]
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT id FROM transactions WHERE import_batch_id = ? AND userId = ? AND deleted_at IS NULL", batchID, userID)
+	if err != nil {
+		log.Printf("Error querying import batch %s: %v", batchID, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	if len(ids) == 0 {
+		http.Error(w, "Import batch not found", http.StatusNotFound)
+		return
+	}
+
+	locked, err := lockedTransactionIDs(tx, ids)
+	if err != nil {
+		log.Printf("Error checking locked transactions: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := models.RollbackResult{BatchID: batchID}
+	now := time.Now()
+	for _, id := range ids {
+		if locked[id] {
+			result.Skipped = append(result.Skipped, id)
+			continue
+		}
+		if _, err := softDeleteTransaction(tx, id, now); err != nil {
+			log.Printf("Error deleting imported transaction %s: %v", id, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result.Deleted++
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Rolled back import batch %s for user %s (%d transactions, %d locked)", batchID, userID, result.Deleted, len(result.Skipped))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bennwallet/backend/models"
+)
+
+// csvImportColumns are the columns a CSV import reads, matched against the
+// header row regardless of case or order. Other columns are ignored.
+var csvImportColumns = []string{"amount", "description", "date", "type", "payTo", "paid", "optional"}
+
+// csvImportRequiredColumns must be in the header row of a CSV import
+var csvImportRequiredColumns = []string{"amount", "description", "type"}
+
+// csvImportRow is a transaction read from a row of a CSV import
+type csvImportRow struct {
+	line        int
+	transaction models.Transaction
+}
+
+// importCSVTransactions imports the rows of a CSV body for the user, such as
+// a spreadsheet export. The header row names the columns; dates are
+// YYYY-MM-DD, and an empty date is today like in the JSON import. Malformed
+// or invalid rows are skipped and listed in the result while the rest are
+// imported in one batch, so only a database failure aborts the import.
+func importCSVTransactions(w http.ResponseWriter, r *http.Request, userID string) {
+	rows, result, err := parseCSVTransactions(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	batchID := generateID()
+	now := time.Now()
+	var transactions []models.Transaction
+	for _, row := range rows {
+		if err := prepareImportedTransaction(&row.transaction, userID, batchID, now); err != nil {
+			result.Errors = append(result.Errors, models.ImportRowError{Line: row.line, Message: err.Error()})
+			continue
+		}
+		transactions = append(transactions, row.transaction)
+	}
+	sort.SliceStable(result.Errors, func(i, j int) bool { return result.Errors[i].Line < result.Errors[j].Line })
+	result.Skipped = len(result.Errors)
+
+	if len(transactions) > 0 {
+		if err := insertImportedTransactions(transactions, now); err != nil {
+			log.Printf("Error importing CSV transactions: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result.BatchID = batchID
+		result.Imported = len(transactions)
+	}
+
+	log.Printf("Imported %d CSV transactions for user %s in batch %s, skipped %d", result.Imported, userID, batchID, result.Skipped)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// parseCSVTransactions reads the transactions of a CSV import. Rows that
+// can't be read are returned as errors in the result; an error is returned
+// only when the CSV as a whole is unusable.
+func parseCSVTransactions(body io.Reader) ([]csvImportRow, models.ImportResult, error) {
+	result := models.ImportResult{Errors: []models.ImportRowError{}}
+
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, result, errors.New("CSV is empty")
+	} else if err != nil {
+		return nil, result, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		for _, column := range csvImportColumns {
+			if strings.EqualFold(strings.TrimSpace(name), column) {
+				columns[column] = i
+			}
+		}
+	}
+	for _, column := range csvImportRequiredColumns {
+		if _, ok := columns[column]; !ok {
+			return nil, result, fmt.Errorf("CSV header is missing the %s column", column)
+		}
+	}
+
+	var rows []csvImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			result.Errors = append(result.Errors, models.ImportRowError{Line: parseErr.StartLine, Message: parseErr.Err.Error()})
+			continue
+		} else if err != nil {
+			return nil, result, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		t, err := csvRowTransaction(record, columns)
+		if err != nil {
+			result.Errors = append(result.Errors, models.ImportRowError{Line: line, Message: err.Error()})
+			continue
+		}
+		rows = append(rows, csvImportRow{line: line, transaction: t})
+	}
+	return rows, result, nil
+}
+
+// csvRowTransaction reads a transaction from a CSV record
+func csvRowTransaction(record []string, columns map[string]int) (models.Transaction, error) {
+	field := func(column string) string {
+		if i, ok := columns[column]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	t := models.Transaction{Description: field("description"), Type: field("type"), PayTo: field("payTo")}
+	if t.Description == "" || t.Type == "" {
+		return t, errors.New("description and type are required")
+	}
+
+	amount, err := strconv.ParseFloat(field("amount"), 64)
+	if err != nil {
+		return t, fmt.Errorf("invalid amount %q", field("amount"))
+	}
+	t.Amount = models.Amount(amount)
+	if value := field("date"); value != "" {
+		if t.Date, err = time.Parse(dateLayout, value); err != nil {
+			return t, fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", value)
+		}
+	}
+	for column, target := range map[string]*bool{"paid": &t.Paid, "optional": &t.Optional} {
+		if value := field(column); value != "" {
+			if *target, err = strconv.ParseBool(value); err != nil {
+				return t, fmt.Errorf("invalid %s %q (expected true or false)", column, value)
+			}
+		}
+	}
+	return t, nil
+}
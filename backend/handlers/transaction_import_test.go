@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+func importTransactions(t *testing.T, body string) models.ImportResult {
+	req := TestRequest("POST", "/transactions/import", &body)
+	w := httptest.NewRecorder()
+	ImportTransactions(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var result models.ImportResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	return result
+}
+
+func rollbackImport(batchID string) *httptest.ResponseRecorder {
+	req := TestRequest("POST", "/transactions/import/"+batchID+"/rollback", nil)
+	req = mux.SetURLVars(req, map[string]string{"batchId": batchID})
+	w := httptest.NewRecorder()
+	RollbackImport(w, req)
+	return w
+}
+
+func TestImportTransactionsTagsBatch(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	result := importTransactions(t, `[
+		{"amount": 10, "description": "Bread", "type": "Groceries"},
+		{"amount": 20, "description": "Milk", "type": "Groceries", "date": "2024-05-01T00:00:00Z"}
+	]`)
+	if result.BatchID == "" || result.Imported != 2 {
+		t.Fatalf("Unexpected import result: %+v", result)
+	}
+
+	var count int
+	database.DB.QueryRow(`
+		SELECT COUNT(*) FROM transactions
+		WHERE import_batch_id = ? AND source = ? AND userId = ?
+	`, result.BatchID, models.TransactionSourceImport, TestUserID).Scan(&count)
+	if count != 2 {
+		t.Errorf("Expected 2 imported transactions in the batch, got %d", count)
+	}
+}
+
+func TestImportTransactionsRejectsInvalidRow(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	body := `[
+		{"amount": 10, "description": "Bread", "type": "Groceries"},
+		{"amount": 20, "description": "Milk", "type": "Groceries", "date": "0001-01-01T00:00:00Z", "transactionDate": "1900-01-01T00:00:00Z"}
+	]`
+	req := TestRequest("POST", "/transactions/import", &body)
+	w := httptest.NewRecorder()
+	ImportTransactions(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	if count := countTransactions(t); count != 0 {
+		t.Errorf("Expected nothing to be imported, found %d transactions", count)
+	}
+}
+
+func importCSV(t *testing.T, body string) *httptest.ResponseRecorder {
+	req := TestRequest("POST", "/transactions/import", &body)
+	req.Header.Set("Content-Type", "text/csv; charset=utf-8")
+	w := httptest.NewRecorder()
+	ImportTransactions(w, req)
+	return w
+}
+
+func TestImportTransactionsCSV(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	w := importCSV(t, `Date,Amount,Description,Type,PayTo,Paid,Optional,Notes
+2024-05-01,12.50,Bread,Groceries,Corner Shop,true,false,weekly
+2024-05-02,abc,Milk,Groceries,Corner Shop,false,false,
+2024-05-03,30,"Dinner, out",Dining,,false,true,
+05/04/2024,8,Eggs,Groceries,,,,
+2024-05-05,9,Cheese,Groceries
+2024-05-06,4,,Groceries,,,,
+,5,Apples,Groceries,,no,,
+`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var result models.ImportResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	if result.BatchID == "" || result.Imported != 2 || result.Skipped != 5 {
+		t.Fatalf("Expected 2 rows imported and 5 skipped, got %+v", result)
+	}
+	expectedLines := []int{3, 5, 6, 7, 8}
+	for i, rowErr := range result.Errors {
+		if i >= len(expectedLines) || rowErr.Line != expectedLines[i] || rowErr.Message == "" {
+			t.Errorf("Expected errors on lines %v, got %+v", expectedLines, result.Errors)
+			break
+		}
+	}
+
+	var count int
+	database.DB.QueryRow(`
+		SELECT COUNT(*) FROM transactions
+		WHERE import_batch_id = ? AND source = ? AND userId = ?
+	`, result.BatchID, models.TransactionSourceImport, TestUserID).Scan(&count)
+	if count != 2 {
+		t.Errorf("Expected 2 imported transactions owned by the caller, got %d", count)
+	}
+
+	var description, payTo string
+	var amount float64
+	var paid, optional bool
+	err := database.DB.QueryRow("SELECT description, amount, COALESCE(payTo, ''), paid, optional FROM transactions WHERE description LIKE 'Dinner%'").
+		Scan(&description, &amount, &payTo, &paid, &optional)
+	if err != nil || description != "Dinner, out" || amount != 30 || payTo != "" || paid || !optional {
+		t.Errorf("Unexpected imported row: %q %v %q paid=%v optional=%v (%v)", description, amount, payTo, paid, optional, err)
+	}
+}
+
+func TestImportTransactionsCSVMissingColumn(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	if w := importCSV(t, "date,description,type\n2024-05-01,Bread,Groceries\n"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestImportTransactionsCSVRollsBackOnDatabaseFailure(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	_, err := database.DB.Exec(`
+		CREATE TRIGGER fail_import BEFORE INSERT ON transactions WHEN NEW.description = 'Boom'
+		BEGIN SELECT RAISE(ABORT, 'boom'); END
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create trigger: %v", err)
+	}
+
+	w := importCSV(t, "amount,description,type\n10,Bread,Groceries\n20,Boom,Groceries\n")
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+	if count := countTransactions(t); count != 0 {
+		t.Errorf("Expected nothing to be imported, found %d transactions", count)
+	}
+}
+
+func TestRollbackImport(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	insertTestTransaction(t, "manual", 5, time.Now(), TestUserID)
+	first := importTransactions(t, `[
+		{"amount": 10, "description": "Bread", "type": "Groceries"},
+		{"amount": 20, "description": "Milk", "type": "Groceries"}
+	]`)
+	second := importTransactions(t, `[{"amount": 30, "description": "Eggs", "type": "Groceries"}]`)
+
+	var taggedID string
+	database.DB.QueryRow("SELECT id FROM transactions WHERE import_batch_id = ? LIMIT 1", first.BatchID).Scan(&taggedID)
+	database.DB.Exec("INSERT INTO transaction_tags (transaction_id, tag) VALUES (?, 'bad-import')", taggedID)
+
+	w := rollbackImport(first.BatchID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var result models.RollbackResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if result.Deleted != 2 {
+		t.Errorf("Expected 2 transactions deleted, got %d", result.Deleted)
+	}
+
+	rows, err := database.DB.Query("SELECT id, COALESCE(import_batch_id, '') FROM transactions WHERE deleted_at IS NULL")
+	if err != nil {
+		t.Fatalf("Error listing transactions: %v", err)
+	}
+	defer rows.Close()
+	remaining := 0
+	for rows.Next() {
+		var id, batchID string
+		rows.Scan(&id, &batchID)
+		if id != "manual" && batchID != second.BatchID {
+			t.Errorf("Unexpected transaction %s (batch %q) left after rollback", id, batchID)
+		}
+		remaining++
+	}
+	if remaining != 2 {
+		t.Errorf("Expected the manual and second batch transactions to remain, got %d", remaining)
+	}
+
+	var tags int
+	database.DB.QueryRow("SELECT COUNT(*) FROM transaction_tags").Scan(&tags)
+	if tags != 0 {
+		t.Errorf("Expected tags of rolled back transactions to be removed, found %d", tags)
+	}
+
+	// The batch is gone, so a second rollback finds nothing
+	if w := rollbackImport(first.BatchID); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d for a rolled back batch, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestRollbackImportOfAnotherUser(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	_, err := database.DB.Exec(`
+		INSERT INTO transactions (id, amount, description, date, type, enteredBy, userId, source, import_batch_id)
+		VALUES ('theirs', 10, 'Test', ?, 'Groceries', 'other-user', 'other-user', 'import', 'their-batch')
+	`, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to insert transaction: %v", err)
+	}
+
+	if w := rollbackImport("their-batch"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, w.Code)
+	}
+	if count := countTransactions(t); count != 1 {
+		t.Errorf("Expected the other user's transaction to remain, found %d transactions", count)
+	}
+}
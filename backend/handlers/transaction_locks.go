@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// checkTransactionLock returns an error, with the HTTP status to respond
+// with, when a transaction is locked. Admins can still modify locked
+// transactions by passing ?override=true.
+func checkTransactionLock(r *http.Request, userID, id string) (int, error) {
+	if !transactionsHaveColumn("locked") {
+		return http.StatusOK, nil
+	}
+
+	var locked bool
+	err := database.DB.QueryRow("SELECT locked FROM transactions WHERE id = ?", id).Scan(&locked)
+	if err == sql.ErrNoRows || (err == nil && !locked) {
+		return http.StatusOK, nil
+	} else if err != nil {
+		log.Printf("Error checking whether transaction %s is locked: %v", id, err)
+		return http.StatusInternalServerError, fmt.Errorf("Error checking transaction lock")
+	}
+
+	if r.URL.Query().Get("override") != "true" {
+		return http.StatusConflict, fmt.Errorf("Transaction is locked")
+	}
+	isAdmin, err := middleware.IsUserAdmin(userID)
+	if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("Failed to check user permissions: %v", err)
+	}
+	if !isAdmin {
+		return http.StatusForbidden, fmt.Errorf("Only admins can override a transaction lock")
+	}
+	log.Printf("Admin %s overriding the lock on transaction %s", userID, id)
+	return http.StatusOK, nil
+}
+
+// lockedTransactionIDs returns which of the given transactions are locked.
+// Bulk writes leave locked transactions alone and report them as skipped.
+func lockedTransactionIDs(q rowsQuerier, ids []string) (map[string]bool, error) {
+	locked := map[string]bool{}
+	if len(ids) == 0 {
+		return locked, nil
+	}
+
+	// Without the locked column nothing is locked
+	rows, err := q.Query("SELECT name FROM pragma_table_info('transactions') WHERE name = 'locked'")
+	if err != nil {
+		return nil, err
+	}
+	hasLocked := rows.Next()
+	rows.Close()
+	if !hasLocked {
+		return locked, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	rows, err = q.Query("SELECT id FROM transactions WHERE locked AND id IN ("+strings.Join(placeholders, ",")+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		locked[id] = true
+	}
+	return locked, rows.Err()
+}
+
+// LockTransactionMonth locks the user's own transactions in a month, e.g.
+// once it has been settled, so they can't be edited or deleted
+func LockTransactionMonth(w http.ResponseWriter, r *http.Request) {
+	setTransactionMonthLocked(w, r, true)
+}
+
+// UnlockTransactionMonth unlocks the user's own transactions in a month
+func UnlockTransactionMonth(w http.ResponseWriter, r *http.Request) {
+	setTransactionMonthLocked(w, r, false)
+}
+
+// setTransactionMonthLocked sets the locked flag on the user's own
+// transactions whose transaction date (or date, without one) falls in the
+// requested month
+func setTransactionMonthLocked(w http.ResponseWriter, r *http.Request, locked bool) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	var request models.LockMonthRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if _, err := time.Parse("2006-01", request.Month); err != nil {
+		http.Error(w, "Invalid month: expected YYYY-MM", http.StatusBadRequest)
+		return
+	}
+
+	result, err := database.DB.Exec(`
+		UPDATE transactions SET locked = ?, updated_at = ?
+		WHERE userId = ? AND deleted_at IS NULL AND locked != ?
+		AND substr(COALESCE(transaction_date, date), 1, 7) = ?
+	`, locked, time.Now(), userID, locked, request.Month)
+	if err != nil {
+		log.Printf("Error setting locked flag: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	updated, _ := result.RowsAffected()
+
+	log.Printf("Set locked=%v on %d transactions in %s for user %s", locked, updated, request.Month, userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"month":   request.Month,
+		"locked":  locked,
+		"updated": updated,
+	})
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+func insertLockTestTransaction(t *testing.T, id string, amount float64, date time.Time) {
+	t.Helper()
+
+	_, err := database.DB.Exec(`
+		INSERT INTO transactions (id, amount, description, date, transaction_date, type, payTo, enteredBy, userId)
+		VALUES (?, ?, 'Test', ?, ?, 'Groceries', '', ?, ?)
+	`, id, amount, date, date, TestUserID, TestUserID)
+	if err != nil {
+		t.Fatalf("Failed to insert transaction: %v", err)
+	}
+}
+
+func setMonthLocked(t *testing.T, handler http.HandlerFunc, month string) int64 {
+	t.Helper()
+
+	body := `{"month": "` + month + `"}`
+	req := TestRequest("POST", "/transactions/lock-month", &body)
+	w := httptest.NewRecorder()
+	handler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var result struct {
+		Updated int64 `json:"updated"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	return result.Updated
+}
+
+func updateLockTestTransaction(id, url string) *httptest.ResponseRecorder {
+	body := `{"amount": 99, "description": "Edited", "type": "Groceries", "date": "2024-03-05T00:00:00Z"}`
+	req := TestRequest("PUT", url, &body)
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+	UpdateTransaction(w, req)
+	return w
+}
+
+func TestLockedTransactionCannotBeEdited(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	march := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+	insertLockTestTransaction(t, "tx-march", 40, march)
+	insertLockTestTransaction(t, "tx-april", 15, march.AddDate(0, 1, 0))
+
+	if updated := setMonthLocked(t, LockTransactionMonth, "2024-03"); updated != 1 {
+		t.Fatalf("Expected 1 transaction locked, got %d", updated)
+	}
+	if !getTestTransaction(t, "tx-march").Locked || getTestTransaction(t, "tx-april").Locked {
+		t.Fatalf("Expected only the March transaction to be locked")
+	}
+
+	if w := updateLockTestTransaction("tx-march", "/transactions/tx-march"); w.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d editing a locked transaction, got %d", http.StatusConflict, w.Code)
+	}
+
+	req := TestRequest("DELETE", "/transactions/tx-march", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "tx-march"})
+	w := httptest.NewRecorder()
+	DeleteTransaction(w, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d deleting a locked transaction, got %d", http.StatusConflict, w.Code)
+	}
+	if got := getTestTransaction(t, "tx-march"); got.Amount != 40 {
+		t.Errorf("Expected the locked transaction to be unchanged, got amount %v", got.Amount)
+	}
+
+	// Other months are unaffected
+	if w := updateLockTestTransaction("tx-april", "/transactions/tx-april"); w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d editing an unlocked transaction, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// Unlocking re-enables edits
+	if updated := setMonthLocked(t, UnlockTransactionMonth, "2024-03"); updated != 1 {
+		t.Fatalf("Expected 1 transaction unlocked, got %d", updated)
+	}
+	if w := updateLockTestTransaction("tx-march", "/transactions/tx-march"); w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d after unlocking, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if got := getTestTransaction(t, "tx-march"); got.Amount != 99 || got.Locked {
+		t.Errorf("Expected an unlocked transaction with amount 99, got %+v", got)
+	}
+}
+
+func TestLockedTransactionAdminOverride(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	insertLockTestTransaction(t, "tx-march", 40, time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC))
+	setMonthLocked(t, LockTransactionMonth, "2024-03")
+
+	// Admins can override the lock
+	if w := updateLockTestTransaction("tx-march", "/transactions/tx-march?override=true"); w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d with an admin override, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if got := getTestTransaction(t, "tx-march"); got.Amount != 99 || !got.Locked {
+		t.Errorf("Expected the edit to keep the transaction locked, got %+v", got)
+	}
+
+	// Everyone else can't
+	if _, err := database.DB.Exec("UPDATE users SET isAdmin = 0 WHERE id = ?", TestUserID); err != nil {
+		t.Fatalf("Error demoting test user: %v", err)
+	}
+	if w := updateLockTestTransaction("tx-march", "/transactions/tx-march?override=true"); w.Code != http.StatusForbidden {
+		t.Errorf("Expected status code %d overriding as a non-admin, got %d", http.StatusForbidden, w.Code)
+	}
+}
+
+func TestLockTransactionMonthInvalidMonth(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	body := `{"month": "March"}`
+	req := TestRequest("POST", "/transactions/lock-month", &body)
+	w := httptest.NewRecorder()
+	LockTransactionMonth(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func lockTestTransactions(t *testing.T, ids ...string) {
+	t.Helper()
+
+	for _, id := range ids {
+		if _, err := database.DB.Exec("UPDATE transactions SET locked = 1 WHERE id = ?", id); err != nil {
+			t.Fatalf("Failed to lock transaction %s: %v", id, err)
+		}
+	}
+}
+
+func isDeleted(t *testing.T, id string) bool {
+	t.Helper()
+
+	var deleted bool
+	if err := database.DB.QueryRow("SELECT deleted_at IS NOT NULL FROM transactions WHERE id = ?", id).Scan(&deleted); err != nil {
+		t.Fatalf("Failed to read transaction %s: %v", id, err)
+	}
+	return deleted
+}
+
+func TestDedupeApplyKeepsLockedTransactions(t *testing.T) {
+	setupTransactionCategoryTestDB()
+	defer CleanupTestDB()
+	seedDuplicateTransactions(t)
+	lockTestTransactions(t, "dup-a")
+
+	result := dedupe(t, "/transactions/dedupe?apply=true")
+
+	if result.Deleted != 1 || len(result.Skipped) != 1 || result.Skipped[0] != "dup-a" {
+		t.Errorf("Expected dup-c deleted and dup-a skipped, got %+v", result)
+	}
+	if isDeleted(t, "dup-a") || !isDeleted(t, "dup-c") {
+		t.Errorf("Expected only the unlocked duplicate to be deleted")
+	}
+}
+
+func TestRollbackImportKeepsLockedTransactions(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	batch := importTransactions(t, `[
+		{"amount": 10, "description": "Bread", "type": "Groceries"},
+		{"amount": 20, "description": "Milk", "type": "Groceries"}
+	]`)
+	var lockedID string
+	database.DB.QueryRow("SELECT id FROM transactions WHERE description = 'Bread'").Scan(&lockedID)
+	lockTestTransactions(t, lockedID)
+
+	w := rollbackImport(batch.BatchID)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var result models.RollbackResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if result.Deleted != 1 || len(result.Skipped) != 1 || result.Skipped[0] != lockedID {
+		t.Errorf("Expected 1 transaction deleted and the locked one skipped, got %+v", result)
+	}
+	if isDeleted(t, lockedID) {
+		t.Errorf("Expected the locked transaction to be kept")
+	}
+}
+
+func TestBulkWritesSkipLockedTransactions(t *testing.T) {
+	setupTransactionCategoryTestDB()
+	defer CleanupTestDB()
+
+	insertTestTransaction(t, "tx-open", 10, time.Now(), TestUserID)
+	insertTestTransaction(t, "tx-locked", 20, time.Now(), TestUserID)
+	insertTestTransaction(t, "tx-theirs-locked", 30, time.Now(), "other-user")
+	lockTestTransactions(t, "tx-locked", "tx-theirs-locked")
+
+	var response struct {
+		Updated int      `json:"updated"`
+		Skipped []string `json:"skipped"`
+	}
+
+	// Another user's locked transaction isn't reported, which would leak its lock
+	body := `{"ids": ["tx-open", "tx-locked", "tx-theirs-locked"], "optional": true}`
+	req := TestRequest("POST", "/transactions/bulk-optional", &body)
+	w := httptest.NewRecorder()
+	BulkSetTransactionsOptional(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if response.Updated != 1 || len(response.Skipped) != 1 || response.Skipped[0] != "tx-locked" {
+		t.Errorf("Expected 1 transaction updated and tx-locked skipped, got %+v", response)
+	}
+	if !transactionOptional(t, "tx-open") || transactionOptional(t, "tx-locked") || transactionOptional(t, "tx-theirs-locked") {
+		t.Errorf("Expected only the unlocked transaction to be set optional")
+	}
+
+	body = `{"ids": ["tx-open", "tx-locked"], "tags": ["trip"]}`
+	req = TestRequest("POST", "/transactions/tag", &body)
+	w = httptest.NewRecorder()
+	BulkTagTransactions(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if response.Updated != 1 || len(response.Skipped) != 1 || response.Skipped[0] != "tx-locked" {
+		t.Errorf("Expected 1 transaction tagged and tx-locked skipped, got %+v", response)
+	}
+	if tags := transactionTags(t, "tx-locked"); len(tags) != 0 {
+		t.Errorf("Expected the locked transaction to stay untagged, got %v", tags)
+	}
+
+	// Assigning categories is refused like any other edit
+	database.DB.Exec("INSERT INTO categories (id, name, user_id) VALUES (1, 'Food', ?)", TestUserID)
+	body = `[{"categoryId": 1}]`
+	req = TestRequest("PUT", "/transactions/tx-locked/categories", &body)
+	req = mux.SetURLVars(req, map[string]string{"id": "tx-locked"})
+	w = httptest.NewRecorder()
+	SetTransactionCategories(w, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d assigning categories to a locked transaction, got %d", http.StatusConflict, w.Code)
+	}
+	var links int
+	database.DB.QueryRow("SELECT COUNT(*) FROM transaction_categories WHERE transaction_id = 'tx-locked'").Scan(&links)
+	if links != 0 {
+		t.Errorf("Expected no categories on the locked transaction, got %d", links)
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+)
+
+// GetTransactionMonths returns the distinct months (YYYY-MM) of the
+// transaction dates of the accessible transactions, newest first, for month
+// pickers. Transactions without a transaction date fall back to their date.
+func GetTransactionMonths(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	accessClause, args := accessibleTransactionsClause(userID)
+	rows, err := database.ReadDB().Query(`
+		SELECT DISTINCT substr(COALESCE(transaction_date, date), 1, 7) AS month
+		FROM transactions
+		WHERE deleted_at IS NULL
+	`+accessClause+" ORDER BY month DESC", args...)
+	if err != nil {
+		log.Printf("Error querying transaction months: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	months := []string{}
+	for rows.Next() {
+		var month string
+		if err := rows.Scan(&month); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		months = append(months, month)
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(months)
+}
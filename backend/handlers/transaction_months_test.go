@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+)
+
+func TestGetTransactionMonths(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	day := func(value string) time.Time {
+		parsed, _ := time.Parse(dateLayout, value)
+		return parsed
+	}
+	insertTestTransaction(t, "tx-jan", 10, day("2024-01-15"), TestUserID)
+	insertTestTransaction(t, "tx-mar-1", 20, day("2024-03-02"), TestUserID)
+	insertTestTransaction(t, "tx-mar-2", 30, day("2024-03-28"), TestUserID)
+	insertTestTransaction(t, "tx-dec", 40, day("2023-12-31"), TestUserID)
+	insertTestTransaction(t, "tx-other", 50, day("2024-02-10"), "other-user")
+	insertTestTransaction(t, "tx-deleted", 60, day("2022-05-05"), TestUserID)
+	insertTestTransaction(t, "tx-no-transaction-date", 70, day("2023-07-04"), TestUserID)
+
+	for _, stmt := range []string{
+		"UPDATE transactions SET deleted_at = CURRENT_TIMESTAMP WHERE id = 'tx-deleted'",
+		"UPDATE transactions SET transaction_date = NULL WHERE id = 'tx-no-transaction-date'",
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to update test data: %v", err)
+		}
+	}
+
+	req := TestRequest("GET", "/transactions/months", nil)
+	w := httptest.NewRecorder()
+	GetTransactionMonths(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var months []string
+	if err := json.NewDecoder(w.Body).Decode(&months); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	expected := []string{"2024-03", "2024-01", "2023-12", "2023-07"}
+	if !reflect.DeepEqual(months, expected) {
+		t.Errorf("Expected months %v, got %v", expected, months)
+	}
+}
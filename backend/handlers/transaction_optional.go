@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// BulkSetTransactionsOptional sets the optional flag on several of the user's
+// own transactions in a single statement. IDs of transactions the user
+// doesn't own are skipped; the response counts the transactions updated and
+// lists the locked ones left unchanged.
+func BulkSetTransactionsOptional(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	var request models.BulkOptionalRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(request.IDs) == 0 {
+		http.Error(w, "ids is required", http.StatusBadRequest)
+		return
+	}
+	if request.Optional == nil {
+		http.Error(w, "optional is required", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// Only the user's own transactions are updated, so only their lock state
+	// is reported; other users' IDs are dropped like unknown ones
+	placeholders := make([]string, len(request.IDs))
+	args := []interface{}{userID}
+	for i, id := range request.IDs {
+		placeholders[i] = "?"
+		args = append(args, id)
+	}
+	rows, err := tx.Query(`
+		SELECT id FROM transactions
+		WHERE userId = ? AND deleted_at IS NULL AND id IN (`+strings.Join(placeholders, ",")+`)
+	`, args...)
+	if err != nil {
+		log.Printf("Error checking transaction ownership: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var owned []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		owned = append(owned, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	locked, err := lockedTransactionIDs(tx, owned)
+	if err != nil {
+		log.Printf("Error checking locked transactions: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	placeholders = nil
+	skipped := []string{}
+	args = []interface{}{*request.Optional, time.Now(), userID}
+	for _, id := range owned {
+		if locked[id] {
+			skipped = append(skipped, id)
+			continue
+		}
+		placeholders = append(placeholders, "?")
+		args = append(args, id)
+	}
+
+	var updated int64
+	if len(placeholders) > 0 {
+		result, err := tx.Exec(`
+			UPDATE transactions SET optional = ?, updated_at = ?
+			WHERE userId = ? AND deleted_at IS NULL AND id IN (`+strings.Join(placeholders, ",")+`)
+		`, args...)
+		if err != nil {
+			log.Printf("Error updating optional flag: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		updated, _ = result.RowsAffected()
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Set optional=%v on %d of %d transactions for user %s", *request.Optional, updated, len(request.IDs), userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"updated":  updated,
+		"optional": *request.Optional,
+		"skipped":  skipped,
+	})
+}
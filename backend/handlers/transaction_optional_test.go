@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+)
+
+func transactionOptional(t *testing.T, id string) bool {
+	var optional bool
+	if err := database.DB.QueryRow("SELECT optional FROM transactions WHERE id = ?", id).Scan(&optional); err != nil {
+		t.Fatalf("Failed to read optional flag of %s: %v", id, err)
+	}
+	return optional
+}
+
+func TestBulkSetTransactionsOptionalOnlyOwned(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	insertTestTransaction(t, "tx-1", 10, time.Now(), TestUserID)
+	insertTestTransaction(t, "tx-2", 20, time.Now(), TestUserID)
+	insertTestTransaction(t, "tx-3", 30, time.Now(), TestUserID)
+	insertTestTransaction(t, "tx-other", 40, time.Now(), "other-user")
+
+	body := `{"ids": ["tx-1", "tx-other", "tx-2", "tx-missing"], "optional": true}`
+	req := TestRequest("POST", "/transactions/bulk-optional", &body)
+	w := httptest.NewRecorder()
+	BulkSetTransactionsOptional(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Updated  int  `json:"updated"`
+		Optional bool `json:"optional"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Updated != 2 || !response.Optional {
+		t.Errorf("Expected 2 transactions set optional, got %+v", response)
+	}
+
+	for id, want := range map[string]bool{"tx-1": true, "tx-2": true, "tx-3": false, "tx-other": false} {
+		if got := transactionOptional(t, id); got != want {
+			t.Errorf("Expected %s optional=%v, got %v", id, want, got)
+		}
+	}
+
+	// Clearing the flag works the same way
+	body = `{"ids": ["tx-1", "tx-other"], "optional": false}`
+	req = TestRequest("POST", "/transactions/bulk-optional", &body)
+	w = httptest.NewRecorder()
+	BulkSetTransactionsOptional(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if transactionOptional(t, "tx-1") || !transactionOptional(t, "tx-2") {
+		t.Error("Expected only tx-1 to be cleared")
+	}
+}
+
+func TestBulkSetTransactionsOptionalValidation(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	for _, body := range []string{
+		`{"ids": [], "optional": true}`,
+		`{"ids": ["tx-1"]}`,
+		`not json`,
+	} {
+		body := body
+		req := TestRequest("POST", "/transactions/bulk-optional", &body)
+		w := httptest.NewRecorder()
+		BulkSetTransactionsOptional(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d for %s, got %d", http.StatusBadRequest, body, w.Code)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+const defaultOverdueDays = 30
+
+// GetOverdueTransactions returns the accessible unpaid transactions dated
+// more than ?days= days ago (default 30), grouped by payee in name order with
+// a total per payee. Transactions without a payee are grouped under "".
+func GetOverdueTransactions(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	days := defaultOverdueDays
+	if param := r.URL.Query().Get("days"); param != "" {
+		var err error
+		days, err = strconv.Atoi(param)
+		if err != nil || days < 0 {
+			http.Error(w, "Invalid days: expected a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Dated before the cutoff day, so a transaction exactly N days old isn't
+	// overdue yet
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	before := today.AddDate(0, 0, -days)
+	dateClause, dateArgs := DateRange{End: before.AddDate(0, 0, -1)}.SQLConditions("date")
+
+	accessClause, args := accessibleTransactionsClause(userID)
+	args = append(args, dateArgs...)
+	rows, err := database.ReadDB().Query(`
+		SELECT id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional, userId,
+			source, status
+		FROM transactions
+		WHERE deleted_at IS NULL AND paid = 0
+	`+accessClause+dateClause+" ORDER BY COALESCE(payTo, ''), date, id", args...)
+	if err != nil {
+		log.Printf("Error querying overdue transactions: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	result := models.OverdueTransactions{
+		Days:   days,
+		Before: before.Format(dateLayout),
+		Groups: []models.OverdueGroup{},
+	}
+	// Sum in cents so large groups don't drift
+	var groupCents, totalCents int64
+	for rows.Next() {
+		var t models.Transaction
+		var payTo, paidDate, ownerID sql.NullString
+		var transactionDate sql.NullTime
+		err := rows.Scan(&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate, &t.Type, &payTo,
+			&t.Paid, &paidDate, &t.EnteredBy, &t.Optional, &ownerID, &t.Source, &t.Status)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		t.PayTo = payTo.String
+		t.PaidDate = paidDate.String
+		t.UserID = ownerID.String
+		if transactionDate.Valid {
+			t.TransactionDate = transactionDate.Time
+		} else {
+			t.TransactionDate = t.Date
+		}
+
+		// Rows are ordered by payee, so a new payee starts a new group
+		if len(result.Groups) == 0 || result.Groups[len(result.Groups)-1].PayTo != t.PayTo {
+			result.Groups = append(result.Groups, models.OverdueGroup{PayTo: t.PayTo, Transactions: []models.Transaction{}})
+			groupCents = 0
+		}
+		group := &result.Groups[len(result.Groups)-1]
+		group.Transactions = append(group.Transactions, t)
+
+		cents := int64(math.Round(float64(t.Amount) * 100))
+		groupCents += cents
+		totalCents += cents
+		group.Total = models.Amount(groupCents) / 100
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	result.Total = models.Amount(totalCents) / 100
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+func TestGetOverdueTransactions(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return today.AddDate(0, 0, -days) }
+
+	insertTestTransaction(t, "tx-electric-old", 80, daysAgo(60), TestUserID)
+	insertTestTransaction(t, "tx-electric", 20, daysAgo(31), TestUserID)
+	insertTestTransaction(t, "tx-water", 45.5, daysAgo(40), TestUserID)
+	insertTestTransaction(t, "tx-recent", 10, daysAgo(5), TestUserID)
+	insertTestTransaction(t, "tx-boundary", 15, daysAgo(30), TestUserID)
+	insertTestTransaction(t, "tx-paid", 99, daysAgo(90), TestUserID)
+	insertTestTransaction(t, "tx-other", 50, daysAgo(90), "other-user")
+
+	for _, stmt := range []string{
+		"UPDATE transactions SET payTo = 'Electric Co' WHERE id IN ('tx-electric-old', 'tx-electric', 'tx-recent', 'tx-boundary')",
+		"UPDATE transactions SET payTo = 'Water Co' WHERE id = 'tx-water'",
+		"UPDATE transactions SET paid = 1 WHERE id = 'tx-paid'",
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to update test data: %v", err)
+		}
+	}
+
+	req := TestRequest("GET", "/transactions/overdue?days=30", nil)
+	w := httptest.NewRecorder()
+	GetOverdueTransactions(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var overdue models.OverdueTransactions
+	if err := json.NewDecoder(w.Body).Decode(&overdue); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	if len(overdue.Groups) != 2 {
+		t.Fatalf("Expected 2 payees, got %+v", overdue.Groups)
+	}
+	electric, water := overdue.Groups[0], overdue.Groups[1]
+	if electric.PayTo != "Electric Co" || len(electric.Transactions) != 2 || electric.Total != 100 {
+		t.Errorf("Expected 2 Electric Co transactions totalling 100, got %+v", electric)
+	} else if electric.Transactions[0].ID != "tx-electric-old" || electric.Transactions[1].ID != "tx-electric" {
+		t.Errorf("Expected the oldest transaction first, got %s then %s", electric.Transactions[0].ID, electric.Transactions[1].ID)
+	}
+	if water.PayTo != "Water Co" || len(water.Transactions) != 1 || water.Total != 45.5 {
+		t.Errorf("Expected 1 Water Co transaction of 45.5, got %+v", water)
+	}
+	if overdue.Total != 145.5 || overdue.Days != 30 {
+		t.Errorf("Expected a total of 145.5 over 30 days, got %v over %d", overdue.Total, overdue.Days)
+	}
+}
+
+func TestGetOverdueTransactionsInvalidDays(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	req := TestRequest("GET", "/transactions/overdue?days=-1", nil)
+	w := httptest.NewRecorder()
+	GetOverdueTransactions(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// ownerNameColumn selects the display name of a transaction's owner, falling
+// back to their username. Being a subquery, it leaves the transaction
+// filters untouched and yields NULL for unowned transactions.
+const ownerNameColumn = "(SELECT COALESCE(NULLIF(users.name, ''), users.username) FROM users WHERE users.id = transactions.userId)"
+
+// parseIncludeOwner reads the ?includeOwner= flag asking for owner names in
+// transaction responses
+func parseIncludeOwner(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("includeOwner")
+	if value == "" {
+		return false, nil
+	}
+	includeOwner, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("Invalid includeOwner: expected true or false")
+	}
+	return includeOwner, nil
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+func setupOwnerNameTestDB(t *testing.T) {
+	t.Helper()
+
+	setupTransactionTestDB()
+	database.DB.Exec(`
+		INSERT INTO users (id, username, name, isAdmin, role) VALUES
+		('household', 'household', 'Household', 0, 'user'),
+		('reader', 'reader', '', 0, 'user'),
+		('stranger', 'stranger', 'Stranger', 0, 'user')
+	`)
+	database.DB.Exec(`
+		INSERT INTO permissions (granted_user_id, owner_user_id, resource_type, permission_type) VALUES
+		('reader', 'household', 'transactions', 'read')
+	`)
+
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for _, owner := range []string{"household", "reader", "stranger"} {
+		_, err := database.DB.Exec(`
+			INSERT INTO transactions (id, amount, description, date, transaction_date, type, payTo, enteredBy, userId)
+			VALUES (?, 10, 'Groceries', ?, ?, 'Groceries', 'Store', ?, ?)
+		`, "tx-"+owner, date, date, owner, owner)
+		if err != nil {
+			t.Fatalf("Failed to insert transaction: %v", err)
+		}
+	}
+}
+
+func TestGetTransactionsIncludeOwner(t *testing.T) {
+	setupOwnerNameTestDB(t)
+	defer CleanupTestDB()
+
+	req := MockAuthContext(httptest.NewRequest("GET", "/transactions?includeOwner=true", nil), "reader")
+	w := httptest.NewRecorder()
+	GetTransactions(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var transactions []models.Transaction
+	if err := json.NewDecoder(w.Body).Decode(&transactions); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	// The stranger's transaction stays hidden; users without a name fall
+	// back to their username
+	owners := map[string]string{}
+	for _, tx := range transactions {
+		owners[tx.ID] = tx.OwnerName
+	}
+	expected := map[string]string{"tx-household": "Household", "tx-reader": "reader"}
+	if len(owners) != len(expected) {
+		t.Fatalf("Expected %d transactions, got %v", len(expected), owners)
+	}
+	for id, name := range expected {
+		if owners[id] != name {
+			t.Errorf("Expected %s to be owned by %q, got %q", id, name, owners[id])
+		}
+	}
+}
+
+func TestGetTransactionIncludeOwner(t *testing.T) {
+	setupOwnerNameTestDB(t)
+	defer CleanupTestDB()
+
+	get := func(url string) (int, models.Transaction) {
+		req := MockAuthContext(httptest.NewRequest("GET", url, nil), "reader")
+		req = mux.SetURLVars(req, map[string]string{"id": "tx-household"})
+		w := httptest.NewRecorder()
+		GetTransaction(w, req)
+		var tx models.Transaction
+		json.NewDecoder(w.Body).Decode(&tx)
+		return w.Code, tx
+	}
+
+	if code, tx := get("/transactions/tx-household?includeOwner=true"); code != http.StatusOK || tx.OwnerName != "Household" {
+		t.Errorf("Expected owner Household, got status %d and owner %q", code, tx.OwnerName)
+	}
+	if code, tx := get("/transactions/tx-household"); code != http.StatusOK || tx.OwnerName != "" {
+		t.Errorf("Expected no owner name unless requested, got status %d and owner %q", code, tx.OwnerName)
+	}
+	if code, _ := get("/transactions/tx-household?includeOwner=maybe"); code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an invalid includeOwner, got %d", http.StatusBadRequest, code)
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+// validateRefund checks the transaction a refund links to: it must be
+// readable by the user and not a refund itself, and its refunds together
+// can't be larger than it. Refunds aren't chained, so refunded transactions can't
+// become refunds either. On failure it also returns the HTTP status to respond with.
+func validateRefund(t models.Transaction, userID string) (int, error) {
+	if t.RefundOf == "" {
+		return http.StatusOK, nil
+	}
+	if t.RefundOf == t.ID {
+		return http.StatusBadRequest, fmt.Errorf("A transaction can't refund itself")
+	}
+
+	if _, status, err := AuthorizeTransactionAccess(userID, t.RefundOf, models.PermissionRead); err != nil {
+		if status == http.StatusNotFound {
+			return http.StatusBadRequest, fmt.Errorf("Refunded transaction %s not found", t.RefundOf)
+		}
+		return status, err
+	}
+
+	var amount float64
+	var refundOf sql.NullString
+	err := database.DB.QueryRow("SELECT amount, refund_of FROM transactions WHERE id = ?", t.RefundOf).Scan(&amount, &refundOf)
+	if err != nil {
+		log.Printf("Error getting refunded transaction %s: %v", t.RefundOf, err)
+		return http.StatusInternalServerError, fmt.Errorf("Error checking refunded transaction")
+	}
+	if refundOf.Valid && refundOf.String != "" {
+		return http.StatusBadRequest, fmt.Errorf("Transaction %s is itself a refund", t.RefundOf)
+	}
+	var refunded bool
+	err = database.DB.QueryRow("SELECT COUNT(*) > 0 FROM transactions WHERE refund_of = ? AND deleted_at IS NULL", t.ID).Scan(&refunded)
+	if err != nil {
+		log.Printf("Error checking refunds of transaction %s: %v", t.ID, err)
+		return http.StatusInternalServerError, fmt.Errorf("Error checking refunded transaction")
+	}
+	if refunded {
+		return http.StatusBadRequest, fmt.Errorf("A refunded transaction can't be a refund itself")
+	}
+	if t.Amount <= 0 {
+		return http.StatusBadRequest, fmt.Errorf("Refund amount must be positive")
+	}
+	var alreadyRefunded float64
+	err = database.DB.QueryRow(`
+		SELECT COALESCE(SUM(amount), 0) FROM transactions
+		WHERE refund_of = ? AND deleted_at IS NULL AND id <> ?
+	`, t.RefundOf, t.ID).Scan(&alreadyRefunded)
+	if err != nil {
+		log.Printf("Error summing refunds of transaction %s: %v", t.RefundOf, err)
+		return http.StatusInternalServerError, fmt.Errorf("Error checking refunded transaction")
+	}
+	// Allow for float rounding when refunds add up to exactly the amount
+	if float64(t.Amount)+alreadyRefunded > amount+0.005 {
+		return http.StatusBadRequest, fmt.Errorf("Refund of %.2f exceeds the %.2f left to refund of %.2f",
+			t.Amount, math.Max(amount-alreadyRefunded, 0), amount)
+	}
+	return http.StatusOK, nil
+}
+
+// refundOfArg is the refund_of value to store for a transaction
+func refundOfArg(t models.Transaction) interface{} {
+	if t.RefundOf == "" {
+		return nil
+	}
+	return t.RefundOf
+}
+
+// isRefundExpression is the SQL telling whether a transaction is a linked
+// refund, which reports count negatively
+func isRefundExpression() string {
+	if !transactionsHaveColumn("refund_of") {
+		return "0"
+	}
+	return "(refund_of IS NOT NULL)"
+}
+
+// refundAwareExpressions returns the SQL reports group and sum transactions
+// by: a linked refund counts negatively towards the group of the transaction
+// it refunds, which nets the two.
+func refundAwareExpressions(column string) (group, amount string) {
+	if !transactionsHaveColumn("refund_of") {
+		return column, "amount"
+	}
+	group = fmt.Sprintf("COALESCE((SELECT refunded.%s FROM transactions refunded WHERE refunded.id = transactions.refund_of), %s)", column, column)
+	return group, "CASE WHEN refund_of IS NULL THEN amount ELSE -amount END"
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+// insertTestRefund adds a paid refund of one of the sample report transactions
+func insertTestRefund(t *testing.T, id, refundOf, txType string, amount float64) {
+	t.Helper()
+
+	date, _ := time.Parse("2006-01-02", "2023-02-20")
+	_, err := database.DB.Exec(`
+		INSERT INTO transactions (id, amount, description, date, type, paid, enteredBy, optional, userId, refund_of)
+		VALUES (?, ?, 'Refund', ?, ?, 1, 'Sarah', 0, ?, ?)
+	`, id, amount, date, txType, testUserID, refundOf)
+	if err != nil {
+		t.Fatalf("Error inserting refund: %v", err)
+	}
+}
+
+func TestRefundReducesOriginalCategoryTotal(t *testing.T) {
+	setupReportTestDB()
+	defer func() {
+		CleanupTestDB()
+		database.DB.Close()
+	}()
+
+	// A partial refund of tx1 (100 of Food), typed differently to show it
+	// counts towards the original's category
+	insertTestRefund(t, "refund1", "tx1", "Returns", 40)
+
+	totals, err := groupTotals(testUserID, "", reportGroupColumns["category"], DateRange{}, nil, nil)
+	if err != nil {
+		t.Fatalf("Error computing totals: %v", err)
+	}
+	if totals["Food"] != 185 {
+		t.Errorf("Expected Food to total 225 - 40 = 185, got %v", totals["Food"])
+	}
+	if _, ok := totals["Returns"]; ok {
+		t.Errorf("Expected no Returns group, got %v", totals)
+	}
+
+	body, _ := json.Marshal(models.ReportFilter{Category: "Food", Paid: boolPtr(true)})
+	req := MockAuthContext(httptest.NewRequest("POST", "/reports/ynab-splits", bytes.NewBuffer(body)), testUserID)
+	w := httptest.NewRecorder()
+	GetYNABSplits(w, req)
+	var splits []models.CategoryTotal
+	if err := json.NewDecoder(w.Body).Decode(&splits); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if len(splits) != 1 || splits[0].Total != 185 {
+		t.Errorf("Expected a Food split of 185, got %+v", splits)
+	}
+
+	ledger := getLedger(t, "/reports/ledger")
+	if ledger.Balance != 595 {
+		t.Errorf("Expected the refund to bring the ledger balance to 635 - 40 = 595, got %v", ledger.Balance)
+	}
+}
+
+func TestAddRefundTransaction(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	original := addTestTransaction(t, `{"amount": 100, "description": "Jacket", "type": "Clothing", "payTo": "Store"}`)
+
+	refund := addTestTransaction(t, `{"amount": 30, "description": "Jacket price match", "type": "Clothing", "payTo": "Store", "refundOf": "`+original.ID+`"}`)
+	if got := getTestTransaction(t, refund.ID); got.RefundOf != original.ID {
+		t.Errorf("Expected the refund to link to %s, got %q", original.ID, got.RefundOf)
+	}
+
+	invalid := []struct {
+		name string
+		body string
+	}{
+		{"more than the original", `{"amount": 150, "description": "Refund", "type": "Clothing", "refundOf": "` + original.ID + `"}`},
+		{"missing original", `{"amount": 10, "description": "Refund", "type": "Clothing", "refundOf": "no-such-transaction"}`},
+		{"refund of a refund", `{"amount": 10, "description": "Refund", "type": "Clothing", "refundOf": "` + refund.ID + `"}`},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			body := tc.body
+			req := TestRequest("POST", "/transactions", &body)
+			w := httptest.NewRecorder()
+			AddTransaction(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status code %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPartialRefundsCantExceedOriginal(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	original := addTestTransaction(t, `{"amount": 100, "description": "Jacket", "type": "Clothing"}`)
+	first := addTestTransaction(t, `{"amount": 60, "description": "Partial refund", "type": "Clothing", "refundOf": "`+original.ID+`"}`)
+
+	// Only 40 is left to refund
+	body := `{"amount": 50, "description": "Second refund", "type": "Clothing", "refundOf": "` + original.ID + `"}`
+	req := TestRequest("POST", "/transactions", &body)
+	w := httptest.NewRecorder()
+	AddTransaction(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for refunds over the original, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+	}
+
+	// The rest can still be refunded, and a refund can be edited up to it
+	addTestTransaction(t, `{"amount": 40, "description": "Second refund", "type": "Clothing", "refundOf": "`+original.ID+`"}`)
+
+	body = `{"amount": 61, "description": "Partial refund", "type": "Clothing", "refundOf": "` + original.ID + `"}`
+	req = TestRequest("PUT", "/transactions/"+first.ID, &body)
+	req = mux.SetURLVars(req, map[string]string{"id": first.ID})
+	w = httptest.NewRecorder()
+	UpdateTransaction(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d raising a refund over the original, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+	}
+}
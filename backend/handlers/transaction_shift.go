@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// ShiftTransactionDates moves both the date and the transaction date of
+// several of the user's own transactions by a number of days, e.g. to fix an
+// import made with the wrong dates. IDs of transactions the user doesn't own
+// are skipped. The shift is all or nothing: a locked transaction or a
+// shifted date outside the accepted window fails the whole request.
+func ShiftTransactionDates(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	var request models.ShiftDatesRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(request.IDs) == 0 {
+		http.Error(w, "ids is required", http.StatusBadRequest)
+		return
+	}
+	if request.Days == 0 {
+		http.Error(w, "days must not be 0", http.StatusBadRequest)
+		return
+	}
+
+	// Work out every new date before changing anything
+	type shiftedDates struct {
+		id              string
+		date            time.Time
+		transactionDate interface{} // Stays NULL when unset
+	}
+	now := time.Now()
+	seen := map[string]bool{}
+	var shifts []shiftedDates
+	for _, id := range request.IDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		var t models.Transaction
+		var transactionDate sql.NullTime
+		err := database.DB.QueryRow(`
+			SELECT date, transaction_date FROM transactions
+			WHERE id = ? AND userId = ? AND deleted_at IS NULL
+		`, id, userID).Scan(&t.Date, &transactionDate)
+		if err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			log.Printf("Error getting transaction %s: %v", id, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if status, err := checkTransactionLock(r, userID, id); err != nil {
+			http.Error(w, fmt.Sprintf("Transaction %s: %v", id, err), status)
+			return
+		}
+
+		// Without a transaction date the date stands in for it, so it
+		// shifts along with the date
+		shift := shiftedDates{id: id, date: t.Date.AddDate(0, 0, request.Days)}
+		t.Date = shift.date
+		t.TransactionDate = shift.date
+		if transactionDate.Valid {
+			t.TransactionDate = transactionDate.Time.AddDate(0, 0, request.Days)
+			shift.transactionDate = t.TransactionDate
+		}
+		if err := validateTransactionDates(t, now); err != nil {
+			http.Error(w, fmt.Sprintf("Transaction %s: %v", id, err), http.StatusBadRequest)
+			return
+		}
+		shifts = append(shifts, shift)
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	for _, shift := range shifts {
+		_, err := tx.Exec(`
+			UPDATE transactions SET date = ?, transaction_date = ?, updated_at = ?
+			WHERE id = ?
+		`, shift.date, shift.transactionDate, now, shift.id)
+		if err != nil {
+			log.Printf("Error shifting dates of transaction %s: %v", shift.id, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Shifted the dates of %d of %d transactions by %d days for user %s", len(shifts), len(request.IDs), request.Days, userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"updated": len(shifts),
+		"days":    request.Days,
+	})
+}
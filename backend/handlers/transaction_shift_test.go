@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+)
+
+func shiftTransactionDates(t *testing.T, body string, expectedStatus int) int {
+	t.Helper()
+
+	req := TestRequest("POST", "/transactions/shift-dates", &body)
+	w := httptest.NewRecorder()
+	ShiftTransactionDates(w, req)
+	if w.Code != expectedStatus {
+		t.Fatalf("Expected status code %d, got %d: %s", expectedStatus, w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Updated int `json:"updated"`
+	}
+	if expectedStatus == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return response.Updated
+}
+
+// transactionDates returns the date and transaction date (empty when unset)
+// of a transaction as YYYY-MM-DD
+func transactionDates(t *testing.T, id string) (string, string) {
+	t.Helper()
+
+	var date time.Time
+	var transactionDate *time.Time
+	err := database.DB.QueryRow("SELECT date, transaction_date FROM transactions WHERE id = ?", id).Scan(&date, &transactionDate)
+	if err != nil {
+		t.Fatalf("Failed to read dates of %s: %v", id, err)
+	}
+	if transactionDate == nil {
+		return date.Format(dateLayout), ""
+	}
+	return date.Format(dateLayout), transactionDate.Format(dateLayout)
+}
+
+func TestShiftTransactionDates(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	march := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	insertTestTransaction(t, "tx-1", 10, march, TestUserID)
+	insertTestTransaction(t, "tx-other", 40, march, "other-user")
+	database.DB.Exec("UPDATE transactions SET transaction_date = ? WHERE id = 'tx-1'", march.AddDate(0, 0, -2))
+	_, err := database.DB.Exec(`
+		INSERT INTO transactions (id, amount, description, date, type, enteredBy, userId)
+		VALUES ('tx-no-transaction-date', 20, 'Test', ?, 'Groceries', 'test-user', ?)
+	`, march, TestUserID)
+	if err != nil {
+		t.Fatalf("Failed to insert transaction: %v", err)
+	}
+
+	// Other users' and unknown transactions are skipped
+	updated := shiftTransactionDates(t, `{"ids": ["tx-1", "tx-no-transaction-date", "tx-other", "tx-missing"], "days": 25}`, http.StatusOK)
+	if updated != 2 {
+		t.Errorf("Expected 2 transactions shifted, got %d", updated)
+	}
+	expected := map[string][2]string{
+		"tx-1":                   {"2024-04-04", "2024-04-02"},
+		"tx-no-transaction-date": {"2024-04-04", ""},
+		"tx-other":               {"2024-03-10", "2024-03-10"},
+	}
+	for id, want := range expected {
+		if date, transactionDate := transactionDates(t, id); date != want[0] || transactionDate != want[1] {
+			t.Errorf("Expected %s dated %v, got [%s %s]", id, want, date, transactionDate)
+		}
+	}
+
+	// Negative offsets move the dates back
+	shiftTransactionDates(t, `{"ids": ["tx-1"], "days": -40}`, http.StatusOK)
+	if date, transactionDate := transactionDates(t, "tx-1"); date != "2024-02-24" || transactionDate != "2024-02-22" {
+		t.Errorf("Expected tx-1 shifted back to 2024-02-24/2024-02-22, got %s/%s", date, transactionDate)
+	}
+
+	// Nothing changes when any shifted date is out of range or a
+	// transaction is locked
+	shiftTransactionDates(t, `{"ids": ["tx-1", "tx-no-transaction-date"], "days": -30000}`, http.StatusBadRequest)
+	database.DB.Exec("UPDATE transactions SET locked = 1 WHERE id = 'tx-no-transaction-date'")
+	shiftTransactionDates(t, `{"ids": ["tx-1", "tx-no-transaction-date"], "days": 1}`, http.StatusConflict)
+	if date, _ := transactionDates(t, "tx-1"); date != "2024-02-24" {
+		t.Errorf("Expected tx-1 to be left alone, got %s", date)
+	}
+
+	shiftTransactionDates(t, `{"ids": [], "days": 1}`, http.StatusBadRequest)
+	shiftTransactionDates(t, `{"ids": ["tx-1"], "days": 0}`, http.StatusBadRequest)
+}
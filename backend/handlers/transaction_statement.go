@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// GetTransactionStatement returns the accessible transactions of a month
+// (?month=, YYYY-MM, default the current month) grouped by transaction date,
+// with a subtotal per day and a total for the month. Refunds count
+// negatively. Unlike the reports, paid and optional transactions aren't
+// filtered out: the statement lists everything entered.
+func GetTransactionStatement(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	now := time.Now()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if param := r.URL.Query().Get("month"); param != "" {
+		var err error
+		month, err = time.Parse(monthLayout, param)
+		if err != nil {
+			http.Error(w, "Invalid month: expected YYYY-MM", http.StatusBadRequest)
+			return
+		}
+	}
+
+	accessClause, args := accessibleTransactionsClause(userID)
+	args = append([]interface{}{month.Format(monthLayout)}, args...)
+	rows, err := database.ReadDB().Query(`
+		SELECT id, amount, description, date, transaction_date, type, payTo, paid, paidDate, enteredBy, optional, userId,
+			source, status, `+isRefundExpression()+`
+		FROM transactions
+		WHERE deleted_at IS NULL AND substr(COALESCE(transaction_date, date), 1, 7) = ?
+	`+accessClause+" ORDER BY COALESCE(transaction_date, date), id", args...)
+	if err != nil {
+		log.Printf("Error querying statement transactions: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	statement := models.TransactionStatement{
+		Month: month.Format(monthLayout),
+		Days:  []models.StatementDay{},
+	}
+	// Sum in cents so long months don't drift
+	var dayCents, totalCents int64
+	for rows.Next() {
+		var t models.Transaction
+		var payTo, paidDate, ownerID sql.NullString
+		var transactionDate sql.NullTime
+		var isRefund bool
+		err := rows.Scan(&t.ID, &t.Amount, &t.Description, &t.Date, &transactionDate, &t.Type, &payTo,
+			&t.Paid, &paidDate, &t.EnteredBy, &t.Optional, &ownerID, &t.Source, &t.Status, &isRefund)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		t.PayTo = payTo.String
+		t.PaidDate = paidDate.String
+		t.UserID = ownerID.String
+		if transactionDate.Valid {
+			t.TransactionDate = transactionDate.Time
+		} else {
+			t.TransactionDate = t.Date
+		}
+
+		// Rows are in date order, so a new date starts a new day
+		day := t.TransactionDate.Format(dateLayout)
+		if len(statement.Days) == 0 || statement.Days[len(statement.Days)-1].Date != day {
+			statement.Days = append(statement.Days, models.StatementDay{Date: day, Transactions: []models.Transaction{}})
+			dayCents = 0
+		}
+		current := &statement.Days[len(statement.Days)-1]
+		current.Transactions = append(current.Transactions, t)
+
+		cents := int64(math.Round(float64(t.Amount) * 100))
+		if isRefund {
+			cents = -cents
+		}
+		dayCents += cents
+		totalCents += cents
+		current.Subtotal = models.Amount(dayCents) / 100
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	statement.Total = models.Amount(totalCents) / 100
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statement)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+func getStatement(t *testing.T, url string) models.TransactionStatement {
+	req := TestRequest("GET", url, nil)
+	w := httptest.NewRecorder()
+	GetTransactionStatement(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var statement models.TransactionStatement
+	if err := json.NewDecoder(w.Body).Decode(&statement); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	return statement
+}
+
+func TestGetTransactionStatement(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	day := func(value string) time.Time {
+		parsed, _ := time.Parse(dateLayout, value)
+		return parsed
+	}
+	insertTestTransaction(t, "tx-1", 10, day("2024-03-02"), TestUserID)
+	insertTestTransaction(t, "tx-2", 15.5, day("2024-03-02"), TestUserID)
+	insertTestTransaction(t, "tx-3", 40, day("2024-03-20"), TestUserID)
+	insertTestTransaction(t, "tx-refund", 5, day("2024-03-20"), TestUserID)
+	insertTestTransaction(t, "tx-4", 7.25, day("2024-03-31"), TestUserID)
+	insertTestTransaction(t, "tx-april", 100, day("2024-04-01"), TestUserID)
+	insertTestTransaction(t, "tx-other", 50, day("2024-03-10"), "other-user")
+	insertTestTransaction(t, "tx-deleted", 60, day("2024-03-10"), TestUserID)
+
+	for _, stmt := range []string{
+		"UPDATE transactions SET refund_of = 'tx-3' WHERE id = 'tx-refund'",
+		"UPDATE transactions SET deleted_at = CURRENT_TIMESTAMP WHERE id = 'tx-deleted'",
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to update test data: %v", err)
+		}
+	}
+
+	statement := getStatement(t, "/transactions/statement?month=2024-03")
+
+	expected := []struct {
+		date     string
+		count    int
+		subtotal models.Amount
+	}{
+		{"2024-03-02", 2, 25.5},
+		{"2024-03-20", 2, 35},
+		{"2024-03-31", 1, 7.25},
+	}
+	if statement.Month != "2024-03" || len(statement.Days) != len(expected) {
+		t.Fatalf("Expected %d days in 2024-03, got %+v", len(expected), statement)
+	}
+	for i, e := range expected {
+		got := statement.Days[i]
+		if got.Date != e.date || len(got.Transactions) != e.count || got.Subtotal != e.subtotal {
+			t.Errorf("Day %d: expected %s with %d transactions and subtotal %v, got %s with %d and %v",
+				i, e.date, e.count, e.subtotal, got.Date, len(got.Transactions), got.Subtotal)
+		}
+	}
+	if statement.Total != 67.75 {
+		t.Errorf("Expected a month total of 67.75, got %v", statement.Total)
+	}
+
+	// A month without transactions has no days
+	empty := getStatement(t, "/transactions/statement?month=2024-05")
+	if len(empty.Days) != 0 || empty.Total != 0 {
+		t.Errorf("Expected an empty statement, got %+v", empty)
+	}
+}
+
+func TestGetTransactionStatementInvalidMonth(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	req := TestRequest("GET", "/transactions/statement?month=March", nil)
+	w := httptest.NewRecorder()
+	GetTransactionStatement(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
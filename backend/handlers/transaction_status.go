@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"bennwallet/backend/models"
+)
+
+// normalizeTransactionStatus lowercases a transaction status and checks it is
+// known. Transactions without a status are cleared.
+func normalizeTransactionStatus(status string) (string, error) {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status == "" {
+		return models.TransactionStatusCleared, nil
+	}
+	if !models.IsValidTransactionStatus(status) {
+		return "", fmt.Errorf("Invalid status %q (expected %s)", status, strings.Join(models.TransactionStatuses, ", "))
+	}
+	return status, nil
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/models"
+
+	"github.com/gorilla/mux"
+)
+
+func addStatusTestTransaction(t *testing.T, body string) models.Transaction {
+	req := TestRequest("POST", "/transactions", &body)
+	w := httptest.NewRecorder()
+	AddTransaction(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var created models.Transaction
+	json.NewDecoder(w.Body).Decode(&created)
+	return created
+}
+
+func TestTransactionStatusSetOnCreateAndUpdate(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	created := addStatusTestTransaction(t, `{"amount": 80, "description": "Double charge", "type": "Shopping", "status": "Disputed"}`)
+	if got := getTestTransaction(t, created.ID); got.Status != models.TransactionStatusDisputed {
+		t.Errorf("Expected status disputed, got %q", got.Status)
+	}
+
+	// Status is independent of paid
+	body := `{"amount": 80, "description": "Double charge", "type": "Shopping", "paid": true, "status": "pending", "date": "` + created.Date.Format(time.RFC3339) + `"}`
+	req := TestRequest("PUT", "/transactions/"+created.ID, &body)
+	req = mux.SetURLVars(req, map[string]string{"id": created.ID})
+	w := httptest.NewRecorder()
+	UpdateTransaction(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if got := getTestTransaction(t, created.ID); got.Status != models.TransactionStatusPending || !got.Paid {
+		t.Errorf("Expected a paid, pending transaction, got paid %v, status %q", got.Paid, got.Status)
+	}
+
+	// Transactions default to cleared
+	plain := addStatusTestTransaction(t, `{"amount": 5, "description": "Coffee", "type": "Dining"}`)
+	if got := getTestTransaction(t, plain.ID); got.Status != models.TransactionStatusCleared {
+		t.Errorf("Expected status cleared by default, got %q", got.Status)
+	}
+}
+
+func TestTransactionStatusInvalid(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	body := `{"amount": 80, "description": "Double charge", "type": "Shopping", "status": "refunded"}`
+	req := TestRequest("POST", "/transactions", &body)
+	w := httptest.NewRecorder()
+	AddTransaction(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestGetTransactionsFilterByStatus(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	disputed := addStatusTestTransaction(t, `{"amount": 80, "description": "Double charge", "type": "Shopping", "status": "disputed"}`)
+	addStatusTestTransaction(t, `{"amount": 20, "description": "Gas", "type": "Gas", "status": "pending"}`)
+	addStatusTestTransaction(t, `{"amount": 5, "description": "Coffee", "type": "Dining"}`)
+
+	req := TestRequest("GET", "/transactions?status=disputed", nil)
+	w := httptest.NewRecorder()
+	GetTransactions(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var transactions []models.Transaction
+	json.NewDecoder(w.Body).Decode(&transactions)
+	if len(transactions) != 1 || transactions[0].ID != disputed.ID || transactions[0].Status != models.TransactionStatusDisputed {
+		t.Errorf("Expected only the disputed transaction, got %+v", transactions)
+	}
+
+	req = TestRequest("GET", "/transactions?status=bogus", nil)
+	w = httptest.NewRecorder()
+	GetTransactions(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an unknown status, got %d", http.StatusBadRequest, w.Code)
+	}
+}
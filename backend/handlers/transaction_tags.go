@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// normalizeTags trims and lowercases tags, dropping blanks and duplicates
+func normalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// BulkTagTransactions applies tags to several of the user's own transactions.
+// In add mode existing tags are kept; in replace mode they are overwritten.
+// Either every transaction is updated or none are; locked transactions are
+// left unchanged and listed as skipped.
+func BulkTagTransactions(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	var request models.BulkTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.Mode == "" {
+		request.Mode = models.TagModeAdd
+	}
+	if request.Mode != models.TagModeAdd && request.Mode != models.TagModeReplace {
+		http.Error(w, fmt.Sprintf("Invalid mode %q (expected add or replace)", request.Mode), http.StatusBadRequest)
+		return
+	}
+	if len(request.IDs) == 0 {
+		http.Error(w, "ids is required", http.StatusBadRequest)
+		return
+	}
+
+	tags := normalizeTags(request.Tags)
+	if len(tags) == 0 && request.Mode == models.TagModeAdd {
+		http.Error(w, "tags is required", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	locked, err := lockedTransactionIDs(tx, request.IDs)
+	if err != nil {
+		log.Printf("Error checking locked transactions: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	skipped := []string{}
+	for _, id := range request.IDs {
+		var owned bool
+		err := tx.QueryRow("SELECT COUNT(*) > 0 FROM transactions WHERE id = ? AND userId = ? AND deleted_at IS NULL", id, userID).Scan(&owned)
+		if err != nil {
+			log.Printf("Error checking transaction %s: %v", id, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !owned {
+			http.Error(w, fmt.Sprintf("Transaction %s not found", id), http.StatusNotFound)
+			return
+		}
+		if locked[id] {
+			skipped = append(skipped, id)
+			continue
+		}
+
+		if request.Mode == models.TagModeReplace {
+			if _, err := tx.Exec("DELETE FROM transaction_tags WHERE transaction_id = ?", id); err != nil {
+				log.Printf("Error clearing tags of transaction %s: %v", id, err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		for _, tag := range tags {
+			_, err := tx.Exec(`
+				INSERT INTO transaction_tags (transaction_id, tag)
+				VALUES (?, ?)
+				ON CONFLICT(transaction_id, tag) DO NOTHING
+			`, id, tag)
+			if err != nil {
+				log.Printf("Error tagging transaction %s: %v", id, err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	updated := len(request.IDs) - len(skipped)
+	log.Printf("Tagged %d transactions for user %s (mode %s)", updated, userID, request.Mode)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"updated": updated,
+		"tags":    tags,
+		"skipped": skipped,
+	})
+}
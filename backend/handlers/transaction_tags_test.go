@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+)
+
+func transactionTags(t *testing.T, id string) []string {
+	rows, err := database.DB.Query("SELECT tag FROM transaction_tags WHERE transaction_id = ? ORDER BY tag", id)
+	if err != nil {
+		t.Fatalf("Failed to query tags: %v", err)
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		rows.Scan(&tag)
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+func setupBulkTagTestDB(t *testing.T) {
+	setupTransactionTestDB()
+	insertTestTransaction(t, "tx-1", 10, time.Now(), TestUserID)
+	insertTestTransaction(t, "tx-2", 20, time.Now(), TestUserID)
+	insertTestTransaction(t, "tx-other", 30, time.Now(), "other-user")
+
+	_, err := database.DB.Exec(`
+		INSERT INTO transaction_tags (transaction_id, tag)
+		VALUES ('tx-1', 'groceries'), ('tx-2', 'work')
+	`)
+	if err != nil {
+		t.Fatalf("Failed to insert tags: %v", err)
+	}
+}
+
+func TestBulkTagTransactionsAddMode(t *testing.T) {
+	setupBulkTagTestDB(t)
+	defer CleanupTestDB()
+
+	body := `{"ids": ["tx-1", "tx-2"], "tags": ["Vacation ", "groceries"], "mode": "add"}`
+	req := TestRequest("POST", "/transactions/tag", &body)
+	w := httptest.NewRecorder()
+	BulkTagTransactions(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	if tags := transactionTags(t, "tx-1"); !reflect.DeepEqual(tags, []string{"groceries", "vacation"}) {
+		t.Errorf("Expected existing tags to be preserved on tx-1, got %v", tags)
+	}
+	if tags := transactionTags(t, "tx-2"); !reflect.DeepEqual(tags, []string{"groceries", "vacation", "work"}) {
+		t.Errorf("Expected existing tags to be preserved on tx-2, got %v", tags)
+	}
+}
+
+func TestBulkTagTransactionsReplaceMode(t *testing.T) {
+	setupBulkTagTestDB(t)
+	defer CleanupTestDB()
+
+	body := `{"ids": ["tx-1", "tx-2"], "tags": ["vacation"], "mode": "replace"}`
+	req := TestRequest("POST", "/transactions/tag", &body)
+	w := httptest.NewRecorder()
+	BulkTagTransactions(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	for _, id := range []string{"tx-1", "tx-2"} {
+		if tags := transactionTags(t, id); !reflect.DeepEqual(tags, []string{"vacation"}) {
+			t.Errorf("Expected tags of %s to be replaced, got %v", id, tags)
+		}
+	}
+}
+
+func TestBulkTagTransactionsRejectsUnownedTransaction(t *testing.T) {
+	setupBulkTagTestDB(t)
+	defer CleanupTestDB()
+
+	body := `{"ids": ["tx-1", "tx-other"], "tags": ["vacation"], "mode": "replace"}`
+	req := TestRequest("POST", "/transactions/tag", &body)
+	w := httptest.NewRecorder()
+	BulkTagTransactions(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Expected status code %d, got %d", http.StatusNotFound, w.Code)
+	}
+
+	// Nothing is applied when any transaction is rejected
+	if tags := transactionTags(t, "tx-1"); !reflect.DeepEqual(tags, []string{"groceries"}) {
+		t.Errorf("Expected tx-1 to be unchanged, got %v", tags)
+	}
+	if tags := transactionTags(t, "tx-other"); len(tags) != 0 {
+		t.Errorf("Expected no tags on another user's transaction, got %v", tags)
+	}
+}
@@ -30,7 +30,56 @@ func setupTransactionTestDB() {
 			paidDate TEXT,
 			enteredBy TEXT NOT NULL,
 			optional BOOLEAN NOT NULL DEFAULT 0,
-			userId TEXT
+			userId TEXT,
+			source TEXT NOT NULL DEFAULT 'manual',
+			status TEXT NOT NULL DEFAULT 'cleared',
+			locked BOOLEAN NOT NULL DEFAULT 0,
+			refund_of TEXT,
+			import_batch_id TEXT,
+			updated_at DATETIME,
+			deleted_at DATETIME,
+			original_amount REAL,
+			original_currency TEXT
+		)
+	`)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = database.DB.Exec(`
+		CREATE TABLE IF NOT EXISTS transaction_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			transaction_id TEXT NOT NULL,
+			changed_by TEXT NOT NULL,
+			changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			changed_fields TEXT NOT NULL,
+			before_snapshot TEXT NOT NULL,
+			after_snapshot TEXT NOT NULL
+		)
+	`)
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = database.DB.Exec(`
+		CREATE TABLE IF NOT EXISTS transaction_categories (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			transaction_id TEXT NOT NULL,
+			category_id INTEGER NOT NULL,
+			amount REAL NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(transaction_id, category_id)
+		)
+	`)
+	if err != nil {
+		panic(err)
+	}
+	_, err = database.DB.Exec(`
+		CREATE TABLE IF NOT EXISTS transaction_tags (
+			transaction_id TEXT NOT NULL,
+			tag TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (transaction_id, tag)
 		)
 	`)
 	if err != nil {
@@ -159,3 +208,434 @@ func TestGetTransactions(t *testing.T) {
 		t.Errorf("Expected description 'Test Transaction', got '%s'", response[0].Description)
 	}
 }
+
+func TestAddTransactionInheritsCategoryOptionalDefault(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	_, err := database.DB.Exec(`
+		CREATE TABLE categories (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			description TEXT,
+			user_id TEXT NOT NULL,
+			color TEXT,
+			optional_default BOOLEAN NOT NULL DEFAULT 0,
+			deleted_at DATETIME,
+			UNIQUE(name, user_id)
+		)
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create categories table: %v", err)
+	}
+	_, err = database.DB.Exec(`
+		INSERT INTO categories (name, description, user_id, optional_default) VALUES
+		('Fun Money', '', ?, 1),
+		('Groceries', '', ?, 0)
+	`, TestUserID, TestUserID)
+	if err != nil {
+		t.Fatalf("Failed to insert categories: %v", err)
+	}
+
+	testCases := []struct {
+		name             string
+		body             string
+		expectedOptional bool
+	}{
+		{"inherits optional default", `{"amount": 20, "description": "Concert", "type": "Fun Money"}`, true},
+		{"explicit false overrides default", `{"amount": 20, "description": "Movie", "type": "Fun Money", "optional": false}`, false},
+		{"category without default", `{"amount": 20, "description": "Milk", "type": "Groceries"}`, false},
+		{"explicit true without default", `{"amount": 20, "description": "Snacks", "type": "Groceries", "optional": true}`, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := TestRequest("POST", "/transactions", &tc.body)
+			w := httptest.NewRecorder()
+
+			AddTransaction(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+
+			var response models.Transaction
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Error decoding response: %v", err)
+			}
+
+			var stored bool
+			if err := database.DB.QueryRow("SELECT optional FROM transactions WHERE id = ?", response.ID).Scan(&stored); err != nil {
+				t.Fatalf("Failed to read stored transaction: %v", err)
+			}
+			if stored != tc.expectedOptional {
+				t.Errorf("Expected optional=%v, got %v", tc.expectedOptional, stored)
+			}
+		})
+	}
+}
+
+func TestGetTransactionsAmountRange(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	amounts := map[string]float64{"tx-small": 5.25, "tx-medium": 42.00, "tx-large": 199.99}
+	for id, amount := range amounts {
+		_, err := database.DB.Exec(`
+			INSERT INTO transactions (id, amount, description, date, type, payTo, paid, paidDate, enteredBy, optional, userId)
+			VALUES (?, ?, 'Test', ?, 'Test', 'Test', 0, '', 'test-user', 0, ?)
+		`, id, amount, time.Now(), TestUserID)
+		if err != nil {
+			t.Fatalf("Failed to insert transaction: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		query       string
+		expectedIDs []string
+	}{
+		{"min only", "?minAmount=42", []string{"tx-medium", "tx-large"}},
+		{"max only", "?maxAmount=42.00", []string{"tx-small", "tx-medium"}},
+		{"min and max", "?minAmount=5.5&maxAmount=100", []string{"tx-medium"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := TestRequest("GET", "/transactions"+tc.query, nil)
+			w := httptest.NewRecorder()
+
+			GetTransactions(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+
+			var response []models.Transaction
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Error decoding response: %v", err)
+			}
+
+			got := make(map[string]bool)
+			for _, tx := range response {
+				got[tx.ID] = true
+			}
+			if len(got) != len(tc.expectedIDs) {
+				t.Errorf("Expected %d transactions, got %d", len(tc.expectedIDs), len(got))
+			}
+			for _, id := range tc.expectedIDs {
+				if !got[id] {
+					t.Errorf("Expected transaction %s in results", id)
+				}
+			}
+		})
+	}
+}
+
+func TestGetTransactionsHead(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	amounts := map[string]float64{"tx-small": 5.25, "tx-medium": 42.00, "tx-large": 199.99}
+	for id, amount := range amounts {
+		_, err := database.DB.Exec(`
+			INSERT INTO transactions (id, amount, description, date, type, payTo, paid, paidDate, enteredBy, optional, userId)
+			VALUES (?, ?, 'Test', ?, 'Test', 'Test', 0, '', 'test-user', 0, ?)
+		`, id, amount, time.Now(), TestUserID)
+		if err != nil {
+			t.Fatalf("Failed to insert transaction: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		query         string
+		expectedCount string
+	}{
+		{"", "3"},
+		{"?minAmount=42", "2"},
+		{"?minAmount=500", "0"},
+	}
+
+	for _, tc := range testCases {
+		req := TestRequest("HEAD", "/transactions"+tc.query, nil)
+		w := httptest.NewRecorder()
+
+		GetTransactions(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%q: expected status code %d, got %d", tc.query, http.StatusOK, w.Code)
+		}
+		if got := w.Header().Get("X-Total-Count"); got != tc.expectedCount {
+			t.Errorf("%q: expected X-Total-Count %s, got %q", tc.query, tc.expectedCount, got)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("%q: expected an empty body, got %q", tc.query, w.Body.String())
+		}
+	}
+}
+
+func TestGetTransactionsUsesReadDB(t *testing.T) {
+	insert := func(id string) {
+		_, err := database.DB.Exec(`
+			INSERT INTO transactions (id, amount, description, date, type, payTo, paid, paidDate, enteredBy, optional, userId)
+			VALUES (?, 10, 'Test', ?, 'Test', 'Test', 0, '', 'test-user', 0, ?)
+		`, id, time.Now(), TestUserID)
+		if err != nil {
+			t.Fatalf("Failed to insert transaction: %v", err)
+		}
+	}
+	listIDs := func() []string {
+		req := TestRequest("GET", "/transactions", nil)
+		w := httptest.NewRecorder()
+		GetTransactions(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var response []models.Transaction
+		json.NewDecoder(w.Body).Decode(&response)
+		ids := []string{}
+		for _, tx := range response {
+			ids = append(ids, tx.ID)
+		}
+		return ids
+	}
+
+	// Two separate databases stand in for the replica and the primary
+	setupTransactionTestDB()
+	insert("replica-tx")
+	replica := database.DB
+	defer replica.Close()
+
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+	insert("primary-tx")
+
+	if ids := listIDs(); len(ids) != 1 || ids[0] != "primary-tx" {
+		t.Errorf("Expected reads from the primary without a replica, got %v", ids)
+	}
+
+	database.SetReadDB(replica)
+	defer database.SetReadDB(nil)
+	if ids := listIDs(); len(ids) != 1 || ids[0] != "replica-tx" {
+		t.Errorf("Expected reads from the replica, got %v", ids)
+	}
+}
+
+func TestGetTransactionsEnteredByMe(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	// enteredBy may hold the user ID, username or display name
+	enteredBy := map[string]string{
+		"tx-by-id":       TestUserID,
+		"tx-by-username": "testuser",
+		"tx-by-name":     "Test User",
+		"tx-by-partner":  "Sarah",
+		"tx-by-similar":  "testuser2",
+	}
+	for id, by := range enteredBy {
+		_, err := database.DB.Exec(`
+			INSERT INTO transactions (id, amount, description, date, type, payTo, paid, paidDate, enteredBy, optional, userId)
+			VALUES (?, 10, 'Test', ?, 'Test', 'Test', 0, '', ?, 0, ?)
+		`, id, time.Now(), by, TestUserID)
+		if err != nil {
+			t.Fatalf("Failed to insert transaction: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		query       string
+		expectedIDs []string
+	}{
+		{"?enteredByMe=true", []string{"tx-by-id", "tx-by-username", "tx-by-name"}},
+		{"?enteredByMe=false", []string{"tx-by-partner", "tx-by-similar"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			req := TestRequest("GET", "/transactions"+tc.query, nil)
+			w := httptest.NewRecorder()
+
+			GetTransactions(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+
+			var response []models.Transaction
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Error decoding response: %v", err)
+			}
+
+			got := make(map[string]bool)
+			for _, tx := range response {
+				got[tx.ID] = true
+			}
+			if len(got) != len(tc.expectedIDs) {
+				t.Errorf("Expected %d transactions, got %d", len(tc.expectedIDs), len(got))
+			}
+			for _, id := range tc.expectedIDs {
+				if !got[id] {
+					t.Errorf("Expected transaction %s in results", id)
+				}
+			}
+		})
+	}
+
+	req := TestRequest("GET", "/transactions?enteredByMe=maybe", nil)
+	w := httptest.NewRecorder()
+	GetTransactions(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for invalid enteredByMe, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestGetTransactionsRejectsInvalidAmount(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	for _, query := range []string{"?minAmount=abc", "?maxAmount=12..5", "?minAmount=NaN", "?minAmount=50&maxAmount=10"} {
+		req := TestRequest("GET", "/transactions"+query, nil)
+		w := httptest.NewRecorder()
+
+		GetTransactions(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d for %s, got %d", http.StatusBadRequest, query, w.Code)
+		}
+	}
+}
+
+func TestAddTransactionRecordsManualSource(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	// A source sent by the client is ignored
+	body := `{"amount": 12.5, "description": "Coffee", "type": "Dining", "source": "import"}`
+	req := TestRequest("POST", "/transactions", &body)
+	w := httptest.NewRecorder()
+	AddTransaction(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response models.Transaction
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	var source string
+	if err := database.DB.QueryRow("SELECT source FROM transactions WHERE id = ?", response.ID).Scan(&source); err != nil {
+		t.Fatalf("Error reading source: %v", err)
+	}
+	if source != models.TransactionSourceManual || response.Source != models.TransactionSourceManual {
+		t.Errorf("Expected source %q, got %q (response %q)", models.TransactionSourceManual, source, response.Source)
+	}
+}
+
+func TestGetTransactionsBySource(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	sources := map[string]string{
+		"tx-manual":    models.TransactionSourceManual,
+		"tx-import":    models.TransactionSourceImport,
+		"tx-recurring": models.TransactionSourceRecurring,
+	}
+	for id, source := range sources {
+		_, err := database.DB.Exec(`
+			INSERT INTO transactions (id, amount, description, date, type, payTo, enteredBy, userId, source)
+			VALUES (?, 10, 'Test', ?, 'Test', 'Test', 'test-user', ?, ?)
+		`, id, time.Now(), TestUserID, source)
+		if err != nil {
+			t.Fatalf("Failed to insert transaction: %v", err)
+		}
+	}
+
+	for id, source := range sources {
+		t.Run(source, func(t *testing.T) {
+			req := TestRequest("GET", "/transactions?source="+source, nil)
+			w := httptest.NewRecorder()
+			GetTransactions(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+
+			var response []models.Transaction
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Error decoding response: %v", err)
+			}
+			if len(response) != 1 || response[0].ID != id {
+				t.Errorf("Expected only %s, got %+v", id, response)
+			}
+		})
+	}
+
+	req := TestRequest("GET", "/transactions?source=spreadsheet", nil)
+	w := httptest.NewRecorder()
+	GetTransactions(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for invalid source, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestAddTransactionOnBehalfOfUser(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+
+	database.DB.Exec(`
+		INSERT INTO users (id, username, name, isAdmin, role) VALUES
+		('partner', 'partner', 'Partner', 0, 'user'),
+		('household', 'household', 'Household', 0, 'user')
+	`)
+	database.DB.Exec(`
+		INSERT INTO permissions (granted_user_id, owner_user_id, resource_type, permission_type)
+		VALUES ('partner', 'household', 'transactions', 'write')
+	`)
+
+	testCases := []struct {
+		name          string
+		callerID      string
+		ownerField    string
+		expectedCode  int
+		expectedOwner string
+	}{
+		{"self create", "partner", "", http.StatusOK, "partner"},
+		{"self create with own userId", "partner", `, "userId": "partner"`, http.StatusOK, "partner"},
+		{"permitted on behalf", "partner", `, "userId": "household"`, http.StatusOK, "household"},
+		{"forbidden on behalf", "partner", `, "userId": "` + TestUserID + `"`, http.StatusForbidden, ""},
+		{"admin on behalf", TestUserID, `, "userId": "partner"`, http.StatusOK, "partner"},
+		{"unknown user", TestUserID, `, "userId": "nobody"`, http.StatusBadRequest, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body := `{"amount": 10, "description": "` + tc.name + `", "type": "Groceries"` + tc.ownerField + `}`
+			req := httptest.NewRequest("POST", "/transactions", bytes.NewBufferString(body))
+			req = MockAuthContext(req, tc.callerID)
+			w := httptest.NewRecorder()
+			AddTransaction(w, req)
+			if w.Code != tc.expectedCode {
+				t.Fatalf("Expected status code %d, got %d: %s", tc.expectedCode, w.Code, w.Body.String())
+			}
+
+			var owner, enteredBy string
+			err := database.DB.QueryRow("SELECT userId, enteredBy FROM transactions WHERE description = ?", tc.name).Scan(&owner, &enteredBy)
+			if tc.expectedOwner == "" {
+				if err == nil {
+					t.Errorf("Expected no transaction to be stored, found one owned by %s", owner)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Error reading transaction: %v", err)
+			}
+			if owner != tc.expectedOwner {
+				t.Errorf("Expected owner %s, got %s", tc.expectedOwner, owner)
+			}
+			if enteredBy != tc.callerID {
+				t.Errorf("Expected the transaction to be entered by %s, got %s", tc.callerID, enteredBy)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// Modes for validating the transaction type, selected with TRANSACTION_TYPE_MODE
+const (
+	TransactionTypeModeFree   = "free"   // Any type is accepted (default)
+	TransactionTypeModeStrict = "strict" // Only types listed in TRANSACTION_TYPES are accepted
+)
+
+// allowedTransactionTypes returns the types configured in TRANSACTION_TYPES
+// (comma separated), or nil when none are configured
+func allowedTransactionTypes() []string {
+	var types []string
+	for _, value := range strings.Split(os.Getenv("TRANSACTION_TYPES"), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			types = append(types, value)
+		}
+	}
+	return types
+}
+
+// normalizeTransactionType validates a transaction type against the allowed
+// types. A type matching an allowed one apart from case or surrounding spaces
+// is rewritten to the allowed spelling so reports don't fragment ("food" vs
+// "Food"). In strict mode any other type is rejected; in free mode it is kept.
+func normalizeTransactionType(transactionType string) (string, error) {
+	transactionType = strings.TrimSpace(transactionType)
+
+	allowed := allowedTransactionTypes()
+	for _, candidate := range allowed {
+		if strings.EqualFold(candidate, transactionType) {
+			return candidate, nil
+		}
+	}
+
+	switch mode := os.Getenv("TRANSACTION_TYPE_MODE"); mode {
+	case "", TransactionTypeModeFree:
+		return transactionType, nil
+	case TransactionTypeModeStrict:
+		if len(allowed) == 0 {
+			log.Printf("Warning: TRANSACTION_TYPE_MODE is strict but TRANSACTION_TYPES is empty; accepting %q", transactionType)
+			return transactionType, nil
+		}
+		return "", fmt.Errorf("unknown type %q (allowed: %s)", transactionType, strings.Join(allowed, ", "))
+	default:
+		log.Printf("Warning: ignoring invalid TRANSACTION_TYPE_MODE %q", mode)
+		return transactionType, nil
+	}
+}
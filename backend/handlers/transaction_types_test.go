@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bennwallet/backend/database"
+)
+
+func TestNormalizeTransactionType(t *testing.T) {
+	testCases := []struct {
+		name         string
+		mode         string
+		value        string
+		expectedType string
+		expectError  bool
+	}{
+		{"allowed value", TransactionTypeModeStrict, "Food", "Food", false},
+		{"allowed value in other case", TransactionTypeModeStrict, " food ", "Food", false},
+		{"unknown value in strict mode", TransactionTypeModeStrict, "Fod", "", true},
+		{"unknown value in free mode", TransactionTypeModeFree, "Anything Goes", "Anything Goes", false},
+		{"free mode still canonicalizes case", "", "HOUSING", "Housing", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("TRANSACTION_TYPES", "Food, Housing,Fun")
+			t.Setenv("TRANSACTION_TYPE_MODE", tc.mode)
+
+			got, err := normalizeTransactionType(tc.value)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected an error for %q, got type %q", tc.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tc.expectedType {
+				t.Errorf("Expected type %q, got %q", tc.expectedType, got)
+			}
+		})
+	}
+}
+
+func TestAddTransactionRejectsUnknownTypeInStrictMode(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+	t.Setenv("TRANSACTION_TYPES", "Food,Housing")
+	t.Setenv("TRANSACTION_TYPE_MODE", TransactionTypeModeStrict)
+
+	body := `{"amount": 10, "description": "Lunch", "type": "Fod", "payTo": "Sarah", "enteredBy": "Patrick"}`
+	req := TestRequest("POST", "/transactions", &body)
+	w := httptest.NewRecorder()
+	AddTransaction(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	body = `{"amount": 10, "description": "Lunch", "type": "food", "payTo": "Sarah", "enteredBy": "Patrick"}`
+	req = TestRequest("POST", "/transactions", &body)
+	w = httptest.NewRecorder()
+	AddTransaction(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected the transaction to be created, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var stored string
+	database.DB.QueryRow("SELECT type FROM transactions").Scan(&stored)
+	if stored != "Food" {
+		t.Errorf("Expected the type to be stored as Food, got %q", stored)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
 
 	"bennwallet/backend/database"
@@ -128,6 +130,13 @@ func GetUserByUsername(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(user)
 }
 
+// bootstrapSuperAdminEnabled reports whether BOOTSTRAP_SUPERADMIN is set, in
+// which case the first user synced while there is no superadmin becomes one
+func bootstrapSuperAdminEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("BOOTSTRAP_SUPERADMIN"))
+	return enabled
+}
+
 // SyncFirebaseUser syncs a Firebase user with the backend database
 // This ensures that Firebase users exist in our users table
 func SyncFirebaseUser(w http.ResponseWriter, r *http.Request) {
@@ -217,20 +226,44 @@ func SyncFirebaseUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if userID == "" {
+		// On a fresh install the first user to sign in can bootstrap the
+		// household as superadmin, so self-hosters don't need database access.
+		// "First" means no superadmin exists yet, since the placeholder users
+		// are seeded on startup. Checking and inserting in one statement keeps
+		// two concurrent first sign-ins from both being promoted.
+		created := false
+		if bootstrapSuperAdminEnabled() {
+			result, err := database.DB.Exec(`
+				INSERT INTO users (id, username, name, status, isAdmin, role)
+				SELECT ?, ?, ?, ?, ?, ?
+				WHERE NOT EXISTS (SELECT 1 FROM users WHERE role = ?)
+			`, request.FirebaseID, request.Email, request.Name, "approved", true, models.RoleSuperAdmin, models.RoleSuperAdmin)
+			if err != nil {
+				http.Error(w, "Failed to create user: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if inserted, _ := result.RowsAffected(); inserted == 1 {
+				created = true
+				log.Printf("Promoted first user %s to superadmin", request.FirebaseID)
+			}
+		}
+
 		// User doesn't exist, create a new one
-		_, err = database.DB.Exec(
-			"INSERT INTO users (id, username, name, status, isAdmin, role) VALUES (?, ?, ?, ?, ?, ?)",
-			request.FirebaseID,
-			request.Email,
-			request.Name,
-			"approved",
-			isDefaultAdmin,
-			role,
-		)
+		if !created {
+			_, err = database.DB.Exec(
+				"INSERT INTO users (id, username, name, status, isAdmin, role) VALUES (?, ?, ?, ?, ?, ?)",
+				request.FirebaseID,
+				request.Email,
+				request.Name,
+				"approved",
+				isDefaultAdmin,
+				role,
+			)
 
-		if err != nil {
-			http.Error(w, "Failed to create user: "+err.Error(), http.StatusInternalServerError)
-			return
+			if err != nil {
+				http.Error(w, "Failed to create user: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
 		}
 
 		userID = request.FirebaseID
@@ -187,3 +187,74 @@ func TestGetUserByUsername_NotFound(t *testing.T) {
 			status, http.StatusNotFound)
 	}
 }
+
+func syncFirebaseUserRole(t *testing.T, firebaseID, name, email string) (string, bool) {
+	body := `{"firebaseId": "` + firebaseID + `", "name": "` + name + `", "email": "` + email + `"}`
+	req := TestRequest("POST", "/users/sync", &body)
+	rr := httptest.NewRecorder()
+	SyncFirebaseUser(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var role string
+	var isAdmin bool
+	err := database.DB.QueryRow("SELECT role, isAdmin FROM users WHERE id = ?", firebaseID).Scan(&role, &isAdmin)
+	if err != nil {
+		t.Fatalf("Failed to read synced user: %v", err)
+	}
+	return role, isAdmin
+}
+
+func TestSyncFirebaseUserBootstrapsSuperAdmin(t *testing.T) {
+	setupTestDB()
+	defer database.DB.Close()
+	database.DB.Exec("DELETE FROM users")
+	t.Setenv("BOOTSTRAP_SUPERADMIN", "true")
+
+	role, isAdmin := syncFirebaseUserRole(t, "first-uid", "First User", "first@example.com")
+	if role != models.RoleSuperAdmin || !isAdmin {
+		t.Errorf("Expected the first user to become an admin superadmin, got role %q, isAdmin %v", role, isAdmin)
+	}
+
+	// Only the very first user is promoted
+	role, isAdmin = syncFirebaseUserRole(t, "second-uid", "Second User", "second@example.com")
+	if role != models.RoleUser || isAdmin {
+		t.Errorf("Expected the second user to be a regular user, got role %q, isAdmin %v", role, isAdmin)
+	}
+}
+
+func TestSyncFirebaseUserBootstrapsSuperAdminOverSeededUsers(t *testing.T) {
+	setupTestDB()
+	defer database.DB.Close()
+	database.DB.Exec("DELETE FROM users")
+	t.Setenv("BOOTSTRAP_SUPERADMIN", "true")
+
+	// Startup seeds the placeholder users before anyone signs in
+	if err := database.SeedDefaultUsers(); err != nil {
+		t.Fatalf("Failed to seed default users: %v", err)
+	}
+
+	role, isAdmin := syncFirebaseUserRole(t, "first-uid", "First User", "first@example.com")
+	if role != models.RoleSuperAdmin || !isAdmin {
+		t.Errorf("Expected the first real user to become an admin superadmin, got role %q, isAdmin %v", role, isAdmin)
+	}
+
+	role, isAdmin = syncFirebaseUserRole(t, "second-uid", "Second User", "second@example.com")
+	if role != models.RoleUser || isAdmin {
+		t.Errorf("Expected the second user to be a regular user, got role %q, isAdmin %v", role, isAdmin)
+	}
+}
+
+func TestSyncFirebaseUserBootstrapDisabled(t *testing.T) {
+	setupTestDB()
+	defer database.DB.Close()
+	database.DB.Exec("DELETE FROM users")
+	t.Setenv("BOOTSTRAP_SUPERADMIN", "")
+
+	role, isAdmin := syncFirebaseUserRole(t, "first-uid", "First User", "first@example.com")
+	if role != models.RoleUser || isAdmin {
+		t.Errorf("Expected a regular user without BOOTSTRAP_SUPERADMIN, got role %q, isAdmin %v", role, isAdmin)
+	}
+}
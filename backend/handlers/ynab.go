@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"time"
@@ -13,6 +14,17 @@ import (
 	"bennwallet/backend/services"
 )
 
+// startInitialCategorySync kicks off the category sync that follows a config save.
+// It is a variable so tests can observe whether the sync is launched.
+var startInitialCategorySync = func(userID, budgetID string) {
+	go func() {
+		log.Printf("Triggering initial YNAB category sync for user %s", userID)
+		if err := services.SyncYNABCategoriesNew(userID, budgetID); err != nil {
+			log.Printf("Error during initial YNAB category sync: %v", err)
+		}
+	}()
+}
+
 // GetYNABCategories returns YNAB categories for a user in a hierarchical structure
 func GetYNABCategories(w http.ResponseWriter, r *http.Request) {
 	userId := r.URL.Query().Get("userId")
@@ -209,6 +221,64 @@ func GetYNABCategories(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(groups)
 }
 
+// GetYNABCategoriesFlat returns the user's YNAB categories as a single list
+// ordered by group and name, paged with limit and offset
+func GetYNABCategoriesFlat(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	limit, offset, err := parseLimitOffset(r, pageResourceYNABCategories)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page := models.YNABCategoryPage{
+		Categories: []models.YNABCategory{},
+		Limit:      limit,
+		Offset:     offset,
+	}
+
+	err = database.DB.QueryRow("SELECT COUNT(*) FROM ynab_categories WHERE user_id = ?", userID).Scan(&page.Total)
+	if err != nil {
+		log.Printf("Error counting YNAB categories: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT c.id, c.name, c.group_id, COALESCE(g.name, '')
+		FROM ynab_categories c
+		LEFT JOIN ynab_category_groups g ON g.id = c.group_id AND g.user_id = c.user_id
+		WHERE c.user_id = ?
+		ORDER BY COALESCE(g.name, ''), c.name, c.id
+		LIMIT ? OFFSET ?
+	`, userID, limit, offset)
+	if err != nil {
+		log.Printf("Error querying YNAB categories: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cat models.YNABCategory
+		if err := rows.Scan(&cat.ID, &cat.Name, &cat.CategoryGroupID, &cat.CategoryGroupName); err != nil {
+			log.Printf("Error scanning category: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		page.Categories = append(page.Categories, cat)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
 // SyncYNABTransaction creates a transaction in YNAB based on split data
 func SyncYNABTransaction(w http.ResponseWriter, r *http.Request) {
 	var request models.YNABSyncRequest
@@ -234,10 +304,19 @@ func SyncYNABTransaction(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create YNAB transaction via service
-	err := models.CreateYNABTransaction(request)
-	if err != nil {
+	err := createYNABTransaction(request)
+	if isPermanentYNABSyncError(err) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
 		log.Printf("Error creating YNAB transaction: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		// Keep the attempt so it can be retried once YNAB is reachable again
+		if queueErr := enqueueYNABSync(request, err); queueErr != nil {
+			log.Printf("Error queueing failed YNAB sync: %v", queueErr)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, err.Error()+" (queued for retry)", http.StatusInternalServerError)
 		return
 	}
 
@@ -312,19 +391,21 @@ func UpdateYNABConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Immediately trigger a sync of the YNAB categories
-	go func() {
-		log.Printf("Triggering initial YNAB category sync for user %s", userID)
-		if err := services.SyncYNABCategoriesNew(userID, request.BudgetID); err != nil {
-			log.Printf("Error during initial YNAB category sync: %v", err)
-		}
-	}()
+	// Immediately trigger a sync of the YNAB categories unless the caller opted out
+	// with ?syncNow=false (e.g. while still in the middle of a multi-step setup)
+	message := "YNAB configuration updated successfully. Categories will be synced in the background."
+	if r.URL.Query().Get("syncNow") == "false" {
+		log.Printf("Skipping initial YNAB category sync for user %s (syncNow=false)", userID)
+		message = "YNAB configuration updated successfully."
+	} else {
+		startInitialCategorySync(userID, request.BudgetID)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{
 		"status":  "success",
-		"message": "YNAB configuration updated successfully. Categories will be synced in the background.",
+		"message": message,
 	})
 }
 
@@ -369,3 +450,230 @@ func SyncYNABCategories(w http.ResponseWriter, r *http.Request) {
 		"message": "YNAB category sync initiated",
 	})
 }
+
+// PreviewYNABCategorySync handles POST requests to see what a category sync
+// would change without applying it
+func PreviewYNABCategorySync(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	// Get the user's YNAB config
+	config, err := models.GetYNABConfig(database.DB, userID)
+	if err != nil {
+		log.Printf("Error retrieving YNAB config: %v", err)
+		http.Error(w, "Error retrieving YNAB configuration", http.StatusInternalServerError)
+		return
+	}
+
+	if !config.HasCredentials {
+		http.Error(w, "YNAB not configured for this user", http.StatusBadRequest)
+		return
+	}
+
+	if config.BudgetID == "" {
+		http.Error(w, "YNAB budget ID not found", http.StatusBadRequest)
+		return
+	}
+
+	preview, err := services.PreviewYNABCategorySync(userID, config.BudgetID)
+	if err != nil {
+		log.Printf("Error previewing YNAB category sync: %v", err)
+		http.Error(w, "Error previewing YNAB category sync", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
+
+// GetYNABAccountBalances handles GET requests for the cleared and uncleared
+// balances of the accounts in the user's budget, for reconciliation
+func GetYNABAccountBalances(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	// Get the user's YNAB config
+	config, err := models.GetYNABConfig(database.DB, userID)
+	if err != nil {
+		log.Printf("Error retrieving YNAB config: %v", err)
+		http.Error(w, "Error retrieving YNAB configuration", http.StatusInternalServerError)
+		return
+	}
+
+	if !config.HasCredentials {
+		http.Error(w, "YNAB not configured for this user", http.StatusBadRequest)
+		return
+	}
+
+	if config.BudgetID == "" {
+		http.Error(w, "YNAB budget ID not found", http.StatusBadRequest)
+		return
+	}
+
+	balances, err := services.GetYNABAccountBalances(userID, config.BudgetID)
+	if err != nil {
+		log.Printf("Error getting YNAB account balances: %v", err)
+		http.Error(w, "Error getting YNAB account balances", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balances)
+}
+
+// ReconcileYNABCategories handles POST requests to bring local categories in line
+// with renames and removals in YNAB. Pass ?dryRun=true to preview the changes.
+func ReconcileYNABCategories(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dryRun") == "true"
+
+	result, err := services.ReconcileYNABCategories(userID, dryRun)
+	if err != nil {
+		log.Printf("Error reconciling YNAB categories: %v", err)
+		http.Error(w, "Error reconciling YNAB categories", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetYNABCategoryMapping reports the YNAB category each of the user's
+// categories resolves to when syncing splits, to help troubleshoot failed syncs
+func GetYNABCategoryMapping(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT name FROM categories
+		WHERE user_id = ? AND archived = 0 AND deleted_at IS NULL
+		ORDER BY name
+	`, userID)
+	if err != nil {
+		log.Printf("Error querying categories: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+
+	mappings := []models.CategoryMapping{}
+	for _, name := range names {
+		mapping := models.CategoryMapping{CategoryName: name}
+		categoryID, err := models.ResolveYNABCategoryID(userID, name)
+		if errors.Is(err, models.ErrNoYNABCategoryMatch) || errors.Is(err, models.ErrAmbiguousYNABCategory) {
+			mapping.YNABCategoryID = models.UnmappedYNABCategory
+			mapping.Reason = err.Error()
+		} else if err != nil {
+			log.Printf("Error resolving YNAB category for '%s': %v", name, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		} else {
+			mapping.YNABCategoryID = categoryID
+		}
+		mappings = append(mappings, mapping)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mappings)
+}
+
+// CopyYNABConfig copies the budget and account selection of one user's YNAB
+// configuration to another user (superadmin only). The target still has to
+// supply their own API token.
+func CopyYNABConfig(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	isSuperAdmin, err := middleware.IsUserSuperAdmin(userID)
+	if err != nil {
+		log.Printf("Error checking superadmin status: %v", err)
+		http.Error(w, "Error checking permissions", http.StatusInternalServerError)
+		return
+	}
+	if !isSuperAdmin {
+		http.Error(w, "Forbidden: superadmin access required", http.StatusForbidden)
+		return
+	}
+
+	var request struct {
+		FromUser string `json:"fromUser"`
+		ToUser   string `json:"toUser"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.FromUser == "" || request.ToUser == "" {
+		http.Error(w, "fromUser and toUser are required", http.StatusBadRequest)
+		return
+	}
+	if request.FromUser == request.ToUser {
+		http.Error(w, "fromUser and toUser must be different users", http.StatusBadRequest)
+		return
+	}
+
+	var targetExists bool
+	if err := database.DB.QueryRow("SELECT COUNT(*) > 0 FROM users WHERE id = ?", request.ToUser).Scan(&targetExists); err != nil {
+		log.Printf("Error looking up user %s: %v", request.ToUser, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !targetExists {
+		http.Error(w, "Target user not found", http.StatusNotFound)
+		return
+	}
+
+	if err := ensureYNABConfigTable(database.DB); err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	err = models.CopyYNABBudgetSelection(database.DB, request.FromUser, request.ToUser)
+	if errors.Is(err, models.ErrNoYNABConfig) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("Error copying YNAB config: %v", err)
+		http.Error(w, "Error copying YNAB configuration", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "success",
+		"message": "Budget and account copied; the target user must set their own API token",
+	})
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+	"bennwallet/backend/services"
+)
+
+// localYNABTransaction is a stored transaction that came from YNAB
+type localYNABTransaction struct {
+	id     string
+	date   string
+	payTo  string
+	amount float64
+}
+
+// ynabMatchKey groups transactions that may be the same one on both sides.
+// Transactions don't record their YNAB ID, so date and payee stand in for it.
+func ynabMatchKey(date, payee string) string {
+	return date + "|" + strings.ToLower(strings.TrimSpace(payee))
+}
+
+// sameYNABAmount compares amounts to the milliunit, ignoring sign since YNAB
+// records outflows as negative
+func sameYNABAmount(a, b float64) bool {
+	return models.DollarsToMilliunits(math.Abs(a)) == models.DollarsToMilliunits(math.Abs(b))
+}
+
+// reconcileYNABTransactions pairs local transactions with YNAB ones of the
+// same date and payee, preferring pairs whose amounts agree, and reports
+// everything that doesn't pair up exactly
+func reconcileYNABTransactions(local []localYNABTransaction, remote []models.YNABAccountTransaction) (int, []models.YNABTransactionDiscrepancy) {
+	candidates := map[string][]int{}
+	for i, t := range remote {
+		key := ynabMatchKey(t.Date, t.PayeeName)
+		candidates[key] = append(candidates[key], i)
+	}
+	usedRemote := make([]bool, len(remote))
+	pairedLocal := make([]bool, len(local))
+
+	matched := 0
+	for i, t := range local {
+		for _, j := range candidates[ynabMatchKey(t.date, t.payTo)] {
+			if !usedRemote[j] && sameYNABAmount(t.amount, remote[j].Amount) {
+				usedRemote[j], pairedLocal[i] = true, true
+				matched++
+				break
+			}
+		}
+	}
+
+	discrepancies := []models.YNABTransactionDiscrepancy{}
+	for i, t := range local {
+		if pairedLocal[i] {
+			continue
+		}
+		localAmount := t.amount
+		d := models.YNABTransactionDiscrepancy{
+			Kind:        models.YNABDiscrepancyMissingInYNAB,
+			Date:        t.date,
+			PayTo:       t.payTo,
+			LocalID:     t.id,
+			LocalAmount: &localAmount,
+		}
+		for _, j := range candidates[ynabMatchKey(t.date, t.payTo)] {
+			if !usedRemote[j] {
+				usedRemote[j] = true
+				ynabAmount := remote[j].Amount
+				d.Kind = models.YNABDiscrepancyAmountMismatch
+				d.YNABID = remote[j].ID
+				d.YNABAmount = &ynabAmount
+				break
+			}
+		}
+		discrepancies = append(discrepancies, d)
+	}
+	for j, t := range remote {
+		if usedRemote[j] {
+			continue
+		}
+		ynabAmount := t.Amount
+		discrepancies = append(discrepancies, models.YNABTransactionDiscrepancy{
+			Kind:       models.YNABDiscrepancyMissingLocally,
+			Date:       t.Date,
+			PayTo:      t.PayeeName,
+			YNABID:     t.ID,
+			YNABAmount: &ynabAmount,
+		})
+	}
+
+	sort.SliceStable(discrepancies, func(a, b int) bool {
+		return discrepancies[a].Date < discrepancies[b].Date
+	})
+	return matched, discrepancies
+}
+
+// ReconcileYNABTransactions handles GET requests comparing the user's locally
+// stored YNAB transactions with those currently in their YNAB account, and
+// lists the amount mismatches and the transactions missing on either side.
+// The account defaults to the configured one; ?accountId= overrides it.
+// The comparison can be limited with startDate/endDate or range.
+func ReconcileYNABTransactions(w http.ResponseWriter, r *http.Request) {
+	// Get user ID from authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	dr, err := ParseDateRange(query.Get("startDate"), query.Get("endDate"), query.Get("range"), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Get the user's YNAB config
+	config, err := models.GetYNABConfig(database.DB, userID)
+	if err != nil {
+		log.Printf("Error retrieving YNAB config: %v", err)
+		http.Error(w, "Error retrieving YNAB configuration", http.StatusInternalServerError)
+		return
+	}
+
+	if !config.HasCredentials {
+		http.Error(w, "YNAB not configured for this user", http.StatusBadRequest)
+		return
+	}
+
+	if config.BudgetID == "" {
+		http.Error(w, "YNAB budget ID not found", http.StatusBadRequest)
+		return
+	}
+
+	accountID := query.Get("accountId")
+	if accountID == "" {
+		accountID = config.AccountID
+	}
+	if accountID == "" {
+		http.Error(w, "YNAB account ID not found", http.StatusBadRequest)
+		return
+	}
+
+	sqlQuery := `
+		SELECT id, amount, date, payTo
+		FROM transactions
+		WHERE userId = ? AND source = ? AND deleted_at IS NULL
+	`
+	args := []interface{}{userID, models.TransactionSourceYNAB}
+	dateClause, dateArgs := dr.SQLConditions("date")
+	sqlQuery += dateClause + " ORDER BY date, id"
+	args = append(args, dateArgs...)
+
+	rows, err := database.DB.Query(sqlQuery, args...)
+	if err != nil {
+		log.Printf("Error querying YNAB transactions: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	local := []localYNABTransaction{}
+	for rows.Next() {
+		var t localYNABTransaction
+		var date time.Time
+		var payTo sql.NullString
+		if err := rows.Scan(&t.id, &t.amount, &date, &payTo); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		t.date = date.Format(dateLayout)
+		t.payTo = payTo.String
+		local = append(local, t)
+	}
+	if err := rows.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	remote, err := services.GetYNABAccountTransactions(userID, config.BudgetID, accountID, dr.StartString())
+	if err != nil {
+		log.Printf("Error getting YNAB account transactions: %v", err)
+		http.Error(w, "Error getting YNAB account transactions", http.StatusInternalServerError)
+		return
+	}
+	// YNAB can only limit the start of the range
+	if end := dr.EndString(); end != "" {
+		inRange := remote[:0]
+		for _, t := range remote {
+			if t.Date <= end {
+				inRange = append(inRange, t)
+			}
+		}
+		remote = inRange
+	}
+
+	result := models.YNABTransactionReconciliation{
+		AccountID: accountID,
+		StartDate: dr.StartString(),
+		EndDate:   dr.EndString(),
+	}
+	result.Matched, result.Discrepancies = reconcileYNABTransactions(local, remote)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
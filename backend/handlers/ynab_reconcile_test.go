@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+	"bennwallet/backend/security"
+)
+
+func TestReconcileYNABTransactions(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+	security.InitializeEncryption("test-encryption-key")
+	if err := ensureYNABConfigTable(database.DB); err != nil {
+		t.Fatalf("Failed to create ynab_config table: %v", err)
+	}
+	config := &models.YNABConfigUpdateRequest{APIToken: "test-token", BudgetID: "budget-1", AccountID: "account-1"}
+	if err := models.UpsertYNABConfig(database.DB, config, TestUserID); err != nil {
+		t.Fatalf("Failed to save YNAB config: %v", err)
+	}
+
+	// Local copies as they were when synced from YNAB
+	_, err := database.DB.Exec(`
+		INSERT INTO transactions (id, amount, description, date, type, payTo, enteredBy, userId, source, deleted_at) VALUES
+		('t-match', 42.50, 'Groceries', '2024-03-01', 'Groceries', 'Market', ?, ?, 'ynab', NULL),
+		('t-changed', 20.00, 'Dinner', '2024-03-02', 'Dining', 'Bistro', ?, ?, 'ynab', NULL),
+		('t-gone', 15.00, 'Movie', '2024-03-03', 'Entertainment', 'Cinema', ?, ?, 'ynab', NULL),
+		('t-manual', 99.00, 'Cash', '2024-03-03', 'Other', 'Shop', ?, ?, 'manual', NULL),
+		('t-deleted', 5.00, 'Coffee', '2024-03-04', 'Dining', 'Cafe', ?, ?, 'ynab', '2024-03-05')
+	`, TestUserID, TestUserID, TestUserID, TestUserID, TestUserID, TestUserID, TestUserID, TestUserID, TestUserID, TestUserID)
+	if err != nil {
+		t.Fatalf("Failed to seed transactions: %v", err)
+	}
+
+	var gotPath, gotSince string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotSince = r.URL.Path, r.URL.Query().Get("since_date")
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"data": {"transactions": [
+			{"id": "y-match", "date": "2024-03-01", "amount": -42500, "payee_name": "market"},
+			{"id": "y-changed", "date": "2024-03-02", "amount": -25000, "payee_name": "Bistro"},
+			{"id": "y-new", "date": "2024-03-04", "amount": -8000, "payee_name": "Hardware"},
+			{"id": "y-removed", "date": "2024-03-04", "amount": -1000, "payee_name": "Kiosk", "deleted": true},
+			{"id": "y-later", "date": "2024-04-01", "amount": -3000, "payee_name": "Later"}
+		]}}`))
+	}))
+	defer server.Close()
+	original := models.YNABAPIBaseURL
+	models.YNABAPIBaseURL = server.URL
+	defer func() { models.YNABAPIBaseURL = original }()
+
+	req := TestRequest("GET", "/ynab/reconcile?startDate=2024-03-01&endDate=2024-03-31", nil)
+	w := httptest.NewRecorder()
+	ReconcileYNABTransactions(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if gotPath != "/budgets/budget-1/accounts/account-1/transactions" || gotSince != "2024-03-01" {
+		t.Errorf("Unexpected YNAB request %s since %q", gotPath, gotSince)
+	}
+
+	var result models.YNABTransactionReconciliation
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	if result.AccountID != "account-1" || result.Matched != 1 {
+		t.Errorf("Expected 1 match in account-1, got %d in %q", result.Matched, result.AccountID)
+	}
+	if len(result.Discrepancies) != 3 {
+		t.Fatalf("Expected 3 discrepancies, got %+v", result.Discrepancies)
+	}
+
+	changed := result.Discrepancies[0]
+	if changed.Kind != models.YNABDiscrepancyAmountMismatch || changed.LocalID != "t-changed" || changed.YNABID != "y-changed" ||
+		changed.LocalAmount == nil || *changed.LocalAmount != 20 || changed.YNABAmount == nil || *changed.YNABAmount != -25 {
+		t.Errorf("Expected the Bistro amounts to mismatch, got %+v", changed)
+	}
+	gone := result.Discrepancies[1]
+	if gone.Kind != models.YNABDiscrepancyMissingInYNAB || gone.LocalID != "t-gone" || gone.YNABID != "" {
+		t.Errorf("Expected the Cinema transaction to be missing in YNAB, got %+v", gone)
+	}
+	added := result.Discrepancies[2]
+	if added.Kind != models.YNABDiscrepancyMissingLocally || added.YNABID != "y-new" || added.LocalID != "" {
+		t.Errorf("Expected the Hardware transaction to be missing locally, got %+v", added)
+	}
+}
+
+func TestReconcileYNABTransactionsRequiresConfig(t *testing.T) {
+	setupTransactionTestDB()
+	defer CleanupTestDB()
+	security.InitializeEncryption("test-encryption-key")
+	if err := ensureYNABConfigTable(database.DB); err != nil {
+		t.Fatalf("Failed to create ynab_config table: %v", err)
+	}
+	if _, err := database.DB.Exec(`CREATE TABLE IF NOT EXISTS user_ynab_settings (
+		user_id TEXT PRIMARY KEY, token TEXT, budget_id TEXT, account_id TEXT, sync_enabled INTEGER, last_synced TIMESTAMP
+	)`); err != nil {
+		t.Fatalf("Failed to create user_ynab_settings table: %v", err)
+	}
+
+	req := TestRequest("GET", "/ynab/reconcile", nil)
+	w := httptest.NewRecorder()
+	ReconcileYNABTransactions(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d without YNAB config, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
+)
+
+// createYNABTransaction sends a sync request to YNAB. It is a variable so
+// tests can simulate YNAB failing.
+var createYNABTransaction = models.CreateYNABTransaction
+
+// isPermanentYNABSyncError reports whether a sync failed because of the
+// request itself, which retrying won't fix
+func isPermanentYNABSyncError(err error) bool {
+	return errors.Is(err, models.ErrUnknownYNABAccount) ||
+		errors.Is(err, models.ErrNoYNABCategoryMatch) ||
+		errors.Is(err, models.ErrAmbiguousYNABCategory)
+}
+
+// enqueueYNABSync records a failed sync request so it can be retried later
+func enqueueYNABSync(request models.YNABSyncRequest, syncErr error) error {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	_, err = database.DB.Exec(`
+		INSERT INTO ynab_sync_queue (user_id, request, last_error)
+		VALUES (?, ?, ?)
+	`, request.UserID, string(data), syncErr.Error())
+	if err == nil {
+		log.Printf("Queued failed YNAB sync for user %s", request.UserID)
+	}
+	return err
+}
+
+// RetryFailedYNABSyncs re-attempts the user's queued YNAB syncs, oldest
+// first. Syncs that succeed are removed from the queue, as are ones that fail
+// because of the request itself; the rest stay queued for the next retry.
+func RetryFailedYNABSyncs(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the authentication context
+	userID := middleware.GetUserIDFromContext(r)
+	if userID == "" {
+		http.Error(w, "Unauthorized: No user ID found", http.StatusUnauthorized)
+		return
+	}
+
+	rows, err := database.DB.Query("SELECT id, request FROM ynab_sync_queue WHERE user_id = ? ORDER BY id", userID)
+	if err != nil {
+		log.Printf("Error querying YNAB sync queue: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	type queuedSync struct {
+		id      int64
+		request string
+	}
+	var queued []queuedSync
+	for rows.Next() {
+		var q queuedSync
+		if err := rows.Scan(&q.id, &q.request); err != nil {
+			rows.Close()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		queued = append(queued, q)
+	}
+	rows.Close()
+
+	var result models.YNABSyncRetryResult
+	for _, q := range queued {
+		result.Attempted++
+
+		// Unreadable entries can't be retried either
+		var request models.YNABSyncRequest
+		var syncErr error
+		permanent := true
+		if syncErr = json.Unmarshal([]byte(q.request), &request); syncErr == nil {
+			syncErr = createYNABTransaction(request)
+			permanent = isPermanentYNABSyncError(syncErr)
+		}
+
+		var dbErr error
+		switch {
+		case syncErr == nil:
+			result.Succeeded++
+			_, dbErr = database.DB.Exec("DELETE FROM ynab_sync_queue WHERE id = ?", q.id)
+		case permanent:
+			log.Printf("Dropping queued YNAB sync %d: %v", q.id, syncErr)
+			result.Dropped++
+			_, dbErr = database.DB.Exec("DELETE FROM ynab_sync_queue WHERE id = ?", q.id)
+		default:
+			log.Printf("Retry of queued YNAB sync %d failed: %v", q.id, syncErr)
+			result.Failed++
+			_, dbErr = database.DB.Exec(`
+				UPDATE ynab_sync_queue SET attempts = attempts + 1, last_error = ?, last_attempt_at = ?
+				WHERE id = ?
+			`, syncErr.Error(), time.Now(), q.id)
+		}
+		if dbErr != nil {
+			log.Printf("Error updating queued YNAB sync %d: %v", q.id, dbErr)
+			http.Error(w, dbErr.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	log.Printf("Retried %d queued YNAB syncs for user %s: %d succeeded, %d failed, %d dropped",
+		result.Attempted, userID, result.Succeeded, result.Failed, result.Dropped)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/migrations"
+	"bennwallet/backend/models"
+)
+
+func setupYNABSyncQueueTestDB(t *testing.T) {
+	SetupTestDB()
+	if err := migrations.AddYNABSyncQueueTable(database.DB); err != nil {
+		t.Fatalf("Failed to create ynab_sync_queue table: %v", err)
+	}
+}
+
+// stubCreateYNABTransaction makes YNAB syncs fail with the given error (or
+// succeed when it is nil) for the duration of a test, recording the requests
+func stubCreateYNABTransaction(t *testing.T, syncErr *error) *[]models.YNABSyncRequest {
+	var calls []models.YNABSyncRequest
+	original := createYNABTransaction
+	createYNABTransaction = func(request models.YNABSyncRequest) error {
+		calls = append(calls, request)
+		return *syncErr
+	}
+	t.Cleanup(func() { createYNABTransaction = original })
+	return &calls
+}
+
+func queuedYNABSyncAttempts(t *testing.T) []int {
+	t.Helper()
+
+	rows, err := database.DB.Query("SELECT attempts FROM ynab_sync_queue ORDER BY id")
+	if err != nil {
+		t.Fatalf("Error querying queue: %v", err)
+	}
+	defer rows.Close()
+	attempts := []int{}
+	for rows.Next() {
+		var n int
+		rows.Scan(&n)
+		attempts = append(attempts, n)
+	}
+	return attempts
+}
+
+func retryFailedYNABSyncs(t *testing.T) models.YNABSyncRetryResult {
+	t.Helper()
+
+	req := TestRequest("POST", "/ynab/sync/retry-failed", nil)
+	w := httptest.NewRecorder()
+	RetryFailedYNABSyncs(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var result models.YNABSyncRetryResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	return result
+}
+
+func TestFailedYNABSyncIsQueuedAndRetried(t *testing.T) {
+	setupYNABSyncQueueTestDB(t)
+	defer CleanupTestDB()
+
+	syncErr := errors.New("YNAB API returned status 503")
+	calls := stubCreateYNABTransaction(t, &syncErr)
+
+	body := fmt.Sprintf(`{"userId": %q, "date": "2024-05-01", "payeeName": "Store", "categories": [{"categoryName": "Groceries", "amount": 12.5}]}`, TestUserID)
+	req := TestRequest("POST", "/ynab/sync", &body)
+	w := httptest.NewRecorder()
+	SyncYNABTransaction(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusInternalServerError, w.Code, w.Body.String())
+	}
+	if attempts := queuedYNABSyncAttempts(t); len(attempts) != 1 {
+		t.Fatalf("Expected the failed sync to be queued, got %d queued", len(attempts))
+	}
+
+	// YNAB is still down: the sync stays queued
+	result := retryFailedYNABSyncs(t)
+	if result.Attempted != 1 || result.Failed != 1 {
+		t.Errorf("Expected 1 failed retry, got %+v", result)
+	}
+	if attempts := queuedYNABSyncAttempts(t); len(attempts) != 1 || attempts[0] != 2 {
+		t.Errorf("Expected one queued sync with 2 attempts, got %v", attempts)
+	}
+
+	// Once YNAB is back the retry goes through and leaves the queue
+	syncErr = nil
+	result = retryFailedYNABSyncs(t)
+	if result.Attempted != 1 || result.Succeeded != 1 {
+		t.Errorf("Expected 1 successful retry, got %+v", result)
+	}
+	if attempts := queuedYNABSyncAttempts(t); len(attempts) != 0 {
+		t.Errorf("Expected an empty queue, got %v", attempts)
+	}
+	last := (*calls)[len(*calls)-1]
+	if len(*calls) != 3 || last.PayeeName != "Store" || len(last.Categories) != 1 || last.Categories[0].Amount != 12.5 {
+		t.Errorf("Expected the original request to be retried, got %+v", *calls)
+	}
+}
+
+func TestYNABSyncValidationErrorsAreNotQueued(t *testing.T) {
+	setupYNABSyncQueueTestDB(t)
+	defer CleanupTestDB()
+
+	syncErr := models.ErrNoYNABCategoryMatch
+	stubCreateYNABTransaction(t, &syncErr)
+
+	body := fmt.Sprintf(`{"userId": %q, "date": "2024-05-01", "categories": [{"categoryName": "Nonsense", "amount": 5}]}`, TestUserID)
+	req := TestRequest("POST", "/ynab/sync", &body)
+	w := httptest.NewRecorder()
+	SyncYNABTransaction(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	if attempts := queuedYNABSyncAttempts(t); len(attempts) != 0 {
+		t.Errorf("Expected nothing queued, got %v", attempts)
+	}
+
+	// Queued syncs that can no longer succeed are dropped on retry
+	enqueueYNABSync(models.YNABSyncRequest{UserID: TestUserID, Date: "2024-05-01"}, errors.New("timeout"))
+	if result := retryFailedYNABSyncs(t); result.Dropped != 1 {
+		t.Errorf("Expected 1 dropped sync, got %+v", result)
+	}
+	if attempts := queuedYNABSyncAttempts(t); len(attempts) != 0 {
+		t.Errorf("Expected an empty queue, got %v", attempts)
+	}
+}
@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+	"bennwallet/backend/security"
+)
+
+func setupYNABConfigTestDB(t *testing.T) {
+	SetupTestDB()
+	security.InitializeEncryption("test-encryption-key")
+
+	if err := ensureYNABConfigTable(database.DB); err != nil {
+		t.Fatalf("Failed to create ynab_config table: %v", err)
+	}
+}
+
+// stubInitialCategorySync replaces the post-save sync with a recorder for the duration of a test
+func stubInitialCategorySync(t *testing.T) *[]string {
+	var calls []string
+	original := startInitialCategorySync
+	startInitialCategorySync = func(userID, budgetID string) {
+		calls = append(calls, userID+":"+budgetID)
+	}
+	t.Cleanup(func() { startInitialCategorySync = original })
+	return &calls
+}
+
+func TestUpdateYNABConfigSyncNow(t *testing.T) {
+	testCases := []struct {
+		name         string
+		url          string
+		expectedSync bool
+	}{
+		{"default triggers sync", "/ynab/config", true},
+		{"syncNow=true triggers sync", "/ynab/config?syncNow=true", true},
+		{"syncNow=false suppresses sync", "/ynab/config?syncNow=false", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setupYNABConfigTestDB(t)
+			defer CleanupTestDB()
+			calls := stubInitialCategorySync(t)
+
+			body := `{"apiToken": "token", "budgetId": "budget-1", "accountId": "account-1"}`
+			req := TestRequest("PUT", tc.url, &body)
+			w := httptest.NewRecorder()
+
+			UpdateYNABConfig(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+
+			launched := len(*calls) > 0
+			if launched != tc.expectedSync {
+				t.Errorf("Expected sync launched=%v, got %v", tc.expectedSync, launched)
+			}
+			if launched && (*calls)[0] != TestUserID+":budget-1" {
+				t.Errorf("Unexpected sync arguments: %v", *calls)
+			}
+		})
+	}
+}
+
+func setupReconcileTestDB() {
+	setupCategoryTestDB()
+
+	_, err := database.DB.Exec(`
+		CREATE TABLE ynab_categories (
+			id TEXT NOT NULL,
+			group_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			last_updated DATETIME NOT NULL,
+			PRIMARY KEY (id, user_id)
+		)
+	`)
+	if err != nil {
+		panic(err)
+	}
+
+	// Local copies as they were after the last sync
+	_, err = database.DB.Exec(`
+		INSERT INTO categories (id, name, description, user_id, color, ynab_category_id) VALUES
+		(1, 'Groceries', 'Synced from YNAB', ?, '#FF6B6B', 'ynab-groceries'),
+		(2, 'Gym', 'Synced from YNAB', ?, '#4ECDC4', 'ynab-gym'),
+		(3, 'Dining Out', 'Synced from YNAB', ?, '#45B7D1', 'ynab-dining'),
+		(4, 'Local Only', 'Created by hand', ?, '#96CEB4', NULL)
+	`, TestUserID, TestUserID, TestUserID, TestUserID)
+	if err != nil {
+		panic(err)
+	}
+
+	// Current YNAB state: groceries renamed, gym deleted, dining unchanged
+	_, err = database.DB.Exec(`
+		INSERT INTO ynab_categories (id, group_id, name, user_id, last_updated) VALUES
+		('ynab-groceries', 'group-1', 'Food & Groceries', ?, CURRENT_TIMESTAMP),
+		('ynab-dining', 'group-1', 'Dining Out', ?, CURRENT_TIMESTAMP)
+	`, TestUserID, TestUserID)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestReconcileYNABCategories(t *testing.T) {
+	setupReconcileTestDB()
+	defer database.DB.Close()
+
+	req := TestRequest("POST", "/ynab/categories/reconcile", nil)
+	w := httptest.NewRecorder()
+	ReconcileYNABCategories(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var result models.CategoryReconcileResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	if len(result.Renamed) != 1 || result.Renamed[0].OldName != "Groceries" || result.Renamed[0].NewName != "Food & Groceries" {
+		t.Errorf("Expected Groceries to be renamed, got %+v", result.Renamed)
+	}
+	if len(result.Archived) != 1 || result.Archived[0].Name != "Gym" {
+		t.Errorf("Expected Gym to be archived, got %+v", result.Archived)
+	}
+
+	// The rename is applied locally and keeps the same category ID
+	var name string
+	database.DB.QueryRow("SELECT name FROM categories WHERE id = 1").Scan(&name)
+	if name != "Food & Groceries" {
+		t.Errorf("Expected local category to be renamed, got %q", name)
+	}
+
+	// The removed category is archived, not deleted
+	var archived bool
+	if err := database.DB.QueryRow("SELECT archived FROM categories WHERE id = 2").Scan(&archived); err != nil {
+		t.Fatalf("Expected archived category to still exist: %v", err)
+	}
+	if !archived {
+		t.Error("Expected category removed from YNAB to be archived")
+	}
+
+	// Unlinked and unchanged categories are untouched
+	database.DB.QueryRow("SELECT archived FROM categories WHERE id = 4").Scan(&archived)
+	if archived {
+		t.Error("Expected hand-made category to be left alone")
+	}
+
+	// Archived categories drop out of the category list
+	req = TestRequest("GET", "/categories", nil)
+	w = httptest.NewRecorder()
+	GetCategories(w, req)
+
+	var categories []models.Category
+	json.NewDecoder(w.Body).Decode(&categories)
+	if len(categories) != 3 {
+		t.Errorf("Expected 3 active categories, got %d", len(categories))
+	}
+	for _, c := range categories {
+		if c.Name == "Gym" {
+			t.Error("Archived category should not be listed")
+		}
+	}
+}
+
+func TestReconcileYNABCategoriesDryRun(t *testing.T) {
+	setupReconcileTestDB()
+	defer database.DB.Close()
+
+	req := TestRequest("POST", "/ynab/categories/reconcile?dryRun=true", nil)
+	w := httptest.NewRecorder()
+	ReconcileYNABCategories(w, req)
+
+	var result models.CategoryReconcileResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+	if !result.DryRun || len(result.Renamed) != 1 || len(result.Archived) != 1 {
+		t.Errorf("Expected dry run to report 1 rename and 1 archive, got %+v", result)
+	}
+
+	var name string
+	var archived bool
+	database.DB.QueryRow("SELECT name FROM categories WHERE id = 1").Scan(&name)
+	database.DB.QueryRow("SELECT archived FROM categories WHERE id = 2").Scan(&archived)
+	if name != "Groceries" || archived {
+		t.Errorf("Dry run should not modify categories (name=%q archived=%v)", name, archived)
+	}
+}
+
+func TestGetYNABCategoryMapping(t *testing.T) {
+	SetupTestDB()
+	defer CleanupTestDB()
+
+	for _, stmt := range []string{
+		`CREATE TABLE categories (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			archived BOOLEAN NOT NULL DEFAULT 0,
+			deleted_at DATETIME
+		)`,
+		`CREATE TABLE ynab_categories (
+			id TEXT NOT NULL,
+			group_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			last_updated DATETIME
+		)`,
+		`INSERT INTO categories (name, user_id) VALUES ('Groceries', 'test-user-id'), ('Pet Supplies', 'test-user-id')`,
+		`INSERT INTO categories (name, user_id) VALUES ('Rent', 'other-user')`,
+		`INSERT INTO ynab_categories (id, group_id, name, user_id) VALUES ('ynab-groceries', 'group-1', 'Groceries', 'test-user-id')`,
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to set up test database: %v", err)
+		}
+	}
+
+	req := TestRequest("GET", "/ynab/categories/mapping", nil)
+	w := httptest.NewRecorder()
+	GetYNABCategoryMapping(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var mappings []models.CategoryMapping
+	if err := json.NewDecoder(w.Body).Decode(&mappings); err != nil {
+		t.Fatalf("Error decoding response: %v", err)
+	}
+
+	if len(mappings) != 2 {
+		t.Fatalf("Expected 2 mappings for the caller's categories, got %d: %+v", len(mappings), mappings)
+	}
+	if mappings[0].CategoryName != "Groceries" || mappings[0].YNABCategoryID != "ynab-groceries" {
+		t.Errorf("Expected Groceries to be mapped, got %+v", mappings[0])
+	}
+	if mappings[1].CategoryName != "Pet Supplies" || mappings[1].YNABCategoryID != models.UnmappedYNABCategory || mappings[1].Reason == "" {
+		t.Errorf("Expected Pet Supplies to be reported as unmapped, got %+v", mappings[1])
+	}
+}
+
+func TestCopyYNABConfig(t *testing.T) {
+	setupYNABConfigTestDB(t)
+	defer CleanupTestDB()
+
+	for _, stmt := range []string{
+		`UPDATE users SET role = 'superadmin' WHERE id = 'test-user-id'`,
+		`INSERT INTO users (id, username, name, isAdmin, role) VALUES ('partner', 'partner', 'Partner', 0, 'user')`,
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to seed users: %v", err)
+		}
+	}
+	source := &models.YNABConfigUpdateRequest{APIToken: "secret-token", BudgetID: "budget-1", AccountID: "account-1"}
+	if err := models.UpsertYNABConfig(database.DB, source, TestUserID); err != nil {
+		t.Fatalf("Failed to store source config: %v", err)
+	}
+
+	body := `{"fromUser": "test-user-id", "toUser": "partner"}`
+	req := TestRequest("POST", "/admin/ynab/copy-config", &body)
+	w := httptest.NewRecorder()
+	CopyYNABConfig(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	config, err := models.GetYNABConfig(database.DB, "partner")
+	if err != nil {
+		t.Fatalf("Failed to read copied config: %v", err)
+	}
+	if config.EncryptedBudgetID == "" || config.EncryptedAccountID == "" {
+		t.Fatalf("Expected budget and account to be copied, got %+v", config)
+	}
+	if config.HasCredentials || config.EncryptedAPIToken != "" {
+		t.Error("Expected the API token not to be copied")
+	}
+
+	budgetID, _ := security.Decrypt(config.EncryptedBudgetID)
+	accountID, _ := security.Decrypt(config.EncryptedAccountID)
+	if budgetID != "budget-1" || accountID != "account-1" {
+		t.Errorf("Expected budget-1/account-1, got %s/%s", budgetID, accountID)
+	}
+}
+
+func TestCopyYNABConfigRequiresSuperadmin(t *testing.T) {
+	setupYNABConfigTestDB(t)
+	defer CleanupTestDB()
+
+	// The test user is an admin, which is not enough
+	body := `{"fromUser": "test-user-id", "toUser": "partner"}`
+	req := TestRequest("POST", "/admin/ynab/copy-config", &body)
+	w := httptest.NewRecorder()
+	CopyYNABConfig(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status code %d, got %d", http.StatusForbidden, w.Code)
+	}
+}
+
+func TestGetYNABCategoriesFlatPaging(t *testing.T) {
+	SetupTestDB()
+	defer CleanupTestDB()
+
+	for _, stmt := range []string{
+		`CREATE TABLE ynab_category_groups (id TEXT NOT NULL, name TEXT NOT NULL, user_id TEXT NOT NULL, last_updated DATETIME)`,
+		`CREATE TABLE ynab_categories (id TEXT NOT NULL, group_id TEXT NOT NULL, name TEXT NOT NULL, user_id TEXT NOT NULL, last_updated DATETIME)`,
+		`INSERT INTO ynab_category_groups (id, name, user_id) VALUES ('group-1', 'Bills', 'test-user-id')`,
+		`INSERT INTO ynab_categories (id, group_id, name, user_id) VALUES ('other', 'group-1', 'Other', 'other-user')`,
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to set up test database: %v", err)
+		}
+	}
+	const seeded = 250
+	for i := 0; i < seeded; i++ {
+		_, err := database.DB.Exec(`INSERT INTO ynab_categories (id, group_id, name, user_id) VALUES (?, 'group-1', ?, 'test-user-id')`,
+			fmt.Sprintf("cat-%03d", i), fmt.Sprintf("Category %03d", i))
+		if err != nil {
+			t.Fatalf("Failed to insert category: %v", err)
+		}
+	}
+
+	seen := map[string]bool{}
+	for offset := 0; offset < seeded; offset += 100 {
+		req := TestRequest("GET", fmt.Sprintf("/ynab/categories/flat?limit=100&offset=%d", offset), nil)
+		w := httptest.NewRecorder()
+		GetYNABCategoriesFlat(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+
+		var page models.YNABCategoryPage
+		if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+			t.Fatalf("Error decoding response: %v", err)
+		}
+		if page.Total != seeded {
+			t.Errorf("Expected total %d, got %d", seeded, page.Total)
+		}
+
+		expected := 100
+		if offset+100 > seeded {
+			expected = seeded - offset
+		}
+		if len(page.Categories) != expected {
+			t.Errorf("Expected %d categories at offset %d, got %d", expected, offset, len(page.Categories))
+		}
+		for _, cat := range page.Categories {
+			if seen[cat.ID] {
+				t.Errorf("Category %s returned on more than one page", cat.ID)
+			}
+			seen[cat.ID] = true
+			if cat.CategoryGroupName != "Bills" {
+				t.Errorf("Expected group name Bills, got %q", cat.CategoryGroupName)
+			}
+		}
+	}
+	if len(seen) != seeded {
+		t.Errorf("Expected to page through %d categories, saw %d", seeded, len(seen))
+	}
+
+	req := TestRequest("GET", "/ynab/categories/flat?limit=1000", nil)
+	w := httptest.NewRecorder()
+	GetYNABCategoriesFlat(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an oversized limit, got %d", http.StatusBadRequest, w.Code)
+	}
+}
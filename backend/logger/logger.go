@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Level represents the severity of a log message
+type Level int32
+
+// Log levels, from most to least verbose
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var currentLevel int32 = int32(LevelInfo)
+
+func init() {
+	SetLevelFromEnv()
+}
+
+// ParseLevel converts a level name (debug, info, warn, error) to a Level.
+// Unknown or empty names fall back to info.
+func ParseLevel(name string) Level {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug
+	case "warn", "warning":
+		return LevelWarn
+	case "error":
+		return LevelError
+	default:
+		return LevelInfo
+	}
+}
+
+// SetLevelFromEnv sets the active level from the LOG_LEVEL environment variable
+func SetLevelFromEnv() {
+	SetLevel(ParseLevel(os.Getenv("LOG_LEVEL")))
+}
+
+// SetLevel sets the minimum level that will be written
+func SetLevel(level Level) {
+	atomic.StoreInt32(&currentLevel, int32(level))
+}
+
+// GetLevel returns the minimum level that will be written
+func GetLevel() Level {
+	return Level(atomic.LoadInt32(&currentLevel))
+}
+
+// Enabled reports whether messages at the given level are written
+func Enabled(level Level) bool {
+	return level >= GetLevel()
+}
+
+// Debugf logs a DEBUG-prefixed message when debug logging is enabled
+func Debugf(format string, args ...interface{}) {
+	logf(LevelDebug, "DEBUG: ", format, args...)
+}
+
+// Infof logs an informational message
+func Infof(format string, args ...interface{}) {
+	logf(LevelInfo, "", format, args...)
+}
+
+// Warnf logs a WARNING-prefixed message
+func Warnf(format string, args ...interface{}) {
+	logf(LevelWarn, "WARNING: ", format, args...)
+}
+
+// Errorf logs an ERROR-prefixed message
+func Errorf(format string, args ...interface{}) {
+	logf(LevelError, "ERROR: ", format, args...)
+}
+
+func logf(level Level, prefix, format string, args ...interface{}) {
+	if !Enabled(level) {
+		return
+	}
+	log.Printf(prefix+format, args...)
+}
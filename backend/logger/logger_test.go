@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureOutput redirects the standard logger while fn runs and returns what was written
+func captureOutput(fn func()) string {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	fn()
+	return buf.String()
+}
+
+func TestDebugSuppressedAtInfoLevel(t *testing.T) {
+	original := GetLevel()
+	defer SetLevel(original)
+
+	SetLevel(LevelInfo)
+	output := captureOutput(func() {
+		Debugf("hidden %s", "message")
+		Infof("visible %s", "message")
+	})
+
+	if strings.Contains(output, "hidden message") {
+		t.Errorf("Expected debug line to be suppressed at info level, got %q", output)
+	}
+	if !strings.Contains(output, "visible message") {
+		t.Errorf("Expected info line to be written, got %q", output)
+	}
+}
+
+func TestDebugWrittenAtDebugLevel(t *testing.T) {
+	original := GetLevel()
+	defer SetLevel(original)
+
+	SetLevel(LevelDebug)
+	output := captureOutput(func() {
+		Debugf("shown %d", 42)
+	})
+
+	if !strings.Contains(output, "DEBUG: shown 42") {
+		t.Errorf("Expected DEBUG-prefixed line, got %q", output)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected Level
+	}{
+		{"debug", LevelDebug},
+		{"INFO", LevelInfo},
+		{"warn", LevelWarn},
+		{"warning", LevelWarn},
+		{"error", LevelError},
+		{"", LevelInfo},
+		{"verbose", LevelInfo},
+	}
+
+	for _, tc := range testCases {
+		if got := ParseLevel(tc.input); got != tc.expected {
+			t.Errorf("ParseLevel(%q) = %v, expected %v", tc.input, got, tc.expected)
+		}
+	}
+}
+
+func TestSetLevelFromEnv(t *testing.T) {
+	original := GetLevel()
+	defer SetLevel(original)
+
+	t.Setenv("LOG_LEVEL", "error")
+	SetLevelFromEnv()
+
+	if GetLevel() != LevelError {
+		t.Errorf("Expected level %v from LOG_LEVEL, got %v", LevelError, GetLevel())
+	}
+	if Enabled(LevelWarn) {
+		t.Error("Expected warn to be disabled at error level")
+	}
+}
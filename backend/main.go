@@ -9,7 +9,9 @@ import (
 
 	"bennwallet/backend/database"
 	"bennwallet/backend/handlers"
+	"bennwallet/backend/logger"
 	"bennwallet/backend/middleware"
+	"bennwallet/backend/models"
 	"bennwallet/backend/security"
 	"bennwallet/backend/services"
 
@@ -17,12 +19,19 @@ import (
 )
 
 func main() {
-	// Check if we're running in database reset mode
-	if os.Getenv("RESET_DB") == "true" {
+	// Apply LOG_LEVEL from the process environment; it is applied again
+	// once any .env file has been loaded
+	logger.SetLevelFromEnv()
+
+	// Check if we're running in database reset mode. Only an explicit
+	// RESET_DB=true resets anything.
+	resetPlan := database.ResetPlanFromEnv(os.Getenv)
+	if resetPlan.Reset {
 		log.Println("Running in database reset mode")
+		warnBeforeReset(resetPlan.GracePeriod)
 
 		// In PR deployments with RESET_DB=true, completely reset the database
-		if os.Getenv("PR_DEPLOYMENT") == "true" {
+		if resetPlan.DeleteFile {
 			log.Println("PR deployment with RESET_DB=true - completely recreating database")
 			dbPath := os.Getenv("DB_PATH")
 			if dbPath == "" {
@@ -70,6 +79,11 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// Send read-only queries to the replica when one is configured
+	if err := database.InitReadDB(); err != nil {
+		log.Fatal(err)
+	}
+
 	// Seed default users but don't start syncing yet
 	err = database.SeedDefaultUsers()
 	if err != nil {
@@ -80,7 +94,7 @@ func main() {
 	if err := database.RunMigrations(); err != nil {
 		// For RESET_DB mode, don't exit with an error if migrations fail
 		// This allows the PR deploys to recover from database locks
-		if os.Getenv("RESET_DB") == "true" {
+		if resetPlan.Reset {
 			log.Printf("Warning: Migrations failed in RESET_DB mode: %v", err)
 			log.Println("Continuing with deployment despite migration errors")
 		} else {
@@ -89,14 +103,25 @@ func main() {
 	}
 
 	// If running in reset mode, exit after database setup is complete
-	if os.Getenv("RESET_DB") == "true" {
+	if resetPlan.Reset {
 		log.Println("Database reset attempted. Exiting.")
 		return
 	}
 
+	// Catch ENCRYPTION_KEY changes before the first sync fails. Production can opt
+	// into refusing to start with ENCRYPTION_KEY_STRICT=true.
+	isProduction := os.Getenv("APP_ENV") == "production" || os.Getenv("NODE_ENV") == "production"
+	strictKeyCheck := isProduction && os.Getenv("ENCRYPTION_KEY_STRICT") == "true"
+	if err := models.CheckEncryptionAtRest(database.DB, strictKeyCheck); err != nil {
+		log.Fatalf("Refusing to start: %v", err)
+	}
+
 	// Load environment variables but don't do any database operations
 	services.LoadEnvVariables()
 
+	// Apply LOG_LEVEL again now that any .env file has been loaded
+	logger.SetLevelFromEnv()
+
 	// Initialize Firebase Admin SDK
 	log.Println("Initializing Firebase Admin SDK...")
 	err = middleware.InitializeFirebase()
@@ -107,6 +132,9 @@ func main() {
 		log.Println("Firebase Admin SDK initialized (or running in dev mode with auth checks disabled)")
 	}
 
+	// Generate transactions from due recurring templates in the background
+	handlers.StartRecurringTransactionGenerator()
+
 	// Create router
 	r := mux.NewRouter()
 
@@ -151,37 +179,141 @@ func main() {
 func registerRoutes(r *mux.Router) {
 	// Public routes (no auth required)
 	r.HandleFunc("/health", handlers.HealthCheck).Methods("GET", "OPTIONS")
+	r.HandleFunc("/openapi.json", handlers.GetOpenAPISpec).Methods("GET", "OPTIONS")
 
 	// Create a subrouter for authenticated routes
 	protectedRouter := r.PathPrefix("").Subrouter()
 	protectedRouter.Use(middleware.AuthMiddleware)
 
 	// Protected transaction routes
-	protectedRouter.HandleFunc("/transactions", handlers.GetTransactions).Methods("GET")
+	protectedRouter.HandleFunc("/transactions", handlers.GetTransactions).Methods("GET", "HEAD")
 	protectedRouter.HandleFunc("/transactions", handlers.AddTransaction).Methods("POST")
 	protectedRouter.HandleFunc("/transactions/unique-fields", handlers.GetUniqueTransactionFields).Methods("GET")
+	protectedRouter.HandleFunc("/transactions/uncategorized", handlers.GetUncategorizedTransactions).Methods("GET")
+	protectedRouter.HandleFunc("/transactions/months", handlers.GetTransactionMonths).Methods("GET")
+	protectedRouter.HandleFunc("/transactions/statement", handlers.GetTransactionStatement).Methods("GET")
+	protectedRouter.HandleFunc("/transactions/overdue", handlers.GetOverdueTransactions).Methods("GET")
+	protectedRouter.HandleFunc("/transactions/apply-rules", handlers.ApplyCategorizationRules).Methods("POST")
+	protectedRouter.HandleFunc("/transactions/filter-schema", handlers.GetTransactionFilterSchema).Methods("GET")
+	protectedRouter.HandleFunc("/transactions/changes", handlers.GetTransactionChanges).Methods("GET")
+	protectedRouter.HandleFunc("/transactions/tag", handlers.BulkTagTransactions).Methods("POST")
+	protectedRouter.HandleFunc("/transactions/bulk-optional", handlers.BulkSetTransactionsOptional).Methods("POST")
+	protectedRouter.HandleFunc("/transactions/shift-dates", handlers.ShiftTransactionDates).Methods("POST")
+	protectedRouter.HandleFunc("/transactions/lock-month", handlers.LockTransactionMonth).Methods("POST")
+	protectedRouter.HandleFunc("/transactions/unlock-month", handlers.UnlockTransactionMonth).Methods("POST")
+	protectedRouter.HandleFunc("/transactions/dedupe", handlers.DedupeTransactions).Methods("POST")
+	protectedRouter.HandleFunc("/transactions/import", handlers.ImportTransactions).Methods("POST")
+	protectedRouter.HandleFunc("/transactions/import/{batchId}/rollback", handlers.RollbackImport).Methods("POST")
 	protectedRouter.HandleFunc("/transactions/{id}", handlers.GetTransaction).Methods("GET")
+	protectedRouter.HandleFunc("/transactions/{id}/make-recurring", handlers.MakeTransactionRecurring).Methods("POST")
+	protectedRouter.HandleFunc("/transactions/{id}/history", handlers.GetTransactionHistory).Methods("GET")
+	protectedRouter.HandleFunc("/transactions/{id}/categories", handlers.GetTransactionCategories).Methods("GET")
+	protectedRouter.HandleFunc("/transactions/{id}/categories", handlers.SetTransactionCategories).Methods("PUT")
 	protectedRouter.HandleFunc("/transactions/{id}", handlers.UpdateTransaction).Methods("PUT")
 	protectedRouter.HandleFunc("/transactions/{id}", handlers.DeleteTransaction).Methods("DELETE")
 
 	// Protected Category routes
 	protectedRouter.HandleFunc("/categories", handlers.GetCategories).Methods("GET")
 	protectedRouter.HandleFunc("/categories", handlers.AddCategory).Methods("POST")
+	protectedRouter.HandleFunc("/categories/all", handlers.GetAllCategories).Methods("GET")
+	protectedRouter.HandleFunc("/categories/deleted", handlers.GetDeletedCategories).Methods("GET")
+	protectedRouter.HandleFunc("/categories/stale", handlers.GetStaleCategories).Methods("GET")
+	protectedRouter.HandleFunc("/categories/order", handlers.ReorderCategories).Methods("PUT")
+	protectedRouter.HandleFunc("/categories/{id}/restore", handlers.RestoreCategory).Methods("POST")
+	protectedRouter.HandleFunc("/categories/{id}/usage-in-reports", handlers.GetCategoryReportUsage).Methods("GET")
+	protectedRouter.HandleFunc("/categories/{id}/budget", handlers.GetCategoryBudget).Methods("GET")
+	protectedRouter.HandleFunc("/categories/{id}/budget", handlers.SetCategoryBudget).Methods("PUT")
+	protectedRouter.HandleFunc("/categories/{id}/budget", handlers.DeleteCategoryBudget).Methods("DELETE")
+	protectedRouter.HandleFunc("/categories/{id}", handlers.GetCategory).Methods("GET")
 	protectedRouter.HandleFunc("/categories/{id}", handlers.UpdateCategory).Methods("PUT")
 	protectedRouter.HandleFunc("/categories/{id}", handlers.DeleteCategory).Methods("DELETE")
+	protectedRouter.HandleFunc("/categorization-rules", handlers.GetCategorizationRules).Methods("GET")
+	protectedRouter.HandleFunc("/categorization-rules", handlers.AddCategorizationRule).Methods("POST")
+	protectedRouter.HandleFunc("/categorization-rules/{id}", handlers.UpdateCategorizationRule).Methods("PUT")
+	protectedRouter.HandleFunc("/categorization-rules/{id}", handlers.DeleteCategorizationRule).Methods("DELETE")
 
 	// Protected User routes
 	protectedRouter.HandleFunc("/users", handlers.GetUsers).Methods("GET")
 	protectedRouter.HandleFunc("/users/sync", handlers.SyncFirebaseUser).Methods("POST")
 	protectedRouter.HandleFunc("/users/{username}", handlers.GetUserByUsername).Methods("GET")
+	protectedRouter.HandleFunc("/me/activity-summary", handlers.GetActivitySummary).Methods("GET")
+	protectedRouter.HandleFunc("/me/logout-all", handlers.LogoutAll).Methods("POST")
+	protectedRouter.HandleFunc("/me/features", handlers.GetMyFeatures).Methods("GET")
+	protectedRouter.HandleFunc("/me/report-settings", handlers.GetReportSettings).Methods("GET")
+	protectedRouter.HandleFunc("/me/report-settings", handlers.UpdateReportSettings).Methods("PUT")
+	protectedRouter.HandleFunc("/me/data-export", handlers.ExportMyData).Methods("GET")
+	protectedRouter.HandleFunc("/me/data", handlers.DeleteMyData).Methods("DELETE")
+	protectedRouter.HandleFunc("/permissions/vocabulary", handlers.GetPermissionVocabulary).Methods("GET")
+
+	// Protected recurring transaction routes
+	protectedRouter.HandleFunc("/recurring", handlers.GetRecurringTransactions).Methods("GET")
+	protectedRouter.HandleFunc("/recurring", handlers.AddRecurringTransaction).Methods("POST")
+	protectedRouter.HandleFunc("/recurring/upcoming", handlers.GetUpcomingRecurringTransactions).Methods("GET")
+	protectedRouter.HandleFunc("/recurring/{id}", handlers.DeleteRecurringTransaction).Methods("DELETE")
+	protectedRouter.HandleFunc("/recurring/{id}/pause", handlers.PauseRecurringTransaction).Methods("POST")
+	protectedRouter.HandleFunc("/recurring/{id}/resume", handlers.ResumeRecurringTransaction).Methods("POST")
+
+	// Protected transaction template routes
+	protectedRouter.HandleFunc("/templates", handlers.GetTransactionTemplates).Methods("GET")
+	protectedRouter.HandleFunc("/templates", handlers.AddTransactionTemplate).Methods("POST")
+	protectedRouter.HandleFunc("/templates/{id}", handlers.DeleteTransactionTemplate).Methods("DELETE")
+	protectedRouter.HandleFunc("/templates/{id}/instantiate", handlers.InstantiateTransactionTemplate).Methods("POST")
+
+	// Protected settlement routes
+	protectedRouter.HandleFunc("/settlements", handlers.GetSettlements).Methods("GET")
+	protectedRouter.HandleFunc("/settlements", handlers.CreateSettlement).Methods("POST")
+	protectedRouter.HandleFunc("/settlements/snapshot", handlers.GetSettlementSnapshot).Methods("GET")
+	protectedRouter.HandleFunc("/settlements/refresh", handlers.RefreshSettlementSnapshot).Methods("POST")
+	protectedRouter.HandleFunc("/settlements/{id}/paid", handlers.MarkSettlementPaid).Methods("POST")
+
+	// Protected utility routes
+	protectedRouter.HandleFunc("/date-range", handlers.NormalizeDateRange).Methods("GET")
 
 	// Protected YNAB routes
+	protectedRouter.HandleFunc("/ynab/accounts/balances", handlers.GetYNABAccountBalances).Methods("GET")
+	protectedRouter.HandleFunc("/ynab/reconcile", handlers.ReconcileYNABTransactions).Methods("GET")
 	protectedRouter.HandleFunc("/ynab/categories", handlers.GetYNABCategories).Methods("GET")
+	protectedRouter.HandleFunc("/ynab/categories/flat", handlers.GetYNABCategoriesFlat).Methods("GET")
+	protectedRouter.HandleFunc("/ynab/categories/mapping", handlers.GetYNABCategoryMapping).Methods("GET")
+	protectedRouter.HandleFunc("/ynab/categories/reconcile", handlers.ReconcileYNABCategories).Methods("POST")
 	protectedRouter.HandleFunc("/ynab/sync", handlers.SyncYNABTransaction).Methods("POST")
+	protectedRouter.HandleFunc("/ynab/sync/retry-failed", handlers.RetryFailedYNABSyncs).Methods("POST")
 	protectedRouter.HandleFunc("/reports/ynab-splits", handlers.GetYNABSplits).Methods("POST")
+	protectedRouter.HandleFunc("/reports/ynab-splits/summary", handlers.GetYNABSplitsSummary).Methods("POST")
+	protectedRouter.HandleFunc("/reports/compare", handlers.ComparePeriods).Methods("POST")
+	protectedRouter.HandleFunc("/reports/category-trends", handlers.GetCategoryTrends).Methods("GET")
+	protectedRouter.HandleFunc("/reports/by-enterer", handlers.GetEntererTotals).Methods("GET")
+	protectedRouter.HandleFunc("/reports/category-percentages", handlers.GetCategoryPercentages).Methods("GET")
+	protectedRouter.HandleFunc("/reports/ynab-groups", handlers.GetYNABGroupTotals).Methods("GET")
+	protectedRouter.HandleFunc("/reports/ledger", handlers.GetLedger).Methods("GET")
+	protectedRouter.HandleFunc("/reports/networth", handlers.GetNetWorthTrend).Methods("GET")
+	protectedRouter.HandleFunc("/reports/balance-as-of", handlers.GetBalanceAsOf).Methods("GET")
+	protectedRouter.HandleFunc("/reports/since-last-settlement", handlers.GetSinceLastSettlement).Methods("GET")
+	protectedRouter.HandleFunc("/reports/custom", handlers.GetAccessibleCustomReports).Methods("GET")
+	protectedRouter.HandleFunc("/reports/custom/validate", handlers.ValidateCustomReportConfig).Methods("POST")
+	protectedRouter.HandleFunc("/reports/custom/{id}/run", handlers.RunCustomReport).Methods("GET")
+	protectedRouter.HandleFunc("/reports/export-all", handlers.ExportAllReports).Methods("GET")
 
 	// YNAB Config routes (add these to match frontend expectations)
 	protectedRouter.HandleFunc("/ynab/config", handlers.GetYNABConfig).Methods("GET")
 	protectedRouter.HandleFunc("/ynab/config", handlers.UpdateYNABConfig).Methods("PUT")
 	protectedRouter.HandleFunc("/ynab/sync/categories", handlers.SyncYNABCategories).Methods("POST")
+	protectedRouter.HandleFunc("/ynab/sync/categories/preview", handlers.PreviewYNABCategorySync).Methods("POST")
+
+	// Admin routes
+	protectedRouter.HandleFunc("/admin/ynab/copy-config", handlers.CopyYNABConfig).Methods("POST")
+	protectedRouter.HandleFunc("/admin/transactions", handlers.GetAllTransactions).Methods("GET")
+	protectedRouter.HandleFunc("/admin/feature-flags", handlers.GetFeatureFlags).Methods("GET")
+	protectedRouter.HandleFunc("/admin/feature-flags", handlers.SetFeatureFlag).Methods("PUT")
+	protectedRouter.HandleFunc("/admin/feature-flags", handlers.DeleteFeatureFlag).Methods("DELETE")
+}
+
+// warnBeforeReset logs a loud warning and counts down before the database is
+// reset, so a developer who set RESET_DB by mistake can abort with Ctrl-C
+func warnBeforeReset(grace time.Duration) {
+	log.Println("WARNING: RESET_DB=true - ALL DATA IN THE DATABASE WILL BE DELETED AND REPLACED WITH TEST DATA")
+	for remaining := grace; remaining > 0; remaining -= time.Second {
+		log.Printf("WARNING: resetting the database in %d seconds, press Ctrl-C to abort (RESET_DB_GRACE_SECONDS=0 skips this wait)", int(remaining/time.Second))
+		time.Sleep(time.Second)
+	}
 }
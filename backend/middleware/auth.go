@@ -178,13 +178,25 @@ func AuthMiddleware(next http.Handler) http.Handler {
 		}
 
 		// Verify the token with Firebase
-		token, err := verifyToken(idToken)
+		token, err := verifyIDToken(idToken)
 		if err != nil {
 			log.Printf("Error verifying token: %v", err)
 			http.Error(w, "Unauthorized: Invalid token", http.StatusUnauthorized)
 			return
 		}
 
+		// Reject tokens issued before the user signed out everywhere
+		revoked, err := tokenRevoked(token.UID, token.IssuedAt)
+		if err != nil {
+			log.Printf("Error checking token revocation: %v", err)
+			http.Error(w, "Error checking token", http.StatusInternalServerError)
+			return
+		}
+		if revoked {
+			http.Error(w, "Unauthorized: Token has been revoked", http.StatusUnauthorized)
+			return
+		}
+
 		// Add the user ID to the request context
 		ctx := context.WithValue(r.Context(), UserIDKey, token.UID)
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -205,6 +217,9 @@ func extractToken(authHeader string) string {
 	return parts[1]
 }
 
+// verifyIDToken verifies tokens in AuthMiddleware; tests replace it
+var verifyIDToken = verifyToken
+
 // verifyToken verifies the Firebase JWT token
 func verifyToken(idToken string) (*auth.Token, error) {
 	if firebaseAuth == nil {
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"bennwallet/backend/database"
+)
+
+// UserFeatureFlags returns the flags in effect for a user: the global flags,
+// overridden by the user's own. Flags that were never set are absent and
+// count as disabled.
+func UserFeatureFlags(userID string) (map[string]bool, error) {
+	// Global rows sort first so the user's rows overwrite them
+	rows, err := database.DB.Query(`
+		SELECT flag, enabled FROM feature_flags
+		WHERE user_id = '' OR user_id = ?
+		ORDER BY user_id != '', flag
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flags := map[string]bool{}
+	for rows.Next() {
+		var flag string
+		var enabled bool
+		if err := rows.Scan(&flag, &enabled); err != nil {
+			return nil, err
+		}
+		flags[flag] = enabled
+	}
+	return flags, rows.Err()
+}
+
+// IsFeatureEnabled reports whether a feature flag is on for a user
+func IsFeatureEnabled(userID, flag string) (bool, error) {
+	flags, err := UserFeatureFlags(userID)
+	if err != nil {
+		return false, err
+	}
+	return flags[flag], nil
+}
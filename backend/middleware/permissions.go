@@ -148,3 +148,29 @@ func GetUserAccessibleResources(userID, resourceType, permissionType string) ([]
 
 	return ownerIDs, nil
 }
+
+// IsUserAdmin reports whether the given user has the admin flag set
+func IsUserAdmin(userID string) (bool, error) {
+	var isAdmin sql.NullBool
+	err := database.DB.QueryRow("SELECT isAdmin FROM users WHERE id = ?", userID).Scan(&isAdmin)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return isAdmin.Valid && isAdmin.Bool, nil
+}
+
+// IsUserSuperAdmin reports whether the given user has the superadmin role
+func IsUserSuperAdmin(userID string) (bool, error) {
+	var role sql.NullString
+	err := database.DB.QueryRow("SELECT role FROM users WHERE id = ?", userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return role.Valid && role.String == models.RoleSuperAdmin, nil
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"bennwallet/backend/database"
+)
+
+// tokenRevoked reports whether a token issued at issuedAt (Unix seconds) was
+// issued before the user's tokens_valid_after cutoff. Users that haven't been
+// synced yet have no cutoff.
+func tokenRevoked(userID string, issuedAt int64) (bool, error) {
+	var validAfter sql.NullTime
+	err := database.DB.QueryRow("SELECT tokens_valid_after FROM users WHERE id = ?", userID).Scan(&validAfter)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	// Token issue times only have second precision
+	return validAfter.Valid && issuedAt < validAfter.Time.Unix(), nil
+}
+
+// RevokeUserTokens rejects every token issued to the user before now and,
+// when Firebase is configured, revokes their refresh tokens so signed-in
+// clients can't mint new ones. It returns the new cutoff, or sql.ErrNoRows
+// for an unknown user.
+func RevokeUserTokens(userID string) (time.Time, error) {
+	cutoff := time.Now().UTC().Truncate(time.Second)
+	result, err := database.DB.Exec("UPDATE users SET tokens_valid_after = ? WHERE id = ?", cutoff, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		return time.Time{}, sql.ErrNoRows
+	}
+	if firebaseAuth != nil {
+		if err := firebaseAuth.RevokeRefreshTokens(context.Background(), userID); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return cutoff, nil
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bennwallet/backend/database"
+
+	"firebase.google.com/go/v4/auth"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// setupRevocationTest points the middleware at an in-memory users table and
+// a token verifier that issues tokens for "user-1" at issuedAt
+func setupRevocationTest(t *testing.T, issuedAt time.Time) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	_, err = db.Exec(`
+		CREATE TABLE users (
+			id TEXT PRIMARY KEY,
+			username TEXT,
+			name TEXT,
+			tokens_valid_after TIMESTAMP
+		);
+		INSERT INTO users (id, username, name) VALUES ('user-1', 'user1', 'User One');
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create users table: %v", err)
+	}
+
+	savedDB, savedAuth, savedVerify := database.DB, firebaseAuth, verifyIDToken
+	t.Cleanup(func() {
+		db.Close()
+		database.DB, firebaseAuth, verifyIDToken = savedDB, savedAuth, savedVerify
+	})
+
+	database.DB = db
+	// Any non-nil client takes AuthMiddleware out of dev mode
+	firebaseAuth = &auth.Client{}
+	verifyIDToken = func(idToken string) (*auth.Token, error) {
+		return &auth.Token{UID: "user-1", IssuedAt: issuedAt.Unix()}, nil
+	}
+}
+
+func serveWithToken(t *testing.T) int {
+	handler := AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest("GET", "/transactions", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr.Code
+}
+
+func TestAuthMiddleware_RejectsTokenIssuedBeforeCutoff(t *testing.T) {
+	setupRevocationTest(t, time.Now().Add(-time.Hour))
+
+	if status := serveWithToken(t); status != http.StatusOK {
+		t.Fatalf("Expected status code %v before revocation, got %v", http.StatusOK, status)
+	}
+
+	_, err := database.DB.Exec("UPDATE users SET tokens_valid_after = ? WHERE id = 'user-1'", time.Now().UTC())
+	if err != nil {
+		t.Fatalf("Failed to set cutoff: %v", err)
+	}
+
+	if status := serveWithToken(t); status != http.StatusUnauthorized {
+		t.Errorf("Expected status code %v for a token issued before the cutoff, got %v", http.StatusUnauthorized, status)
+	}
+}
+
+func TestAuthMiddleware_AcceptsTokenIssuedAfterCutoff(t *testing.T) {
+	setupRevocationTest(t, time.Now().Add(time.Minute))
+
+	_, err := database.DB.Exec("UPDATE users SET tokens_valid_after = ? WHERE id = 'user-1'", time.Now().UTC())
+	if err != nil {
+		t.Fatalf("Failed to set cutoff: %v", err)
+	}
+
+	if status := serveWithToken(t); status != http.StatusOK {
+		t.Errorf("Expected status code %v for a token issued after the cutoff, got %v", http.StatusOK, status)
+	}
+}
+
+func TestTokenRevokedUnknownUser(t *testing.T) {
+	setupRevocationTest(t, time.Now())
+
+	revoked, err := tokenRevoked("not-synced-yet", time.Now().Add(-time.Hour).Unix())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if revoked {
+		t.Error("Expected tokens of users without a cutoff to be accepted")
+	}
+}
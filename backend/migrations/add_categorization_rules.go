@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddCategorizationRulesTable creates the table holding users' rules for
+// assigning a category to new transactions
+func AddCategorizationRulesTable(db *sql.DB) error {
+	log.Println("Adding categorization_rules table...")
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS categorization_rules (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			match_field TEXT NOT NULL,
+			match_op TEXT NOT NULL,
+			match_value TEXT NOT NULL,
+			category_id INTEGER NOT NULL,
+			priority INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create categorization_rules table: %w", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_categorization_rules_user ON categorization_rules (
+			user_id, priority
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create categorization_rules index: %w", err)
+	}
+
+	log.Println("Categorization rules table created successfully")
+	return nil
+}
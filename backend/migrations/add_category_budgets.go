@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddCategoryBudgetsTable creates the table holding the monthly budget users
+// set for their categories
+func AddCategoryBudgetsTable(db *sql.DB) error {
+	log.Println("Adding category_budgets table...")
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS category_budgets (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			category_id INTEGER NOT NULL UNIQUE,
+			amount REAL NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create category_budgets table: %w", err)
+	}
+
+	log.Println("Category budgets table created successfully")
+	return nil
+}
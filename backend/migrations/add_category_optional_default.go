@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddCategoryOptionalDefault adds the optional_default flag to the categories table
+func AddCategoryOptionalDefault(db *sql.DB) error {
+	log.Println("Adding optional_default field to categories table...")
+
+	// First check if the column already exists
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM pragma_table_info('categories')
+		WHERE name = 'optional_default'
+	`).Scan(&count)
+
+	if err != nil {
+		return fmt.Errorf("error checking for optional_default column: %w", err)
+	}
+
+	if count > 0 {
+		log.Println("optional_default column already exists in categories table")
+		return nil
+	}
+
+	// Add the column
+	_, err = db.Exec(`
+		ALTER TABLE categories
+		ADD COLUMN optional_default BOOLEAN NOT NULL DEFAULT 0
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding optional_default column: %w", err)
+	}
+
+	log.Println("Successfully added optional_default field to categories table")
+	return nil
+}
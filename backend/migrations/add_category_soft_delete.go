@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddCategorySoftDelete adds the deleted_at column used to soft-delete categories
+func AddCategorySoftDelete(db *sql.DB) error {
+	log.Println("Adding deleted_at field to categories table...")
+
+	// First check if the column already exists
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM pragma_table_info('categories')
+		WHERE name = 'deleted_at'
+	`).Scan(&count)
+
+	if err != nil {
+		return fmt.Errorf("error checking for deleted_at column: %w", err)
+	}
+
+	if count > 0 {
+		log.Println("deleted_at column already exists in categories table")
+		return nil
+	}
+
+	// Add the column
+	_, err = db.Exec(`
+		ALTER TABLE categories
+		ADD COLUMN deleted_at DATETIME
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding deleted_at column: %w", err)
+	}
+
+	log.Println("Successfully added deleted_at field to categories table")
+	return nil
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddCategorySortOrder adds the sort_order field that holds a user's custom
+// ordering of their categories
+func AddCategorySortOrder(db *sql.DB) error {
+	log.Println("Adding sort_order field to categories table...")
+
+	// First check if the column already exists
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM pragma_table_info('categories')
+		WHERE name = 'sort_order'
+	`).Scan(&count)
+
+	if err != nil {
+		return fmt.Errorf("error checking for sort_order column: %w", err)
+	}
+
+	if count > 0 {
+		log.Println("sort_order column already exists in categories table")
+		return nil
+	}
+
+	// Existing categories have no custom position and keep sorting by name
+	_, err = db.Exec(`
+		ALTER TABLE categories
+		ADD COLUMN sort_order INTEGER
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding sort_order column: %w", err)
+	}
+
+	log.Println("Successfully added sort_order field to categories table")
+	return nil
+}
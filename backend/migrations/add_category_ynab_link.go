@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddCategoryYNABLink adds the columns used to track which YNAB category a local
+// category was synced from, and whether it has been archived after removal in YNAB
+func AddCategoryYNABLink(db *sql.DB) error {
+	log.Println("Adding YNAB link columns to categories table...")
+
+	columns := []struct {
+		name       string
+		definition string
+	}{
+		{"ynab_category_id", "TEXT"},
+		{"archived", "BOOLEAN NOT NULL DEFAULT 0"},
+	}
+
+	for _, column := range columns {
+		// First check if the column already exists
+		var count int
+		err := db.QueryRow(`
+			SELECT COUNT(*)
+			FROM pragma_table_info('categories')
+			WHERE name = ?
+		`, column.name).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("error checking for %s column: %w", column.name, err)
+		}
+
+		if count > 0 {
+			log.Printf("Column %s already exists in categories table", column.name)
+			continue
+		}
+
+		_, err = db.Exec(fmt.Sprintf("ALTER TABLE categories ADD COLUMN %s %s", column.name, column.definition))
+		if err != nil {
+			return fmt.Errorf("error adding %s column: %w", column.name, err)
+		}
+	}
+
+	log.Println("Successfully added YNAB link columns to categories table")
+	return nil
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddCustomReportPublicUntil adds the public_until column letting a custom
+// report be shared only until a given time
+func AddCustomReportPublicUntil(db *sql.DB) error {
+	log.Println("Adding public_until field to custom_reports table...")
+
+	// First check if the column already exists
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM pragma_table_info('custom_reports')
+		WHERE name = 'public_until'
+	`).Scan(&count)
+
+	if err != nil {
+		return fmt.Errorf("error checking for public_until column: %w", err)
+	}
+
+	if count > 0 {
+		log.Println("public_until column already exists in custom_reports table")
+		return nil
+	}
+
+	// NULL keeps existing public reports public indefinitely
+	_, err = db.Exec(`
+		ALTER TABLE custom_reports
+		ADD COLUMN public_until TIMESTAMP
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding public_until column: %w", err)
+	}
+
+	log.Println("Successfully added public_until field to custom_reports table")
+	return nil
+}
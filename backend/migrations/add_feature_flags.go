@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddFeatureFlagsTable creates the table of feature flags. Rows with an empty
+// user_id apply to everyone; a user's own row overrides them.
+func AddFeatureFlagsTable(db *sql.DB) error {
+	log.Println("Adding feature_flags table...")
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS feature_flags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL DEFAULT '',
+			flag TEXT NOT NULL,
+			enabled BOOLEAN NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(user_id, flag)
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create feature_flags table: %w", err)
+	}
+
+	log.Println("Feature flags table created successfully")
+	return nil
+}
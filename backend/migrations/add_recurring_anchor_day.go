@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddRecurringAnchorDay adds the day of month that monthly and yearly
+// recurring templates fire on, so a template on the 31st keeps returning to
+// the 31st after a shorter month
+func AddRecurringAnchorDay(db *sql.DB) error {
+	log.Println("Adding anchor_day field to recurring_transactions table...")
+
+	// First check if the column already exists
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM pragma_table_info('recurring_transactions')
+		WHERE name = 'anchor_day'
+	`).Scan(&count)
+
+	if err != nil {
+		return fmt.Errorf("error checking for anchor_day column: %w", err)
+	}
+
+	if count > 0 {
+		log.Println("anchor_day column already exists in recurring_transactions table")
+		return nil
+	}
+
+	_, err = db.Exec(`
+		ALTER TABLE recurring_transactions
+		ADD COLUMN anchor_day INTEGER
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding anchor_day column: %w", err)
+	}
+
+	// Existing templates are anchored to the day of their next date
+	_, err = db.Exec(`
+		UPDATE recurring_transactions
+		SET anchor_day = CAST(substr(next_date, 9, 2) AS INTEGER)
+	`)
+	if err != nil {
+		return fmt.Errorf("error backfilling anchor_day: %w", err)
+	}
+
+	log.Println("Successfully added anchor_day field to recurring_transactions table")
+	return nil
+}
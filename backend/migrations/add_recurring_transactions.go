@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddRecurringTransactionsTable creates the table holding recurring transaction templates
+func AddRecurringTransactionsTable(db *sql.DB) error {
+	log.Println("Adding recurring_transactions table...")
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS recurring_transactions (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			amount REAL NOT NULL,
+			description TEXT NOT NULL,
+			type TEXT NOT NULL,
+			payTo TEXT,
+			enteredBy TEXT NOT NULL,
+			optional BOOLEAN NOT NULL DEFAULT 0,
+			frequency TEXT NOT NULL,
+			next_date DATETIME NOT NULL,
+			active BOOLEAN NOT NULL DEFAULT 1,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create recurring_transactions table: %w", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_recurring_transactions_due ON recurring_transactions (
+			active, next_date
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create recurring_transactions index: %w", err)
+	}
+
+	log.Println("Recurring transactions table created successfully")
+	return nil
+}
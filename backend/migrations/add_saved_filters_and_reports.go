@@ -0,0 +1,57 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddSavedFiltersAndReports creates the tables holding users' saved
+// transaction filters and custom report definitions. Both keep their
+// settings as JSON.
+func AddSavedFiltersAndReports(db *sql.DB) error {
+	log.Println("Adding saved_filters and custom_reports tables...")
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS saved_filters (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			filter_config TEXT NOT NULL,
+			is_default BOOLEAN NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create saved_filters table: %w", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS custom_reports (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			description TEXT,
+			report_config TEXT NOT NULL,
+			is_public BOOLEAN NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create custom_reports table: %w", err)
+	}
+
+	for _, stmt := range []string{
+		`CREATE INDEX IF NOT EXISTS idx_saved_filters_user ON saved_filters (user_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_custom_reports_user ON custom_reports (user_id);`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to create saved filter or report index: %w", err)
+		}
+	}
+
+	log.Println("Saved filters and custom reports tables created successfully")
+	return nil
+}
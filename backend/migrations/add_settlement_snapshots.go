@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddSettlementSnapshotsTable creates the table caching each user's computed
+// monthly settlement, with figures holding the per-counterpart figures as
+// JSON. Triggers drop the snapshots of a month whenever a transaction in it
+// is added, changed or removed, whichever code path writes it.
+func AddSettlementSnapshotsTable(db *sql.DB) error {
+	log.Println("Adding settlement_snapshots table...")
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS settlement_snapshots (
+			user_id TEXT NOT NULL,
+			month TEXT NOT NULL,
+			figures TEXT NOT NULL,
+			computed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, month)
+		);
+		CREATE INDEX IF NOT EXISTS idx_settlement_snapshots_month ON settlement_snapshots(month);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create settlement_snapshots table: %w", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE TRIGGER IF NOT EXISTS settlement_snapshots_insert AFTER INSERT ON transactions
+		BEGIN
+			DELETE FROM settlement_snapshots WHERE month = substr(COALESCE(NEW.transaction_date, NEW.date), 1, 7);
+		END;
+		CREATE TRIGGER IF NOT EXISTS settlement_snapshots_update AFTER UPDATE ON transactions
+		BEGIN
+			DELETE FROM settlement_snapshots WHERE month IN (
+				substr(COALESCE(OLD.transaction_date, OLD.date), 1, 7),
+				substr(COALESCE(NEW.transaction_date, NEW.date), 1, 7)
+			);
+		END;
+		CREATE TRIGGER IF NOT EXISTS settlement_snapshots_delete AFTER DELETE ON transactions
+		BEGIN
+			DELETE FROM settlement_snapshots WHERE month = substr(COALESCE(OLD.transaction_date, OLD.date), 1, 7);
+		END;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create settlement_snapshots triggers: %w", err)
+	}
+
+	log.Println("Settlement snapshots table created successfully")
+	return nil
+}
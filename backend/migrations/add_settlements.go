@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddSettlementsTable creates the table recording when a user settled up
+// with a counterpart (a payTo), which starts the next balance window
+func AddSettlementsTable(db *sql.DB) error {
+	log.Println("Adding settlements table...")
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS settlements (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			with_user TEXT NOT NULL,
+			settled_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_settlements_user ON settlements(user_id, with_user, settled_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create settlements table: %w", err)
+	}
+
+	log.Println("Settlements table created successfully")
+	return nil
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddTransactionCategoriesTable creates the table linking transactions to one
+// or more categories, each with the portion of the amount assigned to it
+func AddTransactionCategoriesTable(db *sql.DB) error {
+	log.Println("Adding transaction_categories table...")
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS transaction_categories (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			transaction_id TEXT NOT NULL,
+			category_id INTEGER NOT NULL,
+			amount REAL NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(transaction_id, category_id)
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction_categories table: %w", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_transaction_categories_category ON transaction_categories (
+			category_id
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction_categories index: %w", err)
+	}
+
+	log.Println("Transaction categories table created successfully")
+	return nil
+}
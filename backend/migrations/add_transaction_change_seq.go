@@ -0,0 +1,75 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddTransactionChangeSeq adds change_seq, a number that grows with every
+// write to a transaction. Triggers take it from a single counter inside the
+// writing database transaction, and SQLite runs one writer at a time, so the
+// numbers follow commit order and a sync cursor based on them never skips a
+// change that committed late.
+func AddTransactionChangeSeq(db *sql.DB) error {
+	log.Println("Adding change_seq field to transactions table...")
+
+	// First check if the column already exists
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM pragma_table_info('transactions')
+		WHERE name = 'change_seq'
+	`).Scan(&count)
+
+	if err != nil {
+		return fmt.Errorf("error checking for change_seq column: %w", err)
+	}
+
+	if count == 0 {
+		// Existing transactions are numbered in the order they were stored
+		_, err = db.Exec(`
+			ALTER TABLE transactions ADD COLUMN change_seq INTEGER;
+			UPDATE transactions SET change_seq = rowid;
+		`)
+		if err != nil {
+			return fmt.Errorf("error adding change_seq column: %w", err)
+		}
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_transactions_change_seq ON transactions(change_seq);
+		CREATE TABLE IF NOT EXISTS transaction_change_sequence (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			value INTEGER NOT NULL
+		);
+		INSERT OR IGNORE INTO transaction_change_sequence (id, value)
+		SELECT 1, COALESCE(MAX(change_seq), 0) FROM transactions;
+	`)
+	if err != nil {
+		return fmt.Errorf("error creating transaction_change_sequence table: %w", err)
+	}
+
+	// The update trigger skips its own change_seq update
+	_, err = db.Exec(`
+		CREATE TRIGGER IF NOT EXISTS transactions_change_seq_insert AFTER INSERT ON transactions
+		BEGIN
+			UPDATE transaction_change_sequence SET value = value + 1 WHERE id = 1;
+			UPDATE transactions SET change_seq = (SELECT value FROM transaction_change_sequence WHERE id = 1)
+			WHERE id = NEW.id;
+		END;
+		CREATE TRIGGER IF NOT EXISTS transactions_change_seq_update AFTER UPDATE ON transactions
+		WHEN NEW.change_seq IS OLD.change_seq
+		BEGIN
+			UPDATE transaction_change_sequence SET value = value + 1 WHERE id = 1;
+			UPDATE transactions SET change_seq = (SELECT value FROM transaction_change_sequence WHERE id = 1)
+			WHERE id = NEW.id;
+		END;
+	`)
+	if err != nil {
+		return fmt.Errorf("error creating change_seq triggers: %w", err)
+	}
+
+	log.Println("Successfully added change_seq field to transactions table")
+	return nil
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddTransactionHistoryTable creates the audit table recording transaction edits
+func AddTransactionHistoryTable(db *sql.DB) error {
+	log.Println("Adding transaction_history table...")
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS transaction_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			transaction_id TEXT NOT NULL,
+			changed_by TEXT NOT NULL,
+			changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			changed_fields TEXT NOT NULL,
+			before_snapshot TEXT NOT NULL,
+			after_snapshot TEXT NOT NULL
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction_history table: %w", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_transaction_history_transaction ON transaction_history (
+			transaction_id, changed_at
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction_history index: %w", err)
+	}
+
+	log.Println("Transaction history table created successfully")
+	return nil
+}
@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddTransactionImportBatch adds the import_batch_id column linking imported
+// transactions to the import that created them
+func AddTransactionImportBatch(db *sql.DB) error {
+	log.Println("Adding import_batch_id field to transactions table...")
+
+	// First check if the column already exists
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM pragma_table_info('transactions')
+		WHERE name = 'import_batch_id'
+	`).Scan(&count)
+
+	if err != nil {
+		return fmt.Errorf("error checking for import_batch_id column: %w", err)
+	}
+
+	if count > 0 {
+		log.Println("import_batch_id column already exists in transactions table")
+		return nil
+	}
+
+	// Add the column
+	_, err = db.Exec(`
+		ALTER TABLE transactions
+		ADD COLUMN import_batch_id TEXT
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding import_batch_id column: %w", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_transactions_import_batch ON transactions (
+			import_batch_id
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create import_batch_id index: %w", err)
+	}
+
+	log.Println("Successfully added import_batch_id field to transactions table")
+	return nil
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddTransactionLocked adds the locked flag that protects transactions in a
+// settled month from edits
+func AddTransactionLocked(db *sql.DB) error {
+	log.Println("Adding locked field to transactions table...")
+
+	// First check if the column already exists
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM pragma_table_info('transactions')
+		WHERE name = 'locked'
+	`).Scan(&count)
+
+	if err != nil {
+		return fmt.Errorf("error checking for locked column: %w", err)
+	}
+
+	if count > 0 {
+		log.Println("locked column already exists in transactions table")
+		return nil
+	}
+
+	// Existing transactions start out unlocked
+	_, err = db.Exec(`
+		ALTER TABLE transactions
+		ADD COLUMN locked BOOLEAN NOT NULL DEFAULT 0
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding locked column: %w", err)
+	}
+
+	log.Println("Successfully added locked field to transactions table")
+	return nil
+}
@@ -0,0 +1,50 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddTransactionOriginalAmount adds the columns recording the amount and
+// currency a transaction was originally made in, when that differs from the
+// home currency
+func AddTransactionOriginalAmount(db *sql.DB) error {
+	log.Println("Adding original_amount and original_currency fields to transactions table...")
+
+	for _, column := range []struct {
+		name       string
+		definition string
+	}{
+		{"original_amount", "REAL"},
+		{"original_currency", "TEXT"},
+	} {
+		// First check if the column already exists
+		var count int
+		err := db.QueryRow(`
+			SELECT COUNT(*)
+			FROM pragma_table_info('transactions')
+			WHERE name = ?
+		`, column.name).Scan(&count)
+
+		if err != nil {
+			return fmt.Errorf("error checking for %s column: %w", column.name, err)
+		}
+
+		if count > 0 {
+			log.Printf("%s column already exists in transactions table", column.name)
+			continue
+		}
+
+		_, err = db.Exec(fmt.Sprintf(`
+			ALTER TABLE transactions
+			ADD COLUMN %s %s
+		`, column.name, column.definition))
+		if err != nil {
+			return fmt.Errorf("error adding %s column: %w", column.name, err)
+		}
+	}
+
+	log.Println("Successfully added original_amount and original_currency fields to transactions table")
+	return nil
+}
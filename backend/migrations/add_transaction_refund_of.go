@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddTransactionRefundOf adds the refund_of column linking a refund to the
+// transaction it partially refunds
+func AddTransactionRefundOf(db *sql.DB) error {
+	log.Println("Adding refund_of field to transactions table...")
+
+	// First check if the column already exists
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM pragma_table_info('transactions')
+		WHERE name = 'refund_of'
+	`).Scan(&count)
+
+	if err != nil {
+		return fmt.Errorf("error checking for refund_of column: %w", err)
+	}
+
+	if count > 0 {
+		log.Println("refund_of column already exists in transactions table")
+		return nil
+	}
+
+	// Existing transactions are not refunds
+	_, err = db.Exec(`
+		ALTER TABLE transactions
+		ADD COLUMN refund_of TEXT
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding refund_of column: %w", err)
+	}
+
+	log.Println("Successfully added refund_of field to transactions table")
+	return nil
+}
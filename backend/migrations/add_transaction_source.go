@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddTransactionSource adds the source column recording how a transaction was created
+func AddTransactionSource(db *sql.DB) error {
+	log.Println("Adding source field to transactions table...")
+
+	// First check if the column already exists
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM pragma_table_info('transactions')
+		WHERE name = 'source'
+	`).Scan(&count)
+
+	if err != nil {
+		return fmt.Errorf("error checking for source column: %w", err)
+	}
+
+	if count > 0 {
+		log.Println("source column already exists in transactions table")
+		return nil
+	}
+
+	// Existing transactions were all entered by hand
+	_, err = db.Exec(`
+		ALTER TABLE transactions
+		ADD COLUMN source TEXT NOT NULL DEFAULT 'manual'
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding source column: %w", err)
+	}
+
+	log.Println("Successfully added source field to transactions table")
+	return nil
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddTransactionStatus adds the status column tracking whether a charge is
+// cleared, pending or disputed
+func AddTransactionStatus(db *sql.DB) error {
+	log.Println("Adding status field to transactions table...")
+
+	// First check if the column already exists
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM pragma_table_info('transactions')
+		WHERE name = 'status'
+	`).Scan(&count)
+
+	if err != nil {
+		return fmt.Errorf("error checking for status column: %w", err)
+	}
+
+	if count > 0 {
+		log.Println("status column already exists in transactions table")
+		return nil
+	}
+
+	// Existing transactions are treated as settled
+	_, err = db.Exec(`
+		ALTER TABLE transactions
+		ADD COLUMN status TEXT NOT NULL DEFAULT 'cleared'
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding status column: %w", err)
+	}
+
+	log.Println("Successfully added status field to transactions table")
+	return nil
+}
@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddTransactionSyncColumns adds the updated_at and deleted_at columns that
+// let clients sync incremental changes, including deletions
+func AddTransactionSyncColumns(db *sql.DB) error {
+	log.Println("Adding updated_at and deleted_at fields to transactions table...")
+
+	for _, column := range []string{"updated_at", "deleted_at"} {
+		// First check if the column already exists
+		var count int
+		err := db.QueryRow(`
+			SELECT COUNT(*)
+			FROM pragma_table_info('transactions')
+			WHERE name = ?
+		`, column).Scan(&count)
+
+		if err != nil {
+			return fmt.Errorf("error checking for %s column: %w", column, err)
+		}
+
+		if count > 0 {
+			log.Printf("%s column already exists in transactions table", column)
+			continue
+		}
+
+		_, err = db.Exec(fmt.Sprintf(`
+			ALTER TABLE transactions
+			ADD COLUMN %s DATETIME
+		`, column))
+		if err != nil {
+			return fmt.Errorf("error adding %s column: %w", column, err)
+		}
+	}
+
+	// Treat existing transactions as last changed when they were entered
+	_, err := db.Exec(`
+		UPDATE transactions
+		SET updated_at = date
+		WHERE updated_at IS NULL
+	`)
+	if err != nil {
+		return fmt.Errorf("error populating updated_at: %w", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_transactions_updated_at ON transactions (
+			updated_at
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create updated_at index: %w", err)
+	}
+
+	log.Println("Successfully added updated_at and deleted_at fields to transactions table")
+	return nil
+}
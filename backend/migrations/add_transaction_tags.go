@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddTransactionTagsTable creates the table holding free-form tags on transactions
+func AddTransactionTagsTable(db *sql.DB) error {
+	log.Println("Adding transaction_tags table...")
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS transaction_tags (
+			transaction_id TEXT NOT NULL,
+			tag TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (transaction_id, tag)
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction_tags table: %w", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_transaction_tags_tag ON transaction_tags (
+			tag
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction_tags index: %w", err)
+	}
+
+	log.Println("Transaction tags table created successfully")
+	return nil
+}
@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddTransactionTemplatesTable creates the table holding transaction templates,
+// from which transactions are created on demand rather than on a schedule
+func AddTransactionTemplatesTable(db *sql.DB) error {
+	log.Println("Adding transaction_templates table...")
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS transaction_templates (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			amount REAL NOT NULL,
+			description TEXT NOT NULL,
+			type TEXT NOT NULL,
+			payTo TEXT,
+			enteredBy TEXT NOT NULL,
+			optional BOOLEAN NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction_templates table: %w", err)
+	}
+
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_transaction_templates_user ON transaction_templates (
+			user_id, name
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction_templates index: %w", err)
+	}
+
+	log.Println("Transaction templates table created successfully")
+	return nil
+}
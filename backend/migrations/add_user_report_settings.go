@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddUserReportSettings adds the reports_include_optional column holding
+// whether a user's reports count optional transactions when a request
+// doesn't say
+func AddUserReportSettings(db *sql.DB) error {
+	log.Println("Adding reports_include_optional field to users table...")
+
+	// First check if the column already exists
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM pragma_table_info('users')
+		WHERE name = 'reports_include_optional'
+	`).Scan(&count)
+
+	if err != nil {
+		return fmt.Errorf("error checking for reports_include_optional column: %w", err)
+	}
+
+	if count > 0 {
+		log.Println("reports_include_optional column already exists in users table")
+		return nil
+	}
+
+	// Reports have always left optional transactions out by default
+	_, err = db.Exec(`
+		ALTER TABLE users
+		ADD COLUMN reports_include_optional BOOLEAN NOT NULL DEFAULT 0
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding reports_include_optional column: %w", err)
+	}
+
+	log.Println("Successfully added reports_include_optional field to users table")
+	return nil
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddUserTokensValidAfter adds the tokens_valid_after column. Tokens issued
+// before it are rejected, which lets a user sign out everywhere.
+func AddUserTokensValidAfter(db *sql.DB) error {
+	log.Println("Adding tokens_valid_after field to users table...")
+
+	// First check if the column already exists
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM pragma_table_info('users')
+		WHERE name = 'tokens_valid_after'
+	`).Scan(&count)
+
+	if err != nil {
+		return fmt.Errorf("error checking for tokens_valid_after column: %w", err)
+	}
+
+	if count > 0 {
+		log.Println("tokens_valid_after column already exists in users table")
+		return nil
+	}
+
+	// NULL accepts every token the user holds
+	_, err = db.Exec(`
+		ALTER TABLE users
+		ADD COLUMN tokens_valid_after TIMESTAMP
+	`)
+	if err != nil {
+		return fmt.Errorf("error adding tokens_valid_after column: %w", err)
+	}
+
+	log.Println("Successfully added tokens_valid_after field to users table")
+	return nil
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// AddYNABSyncQueueTable creates the table of YNAB transaction syncs that
+// failed and are waiting to be retried. request holds the original sync
+// request as JSON.
+func AddYNABSyncQueueTable(db *sql.DB) error {
+	log.Println("Adding ynab_sync_queue table...")
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS ynab_sync_queue (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			request TEXT NOT NULL,
+			last_error TEXT NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 1,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			last_attempt_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_ynab_sync_queue_user ON ynab_sync_queue(user_id);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create ynab_sync_queue table: %w", err)
+	}
+
+	log.Println("YNAB sync queue table created successfully")
+	return nil
+}
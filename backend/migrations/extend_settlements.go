@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// ExtendSettlementsTable rebuilds the settlements table so a settlement
+// records who pays whom how much for a month's transactions, and stays
+// outstanding until settled_at is set. Existing settlements were already
+// settled: they become zero amount settlements from their user to the
+// counterpart in the month they were settled.
+func ExtendSettlementsTable(db *sql.DB) error {
+	log.Println("Extending settlements table...")
+
+	_, err := db.Exec(`
+		CREATE TABLE settlements_temp (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			from_user_id TEXT NOT NULL,
+			to_user_id TEXT NOT NULL,
+			amount REAL NOT NULL,
+			month TEXT NOT NULL,
+			note TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			settled_at TIMESTAMP
+		);
+		INSERT INTO settlements_temp (id, from_user_id, to_user_id, amount, month, created_at, settled_at)
+		SELECT id, user_id, with_user, 0, substr(settled_at, 1, 7), created_at, settled_at FROM settlements;
+		DROP TABLE settlements;
+		ALTER TABLE settlements_temp RENAME TO settlements;
+		CREATE INDEX IF NOT EXISTS idx_settlements_from ON settlements(from_user_id, to_user_id, month);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to extend settlements table: %w", err)
+	}
+
+	log.Println("Settlements table extended successfully")
+	return nil
+}
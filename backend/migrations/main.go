@@ -35,6 +35,35 @@ func RunMigrations(db *sql.DB) error {
 		{"add_optional_field", AddOptionalField},
 		{"add_permissions_table", AddPermissionsTable},
 		{"update_users_for_permissions", UpdateUsersForPermissions},
+		{"add_recurring_transactions", AddRecurringTransactionsTable},
+		{"add_category_ynab_link", AddCategoryYNABLink},
+		{"add_category_optional_default", AddCategoryOptionalDefault},
+		{"add_category_soft_delete", AddCategorySoftDelete},
+		{"add_transaction_history", AddTransactionHistoryTable},
+		{"add_transaction_categories", AddTransactionCategoriesTable},
+		{"add_transaction_tags", AddTransactionTagsTable},
+		{"add_transaction_source", AddTransactionSource},
+		{"add_transaction_import_batch", AddTransactionImportBatch},
+		{"add_transaction_sync_columns", AddTransactionSyncColumns},
+		{"add_transaction_original_amount", AddTransactionOriginalAmount},
+		{"add_saved_filters_and_reports", AddSavedFiltersAndReports},
+		{"add_custom_report_public_until", AddCustomReportPublicUntil},
+		{"add_categorization_rules", AddCategorizationRulesTable},
+		{"add_user_tokens_valid_after", AddUserTokensValidAfter},
+		{"add_transaction_status", AddTransactionStatus},
+		{"add_category_budgets", AddCategoryBudgetsTable},
+		{"add_feature_flags", AddFeatureFlagsTable},
+		{"add_transaction_locked", AddTransactionLocked},
+		{"add_transaction_refund_of", AddTransactionRefundOf},
+		{"add_ynab_sync_queue", AddYNABSyncQueueTable},
+		{"add_category_sort_order", AddCategorySortOrder},
+		{"add_user_report_settings", AddUserReportSettings},
+		{"add_transaction_templates", AddTransactionTemplatesTable},
+		{"add_settlement_snapshots", AddSettlementSnapshotsTable},
+		{"add_settlements", AddSettlementsTable},
+		{"extend_settlements", ExtendSettlementsTable},
+		{"add_recurring_anchor_day", AddRecurringAnchorDay},
+		{"add_transaction_change_seq", AddTransactionChangeSeq},
 		// For development and PR environments, also seed test data
 		{"seed_test_data", SeedTestData},
 	}
@@ -18,11 +18,10 @@ func SeedTestData(db *sql.DB) error {
 		return nil
 	}
 
-	// Only seed if explicitly requested or in dev/PR environment
-	if os.Getenv("RESET_DB") != "true" &&
-		os.Getenv("APP_ENV") != "development" &&
-		os.Getenv("PR_DEPLOYMENT") != "true" {
-		log.Println("Skipping test data seeding - not explicitly requested and not in dev/PR environment")
+	// Seeding clears existing data, so it only happens when a reset is
+	// explicitly requested, even in dev/PR environments
+	if os.Getenv("RESET_DB") != "true" {
+		log.Println("Skipping test data seeding - RESET_DB=true not set")
 		return nil
 	}
 
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+)
+
+// DefaultAmountDecimals is how many decimals amounts are serialized with,
+// matching the home currency's minor unit. Override with AMOUNT_DECIMALS.
+const DefaultAmountDecimals = 2
+
+// maxAmountDecimals bounds AMOUNT_DECIMALS
+const maxAmountDecimals = 8
+
+// Amount is a money amount in the home currency. It serializes with a fixed
+// number of decimals, so float noise from arithmetic (42.50000000001) never
+// reaches clients.
+type Amount float64
+
+// amountDecimals returns the configured number of decimals for amounts
+func amountDecimals() int {
+	value := os.Getenv("AMOUNT_DECIMALS")
+	if value == "" {
+		return DefaultAmountDecimals
+	}
+	decimals, err := strconv.Atoi(value)
+	if err != nil || decimals < 0 || decimals > maxAmountDecimals {
+		log.Printf("Warning: ignoring invalid AMOUNT_DECIMALS %q", value)
+		return DefaultAmountDecimals
+	}
+	return decimals
+}
+
+// MarshalJSON writes the amount as a JSON number rounded to the configured
+// number of decimals, e.g. 42.50
+func (a Amount) MarshalJSON() ([]byte, error) {
+	value := float64(a)
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, fmt.Errorf("unsupported amount %v", value)
+	}
+	return []byte(strconv.FormatFloat(value, 'f', amountDecimals(), 64)), nil
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAmountMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   Amount
+		expected string
+	}{
+		{"trailing zero kept", 42.5, "42.50"},
+		{"float noise dropped", Amount(0.1) + Amount(0.2), "0.30"},
+		{"long tail rounded", 42.50000000001, "42.50"},
+		{"whole amount", 7, "7.00"},
+		{"negative", -12.345, "-12.35"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.amount)
+			if err != nil {
+				t.Fatalf("Error marshaling amount: %v", err)
+			}
+			if string(data) != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, data)
+			}
+		})
+	}
+}
+
+func TestTransactionAmountSerialization(t *testing.T) {
+	data, err := json.Marshal(Transaction{ID: "tx-1", Amount: 42.5})
+	if err != nil {
+		t.Fatalf("Error marshaling transaction: %v", err)
+	}
+	if !strings.Contains(string(data), `"amount":42.50,`) {
+		t.Errorf("Expected the amount to serialize as 42.50, got %s", data)
+	}
+
+	// Clients still read a plain JSON number
+	var decoded Transaction
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Amount != 42.5 {
+		t.Errorf("Expected to read back 42.5, got %v (err %v)", decoded.Amount, err)
+	}
+}
+
+func TestAmountDecimalsConfigurable(t *testing.T) {
+	t.Setenv("AMOUNT_DECIMALS", "3")
+	if data, _ := json.Marshal(Amount(42.5)); string(data) != "42.500" {
+		t.Errorf("Expected 42.500 with AMOUNT_DECIMALS=3, got %s", data)
+	}
+
+	// Invalid settings fall back to two decimals
+	t.Setenv("AMOUNT_DECIMALS", "many")
+	if data, _ := json.Marshal(Amount(42.5)); string(data) != "42.50" {
+		t.Errorf("Expected 42.50 with an invalid AMOUNT_DECIMALS, got %s", data)
+	}
+}
+
+func TestReportAndSettlementAmountSerialization(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected []string
+	}{
+		{
+			"period comparison group",
+			PeriodComparisonGroup{Group: "Food", TotalA: Amount(0.1) + Amount(0.2), TotalB: 12.5, Delta: 12.2},
+			[]string{`"totalA":0.30`, `"totalB":12.50`, `"delta":12.20`},
+		},
+		{
+			"settlement balance",
+			SettlementBalance{FromUserID: "u1", ToUserID: "u2", Outstanding: 100, Settled: 40.5, Remaining: 59.5},
+			[]string{`"outstanding":100.00`, `"settled":40.50`, `"pending":0.00`, `"remaining":59.50`},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.value)
+			if err != nil {
+				t.Fatalf("Error marshaling %s: %v", tc.name, err)
+			}
+			for _, field := range tc.expected {
+				if !strings.Contains(string(data), field) {
+					t.Errorf("Expected %s in %s", field, data)
+				}
+			}
+		})
+	}
+}
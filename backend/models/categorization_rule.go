@@ -0,0 +1,93 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Transaction fields a categorization rule can match on
+const (
+	RuleFieldPayTo       = "payTo"
+	RuleFieldDescription = "description"
+	RuleFieldEnteredBy   = "enteredBy"
+)
+
+// Ways a categorization rule compares a field with its value. All of them
+// ignore case and surrounding spaces.
+const (
+	RuleOpContains   = "contains"
+	RuleOpEquals     = "equals"
+	RuleOpStartsWith = "startsWith"
+)
+
+// CategorizationRule assigns a category to new transactions whose field
+// matches. When several rules match, the highest priority wins.
+type CategorizationRule struct {
+	ID           int    `json:"id"`
+	UserID       string `json:"userId"`
+	MatchField   string `json:"matchField"` // payTo, description or enteredBy
+	MatchOp      string `json:"matchOp"`    // contains, equals or startsWith
+	MatchValue   string `json:"matchValue"`
+	CategoryID   int    `json:"categoryId"`
+	CategoryName string `json:"categoryName,omitempty"` // Empty once the category is deleted
+	Priority     int    `json:"priority"`
+}
+
+// ApplyRulesResult reports how many existing transactions a run of the
+// categorization rules looked at and how many it categorized
+type ApplyRulesResult struct {
+	Checked     int `json:"checked"`
+	Categorized int `json:"categorized"`
+}
+
+// Validate checks that the rule names a known field and operator and has
+// something to match
+func (r CategorizationRule) Validate() error {
+	switch r.MatchField {
+	case RuleFieldPayTo, RuleFieldDescription, RuleFieldEnteredBy:
+	default:
+		return fmt.Errorf("invalid matchField %q (expected payTo, description or enteredBy)", r.MatchField)
+	}
+	switch r.MatchOp {
+	case RuleOpContains, RuleOpEquals, RuleOpStartsWith:
+	default:
+		return fmt.Errorf("invalid matchOp %q (expected contains, equals or startsWith)", r.MatchOp)
+	}
+	if strings.TrimSpace(r.MatchValue) == "" {
+		return fmt.Errorf("matchValue is required")
+	}
+	if r.CategoryID == 0 {
+		return fmt.Errorf("categoryId is required")
+	}
+	return nil
+}
+
+// Matches reports whether the rule applies to a transaction
+func (r CategorizationRule) Matches(t Transaction) bool {
+	var field string
+	switch r.MatchField {
+	case RuleFieldPayTo:
+		field = t.PayTo
+	case RuleFieldDescription:
+		field = t.Description
+	case RuleFieldEnteredBy:
+		field = t.EnteredBy
+	default:
+		return false
+	}
+
+	field = strings.ToLower(strings.TrimSpace(field))
+	value := strings.ToLower(strings.TrimSpace(r.MatchValue))
+	if value == "" {
+		return false
+	}
+	switch r.MatchOp {
+	case RuleOpContains:
+		return strings.Contains(field, value)
+	case RuleOpEquals:
+		return field == value
+	case RuleOpStartsWith:
+		return strings.HasPrefix(field, value)
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package models
+
+import "testing"
+
+func TestCategorizationRuleMatches(t *testing.T) {
+	transaction := Transaction{PayTo: "Shell Station #42", Description: "Fuel", EnteredBy: "Sarah"}
+
+	testCases := []struct {
+		name     string
+		rule     CategorizationRule
+		expected bool
+	}{
+		{"contains ignores case", CategorizationRule{MatchField: RuleFieldPayTo, MatchOp: RuleOpContains, MatchValue: "shell"}, true},
+		{"contains without match", CategorizationRule{MatchField: RuleFieldPayTo, MatchOp: RuleOpContains, MatchValue: "Chevron"}, false},
+		{"equals whole value", CategorizationRule{MatchField: RuleFieldDescription, MatchOp: RuleOpEquals, MatchValue: " fuel "}, true},
+		{"equals rejects partial", CategorizationRule{MatchField: RuleFieldPayTo, MatchOp: RuleOpEquals, MatchValue: "Shell"}, false},
+		{"startsWith", CategorizationRule{MatchField: RuleFieldPayTo, MatchOp: RuleOpStartsWith, MatchValue: "Shell St"}, true},
+		{"startsWith rejects suffix", CategorizationRule{MatchField: RuleFieldPayTo, MatchOp: RuleOpStartsWith, MatchValue: "#42"}, false},
+		{"enteredBy", CategorizationRule{MatchField: RuleFieldEnteredBy, MatchOp: RuleOpEquals, MatchValue: "sarah"}, true},
+		{"unknown field", CategorizationRule{MatchField: "type", MatchOp: RuleOpContains, MatchValue: "Fuel"}, false},
+		{"blank value", CategorizationRule{MatchField: RuleFieldPayTo, MatchOp: RuleOpContains, MatchValue: " "}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.rule.Matches(transaction); got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestCategorizationRuleValidate(t *testing.T) {
+	valid := CategorizationRule{MatchField: RuleFieldPayTo, MatchOp: RuleOpContains, MatchValue: "Shell", CategoryID: 1}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Expected a valid rule, got %v", err)
+	}
+
+	invalid := []CategorizationRule{
+		{MatchField: "amount", MatchOp: RuleOpContains, MatchValue: "Shell", CategoryID: 1},
+		{MatchField: RuleFieldPayTo, MatchOp: "regex", MatchValue: "Shell", CategoryID: 1},
+		{MatchField: RuleFieldPayTo, MatchOp: RuleOpContains, MatchValue: "", CategoryID: 1},
+		{MatchField: RuleFieldPayTo, MatchOp: RuleOpContains, MatchValue: "Shell"},
+	}
+	for _, rule := range invalid {
+		if err := rule.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", rule)
+		}
+	}
+}
@@ -1,9 +1,91 @@
 package models
 
+import "time"
+
 type Category struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Color       string `json:"color,omitempty"`
-	UserID      string `json:"userId"`
+	ID              int    `json:"id"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	Color           string `json:"color,omitempty"`
+	UserID          string `json:"userId"`
+	OptionalDefault bool   `json:"optionalDefault"`     // New transactions in this category default to optional
+	SortOrder       *int   `json:"sortOrder,omitempty"` // Position in the user's custom ordering, if set
+}
+
+// DeletedCategory is a soft-deleted category that can still be restored
+type DeletedCategory struct {
+	Category
+	DeletedAt       time.Time `json:"deletedAt"`
+	RestorableUntil time.Time `json:"restorableUntil"`
+}
+
+// CategoryDetail is a category with one page of its linked transactions. Total
+// is the sum of the amounts assigned to the category across all of them.
+type CategoryDetail struct {
+	Category
+	Total        Amount          `json:"total"`
+	Transactions TransactionPage `json:"transactions"`
+}
+
+// StaleCategory is a category without recently linked transactions
+type StaleCategory struct {
+	Category
+	LastUsed string `json:"lastUsed,omitempty"` // YYYY-MM-DD of the latest linked transaction; empty if never used
+}
+
+// CategoryReorderRequest sets the user's custom category ordering. IDs lists
+// categories in their new order; the ones left out sort after them by name.
+type CategoryReorderRequest struct {
+	IDs []int `json:"ids"`
+}
+
+// CategoryBudget is the amount a user plans to spend in a category each
+// calendar month
+type CategoryBudget struct {
+	CategoryID int    `json:"categoryId"`
+	Amount     Amount `json:"amount"`
+}
+
+// UserCategories groups a single user's categories for admin views
+type UserCategories struct {
+	UserID     string     `json:"userId"`
+	UserName   string     `json:"userName"`
+	Categories []Category `json:"categories"`
+}
+
+// CategoryRename describes a local category renamed to follow YNAB
+type CategoryRename struct {
+	ID      int    `json:"id"`
+	OldName string `json:"oldName"`
+	NewName string `json:"newName"`
+}
+
+// CategoryReconcileResult reports the changes made (or, on a dry run, that
+// would be made) when reconciling local categories against YNAB
+type CategoryReconcileResult struct {
+	DryRun    bool             `json:"dryRun"`
+	Linked    int              `json:"linked"`
+	Renamed   []CategoryRename `json:"renamed"`
+	Archived  []Category       `json:"archived"`
+	Restored  []Category       `json:"restored"`
+	Conflicts []string         `json:"conflicts"`
+}
+
+// UnmappedYNABCategory is the YNAB category id reported for local categories
+// that don't resolve to any YNAB category
+const UnmappedYNABCategory = "unmapped"
+
+// CategoryMapping shows which YNAB category a local category name resolves to
+type CategoryMapping struct {
+	CategoryName   string `json:"categoryName"`
+	YNABCategoryID string `json:"ynabCategoryId"`
+	Reason         string `json:"reason,omitempty"` // Why the category is unmapped
+}
+
+// TransactionCategory assigns part (or all) of a transaction's amount to a category
+type TransactionCategory struct {
+	TransactionID string `json:"transactionId"`
+	CategoryID    int    `json:"categoryId"`
+	CategoryName  string `json:"categoryName,omitempty"`
+	Amount        Amount `json:"amount"`
 }
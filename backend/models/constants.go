@@ -14,3 +14,33 @@ const (
 	PermissionWrite = "write"
 	PermissionAdmin = "admin"
 )
+
+// ResourceTypes lists every resource type permissions can be granted on
+var ResourceTypes = []string{
+	ResourceTransactions,
+	ResourceCategories,
+	ResourceReports,
+	ResourceUsers,
+	ResourceAll,
+}
+
+// PermissionTypes lists every permission type that can be granted
+var PermissionTypes = []string{
+	PermissionRead,
+	PermissionWrite,
+	PermissionAdmin,
+}
+
+// PermissionVocabulary is the set of resource and permission types the
+// server recognizes
+type PermissionVocabulary struct {
+	ResourceTypes   []string `json:"resourceTypes"`
+	PermissionTypes []string `json:"permissionTypes"`
+}
+
+// User roles
+const (
+	RoleUser       = "user"
+	RoleAdmin      = "admin"
+	RoleSuperAdmin = "superadmin" // Household-wide administration such as copying configuration between users
+)
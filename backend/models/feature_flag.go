@@ -0,0 +1,9 @@
+package models
+
+// FeatureFlag turns a feature on or off for one user, or for everyone when
+// UserID is empty. A user's own flag overrides the global one.
+type FeatureFlag struct {
+	Flag    string `json:"flag"`
+	UserID  string `json:"userId,omitempty"`
+	Enabled bool   `json:"enabled"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// Recurring transaction frequencies
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+	FrequencyYearly  = "yearly"
+)
+
+// RecurringTransaction is a template that generates a transaction on a schedule
+type RecurringTransaction struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"userId"`
+	Amount      Amount    `json:"amount"`
+	Description string    `json:"description"`
+	Type        string    `json:"type"`
+	PayTo       string    `json:"payTo,omitempty"`
+	EnteredBy   string    `json:"enteredBy"`
+	Optional    bool      `json:"optional"`
+	Frequency   string    `json:"frequency"` // daily, weekly, monthly, yearly
+	NextDate    time.Time `json:"nextDate"`
+	AnchorDay   int       `json:"anchorDay,omitempty"` // day of month monthly and yearly templates fire on
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"createdAt,omitempty"`
+}
+
+// IsValidFrequency reports whether frequency is a supported recurrence
+func IsValidFrequency(frequency string) bool {
+	switch frequency {
+	case FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly:
+		return true
+	}
+	return false
+}
+
+// NextOccurrence returns the occurrence following from for the given
+// frequency. Monthly and yearly occurrences fall on anchorDay (from's day when
+// 0), or on the last day of months that are too short for it, so a template
+// on the 31st fires on Jan 31, Feb 29 and Mar 31.
+func NextOccurrence(from time.Time, frequency string, anchorDay int) time.Time {
+	switch frequency {
+	case FrequencyDaily:
+		return from.AddDate(0, 0, 1)
+	case FrequencyWeekly:
+		return from.AddDate(0, 0, 7)
+	case FrequencyYearly:
+		return addMonthsOnDay(from, 12, anchorDay)
+	default:
+		return addMonthsOnDay(from, 1, anchorDay)
+	}
+}
+
+// addMonthsOnDay moves from forward by months onto day of the target month,
+// clamped to the month's last day
+func addMonthsOnDay(from time.Time, months, day int) time.Time {
+	if day < 1 {
+		day = from.Day()
+	}
+	first := time.Date(from.Year(), from.Month()+time.Month(months), 1,
+		from.Hour(), from.Minute(), from.Second(), from.Nanosecond(), from.Location())
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// UpcomingRecurringTransaction is a projected occurrence of a recurring template
+type UpcomingRecurringTransaction struct {
+	RecurringID string    `json:"recurringId"`
+	Description string    `json:"description"`
+	Amount      Amount    `json:"amount"`
+	Type        string    `json:"type"`
+	PayTo       string    `json:"payTo,omitempty"`
+	Frequency   string    `json:"frequency"`
+	Date        time.Time `json:"date"`
+}
+
+// ProjectOccurrences returns the dates a template fires on, starting from its
+// next date, up to and including until
+func (rt RecurringTransaction) ProjectOccurrences(until time.Time) []time.Time {
+	var dates []time.Time
+	for next := rt.NextDate; !next.After(until); next = NextOccurrence(next, rt.Frequency, rt.AnchorDay) {
+		dates = append(dates, next)
+	}
+	return dates
+}
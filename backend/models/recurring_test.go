@@ -0,0 +1,81 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProjectOccurrences(t *testing.T) {
+	start := time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		frequency string
+		until     time.Time
+		expected  []string
+	}{
+		{
+			frequency: FrequencyWeekly,
+			until:     start.AddDate(0, 0, 21),
+			expected:  []string{"2024-01-31", "2024-02-07", "2024-02-14", "2024-02-21"},
+		},
+		{
+			frequency: FrequencyMonthly,
+			until:     time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC),
+			// Short months fall back to their last day, then the 31st returns
+			expected: []string{"2024-01-31", "2024-02-29", "2024-03-31", "2024-04-30"},
+		},
+		{
+			frequency: FrequencyYearly,
+			until:     time.Date(2027, time.December, 31, 0, 0, 0, 0, time.UTC),
+			expected:  []string{"2024-01-31", "2025-01-31", "2026-01-31", "2027-01-31"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.frequency, func(t *testing.T) {
+			rt := RecurringTransaction{Frequency: tc.frequency, NextDate: start, AnchorDay: start.Day()}
+			dates := rt.ProjectOccurrences(tc.until)
+
+			if len(dates) != len(tc.expected) {
+				t.Fatalf("Expected %d occurrences, got %d: %v", len(tc.expected), len(dates), dates)
+			}
+			for i, date := range dates {
+				if date.Format("2006-01-02") != tc.expected[i] {
+					t.Errorf("Occurrence %d: expected %s, got %s", i, tc.expected[i], date.Format("2006-01-02"))
+				}
+				// Each projected date must match what the generator would advance to
+				if i > 0 && !date.Equal(NextOccurrence(dates[i-1], tc.frequency, rt.AnchorDay)) {
+					t.Errorf("Occurrence %d does not follow frequency advancement", i)
+				}
+			}
+		})
+	}
+}
+
+func TestProjectOccurrencesOutsideWindow(t *testing.T) {
+	rt := RecurringTransaction{
+		Frequency: FrequencyMonthly,
+		NextDate:  time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	dates := rt.ProjectOccurrences(time.Date(2024, time.May, 31, 0, 0, 0, 0, time.UTC))
+	if len(dates) != 0 {
+		t.Errorf("Expected no occurrences before the next date, got %v", dates)
+	}
+}
+
+func TestNextOccurrenceLeapDay(t *testing.T) {
+	leapDay := time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)
+
+	next := NextOccurrence(leapDay, FrequencyYearly, 29)
+	if next.Format("2006-01-02") != "2025-02-28" {
+		t.Errorf("Expected a yearly leap day template to fire on 2025-02-28, got %s", next.Format("2006-01-02"))
+	}
+	if again := NextOccurrence(next, FrequencyYearly, 29); again.Format("2006-01-02") != "2026-02-28" {
+		t.Errorf("Expected 2026-02-28, got %s", again.Format("2006-01-02"))
+	}
+	// Without an anchor the current day is kept
+	if next := NextOccurrence(time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC), FrequencyMonthly, 0); next.Day() != 15 {
+		t.Errorf("Expected the 15th without an anchor, got %s", next.Format("2006-01-02"))
+	}
+}
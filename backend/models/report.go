@@ -1,20 +1,300 @@
 package models
 
+import (
+	"strings"
+	"time"
+)
+
 type ReportFilter struct {
 	StartDate string `json:"startDate,omitempty"`
 	EndDate   string `json:"endDate,omitempty"`
+	Range     string `json:"range,omitempty"` // Relative shortcut: thisMonth, lastMonth, ytd
 	Category  string `json:"category,omitempty"`
 	PayTo     string `json:"payTo,omitempty"`
 	EnteredBy string `json:"enteredBy,omitempty"`
 	Paid      *bool  `json:"paid,omitempty"`
 	Optional  *bool  `json:"optional,omitempty"`
 	UserId    string `json:"userId,omitempty"`
+	Tag       string `json:"tag,omitempty"` // Only transactions carrying this tag
+
+	// Categories widens the category filter to any of several categories,
+	// together with Category
+	Categories []string `json:"categories,omitempty"`
+
 	// New fields for transaction date filtering
 	TransactionDateMonth *int `json:"transactionDateMonth,omitempty"` // 1-12 for month
 	TransactionDateYear  *int `json:"transactionDateYear,omitempty"`  // Full year (e.g., 2024)
 }
 
+// CategoryNames returns the categories the filter matches, from both
+// Category and Categories, without blanks or duplicates. None means every
+// category.
+func (f ReportFilter) CategoryNames() []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, name := range append([]string{f.Category}, f.Categories...) {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
 type CategoryTotal struct {
-	Category string  `json:"category"`
-	Total    float64 `json:"total"`
+	Category string `json:"category"`
+	Total    Amount `json:"total"`
+}
+
+// EntererTotal is the total of the transactions one person entered
+type EntererTotal struct {
+	EnteredBy string `json:"enteredBy"`
+	Total     Amount `json:"total"`
+}
+
+// CategoryPercentage is a category's total and its share of the grand total
+type CategoryPercentage struct {
+	Category   string  `json:"category"`
+	Total      Amount  `json:"total"`
+	Percentage float64 `json:"percentage"`
+}
+
+// CategoryPercentages breaks a period's spending down by category. The
+// percentages are rounded to two decimals and add up to exactly 100.
+type CategoryPercentages struct {
+	StartDate  string               `json:"startDate,omitempty"`
+	EndDate    string               `json:"endDate,omitempty"`
+	Total      Amount               `json:"total"`
+	Categories []CategoryPercentage `json:"categories"`
+}
+
+// LedgerEntry is a transaction with the running balance after it
+type LedgerEntry struct {
+	Transaction
+	Balance Amount `json:"balance"`
+}
+
+// Ledger lists a period's transactions oldest first with a running balance
+// starting from zero. Balance is the final running balance.
+type Ledger struct {
+	StartDate string        `json:"startDate,omitempty"`
+	EndDate   string        `json:"endDate,omitempty"`
+	Entries   []LedgerEntry `json:"entries"`
+	Balance   Amount        `json:"balance"`
+}
+
+// NetWorthBucket is one interval of a net worth trend. Cumulative is the net
+// of every transaction up to the end of the bucket.
+type NetWorthBucket struct {
+	Period     string `json:"period"` // YYYY-MM-DD for weeks, YYYY-MM for months, YYYY for years
+	Income     Amount `json:"income"`
+	Expense    Amount `json:"expense"`
+	Net        Amount `json:"net"`
+	Cumulative Amount `json:"cumulative"`
+}
+
+// BalanceAsOf is the net (income minus expense) of every transaction up to
+// and including a date
+type BalanceAsOf struct {
+	Date    string `json:"date"`
+	Income  Amount `json:"income"`
+	Expense Amount `json:"expense"`
+	Balance Amount `json:"balance"`
+}
+
+// NetWorthTrend is the running net (income minus expense) over time. Opening
+// is the net of the transactions before the first bucket.
+type NetWorthTrend struct {
+	Interval string           `json:"interval"`
+	Opening  Amount           `json:"opening"`
+	Buckets  []NetWorthBucket `json:"buckets"`
+}
+
+// ReportPeriod is a date range given either as explicit bounds or a relative shortcut
+type ReportPeriod struct {
+	StartDate string `json:"startDate,omitempty"`
+	EndDate   string `json:"endDate,omitempty"`
+	Range     string `json:"range,omitempty"`
+}
+
+// PeriodComparisonRequest asks for group totals in two periods side by side
+type PeriodComparisonRequest struct {
+	PeriodA  ReportPeriod `json:"periodA"`
+	PeriodB  ReportPeriod `json:"periodB"`
+	GroupBy  string       `json:"groupBy,omitempty"` // category (default), payTo or enteredBy
+	Paid     *bool        `json:"paid,omitempty"`
+	Optional *bool        `json:"optional,omitempty"`
+}
+
+// PeriodComparisonGroup is one group's totals in both periods. PercentChange
+// is omitted when the group had no total in period A.
+type PeriodComparisonGroup struct {
+	Group         string   `json:"group"`
+	TotalA        Amount   `json:"totalA"`
+	TotalB        Amount   `json:"totalB"`
+	Delta         Amount   `json:"delta"`
+	PercentChange *float64 `json:"percentChange,omitempty"`
+}
+
+// PeriodComparison is the result of comparing two periods
+type PeriodComparison struct {
+	GroupBy string                  `json:"groupBy"`
+	PeriodA ReportPeriod            `json:"periodA"`
+	PeriodB ReportPeriod            `json:"periodB"`
+	Groups  []PeriodComparisonGroup `json:"groups"`
+}
+
+// SavedFilter is a named set of transaction filters kept as JSON
+type SavedFilter struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"userId"`
+	Name         string    `json:"name"`
+	FilterConfig string    `json:"filterConfig"`
+	IsDefault    bool      `json:"isDefault"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// CustomReport is a user-defined report whose definition is kept as JSON
+type CustomReport struct {
+	ID           string     `json:"id"`
+	UserID       string     `json:"userId"`
+	Name         string     `json:"name"`
+	Description  string     `json:"description,omitempty"`
+	ReportConfig string     `json:"reportConfig"`
+	IsPublic     bool       `json:"isPublic"`
+	PublicUntil  *time.Time `json:"publicUntil,omitempty"` // Sharing ends at this time; nil shares indefinitely
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+}
+
+// Custom report scopes: whose transactions a custom report covers
+const (
+	ReportScopeOwn        = "own"        // The transactions of the user running the report
+	ReportScopeAccessible = "accessible" // Every transaction the user running the report can read
+	ReportScopeUser       = "user"       // The transactions of ScopeUserID, if the user running the report can read them
+)
+
+// CustomReportConfig is the definition stored in a custom report's
+// reportConfig. The period is given like the other reports' date ranges.
+type CustomReportConfig struct {
+	GroupBy     string `json:"groupBy,omitempty"` // category (default), payTo or enteredBy
+	StartDate   string `json:"startDate,omitempty"`
+	EndDate     string `json:"endDate,omitempty"`
+	Range       string `json:"range,omitempty"`
+	Paid        *bool  `json:"paid,omitempty"`
+	Optional    *bool  `json:"optional,omitempty"`
+	Scope       string `json:"scope,omitempty"`       // own, accessible (default) or user
+	ScopeUserID string `json:"scopeUserId,omitempty"` // Whose transactions a user scoped report covers
+
+	Categories  []string `json:"categories,omitempty"`  // Only these categories; every category when empty
+	PayTo       string   `json:"payTo,omitempty"`       // Only payees containing this, like the splits report
+	Aggregation string   `json:"aggregation,omitempty"` // sum (default), count or average
+}
+
+// How custom reports aggregate each group's transactions
+const (
+	ReportAggregationSum     = "sum"
+	ReportAggregationCount   = "count"
+	ReportAggregationAverage = "average"
+)
+
+// ReportConfigFieldError is a problem with one field of a report config. The
+// field is empty when the config as a whole is malformed.
+type ReportConfigFieldError struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e ReportConfigFieldError) String() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// ReportConfigValidation is the outcome of checking a report config
+type ReportConfigValidation struct {
+	Valid  bool                     `json:"valid"`
+	Errors []ReportConfigFieldError `json:"errors"`
+}
+
+// CustomReportRow is one group's total in a custom report result
+type CustomReportRow struct {
+	Group string `json:"group"`
+	Total Amount `json:"total"`
+}
+
+// CustomReportResult is the outcome of running a custom report
+type CustomReportResult struct {
+	ReportID    string            `json:"reportId"`
+	Name        string            `json:"name"`
+	GroupBy     string            `json:"groupBy"`
+	Scope       string            `json:"scope"`
+	ScopeUserID string            `json:"scopeUserId,omitempty"`
+	Aggregation string            `json:"aggregation"`
+	Rows        []CustomReportRow `json:"rows"`
+	Total       Amount            `json:"total"` // The aggregation over every row's transactions
+}
+
+// Kinds of configuration that can reference a category
+const (
+	ReportUsageSavedFilter  = "savedFilter"
+	ReportUsageCustomReport = "customReport"
+)
+
+// CategoryReportUsage is a saved filter or custom report that references a category
+type CategoryReportUsage struct {
+	Kind string `json:"kind"` // savedFilter or customReport
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// SharedAt reports whether the report is visible to other users at the given
+// time. A report stops being shared once its PublicUntil has passed.
+func (r CustomReport) SharedAt(t time.Time) bool {
+	return r.IsPublic && (r.PublicUntil == nil || t.Before(*r.PublicUntil))
+}
+
+// CategoryTrend is a category whose spending in a period is well above its
+// trailing average
+type CategoryTrend struct {
+	Category        string  `json:"category"`
+	CurrentTotal    Amount  `json:"currentTotal"`
+	TrailingAverage Amount  `json:"trailingAverage"`
+	Delta           Amount  `json:"delta"`
+	PercentChange   float64 `json:"percentChange"`
+}
+
+// CategoryTrendReport lists the categories flagged for a month
+type CategoryTrendReport struct {
+	Month          string          `json:"month"` // YYYY-MM
+	TrailingMonths int             `json:"trailingMonths"`
+	Threshold      float64         `json:"threshold"` // Percent above the trailing average needed to be flagged
+	Flagged        []CategoryTrend `json:"flagged"`
+}
+
+// ReportSettings are a user's report defaults, used when a report request
+// leaves the corresponding filter out
+type ReportSettings struct {
+	IncludeOptional bool `json:"includeOptional"` // Count optional transactions
+}
+
+// YNABGroupTotal is the total of a YNAB category group, made up of the
+// totals of the local categories resolving to categories in the group
+type YNABGroupTotal struct {
+	GroupID    string          `json:"groupId"` // UnmappedYNABCategory for categories that don't resolve
+	GroupName  string          `json:"groupName"`
+	Total      Amount          `json:"total"`
+	Categories []CategoryTotal `json:"categories"`
+}
+
+// YNABGroupTotals rolls a period's category totals up by YNAB category group
+type YNABGroupTotals struct {
+	StartDate string           `json:"startDate,omitempty"`
+	EndDate   string           `json:"endDate,omitempty"`
+	Total     Amount           `json:"total"`
+	Groups    []YNABGroupTotal `json:"groups"`
 }
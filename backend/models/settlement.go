@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// SettlementFigure is what a month's transactions with one counterpart (their
+// payTo) come to. Refunds count negatively.
+type SettlementFigure struct {
+	Counterpart  string `json:"counterpart"` // Empty for transactions without a payTo
+	Net          Amount `json:"net"`
+	Outstanding  Amount `json:"outstanding"` // The part of net not paid yet
+	Transactions int    `json:"transactions"`
+}
+
+// SettlementSnapshot is a user's cached settlement of a month over the
+// transactions they can read
+type SettlementSnapshot struct {
+	Month        string             `json:"month"`
+	ComputedAt   time.Time          `json:"computedAt"`
+	Counterparts []SettlementFigure `json:"counterparts"`
+}
+
+// Settlement is a payment settling what one user owes a payee for a month's
+// or week's transactions. It is outstanding until it is marked paid.
+type Settlement struct {
+	ID         int64      `json:"id"`
+	FromUserID string     `json:"fromUserId"` // Who pays; defaults to the user recording it
+	ToUserID   string     `json:"toUserId"`   // Who is paid, as in the transactions' payTo
+	Amount     Amount     `json:"amount"`
+	Month      string     `json:"month"` // YYYY-MM, or the ISO week as YYYY-Www for weekly settlements
+	Note       string     `json:"note,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	SettledAt  *time.Time `json:"settledAt"` // nil until paid
+}
+
+// SettlementBalance is what a user owes a payee for a period: their unpaid,
+// non-optional transactions with the payee merged with the settlements
+// recorded for them
+type SettlementBalance struct {
+	FromUserID  string       `json:"fromUserId"`
+	ToUserID    string       `json:"toUserId"`
+	Month       string       `json:"month"`       // The period: YYYY-MM, or YYYY-Www for weeks
+	Period      string       `json:"period"`      // month or week
+	Outstanding Amount       `json:"outstanding"` // Refunds count negatively
+	Settled     Amount       `json:"settled"`     // Paid settlements
+	Pending     Amount       `json:"pending"`     // Settlements not paid yet
+	Remaining   Amount       `json:"remaining"`   // Outstanding less settled
+	Settlements []Settlement `json:"settlements"`
+}
+
+// SinceLastSettlement is what the accessible transactions with a counterpart
+// come to since the user last paid a settlement to them. Since is nil when
+// they never did, in which case every transaction counts.
+type SinceLastSettlement struct {
+	WithUser     string     `json:"withUser"`
+	Since        *time.Time `json:"since"`
+	Net          Amount     `json:"net"`
+	Outstanding  Amount     `json:"outstanding"` // The part of net not paid yet
+	Transactions int        `json:"transactions"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// TransactionTemplate is a saved transaction, such as a frequent but
+// irregular purchase, that transactions are created from on demand
+type TransactionTemplate struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"userId"`
+	Name        string    `json:"name"`
+	Amount      Amount    `json:"amount"`
+	Description string    `json:"description"`
+	Type        string    `json:"type"`
+	PayTo       string    `json:"payTo,omitempty"`
+	EnteredBy   string    `json:"enteredBy"`
+	Optional    bool      `json:"optional"`
+	CreatedAt   time.Time `json:"createdAt,omitempty"`
+}
+
+// InstantiateTemplateRequest overrides a template's values for the
+// transaction created from it. A zero date means today; a nil amount keeps
+// the template's.
+type InstantiateTemplateRequest struct {
+	Date   time.Time `json:"date"`
+	Amount *float64  `json:"amount,omitempty"`
+}
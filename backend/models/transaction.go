@@ -4,7 +4,7 @@ import "time"
 
 type Transaction struct {
 	ID              string    `json:"id"`
-	Amount          float64   `json:"amount"`
+	Amount          Amount    `json:"amount"` // In the home currency
 	Description     string    `json:"description"`
 	Date            time.Time `json:"date"`
 	TransactionDate time.Time `json:"transactionDate"`
@@ -15,4 +15,211 @@ type Transaction struct {
 	EnteredBy       string    `json:"enteredBy"`
 	Optional        bool      `json:"optional"`
 	UserID          string    `json:"userId,omitempty"`
+	Source          string    `json:"source,omitempty"`        // How the transaction was created, one of the TransactionSource values
+	ImportBatchID   string    `json:"importBatchId,omitempty"` // The import that created the transaction, if any
+	Status          string    `json:"status,omitempty"`        // Whether the charge has settled, one of the TransactionStatus values
+	Locked          bool      `json:"locked,omitempty"`        // Locked transactions can't be edited or deleted without an admin override
+	OwnerName       string    `json:"ownerName,omitempty"`     // The owner's display name, only set when requested with includeOwner
+	RefundOf        string    `json:"refundOf,omitempty"`      // The transaction this one (partially) refunds; reports subtract it from that transaction's group
+
+	// The amount in the currency the transaction was made in, when that is
+	// not the home currency. Set together or not at all.
+	OriginalAmount   *float64 `json:"originalAmount,omitempty"`
+	OriginalCurrency string   `json:"originalCurrency,omitempty"` // ISO 4217 code, e.g. EUR
+}
+
+// Transaction sources
+const (
+	TransactionSourceManual    = "manual"    // Entered by hand
+	TransactionSourceImport    = "import"    // Created by a bulk import
+	TransactionSourceYNAB      = "ynab"      // Pulled in from YNAB
+	TransactionSourceRecurring = "recurring" // Generated from a recurring template
+	TransactionSourceTemplate  = "template"  // Created on demand from a saved template
+)
+
+// TransactionSources lists every transaction source
+var TransactionSources = []string{
+	TransactionSourceManual,
+	TransactionSourceImport,
+	TransactionSourceYNAB,
+	TransactionSourceRecurring,
+	TransactionSourceTemplate,
+}
+
+// IsValidTransactionSource reports whether source is a known transaction source
+func IsValidTransactionSource(source string) bool {
+	for _, known := range TransactionSources {
+		if source == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Transaction statuses track a charge itself, independently of whether it
+// has been paid back
+const (
+	TransactionStatusCleared  = "cleared"  // Settled with the bank
+	TransactionStatusPending  = "pending"  // Not settled yet
+	TransactionStatusDisputed = "disputed" // Being contested
+)
+
+// TransactionStatuses lists every transaction status
+var TransactionStatuses = []string{
+	TransactionStatusCleared,
+	TransactionStatusPending,
+	TransactionStatusDisputed,
+}
+
+// IsValidTransactionStatus reports whether status is a known transaction status
+func IsValidTransactionStatus(status string) bool {
+	for _, known := range TransactionStatuses {
+		if status == known {
+			return true
+		}
+	}
+	return false
+}
+
+// CreatedTransaction is the response to creating a transaction. Warnings
+// point out non-blocking problems, such as a category going over budget.
+type CreatedTransaction struct {
+	Transaction
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// TransactionPage is one page of a paginated transaction listing
+type TransactionPage struct {
+	Transactions []Transaction `json:"transactions"`
+	Page         int           `json:"page"`
+	PageSize     int           `json:"pageSize"`
+	Total        int           `json:"total"`
+}
+
+// StatementDay is one day of a transaction statement with its subtotal
+type StatementDay struct {
+	Date         string        `json:"date"` // YYYY-MM-DD
+	Transactions []Transaction `json:"transactions"`
+	Subtotal     Amount        `json:"subtotal"`
+}
+
+// TransactionStatement is a month's transactions grouped by day, oldest
+// first. Days without transactions are left out.
+type TransactionStatement struct {
+	Month string         `json:"month"` // YYYY-MM
+	Days  []StatementDay `json:"days"`
+	Total Amount         `json:"total"`
+}
+
+// OverdueGroup is the overdue transactions owed to one payee, oldest first
+type OverdueGroup struct {
+	PayTo        string        `json:"payTo"`
+	Transactions []Transaction `json:"transactions"`
+	Total        Amount        `json:"total"`
+}
+
+// OverdueTransactions lists the unpaid transactions dated before Before,
+// grouped by payee
+type OverdueTransactions struct {
+	Days   int            `json:"days"`
+	Before string         `json:"before"` // YYYY-MM-DD, exclusive
+	Groups []OverdueGroup `json:"groups"`
+	Total  Amount         `json:"total"`
+}
+
+// Modes for bulk tagging
+const (
+	TagModeAdd     = "add"     // Keep existing tags and add the new ones
+	TagModeReplace = "replace" // Replace existing tags with the new ones
+)
+
+// BulkTagRequest applies tags to several transactions at once
+type BulkTagRequest struct {
+	IDs  []string `json:"ids"`
+	Tags []string `json:"tags"`
+	Mode string   `json:"mode"`
+}
+
+// BulkOptionalRequest sets the optional flag of several transactions at once
+type BulkOptionalRequest struct {
+	IDs      []string `json:"ids"`
+	Optional *bool    `json:"optional"`
+}
+
+// ShiftDatesRequest moves the dates of several transactions by a number of days
+type ShiftDatesRequest struct {
+	IDs  []string `json:"ids"`
+	Days int      `json:"days"` // Negative to move the dates back
+}
+
+// LockMonthRequest locks or unlocks the transactions of a month
+type LockMonthRequest struct {
+	Month string `json:"month"` // YYYY-MM
+}
+
+// DuplicateGroup is a set of transactions with the same amount, payee and day
+type DuplicateGroup struct {
+	Amount         Amount   `json:"amount"`
+	PayTo          string   `json:"payTo"`
+	Date           string   `json:"date"` // YYYY-MM-DD
+	TransactionIDs []string `json:"transactionIds"`
+	KeptID         string   `json:"keptId"` // The transaction kept when duplicates are removed
+}
+
+// DedupeResult lists duplicate groups and, when applied, how many transactions
+// were removed and which locked duplicates were kept
+type DedupeResult struct {
+	Groups  []DuplicateGroup `json:"groups"`
+	Applied bool             `json:"applied"`
+	Deleted int              `json:"deleted"`
+	Skipped []string         `json:"skipped,omitempty"`
+}
+
+// ImportResult reports the batch an import created. A CSV import skips the
+// rows it can't import and lists why in Errors; the batch ID is empty when
+// nothing was imported.
+type ImportResult struct {
+	BatchID  string           `json:"batchId,omitempty"`
+	Imported int              `json:"imported"`
+	Skipped  int              `json:"skipped"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+// ImportRowError explains why a row of a CSV import was skipped
+type ImportRowError struct {
+	Line    int    `json:"line"` // The row's line in the CSV, counting the header as line 1
+	Message string `json:"message"`
+}
+
+// RollbackResult reports how many transactions rolling back an import deleted
+// and which locked ones it kept
+type RollbackResult struct {
+	BatchID string   `json:"batchId"`
+	Deleted int      `json:"deleted"`
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// TransactionFilterField describes one way GET /transactions can be filtered
+type TransactionFilterField struct {
+	Field     string            `json:"field"`
+	Type      string            `json:"type"` // string, boolean, number, date or enum
+	Operators []string          `json:"operators"`
+	Params    map[string]string `json:"params"`           // Query parameter to use for each operator
+	Values    []string          `json:"values,omitempty"` // Accepted values for enums and date range shortcuts
+}
+
+// TransactionChange is a transaction created, updated or deleted since a sync
+type TransactionChange struct {
+	Transaction
+	UpdatedAt time.Time  `json:"updatedAt"`
+	Deleted   bool       `json:"deleted"` // Tombstone: the client should drop its copy
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+// TransactionChanges lists the changes since a sync and the cursor to pass
+// on the next sync. Until is the time of the request.
+type TransactionChanges struct {
+	Changes []TransactionChange `json:"changes"`
+	Cursor  int64               `json:"cursor"`
+	Until   time.Time           `json:"until"`
 }
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// TransactionHistoryEntry records a single edit to a transaction
+type TransactionHistoryEntry struct {
+	ID            int         `json:"id"`
+	TransactionID string      `json:"transactionId"`
+	ChangedBy     string      `json:"changedBy"`
+	ChangedAt     time.Time   `json:"changedAt"`
+	ChangedFields []string    `json:"changedFields"`
+	Before        Transaction `json:"before"`
+	After         Transaction `json:"after"`
+}
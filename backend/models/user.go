@@ -31,3 +31,39 @@ type Permission struct {
 
 // ResourceCategories is a resource type not defined in constants.go
 const ResourceCategories = "categories"
+
+// ActivitySummary is a lightweight overview of a user's own activity
+type ActivitySummary struct {
+	TransactionsThisMonth     int        `json:"transactionsThisMonth"`
+	LastSyncTime              *time.Time `json:"lastSyncTime"` // Null if the user never synced with YNAB
+	Categories                int        `json:"categories"`
+	PendingReimbursements     int        `json:"pendingReimbursements"` // Unpaid transactions
+	PendingReimbursementTotal Amount     `json:"pendingReimbursementTotal"`
+}
+
+// DataExport is everything stored about a user, for data portability. The
+// YNAB configuration never includes the API token.
+type DataExport struct {
+	ExportedAt    time.Time      `json:"exportedAt"`
+	User          User           `json:"user"`
+	Transactions  []Transaction  `json:"transactions"`
+	Categories    []Category     `json:"categories"`
+	SavedFilters  []SavedFilter  `json:"savedFilters"`
+	CustomReports []CustomReport `json:"customReports"`
+	Permissions   []Permission   `json:"permissions"` // Granted by or to the user
+	YNABConfig    *YNABConfig    `json:"ynabConfig"`
+}
+
+// DataDeletionConfirmation must be sent to delete all of a user's data, so a
+// stray request can't do it by accident
+const DataDeletionConfirmation = "DELETE MY DATA"
+
+// DataDeletionRequest is the body of a request to delete all of a user's data
+type DataDeletionRequest struct {
+	Confirm string `json:"confirm"`
+}
+
+// DataDeletionResult is how many rows were removed from each table
+type DataDeletionResult struct {
+	Deleted map[string]int64 `json:"deleted"`
+}
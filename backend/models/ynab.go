@@ -27,3 +27,108 @@ type YNABCategoryResponse struct {
 		} `json:"category_groups"`
 	} `json:"data"`
 }
+
+// YNABCategoryPage is one page of a user's YNAB categories across all groups
+type YNABCategoryPage struct {
+	Categories []YNABCategory `json:"categories"`
+	Total      int            `json:"total"`
+	Limit      int            `json:"limit"`
+	Offset     int            `json:"offset"`
+}
+
+// YNABCategoryRename is a YNAB category whose name differs from the stored one
+type YNABCategoryRename struct {
+	ID      string `json:"id"`
+	OldName string `json:"oldName"`
+	NewName string `json:"newName"`
+}
+
+// YNABCategorySyncPreview lists how a category sync would change the stored
+// YNAB categories
+type YNABCategorySyncPreview struct {
+	Added   []YNABCategory       `json:"added"`
+	Renamed []YNABCategoryRename `json:"renamed"`
+	Removed []YNABCategory       `json:"removed"`
+}
+
+// YNABAccountResponse is the YNAB API response listing a budget's accounts.
+// Balances are in milliunits.
+type YNABAccountResponse struct {
+	Data struct {
+		Accounts []struct {
+			ID               string `json:"id"`
+			Name             string `json:"name"`
+			Type             string `json:"type"`
+			Closed           bool   `json:"closed"`
+			Deleted          bool   `json:"deleted"`
+			Balance          int64  `json:"balance"`
+			ClearedBalance   int64  `json:"cleared_balance"`
+			UnclearedBalance int64  `json:"uncleared_balance"`
+		} `json:"accounts"`
+	} `json:"data"`
+}
+
+// YNABAccountBalance is a YNAB account's balance in dollars, for reconciliation
+type YNABAccountBalance struct {
+	ID               string  `json:"id"`
+	Name             string  `json:"name"`
+	Type             string  `json:"type"`
+	Closed           bool    `json:"closed"`
+	Balance          float64 `json:"balance"`
+	ClearedBalance   float64 `json:"clearedBalance"`
+	UnclearedBalance float64 `json:"unclearedBalance"`
+}
+
+// YNABTransactionResponse is the YNAB API response listing an account's
+// transactions. Amounts are in milliunits, negative for outflows.
+type YNABTransactionResponse struct {
+	Data struct {
+		Transactions []struct {
+			ID        string `json:"id"`
+			Date      string `json:"date"`
+			Amount    int64  `json:"amount"`
+			PayeeName string `json:"payee_name"`
+			Memo      string `json:"memo"`
+			Deleted   bool   `json:"deleted"`
+		} `json:"transactions"`
+	} `json:"data"`
+}
+
+// YNABAccountTransaction is a transaction in a YNAB account, with its
+// amount in dollars
+type YNABAccountTransaction struct {
+	ID        string  `json:"id"`
+	Date      string  `json:"date"`
+	Amount    float64 `json:"amount"`
+	PayeeName string  `json:"payeeName"`
+	Memo      string  `json:"memo,omitempty"`
+}
+
+// Kinds of discrepancy found when reconciling local transactions with YNAB
+const (
+	YNABDiscrepancyAmountMismatch = "amountMismatch"
+	YNABDiscrepancyMissingInYNAB  = "missingInYNAB"
+	YNABDiscrepancyMissingLocally = "missingLocally"
+)
+
+// YNABTransactionDiscrepancy is a local YNAB transaction and its YNAB
+// counterpart that disagree, or one of the two on its own
+type YNABTransactionDiscrepancy struct {
+	Kind        string   `json:"kind"`
+	Date        string   `json:"date"`
+	PayTo       string   `json:"payTo"`
+	LocalID     string   `json:"localId,omitempty"`
+	YNABID      string   `json:"ynabId,omitempty"`
+	LocalAmount *float64 `json:"localAmount,omitempty"`
+	YNABAmount  *float64 `json:"ynabAmount,omitempty"`
+}
+
+// YNABTransactionReconciliation is the difference between the locally stored
+// YNAB transactions and those currently in the YNAB account
+type YNABTransactionReconciliation struct {
+	AccountID     string                       `json:"accountId"`
+	StartDate     string                       `json:"startDate,omitempty"`
+	EndDate       string                       `json:"endDate,omitempty"`
+	Matched       int                          `json:"matched"`
+	Discrepancies []YNABTransactionDiscrepancy `json:"discrepancies"`
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"bennwallet/backend/database"
+)
+
+// DefaultYNABCategoryMatchThreshold is the minimum similarity (0-1) a YNAB
+// category name needs to be used when there is no exact match. Override with
+// YNAB_CATEGORY_MATCH_THRESHOLD.
+const DefaultYNABCategoryMatchThreshold = 0.8
+
+var (
+	// ErrNoYNABCategoryMatch is returned when no YNAB category is close enough to a name
+	ErrNoYNABCategoryMatch = errors.New("no matching YNAB category")
+	// ErrAmbiguousYNABCategory is returned when several YNAB categories match a name equally well
+	ErrAmbiguousYNABCategory = errors.New("ambiguous YNAB category")
+)
+
+// ynabCategoryMatchThreshold returns the configured fuzzy match threshold
+func ynabCategoryMatchThreshold() float64 {
+	value := os.Getenv("YNAB_CATEGORY_MATCH_THRESHOLD")
+	if value == "" {
+		return DefaultYNABCategoryMatchThreshold
+	}
+	threshold, err := strconv.ParseFloat(value, 64)
+	if err != nil || threshold <= 0 || threshold > 1 {
+		log.Printf("Warning: ignoring invalid YNAB_CATEGORY_MATCH_THRESHOLD %q", value)
+		return DefaultYNABCategoryMatchThreshold
+	}
+	return threshold
+}
+
+// ResolveYNABCategoryID finds the YNAB category for a local category name.
+// An exact name match wins; otherwise the most similar category is used if
+// its score reaches the threshold and no other category scores the same.
+func ResolveYNABCategoryID(userID, name string) (string, error) {
+	var categoryID string
+	err := database.DB.QueryRow(
+		"SELECT id FROM ynab_categories WHERE user_id = ? AND name = ?",
+		userID, name,
+	).Scan(&categoryID)
+	if err == nil {
+		return categoryID, nil
+	} else if err != sql.ErrNoRows {
+		return "", err
+	}
+
+	rows, err := database.DB.Query("SELECT id, name FROM ynab_categories WHERE user_id = ?", userID)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	threshold := ynabCategoryMatchThreshold()
+	bestScore := -1.0
+	var best []string
+	var bestNames []string
+	for rows.Next() {
+		var id, candidate string
+		if err := rows.Scan(&id, &candidate); err != nil {
+			return "", err
+		}
+
+		score := categoryNameSimilarity(name, candidate)
+		if score < threshold {
+			continue
+		}
+		if math.Abs(score-bestScore) < 1e-9 {
+			best = append(best, id)
+			bestNames = append(bestNames, candidate)
+		} else if score > bestScore {
+			bestScore = score
+			best = []string{id}
+			bestNames = []string{candidate}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	switch len(best) {
+	case 0:
+		return "", fmt.Errorf("%w for '%s'", ErrNoYNABCategoryMatch, name)
+	case 1:
+		log.Printf("Matched category '%s' to YNAB category '%s' (score %.2f)", name, bestNames[0], bestScore)
+		return best[0], nil
+	default:
+		return "", fmt.Errorf("%w: '%s' matches %s equally well", ErrAmbiguousYNABCategory, name, strings.Join(bestNames, ", "))
+	}
+}
+
+// categoryNameSimilarity scores two category names from 0 (unrelated) to 1
+// (identical ignoring case and surrounding whitespace) using edit distance
+func categoryNameSimilarity(a, b string) float64 {
+	ra := []rune(strings.ToLower(strings.TrimSpace(a)))
+	rb := []rune(strings.ToLower(strings.TrimSpace(b)))
+
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the edit distance between two rune slices
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"bennwallet/backend/database"
+)
+
+func TestResolveYNABCategoryID(t *testing.T) {
+	setupYNABSyncTestDB(t)
+
+	for _, stmt := range []string{
+		`INSERT INTO ynab_categories (id, group_id, name, user_id) VALUES ('cat-gas', 'group-1', 'Gas Station Rewards', 'user-1')`,
+		`INSERT INTO ynab_categories (id, group_id, name, user_id) VALUES ('cat-gifts', 'group-1', 'Gifts', 'user-1')`,
+		`INSERT INTO ynab_categories (id, group_id, name, user_id) VALUES ('cat-gift', 'group-1', 'Gift!', 'user-1')`,
+	} {
+		if _, err := database.DB.Exec(stmt); err != nil {
+			t.Fatalf("Failed to insert category: %v", err)
+		}
+	}
+
+	t.Run("exact match", func(t *testing.T) {
+		id, err := ResolveYNABCategoryID("user-1", "Groceries")
+		if err != nil || id != "cat-1" {
+			t.Errorf("Expected cat-1, got %q (err %v)", id, err)
+		}
+	})
+
+	t.Run("clear fuzzy match", func(t *testing.T) {
+		id, err := ResolveYNABCategoryID("user-1", "grocerie")
+		if err != nil || id != "cat-1" {
+			t.Errorf("Expected cat-1, got %q (err %v)", id, err)
+		}
+	})
+
+	t.Run("ambiguous match", func(t *testing.T) {
+		_, err := ResolveYNABCategoryID("user-1", "Gift")
+		if !errors.Is(err, ErrAmbiguousYNABCategory) {
+			t.Errorf("Expected ErrAmbiguousYNABCategory, got %v", err)
+		}
+	})
+
+	t.Run("substring is not close enough", func(t *testing.T) {
+		_, err := ResolveYNABCategoryID("user-1", "Gas")
+		if !errors.Is(err, ErrNoYNABCategoryMatch) {
+			t.Errorf("Expected ErrNoYNABCategoryMatch, got %v", err)
+		}
+	})
+
+	t.Run("threshold is configurable", func(t *testing.T) {
+		t.Setenv("YNAB_CATEGORY_MATCH_THRESHOLD", "0.4")
+		id, err := ResolveYNABCategoryID("user-1", "Groc")
+		if err != nil || id != "cat-1" {
+			t.Errorf("Expected cat-1 with a lower threshold, got %q (err %v)", id, err)
+		}
+	})
+}
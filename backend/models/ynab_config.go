@@ -2,8 +2,11 @@ package models
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
 	"time"
 
 	"bennwallet/backend/security"
@@ -34,6 +37,99 @@ type YNABConfigUpdateRequest struct {
 	SyncFrequency int    `json:"syncFrequency,omitempty"`
 }
 
+// Sync frequencies in minutes. Users choose their own frequency, bounded by
+// YNAB_MIN_SYNC_FREQUENCY and YNAB_MAX_SYNC_FREQUENCY so nobody can hammer
+// the YNAB API.
+const (
+	DefaultYNABSyncFrequency    = 60
+	DefaultMinYNABSyncFrequency = 15
+	DefaultMaxYNABSyncFrequency = 24 * 60
+)
+
+// ynabSyncFrequencyEnv reads a positive frequency from the environment,
+// falling back to the given value when it is unset or invalid
+func ynabSyncFrequencyEnv(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	minutes, err := strconv.Atoi(value)
+	if err != nil || minutes < 1 {
+		log.Printf("Warning: ignoring invalid %s %q", name, value)
+		return fallback
+	}
+	return minutes
+}
+
+// YNABSyncFrequencyBounds returns the configured minimum and maximum sync
+// frequencies in minutes
+func YNABSyncFrequencyBounds() (int, int) {
+	minMinutes := ynabSyncFrequencyEnv("YNAB_MIN_SYNC_FREQUENCY", DefaultMinYNABSyncFrequency)
+	maxMinutes := ynabSyncFrequencyEnv("YNAB_MAX_SYNC_FREQUENCY", DefaultMaxYNABSyncFrequency)
+	if minMinutes > maxMinutes {
+		log.Printf("Warning: YNAB_MIN_SYNC_FREQUENCY %d exceeds YNAB_MAX_SYNC_FREQUENCY %d, using the defaults", minMinutes, maxMinutes)
+		return DefaultMinYNABSyncFrequency, DefaultMaxYNABSyncFrequency
+	}
+	return minMinutes, maxMinutes
+}
+
+// ClampYNABSyncFrequency brings a sync frequency within the configured
+// bounds. An unset frequency (zero or less) becomes the default.
+func ClampYNABSyncFrequency(minutes int) int {
+	if minutes <= 0 {
+		minutes = DefaultYNABSyncFrequency
+	}
+	minMinutes, maxMinutes := YNABSyncFrequencyBounds()
+	if minutes < minMinutes {
+		return minMinutes
+	}
+	if minutes > maxMinutes {
+		return maxMinutes
+	}
+	return minutes
+}
+
+// ErrNoYNABConfig is returned when a user has no stored YNAB configuration
+var ErrNoYNABConfig = errors.New("no YNAB configuration found")
+
+// CopyYNABBudgetSelection copies the budget and account selection from one
+// user's YNAB configuration to another's. The API token is never copied: an
+// existing token of the target is kept, otherwise they must supply their own.
+func CopyYNABBudgetSelection(db *sql.DB, fromUserID, toUserID string) error {
+	var encryptedBudgetID, encryptedAccountID sql.NullString
+	err := db.QueryRow(`
+		SELECT encrypted_budget_id, encrypted_account_id
+		FROM ynab_config
+		WHERE user_id = ?
+	`, fromUserID).Scan(&encryptedBudgetID, &encryptedAccountID)
+	if err == sql.ErrNoRows || (err == nil && encryptedBudgetID.String == "") {
+		return fmt.Errorf("%w for user %s", ErrNoYNABConfig, fromUserID)
+	} else if err != nil {
+		return fmt.Errorf("error reading YNAB config: %w", err)
+	}
+
+	// Insert or update in one statement, as UpsertYNABConfig does, so a
+	// concurrent save can't race the copy to insert the row. An existing
+	// config keeps its API token and sync frequency.
+	now := time.Now()
+	_, err = db.Exec(`
+		INSERT INTO ynab_config
+		(user_id, encrypted_api_token, encrypted_budget_id, encrypted_account_id,
+		 sync_frequency, created_at, updated_at)
+		VALUES (?, '', ?, ?, 60, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE
+		SET encrypted_budget_id = excluded.encrypted_budget_id,
+			encrypted_account_id = excluded.encrypted_account_id,
+			updated_at = excluded.updated_at
+	`, toUserID, encryptedBudgetID.String, encryptedAccountID.String, now, now)
+	if err != nil {
+		return fmt.Errorf("error copying YNAB config: %w", err)
+	}
+
+	log.Printf("Copied YNAB budget selection from user %s to user %s", fromUserID, toUserID)
+	return nil
+}
+
 // GetYNABConfig retrieves a user's YNAB configuration
 func GetYNABConfig(db *sql.DB, userID string) (*YNABConfig, error) {
 	log.Printf("Getting YNAB config for user %s", userID)
@@ -160,13 +256,6 @@ func GetYNABConfig(db *sql.DB, userID string) (*YNABConfig, error) {
 func UpsertYNABConfig(db *sql.DB, config *YNABConfigUpdateRequest, userID string) error {
 	log.Printf("Upserting YNAB config for user %s", userID)
 
-	// Check if we already have a config for this user
-	var count int
-	err := db.QueryRow("SELECT COUNT(*) FROM ynab_config WHERE user_id = ?", userID).Scan(&count)
-	if err != nil {
-		return fmt.Errorf("error checking for existing YNAB config: %w", err)
-	}
-
 	// Encrypt the credentials
 	encryptedToken, err := security.Encrypt(config.APIToken)
 	if err != nil {
@@ -183,42 +272,32 @@ func UpsertYNABConfig(db *sql.DB, config *YNABConfigUpdateRequest, userID string
 		return fmt.Errorf("error encrypting account ID: %w", err)
 	}
 
-	// Default sync frequency to 60 minutes if not specified
-	syncFrequency := config.SyncFrequency
-	if syncFrequency <= 0 {
-		syncFrequency = 60
+	// Default the sync frequency if not specified and keep it within bounds
+	syncFrequency := ClampYNABSyncFrequency(config.SyncFrequency)
+	if syncFrequency != config.SyncFrequency && config.SyncFrequency > 0 {
+		log.Printf("Clamped YNAB sync frequency for user %s from %d to %d minutes", userID, config.SyncFrequency, syncFrequency)
 	}
 
 	now := time.Now()
 
-	if count > 0 {
-		// Update existing config
-		_, err = db.Exec(`
-			UPDATE ynab_config
-			SET encrypted_api_token = ?,
-				encrypted_budget_id = ?,
-				encrypted_account_id = ?,
-				sync_frequency = ?,
-				updated_at = ?
-			WHERE user_id = ?
-		`, encryptedToken, encryptedBudgetID, encryptedAccountID, syncFrequency, now, userID)
+	// Insert or update in one statement so concurrent saves for the same
+	// user can't both see no row and race to insert
+	_, err = db.Exec(`
+		INSERT INTO ynab_config
+		(user_id, encrypted_api_token, encrypted_budget_id, encrypted_account_id, 
+		 sync_frequency, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE
+		SET encrypted_api_token = excluded.encrypted_api_token,
+			encrypted_budget_id = excluded.encrypted_budget_id,
+			encrypted_account_id = excluded.encrypted_account_id,
+			sync_frequency = excluded.sync_frequency,
+			updated_at = excluded.updated_at
+	`, userID, encryptedToken, encryptedBudgetID, encryptedAccountID,
+		syncFrequency, now, now)
 
-		if err != nil {
-			return fmt.Errorf("error updating YNAB config: %w", err)
-		}
-	} else {
-		// Insert new config
-		_, err = db.Exec(`
-			INSERT INTO ynab_config
-			(user_id, encrypted_api_token, encrypted_budget_id, encrypted_account_id, 
-			 sync_frequency, created_at, updated_at)
-			VALUES (?, ?, ?, ?, ?, ?, ?)
-		`, userID, encryptedToken, encryptedBudgetID, encryptedAccountID,
-			syncFrequency, now, now)
-
-		if err != nil {
-			return fmt.Errorf("error inserting YNAB config: %w", err)
-		}
+	if err != nil {
+		return fmt.Errorf("error saving YNAB config: %w", err)
 	}
 
 	// Also update the legacy table for backward compatibility
@@ -271,3 +350,49 @@ func UpdateLastSyncTime(db *sql.DB, userID string) error {
 
 	return nil
 }
+
+// ErrEncryptionKeyMismatch indicates stored credentials can't be decrypted with the current key
+var ErrEncryptionKeyMismatch = errors.New("stored YNAB credentials cannot be decrypted with the current ENCRYPTION_KEY")
+
+// CheckEncryptionKey attempts to decrypt one stored API token. It returns
+// ErrEncryptionKeyMismatch if that fails, which usually means ENCRYPTION_KEY
+// changed since the token was saved. It returns nil when nothing is stored.
+func CheckEncryptionKey(db *sql.DB) error {
+	var encryptedToken string
+	err := db.QueryRow(`
+		SELECT encrypted_api_token FROM ynab_config
+		WHERE encrypted_api_token IS NOT NULL AND encrypted_api_token != ''
+		LIMIT 1
+	`).Scan(&encryptedToken)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error reading stored YNAB config: %w", err)
+	}
+
+	if _, err := security.Decrypt(encryptedToken); err != nil {
+		return fmt.Errorf("%w: %v", ErrEncryptionKeyMismatch, err)
+	}
+	return nil
+}
+
+// CheckEncryptionAtRest runs CheckEncryptionKey at startup and logs a prominent
+// warning if stored credentials can't be decrypted. The error is returned only
+// when strict is set, so callers can refuse to start.
+func CheckEncryptionAtRest(db *sql.DB, strict bool) error {
+	err := CheckEncryptionKey(db)
+	if err == nil {
+		return nil
+	}
+
+	log.Println("WARNING: ================================================================")
+	log.Printf("WARNING: Encryption self-check failed: %v", err)
+	log.Println("WARNING: YNAB syncs will fail until ENCRYPTION_KEY matches the key used to")
+	log.Println("WARNING: save the credentials, or users re-enter their YNAB configuration.")
+	log.Println("WARNING: ================================================================")
+
+	if strict {
+		return err
+	}
+	return nil
+}
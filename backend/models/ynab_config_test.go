@@ -0,0 +1,293 @@
+package models
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"bennwallet/backend/security"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func setupEncryptionCheckDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`
+		CREATE TABLE ynab_config (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			encrypted_api_token TEXT,
+			encrypted_budget_id TEXT,
+			encrypted_account_id TEXT,
+			last_sync_time TIMESTAMP,
+			sync_frequency INTEGER DEFAULT 60,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(user_id)
+		)
+	`)
+	if err != nil {
+		t.Fatalf("Failed to create ynab_config table: %v", err)
+	}
+	return db
+}
+
+func storeEncryptedToken(t *testing.T, db *sql.DB, key string) {
+	security.InitializeEncryption(key)
+	encrypted, err := security.Encrypt("ynab-token")
+	if err != nil {
+		t.Fatalf("Failed to encrypt token: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO ynab_config (user_id, encrypted_api_token) VALUES ('user-1', ?)", encrypted); err != nil {
+		t.Fatalf("Failed to insert config: %v", err)
+	}
+}
+
+func TestCheckEncryptionKey(t *testing.T) {
+	db := setupEncryptionCheckDB(t)
+	defer db.Close()
+
+	// Nothing stored yet
+	security.InitializeEncryption("first-key")
+	if err := CheckEncryptionKey(db); err != nil {
+		t.Errorf("Expected no error with no stored config, got %v", err)
+	}
+
+	storeEncryptedToken(t, db, "first-key")
+	if err := CheckEncryptionKey(db); err != nil {
+		t.Errorf("Expected matching key to pass, got %v", err)
+	}
+
+	security.InitializeEncryption("second-key")
+	if err := CheckEncryptionKey(db); !errors.Is(err, ErrEncryptionKeyMismatch) {
+		t.Errorf("Expected ErrEncryptionKeyMismatch with the wrong key, got %v", err)
+	}
+}
+
+func TestCheckEncryptionAtRestWarnsOnWrongKey(t *testing.T) {
+	db := setupEncryptionCheckDB(t)
+	defer db.Close()
+
+	storeEncryptedToken(t, db, "original-key")
+	security.InitializeEncryption("rotated-key")
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	// Non-strict: warn but allow startup
+	if err := CheckEncryptionAtRest(db, false); err != nil {
+		t.Errorf("Expected non-strict check to return nil, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "WARNING: Encryption self-check failed") {
+		t.Errorf("Expected a prominent warning, got log output: %s", buf.String())
+	}
+
+	// Strict: refuse to start
+	if err := CheckEncryptionAtRest(db, true); !errors.Is(err, ErrEncryptionKeyMismatch) {
+		t.Errorf("Expected strict check to return ErrEncryptionKeyMismatch, got %v", err)
+	}
+
+	// Correct key: no warning
+	security.InitializeEncryption("original-key")
+	buf.Reset()
+	if err := CheckEncryptionAtRest(db, true); err != nil {
+		t.Errorf("Expected matching key to pass, got %v", err)
+	}
+	if strings.Contains(buf.String(), "WARNING: Encryption self-check failed") {
+		t.Error("Did not expect a warning with the matching key")
+	}
+}
+
+func TestClampYNABSyncFrequency(t *testing.T) {
+	t.Setenv("YNAB_MIN_SYNC_FREQUENCY", "30")
+	t.Setenv("YNAB_MAX_SYNC_FREQUENCY", "720")
+
+	tests := []struct {
+		minutes  int
+		expected int
+	}{
+		{0, DefaultYNABSyncFrequency}, // Unset uses the default
+		{1, 30},                       // Below the minimum
+		{90, 90},                      // Within bounds
+		{10000, 720},                  // Above the maximum
+	}
+	for _, tt := range tests {
+		if got := ClampYNABSyncFrequency(tt.minutes); got != tt.expected {
+			t.Errorf("ClampYNABSyncFrequency(%d) = %d, expected %d", tt.minutes, got, tt.expected)
+		}
+	}
+
+	// Inverted bounds fall back to the defaults
+	t.Setenv("YNAB_MIN_SYNC_FREQUENCY", "900")
+	if minMinutes, maxMinutes := YNABSyncFrequencyBounds(); minMinutes != DefaultMinYNABSyncFrequency || maxMinutes != DefaultMaxYNABSyncFrequency {
+		t.Errorf("Expected the default bounds, got %d-%d", minMinutes, maxMinutes)
+	}
+}
+
+func TestUpsertYNABConfigClampsSyncFrequency(t *testing.T) {
+	db := setupEncryptionCheckDB(t)
+	defer db.Close()
+	security.InitializeEncryption("test-key")
+
+	request := &YNABConfigUpdateRequest{APIToken: "token", BudgetID: "budget", AccountID: "account", SyncFrequency: 1}
+	if err := UpsertYNABConfig(db, request, "user-1"); err != nil {
+		t.Fatalf("UpsertYNABConfig failed: %v", err)
+	}
+	var stored int
+	if err := db.QueryRow("SELECT sync_frequency FROM ynab_config WHERE user_id = 'user-1'").Scan(&stored); err != nil {
+		t.Fatalf("Failed to read sync frequency: %v", err)
+	}
+	if stored != DefaultMinYNABSyncFrequency {
+		t.Errorf("Expected a below-minimum frequency to be stored as %d, got %d", DefaultMinYNABSyncFrequency, stored)
+	}
+
+	request.SyncFrequency = 100000
+	if err := UpsertYNABConfig(db, request, "user-1"); err != nil {
+		t.Fatalf("UpsertYNABConfig failed: %v", err)
+	}
+	if err := db.QueryRow("SELECT sync_frequency FROM ynab_config WHERE user_id = 'user-1'").Scan(&stored); err != nil {
+		t.Fatalf("Failed to read sync frequency: %v", err)
+	}
+	if stored != DefaultMaxYNABSyncFrequency {
+		t.Errorf("Expected an above-maximum frequency to be stored as %d, got %d", DefaultMaxYNABSyncFrequency, stored)
+	}
+}
+
+// openYNABConfigFileDB opens a file database, so concurrent saves run on
+// separate connections
+func openYNABConfigFileDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "ynab.db")+"?_busy_timeout=5000")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE ynab_config (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			encrypted_api_token TEXT,
+			encrypted_budget_id TEXT,
+			encrypted_account_id TEXT,
+			last_sync_time TIMESTAMP,
+			sync_frequency INTEGER DEFAULT 60,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(user_id)
+		)`,
+		`CREATE TABLE user_ynab_settings (
+			user_id TEXT PRIMARY KEY, token TEXT, budget_id TEXT, account_id TEXT, sync_enabled INTEGER, last_synced TIMESTAMP
+		)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Failed to create table: %v", err)
+		}
+	}
+	return db
+}
+
+func TestUpsertYNABConfigConcurrently(t *testing.T) {
+	db := openYNABConfigFileDB(t)
+	defer db.Close()
+	security.InitializeEncryption("test-key")
+
+	const saves = 20
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make(chan error, saves)
+	for i := 0; i < saves; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			request := &YNABConfigUpdateRequest{APIToken: "token", BudgetID: "budget", AccountID: "account"}
+			errs <- UpsertYNABConfig(db, request, "user-1")
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Concurrent UpsertYNABConfig failed: %v", err)
+		}
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM ynab_config WHERE user_id = 'user-1'").Scan(&count); err != nil {
+		t.Fatalf("Failed to count configs: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected exactly one config row, got %d", count)
+	}
+}
+
+func TestCopyYNABBudgetSelectionConcurrently(t *testing.T) {
+	db := openYNABConfigFileDB(t)
+	defer db.Close()
+	security.InitializeEncryption("test-key")
+
+	source := &YNABConfigUpdateRequest{APIToken: "token", BudgetID: "budget", AccountID: "account"}
+	if err := UpsertYNABConfig(db, source, "user-1"); err != nil {
+		t.Fatalf("UpsertYNABConfig failed: %v", err)
+	}
+
+	const copies = 20
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make(chan error, copies)
+	for i := 0; i < copies; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs <- CopyYNABBudgetSelection(db, "user-1", "user-2")
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Concurrent CopyYNABBudgetSelection failed: %v", err)
+		}
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM ynab_config WHERE user_id = 'user-2'").Scan(&count); err != nil {
+		t.Fatalf("Failed to count configs: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected exactly one config row, got %d", count)
+	}
+
+	// Copying over an existing config keeps its own token
+	target := &YNABConfigUpdateRequest{APIToken: "their-token", BudgetID: "other", AccountID: "other"}
+	if err := UpsertYNABConfig(db, target, "user-2"); err != nil {
+		t.Fatalf("UpsertYNABConfig failed: %v", err)
+	}
+	if err := CopyYNABBudgetSelection(db, "user-1", "user-2"); err != nil {
+		t.Fatalf("CopyYNABBudgetSelection failed: %v", err)
+	}
+	config, err := GetYNABConfig(db, "user-2")
+	if err != nil {
+		t.Fatalf("GetYNABConfig failed: %v", err)
+	}
+	token, err := security.Decrypt(config.EncryptedAPIToken)
+	if err != nil {
+		t.Fatalf("Failed to decrypt token: %v", err)
+	}
+	if token != "their-token" || config.BudgetID != "budget" || config.AccountID != "account" {
+		t.Errorf("Expected the copied budget with the existing token, got token %q and %+v", token, config)
+	}
+}
@@ -3,9 +3,11 @@ package models
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"strings"
@@ -15,6 +17,12 @@ import (
 	"bennwallet/backend/security"
 )
 
+// YNABAPIBaseURL is the root of the YNAB API. Tests point it at a mock server.
+var YNABAPIBaseURL = "https://api.ynab.com/v1"
+
+// ErrUnknownYNABAccount is returned when a requested account isn't an open account in the user's budget
+var ErrUnknownYNABAccount = errors.New("account is not an open account in the configured YNAB budget")
+
 // YNABSyncRequest represents a request to sync transaction data to YNAB
 type YNABSyncRequest struct {
 	UserID     string          `json:"userId"`
@@ -22,6 +30,31 @@ type YNABSyncRequest struct {
 	PayeeName  string          `json:"payeeName"`
 	Memo       string          `json:"memo"`
 	Categories []CategorySplit `json:"categories"`
+	AccountID  string          `json:"accountId,omitempty"` // Overrides the configured default account
+}
+
+// YNABSyncRetryResult counts the outcomes of retrying queued YNAB syncs.
+// Failed syncs stay queued; dropped ones failed in a way retrying can't fix.
+type YNABSyncRetryResult struct {
+	Attempted int `json:"attempted"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Dropped   int `json:"dropped"`
+}
+
+// YNABAccount is an account in a YNAB budget
+type YNABAccount struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Closed  bool   `json:"closed"`
+	Deleted bool   `json:"deleted"`
+}
+
+// YNABAccountsResponse is the YNAB API response for a budget's accounts
+type YNABAccountsResponse struct {
+	Data struct {
+		Accounts []YNABAccount `json:"accounts"`
+	} `json:"data"`
 }
 
 // CategorySplit represents a category split in a YNAB transaction
@@ -30,6 +63,28 @@ type CategorySplit struct {
 	Amount       float64 `json:"amount"`
 }
 
+// DollarsToMilliunits converts a dollar amount to the integer milliunits
+// (1/1000) YNAB expects, rounding to the nearest milliunit
+func DollarsToMilliunits(amount float64) int64 {
+	return int64(math.Round(amount * 1000))
+}
+
+// YNABSplitSummaryLine is one category of a splits preview: its total in
+// dollars and the milliunits that would be sent to YNAB for it
+type YNABSplitSummaryLine struct {
+	Category   string `json:"category"`
+	Total      Amount `json:"total"`
+	Milliunits int64  `json:"milliunits"`
+}
+
+// YNABSplitSummary previews what syncing a splits report to YNAB would send.
+// TotalMilliunits is the transaction amount, the sum of the category milliunits.
+type YNABSplitSummary struct {
+	Categories      []YNABSplitSummaryLine `json:"categories"`
+	Total           Amount                 `json:"total"`
+	TotalMilliunits int64                  `json:"totalMilliunits"`
+}
+
 // YNABTransaction represents a transaction to create in YNAB
 type YNABTransaction struct {
 	AccountID       string               `json:"account_id"`
@@ -126,6 +181,18 @@ func CreateYNABTransaction(request YNABSyncRequest) error {
 	log.Printf("Found YNAB settings for user %s: budget=%s, account=%s",
 		request.UserID, budgetID, accountID)
 
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	// An account given in the request overrides the configured default, but
+	// only if it is an open account in the same budget
+	if request.AccountID != "" && request.AccountID != accountID {
+		if err := validateYNABAccount(client, token, budgetID, request.AccountID); err != nil {
+			return err
+		}
+		log.Printf("Using requested account %s instead of default %s", request.AccountID, accountID)
+		accountID = request.AccountID
+	}
+
 	// Convert to YNAB transaction
 	transaction := YNABTransaction{
 		AccountID: accountID,
@@ -143,37 +210,16 @@ func CreateYNABTransaction(request YNABSyncRequest) error {
 	for _, split := range request.Categories {
 		log.Printf("Looking up category: '%s'", split.CategoryName)
 
-		// Get category ID
-		var categoryID string
-		err := database.DB.QueryRow(
-			"SELECT id FROM ynab_categories WHERE user_id = ? AND name = ?",
-			request.UserID, split.CategoryName,
-		).Scan(&categoryID)
-
+		categoryID, err := ResolveYNABCategoryID(request.UserID, split.CategoryName)
 		if err != nil {
 			log.Printf("Error finding category '%s' for user %s: %v",
 				split.CategoryName, request.UserID, err)
-
-			// Attempt a more permissive search by using LIKE instead of exact match
-			err = database.DB.QueryRow(
-				"SELECT id FROM ynab_categories WHERE user_id = ? AND name LIKE ?",
-				request.UserID, "%"+split.CategoryName+"%",
-			).Scan(&categoryID)
-
-			if err != nil {
-				log.Printf("Still couldn't find category even with fuzzy search: %v", err)
-				return fmt.Errorf("error finding category '%s': %w", split.CategoryName, err)
-			}
-
-			log.Printf("Found category '%s' with fuzzy match, ID: %s",
-				split.CategoryName, categoryID)
-		} else {
-			log.Printf("Found category '%s' with exact match, ID: %s",
-				split.CategoryName, categoryID)
+			return fmt.Errorf("error finding category '%s': %w", split.CategoryName, err)
 		}
+		log.Printf("Found category '%s', ID: %s", split.CategoryName, categoryID)
 
 		// Convert dollar amount to milliunits (YNAB uses integer)
-		amountMilliunits := int64(split.Amount * 1000)
+		amountMilliunits := DollarsToMilliunits(split.Amount)
 		totalAmount += amountMilliunits
 
 		// Add subtransaction
@@ -189,7 +235,7 @@ func CreateYNABTransaction(request YNABSyncRequest) error {
 	log.Printf("Total transaction amount: %d milliunits", totalAmount)
 
 	// Create request to YNAB API
-	url := fmt.Sprintf("https://api.ynab.com/v1/budgets/%s/transactions", budgetID)
+	url := fmt.Sprintf("%s/budgets/%s/transactions", YNABAPIBaseURL, budgetID)
 	payload := struct {
 		Transaction YNABTransaction `json:"transaction"`
 	}{
@@ -214,7 +260,6 @@ func CreateYNABTransaction(request YNABSyncRequest) error {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 
-	client := &http.Client{Timeout: 10 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
 		log.Printf("Error sending transaction to YNAB: %v", err)
@@ -239,3 +284,37 @@ func CreateYNABTransaction(request YNABSyncRequest) error {
 		request.UserID, string(body))
 	return nil
 }
+
+// validateYNABAccount checks that accountID is an open account in the budget
+func validateYNABAccount(client *http.Client, token, budgetID, accountID string) error {
+	url := fmt.Sprintf("%s/budgets/%s/accounts", YNABAPIBaseURL, budgetID)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error fetching YNAB accounts: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		log.Printf("YNAB API error fetching accounts: %s (%d)", string(body), resp.StatusCode)
+		return fmt.Errorf("YNAB API error fetching accounts (%d)", resp.StatusCode)
+	}
+
+	var accounts YNABAccountsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&accounts); err != nil {
+		return fmt.Errorf("error decoding YNAB accounts: %w", err)
+	}
+
+	for _, account := range accounts.Data.Accounts {
+		if account.ID == accountID && !account.Closed && !account.Deleted {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownYNABAccount, accountID)
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bennwallet/backend/database"
+)
+
+func setupYNABSyncTestDB(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	database.DB = db
+
+	statements := []string{
+		`CREATE TABLE ynab_config (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			encrypted_api_token TEXT,
+			encrypted_budget_id TEXT,
+			encrypted_account_id TEXT,
+			last_sync_time TIMESTAMP,
+			sync_frequency INTEGER DEFAULT 60,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE user_ynab_settings (
+			user_id TEXT PRIMARY KEY,
+			token TEXT,
+			budget_id TEXT,
+			account_id TEXT,
+			sync_enabled INTEGER,
+			last_synced TIMESTAMP
+		)`,
+		`CREATE TABLE ynab_categories (
+			id TEXT NOT NULL,
+			group_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			last_updated DATETIME
+		)`,
+		`INSERT INTO user_ynab_settings (user_id, token, budget_id, account_id, sync_enabled)
+			VALUES ('user-1', 'enc:test-token', 'budget-1', 'default-account', 1)`,
+		`INSERT INTO ynab_categories (id, group_id, name, user_id) VALUES ('cat-1', 'group-1', 'Groceries', 'user-1')`,
+	}
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Failed to set up test database: %v", err)
+		}
+	}
+	t.Cleanup(func() { db.Close() })
+}
+
+// mockYNABServer serves the budget's accounts and records the account of each created transaction
+func mockYNABServer(t *testing.T, usedAccounts *[]string) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/budgets/budget-1/accounts":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"accounts": []map[string]interface{}{
+						{"id": "default-account", "name": "Checking", "closed": false, "deleted": false},
+						{"id": "savings-account", "name": "Savings", "closed": false, "deleted": false},
+						{"id": "closed-account", "name": "Old Card", "closed": true, "deleted": false},
+					},
+				},
+			})
+		case r.Method == "POST" && r.URL.Path == "/budgets/budget-1/transactions":
+			var payload struct {
+				Transaction YNABTransaction `json:"transaction"`
+			}
+			json.NewDecoder(r.Body).Decode(&payload)
+			*usedAccounts = append(*usedAccounts, payload.Transaction.AccountID)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"data": {}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	original := YNABAPIBaseURL
+	YNABAPIBaseURL = server.URL
+	t.Cleanup(func() { YNABAPIBaseURL = original })
+}
+
+func TestCreateYNABTransactionAccountOverride(t *testing.T) {
+	testCases := []struct {
+		name            string
+		accountID       string
+		expectedAccount string
+		expectedErr     error
+	}{
+		{"default account", "", "default-account", nil},
+		{"override account", "savings-account", "savings-account", nil},
+		{"unknown account", "someone-elses-account", "", ErrUnknownYNABAccount},
+		{"closed account", "closed-account", "", ErrUnknownYNABAccount},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setupYNABSyncTestDB(t)
+			var usedAccounts []string
+			mockYNABServer(t, &usedAccounts)
+
+			err := CreateYNABTransaction(YNABSyncRequest{
+				UserID:     "user-1",
+				Date:       "2024-05-01",
+				PayeeName:  "Store",
+				Categories: []CategorySplit{{CategoryName: "Groceries", Amount: -12.5}},
+				AccountID:  tc.accountID,
+			})
+
+			if tc.expectedErr != nil {
+				if !errors.Is(err, tc.expectedErr) {
+					t.Fatalf("Expected error %v, got %v", tc.expectedErr, err)
+				}
+				if len(usedAccounts) != 0 {
+					t.Errorf("Expected no transaction to be created, got %v", usedAccounts)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(usedAccounts) != 1 || usedAccounts[0] != tc.expectedAccount {
+				t.Errorf("Expected transaction in account %s, got %v", tc.expectedAccount, usedAccounts)
+			}
+		})
+	}
+}
+
+func TestDollarsToMilliunits(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		expected int64
+	}{
+		{0, 0},
+		{1, 1000},
+		{4.35, 4350}, // 4349.999... before rounding
+		{19.99, 19990},
+		{-12.34, -12340},
+	}
+	for _, tt := range tests {
+		if got := DollarsToMilliunits(tt.amount); got != tt.expected {
+			t.Errorf("DollarsToMilliunits(%v) = %d, expected %d", tt.amount, got, tt.expected)
+		}
+	}
+}
@@ -8,27 +8,49 @@ import (
 	"errors"
 	"io"
 	"log"
+	"sync"
 )
 
-var encryptionKey []byte
+// ErrEncryptionNotInitialized is returned when encrypting or decrypting before InitializeEncryption
+var ErrEncryptionNotInitialized = errors.New("encryption key not initialized")
 
-// InitializeEncryption sets up the encryption key from environment variable
+var (
+	encryptionKey []byte
+	keyMutex      sync.RWMutex
+)
+
+// InitializeEncryption sets up the encryption key from environment variable.
+// It is safe to call concurrently and more than once; the last call wins.
 func InitializeEncryption(key string) {
 	// Pad the key to 32 bytes if needed
 	if len(key) < 32 {
 		padding := make([]byte, 32-len(key))
 		key = key + string(padding)
 	}
+
+	keyMutex.Lock()
+	defer keyMutex.Unlock()
 	encryptionKey = []byte(key[:32])
 }
 
+// getEncryptionKey returns the current key, or ErrEncryptionNotInitialized
+func getEncryptionKey() ([]byte, error) {
+	keyMutex.RLock()
+	defer keyMutex.RUnlock()
+	if len(encryptionKey) == 0 {
+		return nil, ErrEncryptionNotInitialized
+	}
+	return encryptionKey, nil
+}
+
 // Encrypt encrypts a string using AES-GCM
 func Encrypt(plaintext string) (string, error) {
-	if len(encryptionKey) == 0 {
-		return "", errors.New("encryption key not initialized")
+	key, err := getEncryptionKey()
+	if err != nil {
+		return "", err
 	}
 
-	block, err := aes.NewCipher(encryptionKey)
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return "", err
 	}
@@ -49,8 +71,9 @@ func Encrypt(plaintext string) (string, error) {
 
 // Decrypt decrypts a string using AES-GCM
 func Decrypt(encrypted string) (string, error) {
-	if len(encryptionKey) == 0 {
-		return "", errors.New("encryption key not initialized")
+	key, err := getEncryptionKey()
+	if err != nil {
+		return "", err
 	}
 
 	log.Printf("Attempting to decrypt value (length: %d)", len(encrypted))
@@ -61,7 +84,7 @@ func Decrypt(encrypted string) (string, error) {
 		return "", err
 	}
 
-	block, err := aes.NewCipher(encryptionKey)
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		log.Printf("Failed to create cipher: %v", err)
 		return "", err
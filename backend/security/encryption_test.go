@@ -1,6 +1,8 @@
 package security
 
 import (
+	"errors"
+	"sync"
 	"testing"
 )
 
@@ -140,3 +142,48 @@ func TestDecryptInvalidData(t *testing.T) {
 		t.Error("Expected error when decrypting invalid ciphertext, got nil")
 	}
 }
+
+func TestReinitializeEncryptionConcurrently(t *testing.T) {
+	testKey := "test-encryption-key-12345678901234"
+	defer InitializeEncryption(testKey)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			InitializeEncryption(testKey)
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := Encrypt("value"); err != nil {
+				t.Errorf("Unexpected error while re-initializing: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Values encrypted before a re-init with the same key still decrypt
+	encrypted, err := Encrypt("stable")
+	if err != nil {
+		t.Fatalf("Error encrypting: %v", err)
+	}
+	InitializeEncryption(testKey)
+	decrypted, err := Decrypt(encrypted)
+	if err != nil || decrypted != "stable" {
+		t.Errorf("Expected 'stable' after re-init, got '%s' (err %v)", decrypted, err)
+	}
+}
+
+func TestEncryptDecryptBeforeInitialization(t *testing.T) {
+	originalKey := encryptionKey
+	encryptionKey = nil
+	defer func() { encryptionKey = originalKey }()
+
+	if _, err := Encrypt("test"); !errors.Is(err, ErrEncryptionNotInitialized) {
+		t.Errorf("Expected ErrEncryptionNotInitialized from Encrypt, got %v", err)
+	}
+	if _, err := Decrypt("dGVzdA=="); !errors.Is(err, ErrEncryptionNotInitialized) {
+		t.Errorf("Expected ErrEncryptionNotInitialized from Decrypt, got %v", err)
+	}
+}
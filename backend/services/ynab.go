@@ -14,46 +14,51 @@ import (
 	"bennwallet/backend/security"
 )
 
-// SyncYNABCategoriesNew syncs YNAB categories for a user (using the new encrypted config)
-func SyncYNABCategoriesNew(userID, budgetID string) error {
-	log.Printf("Syncing YNAB categories for user %s with budget %s", userID, budgetID)
-
-	// Get config to retrieve API token
+// ynabAPIToken returns the user's YNAB API token, from the encrypted config or
+// failing that the legacy settings table
+func ynabAPIToken(userID string) (string, error) {
 	config, err := models.GetYNABConfig(database.DB, userID)
 	if err != nil {
-		return fmt.Errorf("error getting YNAB config: %w", err)
+		return "", fmt.Errorf("error getting YNAB config: %w", err)
 	}
 
-	var token string
 	if config.HasCredentials && config.EncryptedAPIToken != "" {
 		// Get from encrypted field
-		token, err = security.Decrypt(config.EncryptedAPIToken)
+		token, err := security.Decrypt(config.EncryptedAPIToken)
 		if err != nil {
-			return fmt.Errorf("error decrypting API token: %w", err)
+			return "", fmt.Errorf("error decrypting API token: %w", err)
 		}
-	} else {
-		// Try legacy format
-		var dbToken string
-		err := database.DB.QueryRow(
-			"SELECT token FROM user_ynab_settings WHERE user_id = ?",
-			userID,
-		).Scan(&dbToken)
+		return token, nil
+	}
 
-		if err != nil {
-			return fmt.Errorf("error getting YNAB token from legacy table: %w", err)
-		}
+	// Try legacy format
+	var dbToken string
+	err = database.DB.QueryRow(
+		"SELECT token FROM user_ynab_settings WHERE user_id = ?",
+		userID,
+	).Scan(&dbToken)
 
-		if strings.HasPrefix(dbToken, "enc:") {
-			// For local dev, token is prefixed in DB
-			token = strings.TrimPrefix(dbToken, "enc:")
-		} else {
-			return fmt.Errorf("unsupported token format in legacy table")
-		}
+	if err != nil {
+		return "", fmt.Errorf("error getting YNAB token from legacy table: %w", err)
+	}
+
+	if strings.HasPrefix(dbToken, "enc:") {
+		// For local dev, token is prefixed in DB
+		return strings.TrimPrefix(dbToken, "enc:"), nil
+	}
+	return "", fmt.Errorf("unsupported token format in legacy table")
+}
+
+// getYNAB makes an authenticated GET request for a path under the YNAB API
+// on the user's behalf and decodes the JSON response into out
+func getYNAB(userID, path string, out interface{}) error {
+	token, err := ynabAPIToken(userID)
+	if err != nil {
+		return err
 	}
 
 	// Make API request to YNAB
-	url := fmt.Sprintf("https://api.ynab.com/v1/budgets/%s/categories", budgetID)
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequest("GET", models.YNABAPIBaseURL+path, nil)
 	if err != nil {
 		return fmt.Errorf("error creating request: %w", err)
 	}
@@ -75,10 +80,36 @@ func SyncYNABCategoriesNew(userID, budgetID string) error {
 	}
 
 	// Parse response
-	var response models.YNABCategoryResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
 		return fmt.Errorf("error decoding response: %w", err)
 	}
+	return nil
+}
+
+// fetchYNABCategories gets the category groups of a user's budget from the
+// YNAB API, authenticating with the user's stored token
+func fetchYNABCategories(userID, budgetID string) (*models.YNABCategoryResponse, error) {
+	var response models.YNABCategoryResponse
+	if err := getYNAB(userID, fmt.Sprintf("/budgets/%s/categories", budgetID), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// skipYNABCategoryGroup reports whether the sync leaves out a category group:
+// internal, hidden, or deleted groups are not stored
+func skipYNABCategoryGroup(id string, hidden, deleted bool) bool {
+	return hidden || deleted || strings.HasPrefix(id, "internal:")
+}
+
+// SyncYNABCategoriesNew syncs YNAB categories for a user (using the new encrypted config)
+func SyncYNABCategoriesNew(userID, budgetID string) error {
+	log.Printf("Syncing YNAB categories for user %s with budget %s", userID, budgetID)
+
+	response, err := fetchYNABCategories(userID, budgetID)
+	if err != nil {
+		return err
+	}
 
 	// Begin transaction
 	tx, err := database.DB.Begin()
@@ -102,7 +133,7 @@ func SyncYNABCategoriesNew(userID, budgetID string) error {
 	now := time.Now()
 	for _, group := range response.Data.CategoryGroups {
 		// Skip internal, hidden, or deleted groups
-		if group.Hidden || group.Deleted || strings.HasPrefix(group.ID, "internal:") {
+		if skipYNABCategoryGroup(group.ID, group.Hidden, group.Deleted) {
 			continue
 		}
 
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+
+	"bennwallet/backend/models"
+)
+
+// milliunitsToDollars converts a YNAB milliunit amount to dollars
+func milliunitsToDollars(milliunits int64) float64 {
+	return float64(milliunits) / 1000
+}
+
+// GetYNABAccountBalances fetches the accounts of the user's budget from YNAB
+// with their balances in dollars. Deleted accounts are left out.
+func GetYNABAccountBalances(userID, budgetID string) ([]models.YNABAccountBalance, error) {
+	var response models.YNABAccountResponse
+	if err := getYNAB(userID, fmt.Sprintf("/budgets/%s/accounts", budgetID), &response); err != nil {
+		return nil, err
+	}
+
+	balances := []models.YNABAccountBalance{}
+	for _, account := range response.Data.Accounts {
+		if account.Deleted {
+			continue
+		}
+		balances = append(balances, models.YNABAccountBalance{
+			ID:               account.ID,
+			Name:             account.Name,
+			Type:             account.Type,
+			Closed:           account.Closed,
+			Balance:          milliunitsToDollars(account.Balance),
+			ClearedBalance:   milliunitsToDollars(account.ClearedBalance),
+			UnclearedBalance: milliunitsToDollars(account.UnclearedBalance),
+		})
+	}
+	return balances, nil
+}
+
+// GetYNABAccountTransactions fetches the transactions of a YNAB account on or
+// after sinceDate (YYYY-MM-DD, or "" for all) with amounts in dollars.
+// Deleted transactions are left out.
+func GetYNABAccountTransactions(userID, budgetID, accountID, sinceDate string) ([]models.YNABAccountTransaction, error) {
+	path := fmt.Sprintf("/budgets/%s/accounts/%s/transactions", budgetID, accountID)
+	if sinceDate != "" {
+		path += "?since_date=" + url.QueryEscape(sinceDate)
+	}
+
+	var response models.YNABTransactionResponse
+	if err := getYNAB(userID, path, &response); err != nil {
+		return nil, err
+	}
+
+	transactions := []models.YNABAccountTransaction{}
+	for _, t := range response.Data.Transactions {
+		if t.Deleted {
+			continue
+		}
+		transactions = append(transactions, models.YNABAccountTransaction{
+			ID:        t.ID,
+			Date:      t.Date,
+			Amount:    milliunitsToDollars(t.Amount),
+			PayeeName: t.PayeeName,
+			Memo:      t.Memo,
+		})
+	}
+	return transactions, nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+func TestGetYNABAccountBalances(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	database.DB = db
+	defer db.Close()
+
+	statements := []string{
+		`CREATE TABLE ynab_config (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			encrypted_api_token TEXT,
+			encrypted_budget_id TEXT,
+			encrypted_account_id TEXT,
+			last_sync_time TIMESTAMP,
+			sync_frequency INTEGER DEFAULT 60,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE user_ynab_settings (
+			user_id TEXT PRIMARY KEY,
+			token TEXT,
+			budget_id TEXT,
+			account_id TEXT,
+			sync_enabled INTEGER,
+			last_synced TIMESTAMP
+		)`,
+		`INSERT INTO user_ynab_settings (user_id, token, budget_id, account_id) VALUES ('user-1', 'enc:test-token', 'budget-1', 'account-1')`,
+	}
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Failed to set up test database: %v", err)
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/budgets/budget-1/accounts" || r.Header.Get("Authorization") != "Bearer test-token" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"data": {"accounts": [
+			{"id": "checking", "name": "Checking", "type": "checking", "balance": 1234560, "cleared_balance": 1250000, "uncleared_balance": -15440},
+			{"id": "card", "name": "Visa", "type": "creditCard", "closed": true, "balance": -99990, "cleared_balance": -99990, "uncleared_balance": 0},
+			{"id": "old", "name": "Old savings", "type": "savings", "deleted": true, "balance": 5000}
+		]}}`))
+	}))
+	defer server.Close()
+	original := models.YNABAPIBaseURL
+	models.YNABAPIBaseURL = server.URL
+	defer func() { models.YNABAPIBaseURL = original }()
+
+	balances, err := GetYNABAccountBalances("user-1", "budget-1")
+	if err != nil {
+		t.Fatalf("Error getting balances: %v", err)
+	}
+
+	expected := []models.YNABAccountBalance{
+		{ID: "checking", Name: "Checking", Type: "checking", Balance: 1234.56, ClearedBalance: 1250, UnclearedBalance: -15.44},
+		{ID: "card", Name: "Visa", Type: "creditCard", Closed: true, Balance: -99.99, ClearedBalance: -99.99},
+	}
+	if len(balances) != len(expected) {
+		t.Fatalf("Expected %d accounts, got %+v", len(expected), balances)
+	}
+	for i := range expected {
+		if balances[i] != expected[i] {
+			t.Errorf("Expected %+v, got %+v", expected[i], balances[i])
+		}
+	}
+
+	if _, err := GetYNABAccountBalances("user-1", "other-budget"); err == nil {
+		t.Error("Expected an error when YNAB rejects the request")
+	}
+}
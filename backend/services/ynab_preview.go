@@ -0,0 +1,84 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+// PreviewYNABCategorySync fetches the user's categories from YNAB and compares
+// them with the stored ynab_categories, reporting the categories a sync would
+// add, rename and remove. Nothing is written.
+func PreviewYNABCategorySync(userID, budgetID string) (*models.YNABCategorySyncPreview, error) {
+	response, err := fetchYNABCategories(userID, budgetID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT y.id, y.group_id, COALESCE(g.name, ''), y.name
+		FROM ynab_categories y
+		LEFT JOIN ynab_category_groups g ON g.id = y.group_id AND g.user_id = y.user_id
+		WHERE y.user_id = ?
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying stored YNAB categories: %w", err)
+	}
+	stored := map[string]models.YNABCategory{}
+	for rows.Next() {
+		var c models.YNABCategory
+		if err := rows.Scan(&c.ID, &c.CategoryGroupID, &c.CategoryGroupName, &c.Name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error scanning stored YNAB category: %w", err)
+		}
+		stored[c.ID] = c
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying stored YNAB categories: %w", err)
+	}
+
+	preview := &models.YNABCategorySyncPreview{
+		Added:   []models.YNABCategory{},
+		Renamed: []models.YNABCategoryRename{},
+		Removed: []models.YNABCategory{},
+	}
+
+	// Only the categories the sync would store count as present in YNAB
+	fetched := map[string]bool{}
+	for _, group := range response.Data.CategoryGroups {
+		if skipYNABCategoryGroup(group.ID, group.Hidden, group.Deleted) {
+			continue
+		}
+		for _, category := range group.Categories {
+			if category.Hidden || category.Deleted {
+				continue
+			}
+			category.CategoryGroupID = group.ID
+			category.CategoryGroupName = group.Name
+			fetched[category.ID] = true
+
+			existing, ok := stored[category.ID]
+			if !ok {
+				preview.Added = append(preview.Added, category)
+			} else if existing.Name != category.Name {
+				preview.Renamed = append(preview.Renamed, models.YNABCategoryRename{
+					ID: category.ID, OldName: existing.Name, NewName: category.Name,
+				})
+			}
+		}
+	}
+
+	for id, category := range stored {
+		if !fetched[id] {
+			preview.Removed = append(preview.Removed, category)
+		}
+	}
+	sort.Slice(preview.Removed, func(i, j int) bool {
+		return preview.Removed[i].Name < preview.Removed[j].Name
+	})
+
+	return preview, nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+func TestPreviewYNABCategorySync(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	database.DB = db
+	defer db.Close()
+
+	statements := []string{
+		`CREATE TABLE ynab_config (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			encrypted_api_token TEXT,
+			encrypted_budget_id TEXT,
+			encrypted_account_id TEXT,
+			last_sync_time TIMESTAMP,
+			sync_frequency INTEGER DEFAULT 60,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE user_ynab_settings (
+			user_id TEXT PRIMARY KEY,
+			token TEXT,
+			budget_id TEXT,
+			account_id TEXT,
+			sync_enabled INTEGER,
+			last_synced TIMESTAMP
+		)`,
+		`CREATE TABLE ynab_category_groups (id TEXT PRIMARY KEY, name TEXT NOT NULL, user_id TEXT NOT NULL, last_updated DATETIME)`,
+		`CREATE TABLE ynab_categories (id TEXT PRIMARY KEY, group_id TEXT NOT NULL, name TEXT NOT NULL, user_id TEXT NOT NULL, last_updated DATETIME)`,
+		`INSERT INTO user_ynab_settings (user_id, token, budget_id, account_id) VALUES ('user-1', 'enc:test-token', 'budget-1', 'account-1')`,
+		`INSERT INTO ynab_category_groups (id, name, user_id) VALUES ('group-1', 'Bills', 'user-1')`,
+		`INSERT INTO ynab_categories (id, group_id, name, user_id) VALUES
+			('rent', 'group-1', 'Rent', 'user-1'),
+			('power', 'group-1', 'Electric', 'user-1'),
+			('gym', 'group-1', 'Gym', 'user-1'),
+			('phone', 'group-1', 'Phone', 'user-1'),
+			('other-user', 'group-1', 'Not mine', 'user-2')`,
+	}
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Failed to set up test database: %v", err)
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/budgets/budget-1/categories" || r.Header.Get("Authorization") != "Bearer test-token" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"data": {"category_groups": [
+			{"id": "group-1", "name": "Bills", "categories": [
+				{"id": "rent", "name": "Rent"},
+				{"id": "power", "name": "Electricity"},
+				{"id": "gym", "name": "Gym", "hidden": true},
+				{"id": "water", "name": "Water"}
+			]},
+			{"id": "internal:master", "name": "Internal", "categories": [
+				{"id": "inflow", "name": "Inflow: Ready to Assign"}
+			]}
+		]}}`))
+	}))
+	defer server.Close()
+	original := models.YNABAPIBaseURL
+	models.YNABAPIBaseURL = server.URL
+	defer func() { models.YNABAPIBaseURL = original }()
+
+	preview, err := PreviewYNABCategorySync("user-1", "budget-1")
+	if err != nil {
+		t.Fatalf("Error previewing sync: %v", err)
+	}
+
+	if len(preview.Added) != 1 || preview.Added[0].ID != "water" || preview.Added[0].CategoryGroupName != "Bills" {
+		t.Errorf("Expected Water to be added, got %+v", preview.Added)
+	}
+	expectedRename := models.YNABCategoryRename{ID: "power", OldName: "Electric", NewName: "Electricity"}
+	if len(preview.Renamed) != 1 || preview.Renamed[0] != expectedRename {
+		t.Errorf("Expected %+v, got %+v", expectedRename, preview.Renamed)
+	}
+	// Hidden categories are dropped by a sync, so they count as removed
+	if len(preview.Removed) != 2 || preview.Removed[0].ID != "gym" || preview.Removed[1].ID != "phone" {
+		t.Errorf("Expected Gym and Phone to be removed, got %+v", preview.Removed)
+	}
+
+	// Nothing is written
+	var count int
+	db.QueryRow("SELECT COUNT(*) FROM ynab_categories WHERE user_id = 'user-1'").Scan(&count)
+	if count != 4 {
+		t.Errorf("Expected the stored categories to be untouched, found %d", count)
+	}
+}
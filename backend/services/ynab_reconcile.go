@@ -0,0 +1,143 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"bennwallet/backend/database"
+	"bennwallet/backend/models"
+)
+
+// ReconcileYNABCategories brings local categories synced from YNAB in line with
+// the user's current ynab_categories. Categories renamed in YNAB are renamed
+// locally, categories removed from YNAB are archived, and archived categories
+// that reappear are restored. Unlike the category sync this never adds
+// categories. When dryRun is true the changes are reported but not applied.
+func ReconcileYNABCategories(userID string, dryRun bool) (*models.CategoryReconcileResult, error) {
+	result := &models.CategoryReconcileResult{
+		DryRun:    dryRun,
+		Renamed:   []models.CategoryRename{},
+		Archived:  []models.Category{},
+		Restored:  []models.Category{},
+		Conflicts: []string{},
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Link categories synced before YNAB IDs were tracked, matching on name
+	linkResult, err := tx.Exec(`
+		UPDATE categories
+		SET ynab_category_id = (
+			SELECT y.id FROM ynab_categories y
+			WHERE y.user_id = categories.user_id AND y.name = categories.name
+			LIMIT 1
+		)
+		WHERE user_id = ? AND ynab_category_id IS NULL AND description = 'Synced from YNAB'
+		AND EXISTS (
+			SELECT 1 FROM ynab_categories y
+			WHERE y.user_id = categories.user_id AND y.name = categories.name
+		)
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error linking categories to YNAB: %w", err)
+	}
+	linked, _ := linkResult.RowsAffected()
+	result.Linked = int(linked)
+
+	rows, err := tx.Query(`
+		SELECT c.id, c.name, COALESCE(c.description, ''), COALESCE(c.color, ''), c.archived, y.name
+		FROM categories c
+		LEFT JOIN ynab_categories y ON y.id = c.ynab_category_id AND y.user_id = c.user_id
+		WHERE c.user_id = ? AND c.ynab_category_id IS NOT NULL AND c.deleted_at IS NULL
+		ORDER BY c.id
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying linked categories: %w", err)
+	}
+
+	type linkedCategory struct {
+		category models.Category
+		archived bool
+		ynabName sql.NullString
+	}
+	var categories []linkedCategory
+	for rows.Next() {
+		var lc linkedCategory
+		if err := rows.Scan(&lc.category.ID, &lc.category.Name, &lc.category.Description,
+			&lc.category.Color, &lc.archived, &lc.ynabName); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error scanning linked category: %w", err)
+		}
+		lc.category.UserID = userID
+		categories = append(categories, lc)
+	}
+	rows.Close()
+
+	now := time.Now()
+	for _, lc := range categories {
+		c := lc.category
+
+		// Removed from YNAB: archive rather than delete so transactions keep their category
+		if !lc.ynabName.Valid {
+			if !lc.archived {
+				result.Archived = append(result.Archived, c)
+				if !dryRun {
+					if _, err := tx.Exec("UPDATE categories SET archived = 1, last_updated = ? WHERE id = ?", now, c.ID); err != nil {
+						return nil, fmt.Errorf("error archiving category %d: %w", c.ID, err)
+					}
+				}
+			}
+			continue
+		}
+
+		if lc.archived {
+			result.Restored = append(result.Restored, c)
+			if !dryRun {
+				if _, err := tx.Exec("UPDATE categories SET archived = 0, last_updated = ? WHERE id = ?", now, c.ID); err != nil {
+					return nil, fmt.Errorf("error restoring category %d: %w", c.ID, err)
+				}
+			}
+		}
+
+		if lc.ynabName.String == c.Name {
+			continue
+		}
+
+		// Names are unique per user, so a rename onto an existing category can't be applied
+		var existing int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM categories WHERE user_id = ? AND name = ? AND id != ?",
+			userID, lc.ynabName.String, c.ID).Scan(&existing); err != nil {
+			return nil, fmt.Errorf("error checking for category name conflict: %w", err)
+		}
+		if existing > 0 {
+			result.Conflicts = append(result.Conflicts, fmt.Sprintf(
+				"cannot rename %q to %q: a category with that name already exists", c.Name, lc.ynabName.String))
+			continue
+		}
+
+		result.Renamed = append(result.Renamed, models.CategoryRename{ID: c.ID, OldName: c.Name, NewName: lc.ynabName.String})
+		if !dryRun {
+			if _, err := tx.Exec("UPDATE categories SET name = ?, last_updated = ? WHERE id = ?", lc.ynabName.String, now, c.ID); err != nil {
+				return nil, fmt.Errorf("error renaming category %d: %w", c.ID, err)
+			}
+		}
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	log.Printf("Reconciled YNAB categories for user %s: %d linked, %d renamed, %d archived, %d restored, %d conflicts",
+		userID, result.Linked, len(result.Renamed), len(result.Archived), len(result.Restored), len(result.Conflicts))
+	return result, nil
+}
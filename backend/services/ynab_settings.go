@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"bennwallet/backend/database"
+	"bennwallet/backend/logger"
 	"bennwallet/backend/models"
 	"bennwallet/backend/security"
 )
@@ -71,12 +72,12 @@ func setupYNABFromEnvironment() {
 
 // setupYNABFromEnvForUser sets up YNAB settings for a specific user from environment variables
 func setupYNABFromEnvForUser(userID string) {
-	log.Printf("DEBUG: Checking for YNAB credentials for user %s", userID)
+	logger.Debugf("Checking for YNAB credentials for user %s", userID)
 
 	// First check if user already has credentials in the database
 	config, err := models.GetYNABConfig(database.DB, userID)
 	if err == nil && config.HasCredentials {
-		log.Printf("DEBUG: User %s already has YNAB credentials in ynab_config table, skipping setup from env", userID)
+		logger.Debugf("User %s already has YNAB credentials in ynab_config table, skipping setup from env", userID)
 		return
 	}
 
@@ -92,7 +93,7 @@ func setupYNABFromEnvForUser(userID string) {
 		userID).Scan(&count)
 
 	if err == nil && count > 0 {
-		log.Printf("DEBUG: User %s already has YNAB credentials in legacy table, skipping setup from env", userID)
+		logger.Debugf("User %s already has YNAB credentials in legacy table, skipping setup from env", userID)
 		return
 	}
 
@@ -100,10 +101,10 @@ func setupYNABFromEnvForUser(userID string) {
 	tokenEnvVar := fmt.Sprintf("YNAB_TOKEN_USER_%s", userID)
 	token := os.Getenv(tokenEnvVar)
 	if token == "" {
-		log.Printf("DEBUG: No YNAB token found for user %s (env var: %s)", userID, tokenEnvVar)
+		logger.Debugf("No YNAB token found for user %s (env var: %s)", userID, tokenEnvVar)
 		return
 	}
-	log.Printf("DEBUG: Found YNAB token for user %s", userID)
+	logger.Debugf("Found YNAB token for user %s", userID)
 
 	budgetIDEnvVar := fmt.Sprintf("YNAB_BUDGET_ID_USER_%s", userID)
 	accountIDEnvVar := fmt.Sprintf("YNAB_ACCOUNT_ID_USER_%s", userID)
@@ -112,17 +113,17 @@ func setupYNABFromEnvForUser(userID string) {
 	accountID := os.Getenv(accountIDEnvVar)
 
 	if budgetID == "" {
-		log.Printf("DEBUG: No YNAB budget ID found for user %s (env var: %s)", userID, budgetIDEnvVar)
+		logger.Debugf("No YNAB budget ID found for user %s (env var: %s)", userID, budgetIDEnvVar)
 		return
 	}
 
 	if accountID == "" {
-		log.Printf("DEBUG: No YNAB account ID found for user %s (env var: %s)", userID, accountIDEnvVar)
+		logger.Debugf("No YNAB account ID found for user %s (env var: %s)", userID, accountIDEnvVar)
 		return
 	}
 
-	log.Printf("DEBUG: Found complete YNAB credentials for user %s, updating database", userID)
-	log.Printf("DEBUG: Using budget ID: %s, account ID: %s", budgetID, accountID)
+	logger.Debugf("Found complete YNAB credentials for user %s, updating database", userID)
+	logger.Debugf("Using budget ID: %s, account ID: %s", budgetID, accountID)
 
 	// Ensure user exists in users table
 	_, err = database.DB.Exec(`
@@ -130,9 +131,9 @@ func setupYNABFromEnvForUser(userID string) {
 		VALUES (?, ?, ?)
 	`, userID, fmt.Sprintf("user_%s", userID), fmt.Sprintf("User %s", userID))
 	if err != nil {
-		log.Printf("DEBUG: Error ensuring user exists: %v", err)
+		logger.Warnf("Error ensuring user exists: %v", err)
 	} else {
-		log.Printf("DEBUG: Successfully ensured user %s exists in users table", userID)
+		logger.Debugf("Successfully ensured user %s exists in users table", userID)
 	}
 
 	// Create config update request
@@ -145,11 +146,11 @@ func setupYNABFromEnvForUser(userID string) {
 	// Update YNAB config with encrypted values
 	err = models.UpsertYNABConfig(database.DB, &configRequest, userID)
 	if err != nil {
-		log.Printf("DEBUG: Error updating YNAB config for user %s: %v", userID, err)
+		logger.Errorf("Error updating YNAB config for user %s: %v", userID, err)
 		return
 	}
 
-	log.Printf("DEBUG: Successfully updated YNAB config for user %s with encrypted values", userID)
+	logger.Debugf("Successfully updated YNAB config for user %s with encrypted values", userID)
 
 	// Also update legacy table for backward compatibility
 	// Store token with 'enc:' prefix for local dev
@@ -170,11 +171,11 @@ func setupYNABFromEnvForUser(userID string) {
 	`, userID, hashedToken, budgetID, accountID)
 
 	if err != nil {
-		log.Printf("DEBUG: Error updating legacy YNAB settings for user %s: %v", userID, err)
+		logger.Warnf("Error updating legacy YNAB settings for user %s: %v", userID, err)
 	} else {
 		rowsAffected, _ := result.RowsAffected()
 		if rowsAffected > 0 {
-			log.Printf("DEBUG: Successfully updated legacy YNAB settings for user %s", userID)
+			logger.Debugf("Successfully updated legacy YNAB settings for user %s", userID)
 		}
 	}
 }
@@ -280,12 +281,12 @@ func InitialSync() {
 		}
 
 		// List all environment variables related to YNAB
-		log.Println("DEBUG: Checking for YNAB environment variables:")
+		logger.Debugf("Checking for YNAB environment variables:")
 		for _, env := range os.Environ() {
 			if strings.Contains(strings.ToUpper(env), "YNAB") {
 				parts := strings.SplitN(env, "=", 2)
 				if len(parts) > 0 {
-					log.Printf("DEBUG: Found YNAB env var: %s", parts[0])
+					logger.Debugf("Found YNAB env var: %s", parts[0])
 				}
 			}
 		}
@@ -11,12 +11,13 @@ import (
 	"time"
 
 	"bennwallet/backend/database"
+	"bennwallet/backend/logger"
 	"bennwallet/backend/models"
 )
 
 // SyncYNABCategories syncs YNAB categories for a specific user
 func SyncYNABCategories(userID string, budgetID string) error {
-	log.Printf("DEBUG: Starting YNAB categories sync for user %s with budget ID %s", userID, budgetID)
+	logger.Debugf("Starting YNAB categories sync for user %s with budget ID %s", userID, budgetID)
 
 	// Get YNAB token directly from database for now
 	var token string
@@ -30,7 +31,7 @@ func SyncYNABCategories(userID string, budgetID string) error {
 
 		if err != nil {
 			if strings.Contains(err.Error(), "database is locked") {
-				log.Printf("DEBUG: Database locked when getting token, retry %d/3", retries+1)
+				logger.Debugf("Database locked when getting token, retry %d/3", retries+1)
 				time.Sleep(time.Duration(retries+1) * 500 * time.Millisecond)
 				continue
 			}
@@ -42,24 +43,24 @@ func SyncYNABCategories(userID string, budgetID string) error {
 	}
 
 	if tokenErr != nil {
-		log.Printf("DEBUG: Error getting YNAB token for user %s: %v", userID, tokenErr)
+		logger.Errorf("Error getting YNAB token for user %s: %v", userID, tokenErr)
 		return fmt.Errorf("error getting YNAB token: %w", tokenErr)
 	}
 
-	log.Printf("DEBUG: Successfully retrieved token for user %s", userID)
+	logger.Debugf("Successfully retrieved token for user %s", userID)
 
 	// If token starts with "enc:", remove the prefix
 	if strings.HasPrefix(token, "enc:") {
 		token = strings.TrimPrefix(token, "enc:")
-		log.Printf("DEBUG: Removed 'enc:' prefix from token")
+		logger.Debugf("Removed 'enc:' prefix from token")
 	}
 
 	url := fmt.Sprintf("https://api.ynab.com/v1/budgets/%s/categories", budgetID)
-	log.Printf("DEBUG: Making request to YNAB API: %s", url)
+	logger.Debugf("Making request to YNAB API: %s", url)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		log.Printf("DEBUG: Error creating HTTP request: %v", err)
+		logger.Errorf("Error creating HTTP request: %v", err)
 		return fmt.Errorf("error creating request: %w", err)
 	}
 
@@ -68,35 +69,35 @@ func SyncYNABCategories(userID string, budgetID string) error {
 	client := &http.Client{Timeout: 10 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Printf("DEBUG: Error making HTTP request to YNAB API: %v", err)
+		logger.Errorf("Error making HTTP request to YNAB API: %v", err)
 		return fmt.Errorf("error making request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	log.Printf("DEBUG: YNAB API response status: %d %s", resp.StatusCode, resp.Status)
+	logger.Debugf("YNAB API response status: %d %s", resp.StatusCode, resp.Status)
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := ioutil.ReadAll(resp.Body)
-		log.Printf("DEBUG: YNAB API error response: %s", string(body))
+		logger.Errorf("YNAB API error response: %s", string(body))
 		return fmt.Errorf("YNAB API returned status code %d: %s", resp.StatusCode, string(body))
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("DEBUG: Error reading response body: %v", err)
+		logger.Errorf("Error reading response body: %v", err)
 		return fmt.Errorf("error reading response body: %w", err)
 	}
 
-	log.Printf("DEBUG: Received %d bytes from YNAB API", len(body))
+	logger.Debugf("Received %d bytes from YNAB API", len(body))
 
 	var categoryResponse models.YNABCategoryResponse
 	if err := json.Unmarshal(body, &categoryResponse); err != nil {
-		log.Printf("DEBUG: Error unmarshaling response: %v", err)
-		log.Printf("DEBUG: Response body: %s", string(body))
+		logger.Errorf("Error unmarshaling response: %v", err)
+		logger.Debugf("Response body: %s", string(body))
 		return fmt.Errorf("error unmarshaling response: %w", err)
 	}
 
-	log.Printf("DEBUG: Successfully unmarshaled YNAB categories response")
+	logger.Debugf("Successfully unmarshaled YNAB categories response")
 
 	// Count categories received
 	var totalCategories int
@@ -106,7 +107,7 @@ func SyncYNABCategories(userID string, budgetID string) error {
 		}
 	}
 
-	log.Printf("DEBUG: Received %d category groups and %d total categories",
+	logger.Debugf("Received %d category groups and %d total categories",
 		len(categoryResponse.Data.CategoryGroups), totalCategories)
 
 	// Retry the database transaction up to 3 times
@@ -118,7 +119,7 @@ func SyncYNABCategories(userID string, budgetID string) error {
 		}
 
 		if strings.Contains(dbErr.Error(), "database is locked") {
-			log.Printf("DEBUG: Database locked during transaction, retry %d/3", attempt+1)
+			logger.Debugf("Database locked during transaction, retry %d/3", attempt+1)
 			time.Sleep(time.Duration(attempt+1) * time.Second)
 			continue
 		}
@@ -140,7 +141,7 @@ func processCategoriesTransaction(userID string, categoryResponse models.YNABCat
 	// Begin transaction
 	tx, err := database.DB.Begin()
 	if err != nil {
-		log.Printf("DEBUG: Error beginning database transaction: %v", err)
+		logger.Errorf("Error beginning database transaction: %v", err)
 		return fmt.Errorf("error beginning transaction: %w", err)
 	}
 	defer func() {
@@ -158,7 +159,7 @@ func processCategoriesTransaction(userID string, categoryResponse models.YNABCat
 		VALUES (?, ?, ?, ?)
 	`)
 	if err != nil {
-		log.Printf("DEBUG: Error preparing category group statement: %v", err)
+		logger.Errorf("Error preparing category group statement: %v", err)
 		return fmt.Errorf("error preparing statement: %w", err)
 	}
 	defer stmtCategoryGroup.Close()
@@ -168,7 +169,7 @@ func processCategoriesTransaction(userID string, categoryResponse models.YNABCat
 		VALUES (?, ?, ?, ?, ?)
 	`)
 	if err != nil {
-		log.Printf("DEBUG: Error preparing category statement: %v", err)
+		logger.Errorf("Error preparing category statement: %v", err)
 		return fmt.Errorf("error preparing statement: %w", err)
 	}
 	defer stmtCategory.Close()
@@ -183,7 +184,7 @@ func processCategoriesTransaction(userID string, categoryResponse models.YNABCat
 		// Insert or update category group
 		_, err = stmtCategoryGroup.Exec(group.ID, group.Name, userID, syncTime)
 		if err != nil {
-			log.Printf("DEBUG: Error inserting category group %s: %v", group.ID, err)
+			logger.Errorf("Error inserting category group %s: %v", group.ID, err)
 			return fmt.Errorf("error inserting category group: %w", err)
 		}
 
@@ -198,7 +199,7 @@ func processCategoriesTransaction(userID string, categoryResponse models.YNABCat
 
 			_, err = stmtCategory.Exec(cat.ID, group.ID, cat.Name, userID, syncTime)
 			if err != nil {
-				log.Printf("DEBUG: Error inserting category %s: %v", cat.ID, err)
+				logger.Errorf("Error inserting category %s: %v", cat.ID, err)
 				return fmt.Errorf("error inserting category: %w", err)
 			}
 
@@ -206,37 +207,39 @@ func processCategoriesTransaction(userID string, categoryResponse models.YNABCat
 		}
 	}
 
-	log.Printf("DEBUG: Inserted or updated %d category groups and %d categories for user %s",
+	logger.Debugf("Inserted or updated %d category groups and %d categories for user %s",
 		insertedGroups, insertedCategories, userID)
 
 	// Convert YNAB categories to local categories for use in the transaction form
 	result, err := tx.Exec(`
-		INSERT INTO categories (name, description, user_id, color)
+		INSERT INTO categories (name, description, user_id, color, ynab_category_id)
 		SELECT y.name, 'Synced from YNAB', ?, COALESCE(
 			(SELECT color FROM categories WHERE name = y.name AND user_id = ? LIMIT 1),
-			?) 
+			?), y.id
 		FROM ynab_categories y
 		WHERE y.user_id = ?
 		ON CONFLICT(name, user_id) DO UPDATE SET
 			description = 'Synced from YNAB',
+			ynab_category_id = excluded.ynab_category_id,
+			archived = 0,
 			last_updated = ?
 	`, userID, userID, generateRandomColor(), userID, syncTime)
 
 	if err != nil {
-		log.Printf("DEBUG: Error converting to local categories: %v", err)
+		logger.Errorf("Error converting to local categories: %v", err)
 		return fmt.Errorf("error converting to local categories: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
-	log.Printf("DEBUG: Converted %d YNAB categories to local categories", rowsAffected)
+	logger.Debugf("Converted %d YNAB categories to local categories", rowsAffected)
 
 	err = tx.Commit()
 	if err != nil {
-		log.Printf("DEBUG: Error committing transaction: %v", err)
+		logger.Errorf("Error committing transaction: %v", err)
 		return fmt.Errorf("error committing transaction: %w", err)
 	}
 
-	log.Printf("DEBUG: Successfully committed transaction for user %s", userID)
+	logger.Debugf("Successfully committed transaction for user %s", userID)
 	return nil
 }
 
@@ -289,10 +292,10 @@ func init() {
 
 	for _, path := range envPaths {
 		if _, err := os.Stat(path); err == nil {
-			log.Printf("DEBUG: Found .env file at %s", path)
+			logger.Debugf("Found .env file at %s", path)
 			content, err := ioutil.ReadFile(path)
 			if err == nil {
-				log.Printf("DEBUG: Successfully read .env file")
+				logger.Debugf("Successfully read .env file")
 				lines := strings.Split(string(content), "\n")
 				for _, line := range lines {
 					if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
@@ -305,16 +308,16 @@ func init() {
 						os.Setenv(key, value)
 						if strings.Contains(strings.ToUpper(key), "YNAB") {
 							// Log the key but not the value for security
-							log.Printf("DEBUG: Set environment variable: %s", key)
+							logger.Debugf("Set environment variable: %s", key)
 						}
 					}
 				}
 				return // Exit after loading the first found .env file
 			} else {
-				log.Printf("DEBUG: Error reading .env file: %v", err)
+				logger.Warnf("Error reading .env file: %v", err)
 			}
 		}
 	}
 
-	log.Printf("DEBUG: No .env file found in search paths: %v", envPaths)
+	logger.Debugf("No .env file found in search paths: %v", envPaths)
 }
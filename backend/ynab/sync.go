@@ -127,7 +127,9 @@ func startBackgroundSync(db *sql.DB) {
 				// First sync
 				shouldSync = true
 			} else {
-				// Check if enough time has passed since last sync
+				// Check if enough time has passed since last sync. Frequencies
+				// stored before the bounds existed are clamped here.
+				syncFrequency = models.ClampYNABSyncFrequency(syncFrequency)
 				nextSync := lastSyncTime.Time.Add(time.Duration(syncFrequency) * time.Minute)
 				shouldSync = time.Now().After(nextSync)
 			}
@@ -28,6 +28,7 @@ cd "$(dirname "$0")"
 # Export environment variables
 export APP_ENV=development
 export RESET_DB=true
+export RESET_DB_GRACE_SECONDS=0
 
 # Run the app temporarily to create the database
 echo "Running backend with RESET_DB=true to create a fresh database..."